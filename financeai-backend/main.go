@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
 	"financeai-backend/routes"
+	"financeai-backend/services"
 )
 
 func main() {
@@ -32,6 +36,53 @@ func main() {
         fmt.Printf("🔑 API Key length: %d characters\n", len(openAIKey))
     }
 
+    // Receipts and other transaction attachments are stored on local disk
+    services.ConfigureAttachmentStorage(services.NewLocalAttachmentStorage(os.Getenv("ATTACHMENTS_DIR")))
+
+    // Email notifications go out through SMTP when a mail server is set
+    smtpPort, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+    services.ConfigureSMTP(services.SMTPConfig{
+        Host:     os.Getenv("SMTP_HOST"),
+        Port:     smtpPort,
+        Username: os.Getenv("SMTP_USERNAME"),
+        Password: os.Getenv("SMTP_PASSWORD"),
+        From:     os.Getenv("SMTP_FROM"),
+    })
+
+    // Provider responses and insights are cached in memory, or on disk with CACHE_BACKEND=disk
+    cacheSize, _ := strconv.Atoi(os.Getenv("CACHE_SIZE"))
+    if err := services.ConfigureCache(os.Getenv("CACHE_BACKEND"), cacheSize, os.Getenv("CACHE_DIR")); err != nil {
+        fmt.Printf("❌ Failed to configure cache, using memory: %v\n", err)
+    }
+
+    // Daily exchange rates for converting totals to each customer's reporting currency
+    ratesFile := os.Getenv("EXCHANGE_RATES_FILE")
+    if ratesFile == "" {
+        ratesFile = services.DefaultExchangeRatesFile
+    }
+    if err := services.ConfigureExchangeRates(ratesFile); err != nil {
+        fmt.Printf("❌ Failed to load exchange rates, reporting in USD only: %v\n", err)
+    }
+
+    // Background jobs: Nessie sync, aggregates, nightly insights, digests and cleanup
+    scheduler := services.DefaultScheduler()
+    scheduler.SetStatePath(os.Getenv("SCHEDULER_STATE_FILE"))
+    var nessieCustomerIDs []string
+    for _, id := range strings.Split(os.Getenv("NESSIE_CUSTOMER_IDS"), ",") {
+        if id = strings.TrimSpace(id); id != "" {
+            nessieCustomerIDs = append(nessieCustomerIDs, id)
+        }
+    }
+    if err := services.RegisterDefaultJobs(scheduler, services.JobsConfig{
+        OpenAIKey:         openAIKey,
+        NessieKey:         apiKey,
+        NessieCustomerIDs: nessieCustomerIDs,
+    }); err != nil {
+        fmt.Printf("❌ Failed to register background jobs: %v\n", err)
+        os.Exit(1)
+    }
+    scheduler.Start(context.Background())
+
     // Set Gin to release mode for production
     gin.SetMode(gin.ReleaseMode)
     
@@ -45,8 +96,9 @@ func main() {
     // Add CORS middleware
     r.Use(func(c *gin.Context) {
         c.Header("Access-Control-Allow-Origin", "*")
-        c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-        c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+        c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+        c.Header("Access-Control-Expose-Headers", "Idempotent-Replayed, X-Request-ID, API-Version, Deprecation, Sunset, Link")
+        c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key, X-Request-ID, API-Version")
         
         if c.Request.Method == "OPTIONS" {
             c.AbortWithStatus(204)
@@ -67,6 +119,11 @@ func main() {
         }
     }()
     
+    // Check responses against the OpenAPI description, logging any that don't match
+    routes.ConfigureContractChecks(os.Getenv("OPENAPI_CHECK_RESPONSES") == "true")
+    // Keep serving the deprecated /api routes alongside /api/v1 until clients have moved
+    routes.ConfigureLegacyAPI(os.Getenv("LEGACY_API_ENABLED") != "false")
+
     routes.RegisterRoutes(r, apiKey, openAIKey)
     fmt.Printf("✅ API routes registered successfully\n")
 
@@ -31,26 +31,80 @@ type Account struct {
 	Balance       int    `json:"balance"`
 	AccountNumber string `json:"account_number"`
 	CustomerID    string `json:"customer_id"`
+	// Currency is the ISO 4217 code balances are in
+	Currency string `json:"currency,omitempty"`
+	// OriginalBalance and OriginalCurrency are set when the balance has been
+	// converted to the customer's reporting currency
+	OriginalBalance  int    `json:"original_balance,omitempty"`
+	OriginalCurrency string `json:"original_currency,omitempty"`
 }
 
 // Transaction represents a transaction from Nessie API
 type Transaction struct {
-	ID              string    `json:"_id"`
-	Type            string    `json:"type"`
-	Amount          float64   `json:"amount"`
-	Description     string    `json:"description"`
-	TransactionDate time.Time `json:"transaction_date"`
-	Status          string    `json:"status"`
-	AccountID       string    `json:"account_id"`
-	MerchantID      string    `json:"merchant_id,omitempty"`
-	Merchant        Merchant  `json:"merchant,omitempty"`
+	ID              string             `json:"_id"`
+	Type            string             `json:"type"`
+	Amount          float64            `json:"amount"`
+	Description     string             `json:"description"`
+	TransactionDate time.Time          `json:"transaction_date"`
+	Status          string             `json:"status"`
+	AccountID       string             `json:"account_id"`
+	MerchantID      string             `json:"merchant_id,omitempty"`
+	Merchant        Merchant           `json:"merchant,omitempty"`
+	Splits          []TransactionSplit `json:"splits,omitempty"`
+	Tags            []string           `json:"tags,omitempty"`
+	Notes           string             `json:"notes,omitempty"`
+	Attachments     []Attachment       `json:"attachments,omitempty"`
+	IsTransfer      bool               `json:"is_transfer,omitempty"`
+	TransferType    string             `json:"transfer_type,omitempty"`
+	TransferPairID  string             `json:"transfer_pair_id,omitempty"`
+	IncomeType      string             `json:"income_type,omitempty"`
+	Location        *Location          `json:"location,omitempty"`
+	// Currency is the ISO 4217 code the amount is in
+	Currency string `json:"currency,omitempty"`
+	// OriginalAmount, OriginalCurrency and ExchangeRate are set when the
+	// amount has been converted to the customer's reporting currency
+	OriginalAmount   float64 `json:"original_amount,omitempty"`
+	OriginalCurrency string  `json:"original_currency,omitempty"`
+	ExchangeRate     float64 `json:"exchange_rate,omitempty"`
+}
+
+// Location represents where a card transaction took place
+type Location struct {
+	City    string `json:"city,omitempty"`
+	State   string `json:"state,omitempty"`
+	Country string `json:"country,omitempty"`
+}
+
+// Attachment represents a file, such as a receipt, attached to a transaction
+type Attachment struct {
+	ID          string    `json:"id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+// TransactionSplit represents one category allocation of a split transaction.
+// The amounts of all splits add up to the parent transaction's amount.
+type TransactionSplit struct {
+	Category string  `json:"category"`
+	Amount   float64 `json:"amount"`
+	Note     string  `json:"note,omitempty"`
 }
 
 // Merchant represents merchant information
 type Merchant struct {
-	ID       string `json:"_id"`
-	Name     string `json:"name"`
-	Category string `json:"category,omitempty"`
+	ID       string   `json:"_id"`
+	Name     string   `json:"name"`
+	Category string   `json:"category,omitempty"`
+	LogoKey  string   `json:"logo_key,omitempty"`
+	Geocode  *Geocode `json:"geocode,omitempty"`
+}
+
+// Geocode is where a merchant is, as latitude and longitude
+type Geocode struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
 }
 
 // DashboardData aggregates all data needed for the dashboard
@@ -59,6 +113,21 @@ type DashboardData struct {
 	Accounts     []Account     `json:"accounts"`
 	Transactions []Transaction `json:"transactions"`
 	SpendingData SpendingData  `json:"spending_data"`
+	// Currency and Locale are the reporting currency spending data is in and
+	// how the customer wants amounts formatted
+	Currency string `json:"currency,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+	// Partial is set when some accounts' transactions couldn't be fetched
+	Partial        bool                  `json:"partial,omitempty"`
+	FailedAccounts []AccountFetchFailure `json:"failed_accounts,omitempty"`
+}
+
+// AccountFetchFailure describes an account whose data couldn't be fetched
+type AccountFetchFailure struct {
+	AccountID  string `json:"account_id"`
+	Error      string `json:"error"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Attempts   int    `json:"attempts"`
 }
 
 // SpendingData represents processed spending information
@@ -109,7 +178,538 @@ type SpendingInsight struct {
 }
 
 // NessieResponse represents the standard Nessie API response format
-type NessieResponse struct {
-	Results []interface{} `json:"results"`
-	Total   int           `json:"total"`
+type NessieResponse[T any] struct {
+	Results []T `json:"results"`
+	Total   int `json:"total"`
+}
+
+// NessieDecodeError describes a record in a Nessie response that couldn't be
+// decoded
+type NessieDecodeError struct {
+	Index    int    `json:"index"`
+	RecordID string `json:"record_id,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Error    string `json:"error"`
+}
+
+// NessieDecodeResult reports the records a Nessie endpoint last returned that
+// couldn't be decoded
+type NessieDecodeResult struct {
+	Endpoint  string              `json:"endpoint"`
+	Records   int                 `json:"records"`
+	Decoded   int                 `json:"decoded"`
+	Errors    []NessieDecodeError `json:"errors"`
+	DecodedAt time.Time           `json:"decoded_at"`
+}
+
+// MonthlyReport represents the data behind a printable monthly statement
+type MonthlyReport struct {
+	CustomerID       string             `json:"customer_id"`
+	CustomerName     string             `json:"customer_name"`
+	Month            string             `json:"month"`
+	Income           float64            `json:"income"`
+	Expenses         float64            `json:"expenses"`
+	Net              float64            `json:"net"`
+	CategorySpending []CategorySpending `json:"category_spending"`
+	TopMerchants     []MerchantSpending `json:"top_merchants"`
+	TagSpending      []TagSpending      `json:"tag_spending"`
+	BudgetVariance   []BudgetVariance   `json:"budget_variance"`
+	Changes          []CategoryChange   `json:"changes"`
+	Insights         []SpendingInsight  `json:"insights"`
+	Currency         string             `json:"currency,omitempty"`
+	Locale           string             `json:"locale,omitempty"`
+	GeneratedAt      time.Time          `json:"generated_at"`
+}
+
+// MerchantSpending represents spending at a single merchant
+type MerchantSpending struct {
+	Merchant string  `json:"merchant"`
+	Amount   float64 `json:"amount"`
+	Count    int     `json:"count"`
+}
+
+// TagSpending represents spending on transactions with a user tag
+type TagSpending struct {
+	Tag    string  `json:"tag"`
+	Amount float64 `json:"amount"`
+	Count  int     `json:"count"`
+}
+
+// BudgetVariance represents spending against a category or tag budget.
+// Tag budgets use a "tag:" prefix on Category, e.g. "tag:work-trip".
+type BudgetVariance struct {
+	Category string  `json:"category"`
+	Budget   float64 `json:"budget"`
+	Spent    float64 `json:"spent"`
+	Variance float64 `json:"variance"`
+}
+
+// CategoryChange represents the change in category spending vs the prior month
+type CategoryChange struct {
+	Category      string  `json:"category"`
+	Previous      float64 `json:"previous"`
+	Current       float64 `json:"current"`
+	Change        float64 `json:"change"`
+	PercentChange float64 `json:"percent_change"`
+}
+
+// TransactionFilter describes the filters, sort order and page requested
+// for a transaction list. Zero values mean "no filter".
+type TransactionFilter struct {
+	StartDate time.Time `json:"start_date,omitempty"` // inclusive
+	EndDate   time.Time `json:"end_date,omitempty"`   // exclusive
+	AccountID string    `json:"account_id,omitempty"`
+	Category  string    `json:"category,omitempty"`
+	Merchant  string    `json:"merchant,omitempty"`
+	MinAmount *float64  `json:"min_amount,omitempty"` // compared against the absolute amount
+	MaxAmount *float64  `json:"max_amount,omitempty"` // compared against the absolute amount
+	Status    string    `json:"status,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`      // transactions must have every tag
+	Transfers string    `json:"transfers,omitempty"` // include (default), exclude or only
+	Search    string    `json:"search,omitempty"`
+	SortBy    string    `json:"sort_by,omitempty"`  // date, amount, merchant, category or description
+	SortDir   string    `json:"sort_dir,omitempty"` // asc or desc
+	Cursor    string    `json:"cursor,omitempty"`
+	Limit     int       `json:"limit,omitempty"`
+}
+
+// TransactionPage represents one page of a filtered transaction list
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+	Total        int           `json:"total"`
+	Count        int           `json:"count"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+	HasMore      bool          `json:"has_more"`
+	// Partial is set when some accounts' transactions couldn't be fetched
+	Partial        bool                  `json:"partial,omitempty"`
+	FailedAccounts []AccountFetchFailure `json:"failed_accounts,omitempty"`
+}
+
+// SearchResult represents a transaction matched by a full-text search
+type SearchResult struct {
+	Transaction Transaction       `json:"transaction"`
+	Score       float64           `json:"score"`
+	Highlights  map[string]string `json:"highlights"`
+}
+
+// CashflowSummary represents income vs expenses over a range of months
+type CashflowSummary struct {
+	Months        []MonthlyCashflow  `json:"months"`
+	TotalIncome   float64            `json:"total_income"`
+	TotalExpenses float64            `json:"total_expenses"`
+	Net           float64            `json:"net"`
+	SavingsRate   float64            `json:"savings_rate"`
+	IncomeByType  map[string]float64 `json:"income_by_type"`
+	Payroll       *PayrollCadence    `json:"payroll,omitempty"`
+}
+
+// MonthlyCashflow represents income and expenses for a single month
+type MonthlyCashflow struct {
+	Month       string  `json:"month"`
+	Income      float64 `json:"income"`
+	Expenses    float64 `json:"expenses"`
+	Net         float64 `json:"net"`
+	SavingsRate float64 `json:"savings_rate"`
+}
+
+// PayrollCadence describes how often a customer gets paid
+type PayrollCadence struct {
+	Frequency        string     `json:"frequency"`
+	Payer            string     `json:"payer"`
+	AverageAmount    float64    `json:"average_amount"`
+	Occurrences      int        `json:"occurrences"`
+	AccountID        string     `json:"account_id"`
+	LastDate         time.Time  `json:"last_date"`
+	NextExpectedDate *time.Time `json:"next_expected_date,omitempty"`
+}
+
+// BalanceSnapshot represents an account's balance at the end of a day
+type BalanceSnapshot struct {
+	AccountID string    `json:"account_id"`
+	Date      time.Time `json:"date"`
+	Balance   float64   `json:"balance"`
+	Currency  string    `json:"currency,omitempty"`
+	Source    string    `json:"source"`
+}
+
+// ManualAccount represents an asset or liability the customer tracks by hand,
+// such as a vehicle or a student loan
+type ManualAccount struct {
+	ID          string    `json:"_id"`
+	CustomerID  string    `json:"customer_id"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Balance     float64   `json:"balance"`
+	Currency    string    `json:"currency"`
+	IsLiability bool      `json:"is_liability"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NetWorthSummary represents a customer's net worth and how it has changed
+type NetWorthSummary struct {
+	Assets      float64             `json:"assets"`
+	Liabilities float64             `json:"liabilities"`
+	NetWorth    float64             `json:"net_worth"`
+	Change      float64             `json:"change"`
+	History     []NetWorthPoint     `json:"history"`
+	Breakdown   []NetWorthBreakdown `json:"breakdown"`
+	Accounts    []AccountBalance    `json:"accounts"`
+	Currency    string              `json:"currency,omitempty"`
+}
+
+// NetWorthPoint represents net worth at the end of a day
+type NetWorthPoint struct {
+	Date        time.Time `json:"date"`
+	Assets      float64   `json:"assets"`
+	Liabilities float64   `json:"liabilities"`
+	NetWorth    float64   `json:"net_worth"`
+}
+
+// NetWorthBreakdown represents the total held in one type of account
+type NetWorthBreakdown struct {
+	Type           string  `json:"type"`
+	Classification string  `json:"classification"`
+	Balance        float64 `json:"balance"`
+	Accounts       int     `json:"accounts"`
+}
+
+// AccountBalance represents one account's contribution to net worth
+type AccountBalance struct {
+	AccountID      string  `json:"account_id"`
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	Classification string  `json:"classification"`
+	Balance        float64 `json:"balance"`
+	Manual         bool    `json:"manual"`
+}
+
+// Debt represents a liability account and the terms used to plan its payoff
+type Debt struct {
+	AccountID      string  `json:"account_id"`
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	Balance        float64 `json:"balance"`
+	APR            float64 `json:"apr"`
+	MinimumPayment float64 `json:"minimum_payment"`
+	DueDay         int     `json:"due_day"`
+	Manual         bool    `json:"manual"`
+	Configured     bool    `json:"configured"`
+}
+
+// PayoffPlan represents the result of paying down debts with one strategy
+type PayoffPlan struct {
+	Strategy      string        `json:"strategy"`
+	MonthlyBudget float64       `json:"monthly_budget"`
+	Order         []string      `json:"order"`
+	Months        int           `json:"months"`
+	PayoffDate    time.Time     `json:"payoff_date"`
+	TotalInterest float64       `json:"total_interest"`
+	TotalPaid     float64       `json:"total_paid"`
+	Debts         []DebtPayoff  `json:"debts"`
+	Schedule      []PayoffMonth `json:"schedule"`
+	// Skipped lists debts with a balance that were left out of the plan
+	// because their terms aren't set
+	Skipped []SkippedDebt `json:"skipped,omitempty"`
+}
+
+// SkippedDebt represents a debt a payoff plan couldn't include
+type SkippedDebt struct {
+	AccountID string `json:"account_id"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+}
+
+// DebtPayoff represents when a single debt is paid off under a plan
+type DebtPayoff struct {
+	AccountID    string    `json:"account_id"`
+	Name         string    `json:"name"`
+	PayoffDate   time.Time `json:"payoff_date"`
+	Months       int       `json:"months"`
+	InterestPaid float64   `json:"interest_paid"`
+}
+
+// PayoffMonth represents the payments made in one month of a plan
+type PayoffMonth struct {
+	Month            string        `json:"month"`
+	Payments         []DebtPayment `json:"payments"`
+	TotalPayment     float64       `json:"total_payment"`
+	TotalInterest    float64       `json:"total_interest"`
+	RemainingBalance float64       `json:"remaining_balance"`
+}
+
+// DebtPayment represents one payment toward a debt
+type DebtPayment struct {
+	AccountID string    `json:"account_id"`
+	DueDate   time.Time `json:"due_date"`
+	Payment   float64   `json:"payment"`
+	Interest  float64   `json:"interest"`
+	Principal float64   `json:"principal"`
+	Balance   float64   `json:"balance"`
+}
+
+// RecurringCharge represents a charge detected repeating on a regular schedule
+type RecurringCharge struct {
+	ID            string    `json:"_id"`
+	MerchantID    string    `json:"merchant_id"`
+	Name          string    `json:"name"`
+	Category      string    `json:"category"`
+	AccountID     string    `json:"account_id"`
+	Frequency     string    `json:"frequency"`
+	AverageAmount float64   `json:"average_amount"`
+	LastAmount    float64   `json:"last_amount"`
+	LastDate      time.Time `json:"last_date"`
+	NextDueDate   time.Time `json:"next_due_date"`
+	Occurrences   int       `json:"occurrences"`
+}
+
+// Bill represents a bill the customer entered, such as rent or tuition
+type Bill struct {
+	ID         string    `json:"_id"`
+	CustomerID string    `json:"customer_id"`
+	Name       string    `json:"name"`
+	MerchantID string    `json:"merchant_id"`
+	Category   string    `json:"category"`
+	Amount     float64   `json:"amount"`
+	AccountID  string    `json:"account_id"`
+	Frequency  string    `json:"frequency"`
+	DueDate    time.Time `json:"due_date"`
+	AutoPay    bool      `json:"auto_pay"`
+}
+
+// UpcomingBill represents one bill due on a date, with the projected balance
+// of the account paying it
+type UpcomingBill struct {
+	BillID            string    `json:"bill_id"`
+	Source            string    `json:"source"`
+	Name              string    `json:"name"`
+	Category          string    `json:"category"`
+	Amount            float64   `json:"amount"`
+	DueDate           time.Time `json:"due_date"`
+	Frequency         string    `json:"frequency"`
+	AccountID         string    `json:"account_id"`
+	AccountName       string    `json:"account_name"`
+	AutoPay           bool      `json:"auto_pay"`
+	ProjectedBalance  *float64  `json:"projected_balance,omitempty"`
+	InsufficientFunds bool      `json:"insufficient_funds"`
+	Shortfall         float64   `json:"shortfall,omitempty"`
+}
+
+// Alert represents an unusual transaction or spending pattern flagged for the
+// customer to review
+type Alert struct {
+	ID            string          `json:"_id"`
+	CustomerID    string          `json:"customer_id"`
+	Type          string          `json:"type"`
+	TransactionID string          `json:"transaction_id,omitempty"`
+	Category      string          `json:"category,omitempty"`
+	Amount        float64         `json:"amount"`
+	Score         float64         `json:"score"`
+	Reason        string          `json:"reason"`
+	Signals       []AnomalySignal `json:"signals"`
+	Date          time.Time       `json:"date"`
+	Dismissed     bool            `json:"dismissed"`
+	Feedback      *AlertFeedback  `json:"feedback,omitempty"`
+}
+
+// AnomalySignal represents one reason a transaction looks unusual
+type AnomalySignal struct {
+	Type   string  `json:"type"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// AlertFeedback represents the customer's response when dismissing an alert
+type AlertFeedback struct {
+	Verdict     string    `json:"verdict"`
+	Note        string    `json:"note,omitempty"`
+	DismissedAt time.Time `json:"dismissed_at"`
+}
+
+// Event represents something that happened to a customer's finances, which
+// notifications and other subscribers react to
+type Event struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	CustomerID string                 `json:"customer_id"`
+	Key        string                 `json:"key"`
+	Title      string                 `json:"title"`
+	Message    string                 `json:"message"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// Notification represents an event delivered to a customer
+type Notification struct {
+	ID         string                 `json:"_id"`
+	CustomerID string                 `json:"customer_id"`
+	EventType  string                 `json:"event_type"`
+	Title      string                 `json:"title"`
+	Message    string                 `json:"message"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	Read       bool                   `json:"read"`
+	Deliveries []NotificationDelivery `json:"deliveries"`
+}
+
+// NotificationDelivery represents the result of sending a notification on one channel
+type NotificationDelivery struct {
+	Channel string `json:"channel"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CurrencyPreferences represents the currency a customer's totals are
+// reported in and the locale amounts are formatted for
+type CurrencyPreferences struct {
+	CustomerID        string `json:"customer_id"`
+	ReportingCurrency string `json:"reporting_currency"`
+	Locale            string `json:"locale"`
+}
+
+// ExchangeRateTable describes the loaded exchange rates
+type ExchangeRateTable struct {
+	Base       string    `json:"base"`
+	Currencies []string  `json:"currencies"`
+	FirstDate  time.Time `json:"first_date"`
+	LastDate   time.Time `json:"last_date"`
+	Days       int       `json:"days"`
+}
+
+// NotificationPreferences represents which notifications a customer gets and how
+type NotificationPreferences struct {
+	CustomerID                string             `json:"customer_id"`
+	Events                    map[string]bool    `json:"events"`
+	Channels                  []string           `json:"channels"`
+	Email                     string             `json:"email,omitempty"`
+	WebhookURL                string             `json:"webhook_url,omitempty"`
+	Budgets                   map[string]float64 `json:"budgets"`
+	BudgetThresholds          []int              `json:"budget_thresholds"`
+	LargeTransactionThreshold float64            `json:"large_transaction_threshold"`
+	LowBalanceThreshold       float64            `json:"low_balance_threshold"`
+	BillReminderDays          int                `json:"bill_reminder_days"`
+	// WebhookSecret signs notifications sent to WebhookURL. It's only set
+	// in the response that turns the webhook channel on.
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+}
+
+// Goal represents a savings goal tracked against an account balance
+type Goal struct {
+	ID            string     `json:"_id"`
+	CustomerID    string     `json:"customer_id"`
+	Name          string     `json:"name"`
+	AccountID     string     `json:"account_id"`
+	TargetAmount  float64    `json:"target_amount"`
+	TargetDate    *time.Time `json:"target_date,omitempty"`
+	CurrentAmount float64    `json:"current_amount"`
+	Progress      float64    `json:"progress"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// WebhookSubscription is an integrator's endpoint that receives events
+type WebhookSubscription struct {
+	ID         string    `json:"_id"`
+	CustomerID string    `json:"customer_id"`
+	URL        string    `json:"url"`
+	Events     []string  `json:"events"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Notifications marks the subscription behind a customer's notification
+	// webhook channel, which gets the notifications they've chosen rather
+	// than the events it lists
+	Notifications bool `json:"notifications,omitempty"`
+}
+
+// WebhookDelivery records sending one event to one subscription, with every attempt
+type WebhookDelivery struct {
+	ID             string           `json:"_id"`
+	SubscriptionID string           `json:"subscription_id"`
+	CustomerID     string           `json:"customer_id"`
+	EventID        string           `json:"event_id"`
+	EventType      string           `json:"event_type"`
+	URL            string           `json:"url"`
+	Status         string           `json:"status"`
+	Attempts       []WebhookAttempt `json:"attempts"`
+	NextAttemptAt  *time.Time       `json:"next_attempt_at,omitempty"`
+	Payload        string           `json:"payload"`
+	CreatedAt      time.Time        `json:"created_at"`
+	CompletedAt    *time.Time       `json:"completed_at,omitempty"`
+}
+
+// WebhookAttempt is one try at delivering a webhook
+type WebhookAttempt struct {
+	Number     int       `json:"number"`
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// InsightSnapshot is the latest set of insights generated for a customer
+type InsightSnapshot struct {
+	CustomerID  string            `json:"customer_id"`
+	Insights    []SpendingInsight `json:"insights"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Source      string            `json:"source"`
+}
+
+// Job describes a background job, its schedule and how its runs went
+type Job struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Schedule    string     `json:"schedule"`
+	Enabled     bool       `json:"enabled"`
+	Running     bool       `json:"running"`
+	NextRunAt   *time.Time `json:"next_run_at,omitempty"`
+	LastRun     *JobRun    `json:"last_run,omitempty"`
+	History     []JobRun   `json:"history,omitempty"`
+}
+
+// JobRun records one run of a background job
+type JobRun struct {
+	ID         string     `json:"_id"`
+	Job        string     `json:"job"`
+	Trigger    string     `json:"trigger"`
+	Status     string     `json:"status"`
+	Attempts   int        `json:"attempts"`
+	Output     string     `json:"output,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// CacheStats reports how well the response cache is working
+type CacheStats struct {
+	Backend   string  `json:"backend"`
+	Entries   int     `json:"entries"`
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Sets      int64   `json:"sets"`
+	Evictions int64   `json:"evictions"`
+	HitRate   float64 `json:"hit_rate"`
+	// Namespaces breaks hits and misses down by the kind of data cached
+	Namespaces map[string]CacheNamespaceStats `json:"namespaces,omitempty"`
+}
+
+// CacheNamespaceStats counts lookups of one kind of cached data
+type CacheNamespaceStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// MoneyMovement is a transfer, deposit, withdrawal or purchase made on one of
+// a customer's accounts
+type MoneyMovement struct {
+	ID          string    `json:"_id"`
+	Kind        string    `json:"kind"`
+	CustomerID  string    `json:"customer_id"`
+	AccountID   string    `json:"account_id"`
+	PayeeID     string    `json:"payee_id,omitempty"`
+	MerchantID  string    `json:"merchant_id,omitempty"`
+	Medium      string    `json:"medium"`
+	Amount      float64   `json:"amount"`
+	Description string    `json:"description"`
+	Date        string    `json:"date"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
 }
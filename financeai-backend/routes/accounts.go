@@ -2,25 +2,41 @@ package routes
 
 import (
     "net/http"
+    "strconv"
+    "time"
 
     "github.com/gin-gonic/gin"
     "financeai-backend/services"
+    "financeai-backend/models"
 )
 
 func RegisterAccountRoutes(rg *gin.RouterGroup, apiKey string) {
     mockService := services.NewMockDataService()
+    nessieService := services.NewNessieService(apiKey)
+
+    // Demo customers are served from mock data; anyone else comes from
+    // Nessie when a key is configured
+    useNessie := func(customerId string) bool {
+        return apiKey != "" && !mockService.HasCustomer(customerId)
+    }
 
     // Get customer accounts
     rg.GET("/accounts", func(c *gin.Context) {
         customerId := c.Query("customerId")
         if customerId == "" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "customerId required"})
+            c.Error(errCustomerIDRequired)
             return
         }
 
-        accounts, err := mockService.GetCustomerAccounts(customerId)
+        var accounts []models.Account
+        var err error
+        if useNessie(customerId) {
+            accounts, err = nessieService.GetCustomerAccounts(c.Request.Context(), customerId)
+        } else {
+            accounts, err = mockService.GetCustomerAccounts(customerId)
+        }
         if err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            c.Error(withLegacyStatus(err, legacyNessieStatus(err)))
             return
         }
 
@@ -31,47 +47,94 @@ func RegisterAccountRoutes(rg *gin.RouterGroup, apiKey string) {
     rg.GET("/customer", func(c *gin.Context) {
         customerId := c.Query("customerId")
         if customerId == "" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "customerId required"})
+            c.Error(errCustomerIDRequired)
             return
         }
 
-        customer, err := mockService.GetCustomer(customerId)
+        var customer *models.Customer
+        var err error
+        if useNessie(customerId) {
+            customer, err = nessieService.GetCustomer(c.Request.Context(), customerId)
+        } else {
+            customer, err = mockService.GetCustomer(customerId)
+        }
         if err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            c.Error(withLegacyStatus(err, legacyNessieStatus(err)))
             return
         }
 
         c.JSON(http.StatusOK, customer)
     })
 
-    // Get all transactions for customer
+    // Get transactions for customer, filtered, sorted and paginated
     rg.GET("/transactions", func(c *gin.Context) {
         customerId := c.Query("customerId")
         if customerId == "" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "customerId required"})
+            c.Error(errCustomerIDRequired)
+            return
+        }
+
+        filter, err := parseTransactionFilter(c)
+        if err != nil {
+            c.Error(err)
+            return
+        }
+
+        var transactions []models.Transaction
+        var failures []models.AccountFetchFailure
+        if useNessie(customerId) {
+            transactions, failures, err = nessieService.GetAllCustomerTransactions(c.Request.Context(), customerId, filter)
+        } else {
+            transactions, err = mockService.GetAllCustomerTransactions(customerId, filter)
+        }
+        if err != nil {
+            // Say which accounts failed alongside the usual error fields.
+            // The legacy route reported every failure as a 500.
+            status, body := errorResponse(c, withLegacyStatus(err, http.StatusInternalServerError))
+            if legacyShape(c) {
+                c.JSON(status, struct {
+                    legacyError
+                    FailedAccounts []models.AccountFetchFailure `json:"failed_accounts"`
+                }{body.legacy(), failures})
+                return
+            }
+            c.JSON(status, struct {
+                apiError
+                FailedAccounts []models.AccountFetchFailure `json:"failed_accounts,omitempty"`
+            }{body, failures})
             return
         }
 
-        transactions, err := mockService.GetAllCustomerTransactions(customerId)
+        page, err := services.PaginateTransactions(transactions, filter)
         if err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            c.Error(err)
             return
         }
 
-        c.JSON(http.StatusOK, gin.H{"transactions": transactions})
+        // Some accounts didn't load; say which instead of silently leaving them out
+        page.Partial = len(failures) > 0
+        page.FailedAccounts = failures
+
+        c.JSON(http.StatusOK, page)
     })
 
     // Get complete dashboard data
     rg.GET("/dashboard", func(c *gin.Context) {
         customerId := c.Query("customerId")
         if customerId == "" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "customerId required"})
+            c.Error(errCustomerIDRequired)
             return
         }
 
-        dashboardData, err := mockService.GetDashboardData(customerId)
+        var dashboardData *models.DashboardData
+        var err error
+        if useNessie(customerId) {
+            dashboardData, err = nessieService.GetDashboardData(c.Request.Context(), customerId)
+        } else {
+            dashboardData, err = mockService.GetDashboardData(customerId)
+        }
         if err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            c.Error(withLegacyStatus(err, legacyNessieStatus(err)))
             return
         }
 
@@ -83,4 +146,68 @@ func RegisterAccountRoutes(rg *gin.RouterGroup, apiKey string) {
         customers := mockService.GetAvailableCustomers()
         c.JSON(http.StatusOK, gin.H{"customers": customers})
     })
-}
\ No newline at end of file
+}
+
+// parseTransactionFilter reads the transaction list query parameters:
+// startDate/endDate (YYYY-MM-DD, inclusive), accountId, category, merchant,
+// minAmount, maxAmount, status, tag (repeatable), transfers (include, exclude
+// or only), q, sort, order, cursor and limit
+func parseTransactionFilter(c *gin.Context) (models.TransactionFilter, error) {
+    filter := models.TransactionFilter{
+        AccountID: c.Query("accountId"),
+        Category:  c.Query("category"),
+        Merchant:  c.Query("merchant"),
+        Status:    c.Query("status"),
+        Tags:      c.QueryArray("tag"),
+        Transfers: c.Query("transfers"),
+        Search:    c.Query("q"),
+        SortBy:    c.Query("sort"),
+        SortDir:   c.Query("order"),
+        Cursor:    c.Query("cursor"),
+    }
+
+    if value := c.Query("startDate"); value != "" {
+        date, err := time.Parse("2006-01-02", value)
+        if err != nil {
+            return filter, services.NewValidationError("startDate", "startDate must be in YYYY-MM-DD format")
+        }
+        filter.StartDate = date
+    }
+    if value := c.Query("endDate"); value != "" {
+        date, err := time.Parse("2006-01-02", value)
+        if err != nil {
+            return filter, services.NewValidationError("endDate", "endDate must be in YYYY-MM-DD format")
+        }
+        filter.EndDate = date.AddDate(0, 0, 1)
+    }
+
+    for name, target := range map[string]**float64{"minAmount": &filter.MinAmount, "maxAmount": &filter.MaxAmount} {
+        if value := c.Query(name); value != "" {
+            amount, err := strconv.ParseFloat(value, 64)
+            if err != nil {
+                return filter, services.NewValidationError(name, "%s must be a number", name)
+            }
+            *target = &amount
+        }
+    }
+
+    if value := c.Query("limit"); value != "" {
+        limit, err := strconv.Atoi(value)
+        if err != nil || limit < 1 {
+            return filter, services.NewValidationError("limit", "limit must be a positive integer")
+        }
+        filter.Limit = limit
+    }
+
+    switch filter.Transfers {
+    case "", "include", "exclude", "only":
+    default:
+        return filter, services.NewValidationError("transfers", "transfers must be include, exclude or only")
+    }
+
+    if err := services.NormalizeTransactionSort(&filter); err != nil {
+        return filter, err
+    }
+
+    return filter, nil
+}
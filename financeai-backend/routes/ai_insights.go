@@ -20,25 +20,31 @@ func RegisterAIInsightRoutes(rg *gin.RouterGroup, apiKey string) {
 		}
 
 		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+			c.Error(errInvalidRequestFormat)
 			return
 		}
 
 		if request.CustomerId == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "customerId required"})
+			c.Error(errCustomerIDRequired)
 			return
 		}
 
-		// Get customer data
-		dashboardData, err := mockService.GetDashboardData(request.CustomerId)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if !mockService.HasCustomer(request.CustomerId) {
+			c.Error(withLegacyStatus(services.NewNotFoundError("customer not found: %s", request.CustomerId), http.StatusInternalServerError))
 			return
 		}
 
-		// Generate AI insights with budget data
-		insights, err := aiService.GenerateInsights(dashboardData.Transactions, dashboardData.SpendingData.TotalMonthlySpend, request.BudgetData)
-		if err != nil {
+		// Budget notifications use the same budgets until the customer sets
+		// their own
+		mockService.RememberBudgets(request.CustomerId, request.BudgetData)
+
+		// Generate AI insights with budget data, reusing them while the
+		// customer's transactions and budgets are unchanged
+		insights, err := mockService.GenerateInsights(aiService, request.CustomerId, request.BudgetData)
+		if err == nil {
+			mockService.SaveInsights(request.CustomerId, insights, services.InsightSourceOnDemand)
+			mockService.PublishInsights(request.CustomerId, insights)
+		} else {
 			// Log the error for debugging
 			fmt.Printf("AI Insights Error: %v\n", err)
 			// If AI fails, return fallback insights
@@ -58,4 +64,27 @@ func RegisterAIInsightRoutes(rg *gin.RouterGroup, apiKey string) {
 			"insights":   insights,
 		})
 	})
+
+	// The insights last generated for a customer, either on request or by
+	// the nightly refresh
+	rg.GET("/ai-insights", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		snapshot, err := mockService.GetLatestInsights(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"customerId":  customerId,
+			"insights":    snapshot.Insights,
+			"generatedAt": snapshot.GeneratedAt,
+			"source":      snapshot.Source,
+		})
+	})
 }
@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// RegisterAlertRoutes sets up /api/alerts for unusual transactions
+func RegisterAlertRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+
+	// Flagged transactions and spending spikes, newest first.
+	// Pass ?includeDismissed=true to see dismissed alerts too.
+	rg.GET("/alerts", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		alerts, err := mockService.GetAlerts(customerId, c.Query("includeDismissed") == "true")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"customerId": customerId,
+			"total":      len(alerts),
+			"alerts":     alerts,
+		})
+	})
+
+	// Dismiss an alert with a verdict of expected, fraud or ignore
+	rg.POST("/alerts/:id/dismiss", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+			services.AlertDismissal
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		alerts, err := mockService.GetAlerts(request.CustomerId, true)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		found := false
+		for _, alert := range alerts {
+			found = found || alert.ID == c.Param("id")
+		}
+		if !found {
+			c.Error(services.NewNotFoundError("alert not found: %s", c.Param("id")))
+			return
+		}
+
+		alert, err := mockService.DismissAlert(request.CustomerId, c.Param("id"), request.AlertDismissal)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"alert": alert})
+	})
+}
@@ -0,0 +1,124 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// RegisterAnnotationRoutes sets up PATCH /api/transactions/:id and the
+// attachment endpoints for user tags, notes and receipts
+func RegisterAnnotationRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+
+	// Update tags and notes, and upload attachments. Accepts JSON, or
+	// multipart/form-data with the same fields plus "attachments" files.
+	rg.PATCH("/transactions/:id", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+			services.TransactionAnnotationUpdate
+		}
+
+		multipart := strings.HasPrefix(c.ContentType(), "multipart/form-data")
+		if multipart {
+			request.CustomerId = c.PostForm("customerId")
+			if tags, exists := c.GetPostFormArray("tags"); exists {
+				request.Tags = &tags
+			}
+			request.AddTags = c.PostFormArray("addTags")
+			request.RemoveTags = c.PostFormArray("removeTags")
+			if notes, exists := c.GetPostForm("notes"); exists {
+				request.Notes = &notes
+			}
+		} else if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		transactionId := c.Param("id")
+		if _, err := mockService.GetTransaction(request.CustomerId, transactionId); err != nil {
+			c.Error(err)
+			return
+		}
+
+		transaction, err := mockService.UpdateTransaction(request.CustomerId, transactionId, request.TransactionAnnotationUpdate)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		if multipart {
+			form, err := c.MultipartForm()
+			if err != nil {
+				c.Error(services.NewValidationError("", "Invalid multipart form"))
+				return
+			}
+			for _, fileHeader := range form.File["attachments"] {
+				file, err := fileHeader.Open()
+				if err != nil {
+					c.Error(services.NewValidationError("attachments", "failed to read %s", fileHeader.Filename))
+					return
+				}
+				_, err = mockService.AddTransactionAttachment(request.CustomerId, transactionId, fileHeader.Filename, fileHeader.Header.Get("Content-Type"), file)
+				file.Close()
+				if err != nil {
+					c.Error(withLegacyStatus(err, http.StatusBadRequest))
+					return
+				}
+			}
+			transaction, _ = mockService.GetTransaction(request.CustomerId, transactionId)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"transaction": transaction})
+	})
+
+	// Download an attachment
+	rg.GET("/transactions/:id/attachments/:attachmentId", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		attachment, reader, err := mockService.OpenTransactionAttachment(customerId, c.Param("id"), c.Param("attachmentId"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		defer reader.Close()
+
+		c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, reader, map[string]string{
+			"Content-Disposition": fmt.Sprintf("inline; filename=%q", attachment.Filename),
+		})
+	})
+
+	// Remove an attachment
+	rg.DELETE("/transactions/:id/attachments/:attachmentId", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if err := mockService.DeleteTransactionAttachment(customerId, c.Param("id"), c.Param("attachmentId")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		transaction, err := mockService.GetTransaction(customerId, c.Param("id"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"transaction": transaction})
+	})
+}
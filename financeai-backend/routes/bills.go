@@ -0,0 +1,242 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+	"financeai-backend/models"
+)
+
+// RegisterBillRoutes sets up /api/bills and the bills calendar
+func RegisterBillRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+
+	// List the bills a customer entered by hand
+	rg.GET("/bills", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		bills, err := mockService.GetBills(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"bills": bills})
+	})
+
+	// Add a bill such as rent, a utility or tuition
+	rg.POST("/bills", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+			services.BillInput
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if _, err := mockService.GetBills(request.CustomerId); err != nil {
+			c.Error(err)
+			return
+		}
+
+		bill, err := mockService.AddBill(request.CustomerId, request.BillInput)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"bill": bill})
+	})
+
+	// Update a bill
+	rg.PATCH("/bills/:id", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+			services.BillInput
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if !hasBill(mockService, request.CustomerId, c.Param("id")) {
+			c.Error(services.NewNotFoundError("bill not found: %s", c.Param("id")))
+			return
+		}
+
+		bill, err := mockService.UpdateBill(request.CustomerId, c.Param("id"), request.BillInput)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"bill": bill})
+	})
+
+	// Remove a bill
+	rg.DELETE("/bills/:id", func(c *gin.Context) {
+		customerId := strings.TrimSpace(c.Query("customerId"))
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if err := mockService.DeleteBill(customerId, c.Param("id")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	// Subscriptions and other charges detected repeating on the customer's accounts
+	rg.GET("/bills/recurring", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		recurring, err := mockService.GetRecurringCharges(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"recurring": recurring})
+	})
+
+	// Bills due in the next N days, e.g. ?days=14
+	rg.GET("/bills/upcoming", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		days := services.DefaultUpcomingBillDays
+		if value := c.Query("days"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > services.MaxUpcomingBillDays {
+				c.Error(services.NewValidationError("days", "days must be between 1 and %d", services.MaxUpcomingBillDays))
+				return
+			}
+			days = parsed
+		}
+
+		now := time.Now().UTC()
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		bills, err := mockService.GetBillCalendar(customerId, start, start.AddDate(0, 0, days+1))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		var total float64
+		flagged := 0
+		for _, bill := range bills {
+			total += bill.Amount
+			if bill.InsufficientFunds {
+				flagged++
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"customerId": customerId,
+			"days":       days,
+			"bills":      bills,
+			"total":      total,
+			"flagged":    flagged,
+		})
+	})
+
+	// Bills grouped by due date for one month, e.g. ?month=2026-11
+	rg.GET("/bills/calendar", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		now := time.Now().UTC()
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if value := c.Query("month"); value != "" {
+			parsed, err := time.Parse(services.ReportMonthLayout, value)
+			if err != nil {
+				c.Error(services.NewValidationError("month", "month must be in YYYY-MM format"))
+				return
+			}
+			start = parsed
+		}
+
+		bills, err := mockService.GetBillCalendar(customerId, start, start.AddDate(0, 1, 0))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		days := []gin.H{}
+		var current []models.UpcomingBill
+		var total float64
+		flush := func() {
+			if len(current) == 0 {
+				return
+			}
+			days = append(days, gin.H{
+				"date":  current[0].DueDate.Format(services.BillDateLayout),
+				"bills": current,
+				"total": total,
+			})
+			current, total = nil, 0
+		}
+		for _, bill := range bills {
+			if len(current) > 0 && !current[0].DueDate.Equal(bill.DueDate) {
+				flush()
+			}
+			current = append(current, bill)
+			total += bill.Amount
+		}
+		flush()
+
+		c.JSON(http.StatusOK, gin.H{
+			"customerId": customerId,
+			"month":      start.Format(services.ReportMonthLayout),
+			"days":       days,
+		})
+	})
+}
+
+// hasBill reports whether billId is one of the customer's bills
+func hasBill(mockService *services.MockDataService, customerId string, billId string) bool {
+	bills, err := mockService.GetBills(customerId)
+	if err != nil {
+		return false
+	}
+	for _, bill := range bills {
+		if bill.ID == billId {
+			return true
+		}
+	}
+	return false
+}
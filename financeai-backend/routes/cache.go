@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// RegisterCacheRoutes sets up /api/cache for inspecting and clearing the
+// response cache
+func RegisterCacheRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+
+	// Entries, hits and misses overall and per kind of data
+	rg.GET("/cache/stats", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"stats": mockService.GetCacheStats()})
+	})
+
+	// Drop everything cached for a customer, such as after changing their
+	// data in Nessie directly
+	rg.POST("/cache/invalidate", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		removed := mockService.InvalidateCache(customerId)
+		c.JSON(http.StatusOK, gin.H{
+			"customerId": customerId,
+			"removed":    removed,
+		})
+	})
+}
@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+	"financeai-backend/models"
+)
+
+// MaxCashflowMonths caps how far back the cashflow endpoint looks
+const MaxCashflowMonths = 24
+
+// RegisterCashflowRoutes sets up /api/cashflow
+func RegisterCashflowRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+
+	// Monthly income vs expenses, e.g. ?months=6
+	rg.GET("/cashflow", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		months := 6
+		if value := c.Query("months"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > MaxCashflowMonths {
+				c.Error(services.NewValidationError("months", "months must be between 1 and 24"))
+				return
+			}
+			months = parsed
+		}
+
+		transactions, err := mockService.GetAllCustomerTransactions(customerId, models.TransactionFilter{})
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusInternalServerError))
+			return
+		}
+
+		// Income and spending are totalled in the customer's reporting currency
+		summary := services.AnalyzeCashflow(mockService.ConvertTransactions(customerId, transactions), months, time.Now())
+		c.JSON(http.StatusOK, gin.H{
+			"customerId": customerId,
+			"currency":   mockService.MoneyFormatter(customerId).Currency,
+			"cashflow":   summary,
+		})
+	})
+}
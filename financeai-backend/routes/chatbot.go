@@ -25,19 +25,19 @@ func RegisterChatbotRoutes(rg *gin.RouterGroup, apiKey string) {
 		}
 
 		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+			c.Error(errInvalidRequestFormat)
 			return
 		}
 
 		if request.Username == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "username required"})
+			c.Error(services.NewValidationError("username", "username required"))
 			return
 		}
 
 		// Get customer data
 		customerData, err := mockService.GetDashboardData(request.Username)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get customer data"})
+			c.Error(withLegacyStatus(err, http.StatusInternalServerError))
 			return
 		}
 
@@ -75,19 +75,19 @@ func RegisterChatbotRoutes(rg *gin.RouterGroup, apiKey string) {
 		}
 
 		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+			c.Error(errInvalidRequestFormat)
 			return
 		}
 
 		if request.Username == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "username required"})
+			c.Error(services.NewValidationError("username", "username required"))
 			return
 		}
 
 		// Get customer data
 		customerData, err := mockService.GetDashboardData(request.Username)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get customer data"})
+			c.Error(withLegacyStatus(err, http.StatusInternalServerError))
 			return
 		}
 
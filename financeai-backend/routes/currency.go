@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// RegisterCurrencyRoutes sets up /api/currencies and currency preferences
+func RegisterCurrencyRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+
+	// The currencies amounts can be converted between and the days the
+	// exchange rate table covers
+	rg.GET("/currencies", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"rates": mockService.GetExchangeRates()})
+	})
+
+	// The currency the customer's totals are reported in and the locale
+	// amounts are written for
+	rg.GET("/currency/preferences", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		preferences, err := mockService.GetCurrencyPreferences(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"preferences": preferences})
+	})
+
+	// Change the reporting currency or locale. Fields left out of the body
+	// keep their current values.
+	rg.PATCH("/currency/preferences", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		preferences, err := mockService.GetCurrencyPreferences(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		// Decode onto the current preferences so only the fields sent change
+		if err := json.NewDecoder(c.Request.Body).Decode(preferences); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		updated, err := mockService.SetCurrencyPreferences(customerId, *preferences)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"preferences": updated})
+	})
+}
@@ -0,0 +1,175 @@
+package routes
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// RegisterDebtRoutes sets up /api/debts and the payoff planner
+func RegisterDebtRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+
+	// List credit cards and loans with their recorded terms
+	rg.GET("/debts", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		debts, err := mockService.GetDebts(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"debts":          debts,
+			"minimumPayment": services.MinimumPaymentTotal(debts),
+		})
+	})
+
+	// Record the APR, minimum payment and due day of a liability account
+	rg.PUT("/debts/:accountId/terms", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+			services.DebtTermsInput
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if !hasDebt(mockService, request.CustomerId, c.Param("accountId")) {
+			c.Error(services.NewNotFoundError("debt not found: %s", c.Param("accountId")))
+			return
+		}
+
+		debt, err := mockService.SetDebtTerms(request.CustomerId, c.Param("accountId"), request.DebtTermsInput)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"debt": debt})
+	})
+
+	// Simulate one strategy, e.g. ?strategy=snowball&monthlyBudget=600
+	rg.GET("/debts/plan", func(c *gin.Context) {
+		customerId, monthlyBudget, order, ok := parsePayoffRequest(c, mockService)
+		if !ok {
+			return
+		}
+
+		strategy := c.DefaultQuery("strategy", services.StrategyAvalanche)
+		plan, err := mockService.PlanDebtPayoff(customerId, strategy, monthlyBudget, order)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"plan": plan})
+	})
+
+	// Compare avalanche, snowball and an optional custom order side by side
+	rg.GET("/debts/compare", func(c *gin.Context) {
+		customerId, monthlyBudget, order, ok := parsePayoffRequest(c, mockService)
+		if !ok {
+			return
+		}
+
+		plans, err := mockService.CompareDebtPayoff(customerId, monthlyBudget, order)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		// Recommend the plan that pays the least interest, then the fastest
+		recommended := plans[0]
+		for _, plan := range plans[1:] {
+			if plan.TotalInterest < recommended.TotalInterest || (plan.TotalInterest == recommended.TotalInterest && plan.Months < recommended.Months) {
+				recommended = plan
+			}
+		}
+
+		comparison := make([]gin.H, 0, len(plans))
+		for _, plan := range plans {
+			comparison = append(comparison, gin.H{
+				"strategy":      plan.Strategy,
+				"months":        plan.Months,
+				"payoffDate":    plan.PayoffDate,
+				"totalInterest": plan.TotalInterest,
+				"totalPaid":     plan.TotalPaid,
+				"interestVsRecommended": math.Round((plan.TotalInterest-recommended.TotalInterest)*100) / 100,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"recommended": recommended.Strategy,
+			"comparison":  comparison,
+			"plans":       plans,
+		})
+	})
+}
+
+// parsePayoffRequest reads the customer, monthly budget and custom order shared
+// by the payoff endpoints, writing an error response if they're invalid
+func parsePayoffRequest(c *gin.Context, mockService *services.MockDataService) (string, float64, []string, bool) {
+	customerId := c.Query("customerId")
+	if customerId == "" {
+		c.Error(errCustomerIDRequired)
+		return "", 0, nil, false
+	}
+
+	var monthlyBudget float64
+	if value := c.Query("monthlyBudget"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 {
+			c.Error(services.NewValidationError("monthlyBudget", "monthlyBudget must be a positive amount"))
+			return "", 0, nil, false
+		}
+		monthlyBudget = parsed
+	}
+
+	// Accept ?order=a,b as well as ?order=a&order=b
+	var order []string
+	for _, value := range c.QueryArray("order") {
+		for _, accountId := range strings.Split(value, ",") {
+			if accountId = strings.TrimSpace(accountId); accountId != "" {
+				order = append(order, accountId)
+			}
+		}
+	}
+
+	if _, err := mockService.GetDebts(customerId); err != nil {
+		c.Error(err)
+		return "", 0, nil, false
+	}
+
+	return customerId, monthlyBudget, order, true
+}
+
+// hasDebt reports whether accountId is one of the customer's liabilities
+func hasDebt(mockService *services.MockDataService, customerId string, accountId string) bool {
+	debts, err := mockService.GetDebts(customerId)
+	if err != nil {
+		return false
+	}
+	for _, debt := range debts {
+		if debt.AccountID == accountId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package routes
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// RequestIDHeader carries the ID a request is reported under. Clients can
+// send their own; otherwise one is generated.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is where the request ID is kept on the gin context
+const requestIDKey = "requestId"
+
+// maxRequestIDLength caps IDs taken from clients
+const maxRequestIDLength = 128
+
+// errInvalidRequestFormat is the error for bodies that aren't valid JSON
+var errInvalidRequestFormat = services.NewValidationError("", "Invalid request format")
+
+// errCustomerIDRequired is the error for requests that don't name a customer
+var errCustomerIDRequired = services.NewValidationError("customerId", "customerId required")
+
+// upstreamError is an ErrUpstream found in a handler rather than a service,
+// such as a GraphQL field whose provider data failed to load
+type upstreamError struct {
+	message string
+}
+
+func (e *upstreamError) Error() string {
+	return e.message
+}
+
+func (e *upstreamError) Unwrap() error {
+	return services.ErrUpstream
+}
+
+func newUpstreamError(format string, args ...interface{}) error {
+	return &upstreamError{message: fmt.Sprintf(format, args...)}
+}
+
+// apiError is the body of every error response
+type apiError struct {
+	// Code names the kind of error, such as not_found or invalid_request
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId"`
+	// Details lists the request fields at fault, for invalid requests
+	Details []services.FieldError `json:"details,omitempty"`
+}
+
+// legacyError is the body of error responses on the legacy routes, from
+// before the envelope
+type legacyError struct {
+	Error   string                `json:"error"`
+	Details []services.FieldError `json:"details,omitempty"`
+}
+
+// legacy returns the legacy body for the same error
+func (e apiError) legacy() legacyError {
+	return legacyError{Error: e.Message, Details: e.Details}
+}
+
+// errorKinds is the status and code each kind of service error is reported
+// with. The first match wins, so more specific errors come first.
+var errorKinds = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{services.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, "idempotency_key_reused"},
+	{services.ErrNotFound, http.StatusNotFound, "not_found"},
+	{services.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{services.ErrValidation, http.StatusBadRequest, "invalid_request"},
+	{services.ErrConflict, http.StatusConflict, "conflict"},
+	{services.ErrUpstream, http.StatusBadGateway, "upstream_failure"},
+}
+
+// newRequestID returns a random request ID
+func newRequestID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("req_%d", time.Now().UnixNano())
+	}
+	return "req_" + hex.EncodeToString(id)
+}
+
+// RequestID gives each request an ID, echoed in the X-Request-ID header and
+// in error responses so a report can be matched to the logs
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		c.Set(requestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// errorResponse returns the status and body to report err with. Errors that
+// aren't one of the service kinds are internal errors. The legacy routes
+// keep their old status where it differs.
+func errorResponse(c *gin.Context, err error) (int, apiError) {
+	body := apiError{
+		Code:      "internal_error",
+		Message:   err.Error(),
+		RequestID: c.GetString(requestIDKey),
+	}
+	status := http.StatusInternalServerError
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind.err) {
+			status, body.Code = kind.status, kind.code
+			break
+		}
+	}
+	var legacyErr *legacyStatusError
+	if legacyShape(c) && errors.As(err, &legacyErr) {
+		status = legacyErr.status
+	}
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		for _, field := range validationErr.Fields {
+			if field.Field != "" {
+				body.Details = append(body.Details, field)
+			}
+		}
+	}
+	if status == http.StatusInternalServerError {
+		fmt.Printf("API Error: %s %s (%s): %v\n", c.Request.Method, c.Request.URL.Path, body.RequestID, err)
+	}
+	return status, body
+}
+
+// abortWithError responds with err in the error envelope, or the legacy
+// body on the legacy routes, and stops the handlers after this one
+func abortWithError(c *gin.Context, err error) {
+	status, body := errorResponse(c, err)
+	if legacyShape(c) {
+		c.AbortWithStatusJSON(status, body.legacy())
+		return
+	}
+	c.AbortWithStatusJSON(status, body)
+}
+
+// HandleErrors responds to the last error a handler reported with c.Error,
+// unless the handler has already written a response
+func HandleErrors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		abortWithError(c, c.Errors.Last().Err)
+	}
+}
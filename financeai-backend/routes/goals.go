@@ -0,0 +1,78 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// RegisterGoalRoutes sets up /api/goals for savings goals
+func RegisterGoalRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+
+	// List a customer's savings goals with their progress
+	rg.GET("/goals", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		goals, err := mockService.GetGoals(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"goals": goals})
+	})
+
+	// Create a savings goal tracked against one of the customer's accounts
+	rg.POST("/goals", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+			services.GoalInput
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if _, err := mockService.GetGoals(request.CustomerId); err != nil {
+			c.Error(err)
+			return
+		}
+
+		goal, err := mockService.AddGoal(request.CustomerId, request.GoalInput)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"goal": goal})
+	})
+
+	// Remove a savings goal
+	rg.DELETE("/goals/:id", func(c *gin.Context) {
+		customerId := strings.TrimSpace(c.Query("customerId"))
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if err := mockService.DeleteGoal(customerId, c.Param("id")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// graphQLRequest is the body of a GraphQL request
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// RegisterGraphQLRoutes sets up /api/graphql, which resolves a customer,
+// their accounts, transactions, merchants, spending and insights in one
+// request. It sits behind the same middleware as every other route.
+func RegisterGraphQLRoutes(rg *gin.RouterGroup, apiKey string) {
+	provider := newGraphProvider(apiKey)
+	schema := newFinanceGraphSchema()
+	sdl := schema.sdl()
+
+	// Run a query. Problems with the query and fields that fail are reported
+	// in the errors of a 200 response, as GraphQL clients expect.
+	rg.POST("/graphql", func(c *gin.Context) {
+		var request graphQLRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		// Loaders live for one request, so nothing fetched is shared between customers
+		graphRequest := &graphRequest{c: c, ctx: c.Request.Context(), loaders: provider.newLoaders()}
+		c.JSON(http.StatusOK, executeGraphQL(schema, graphRequest, request.Query, request.OperationName, request.Variables))
+	})
+
+	// The schema in SDL, for clients and code generators
+	rg.GET("/graphql/schema", func(c *gin.Context) {
+		c.String(http.StatusOK, sdl)
+	})
+}
@@ -0,0 +1,785 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits on the queries the GraphQL endpoint runs. Complexity counts one for
+// every field, multiplied through lists by their page size, so it grows with
+// the work and response size a query asks for.
+const (
+	maxGraphQLDepth      = 8
+	maxGraphQLComplexity = 5000
+)
+
+// graphSchema is a GraphQL schema built in code
+type graphSchema struct {
+	query *graphObject
+	// types holds every object type by name, in the order they were given
+	types     map[string]*graphObject
+	typeOrder []*graphObject
+}
+
+// graphObject is an object type
+type graphObject struct {
+	name        string
+	description string
+	fields      []*graphField
+	byName      map[string]*graphField
+}
+
+// graphField is a field of an object type
+type graphField struct {
+	name        string
+	description string
+	// typ is the field's type as written in a schema, such as [Account!]
+	typ       string
+	arguments []graphArgument
+	// listSize is how many items a list field is expected to return, for
+	// working out complexity. sizeArgument names an argument that bounds it
+	// instead: an Int page size, or a list with an item per result.
+	listSize     int
+	sizeArgument string
+	resolve      graphResolver
+}
+
+// graphArgument is an argument a field takes
+type graphArgument struct {
+	name         string
+	description  string
+	typ          string
+	defaultValue interface{}
+}
+
+// graphResolver returns a field's value for its source object. It can return
+// a graphThunk to wait for a loader to fetch the value.
+type graphResolver func(r *graphRequest, source interface{}, args map[string]interface{}) (interface{}, error)
+
+// graphThunk returns a value once the loaders it's waiting on have fetched
+type graphThunk func() (interface{}, error)
+
+// graphRequest is what resolvers can use while a query runs
+type graphRequest struct {
+	c       *gin.Context
+	ctx     context.Context
+	loaders *graphLoaders
+}
+
+// newGraphSchema indexes the query type and the object types it uses
+func newGraphSchema(query *graphObject, types ...*graphObject) *graphSchema {
+	schema := &graphSchema{query: query, types: make(map[string]*graphObject)}
+	for _, object := range append([]*graphObject{query}, types...) {
+		object.byName = make(map[string]*graphField, len(object.fields))
+		for _, field := range object.fields {
+			object.byName[field.name] = field
+		}
+		schema.types[object.name] = object
+		schema.typeOrder = append(schema.typeOrder, object)
+	}
+	return schema
+}
+
+// sdl writes the schema in the GraphQL schema definition language
+func (s *graphSchema) sdl() string {
+	var out strings.Builder
+	out.WriteString("schema {\n  query: " + s.query.name + "\n}\n")
+	for _, object := range s.typeOrder {
+		out.WriteString("\n")
+		writeDescription(&out, "", object.description)
+		out.WriteString("type " + object.name + " {\n")
+		for _, field := range object.fields {
+			writeDescription(&out, "  ", field.description)
+			out.WriteString("  " + field.name)
+			if len(field.arguments) > 0 {
+				arguments := make([]string, len(field.arguments))
+				for i, argument := range field.arguments {
+					arguments[i] = argument.name + ": " + argument.typ
+					if argument.defaultValue != nil {
+						value, _ := json.Marshal(argument.defaultValue)
+						arguments[i] += " = " + string(value)
+					}
+				}
+				out.WriteString("(" + strings.Join(arguments, ", ") + ")")
+			}
+			out.WriteString(": " + field.typ + "\n")
+		}
+		out.WriteString("}\n")
+	}
+	return out.String()
+}
+
+func writeDescription(out *strings.Builder, indent string, description string) {
+	if description != "" {
+		out.WriteString(indent + `"""` + description + `"""` + "\n")
+	}
+}
+
+// namedType strips the list and non-null wrappers from a type
+func namedType(typ string) string {
+	return strings.Trim(typ, "[]!")
+}
+
+// graphQLError is an error in a GraphQL response
+type graphQLError struct {
+	Message    string                  `json:"message"`
+	Locations  []queryPosition         `json:"locations,omitempty"`
+	Path       []interface{}           `json:"path,omitempty"`
+	Extensions *graphQLErrorExtensions `json:"extensions,omitempty"`
+}
+
+// graphQLErrorExtensions carries the same code and request ID as the error
+// envelope of the REST routes
+type graphQLErrorExtensions struct {
+	Code      string `json:"code"`
+	RequestID string `json:"requestId"`
+}
+
+// graphQLResponse is the body of every GraphQL response. Data is left out
+// when the query couldn't be run at all.
+type graphQLResponse struct {
+	Data   interface{}    `json:"data,omitempty"`
+	Errors []graphQLError `json:"errors,omitempty"`
+}
+
+// collectedField is a field a query selects, with every selection of the
+// same response key merged and its arguments checked
+type collectedField struct {
+	key        string
+	name       string
+	position   queryPosition
+	selections []querySelection
+	definition *graphField
+	arguments  map[string]interface{}
+	// object is the type the field's value has, if it's an object, and
+	// children the fields selected on it
+	object   *graphObject
+	children []*collectedField
+}
+
+// graphExecution runs one operation of a document
+type graphExecution struct {
+	schema    *graphSchema
+	document  *queryDocument
+	variables map[string]interface{}
+	request   *graphRequest
+	errors    []graphQLError
+}
+
+// executeGraphQL parses, checks and runs a query. Problems with the query
+// itself are reported without running any of it; resolvers that fail leave
+// their field null and add an error, while the rest of the query runs.
+func executeGraphQL(schema *graphSchema, request *graphRequest, query string, operationName string, variables map[string]interface{}) graphQLResponse {
+	document, err := parseQuery(query)
+	if err != nil {
+		return graphQLResponse{Errors: []graphQLError{request.queryError(err, "invalid_request")}}
+	}
+	operation, err := selectOperation(document, operationName)
+	if err != nil {
+		return graphQLResponse{Errors: []graphQLError{request.queryError(err, "invalid_request")}}
+	}
+
+	execution := &graphExecution{schema: schema, document: document, request: request}
+	if execution.variables, err = coerceVariables(operation, variables); err != nil {
+		return graphQLResponse{Errors: []graphQLError{request.queryError(err, "invalid_request")}}
+	}
+	root, err := execution.collectFields(schema.query, operation.selections, nil, make(map[string]bool))
+	if err != nil {
+		return graphQLResponse{Errors: []graphQLError{request.queryError(err, "invalid_request")}}
+	}
+	complexity, err := execution.check(schema.query, root, 1)
+	if err != nil {
+		return graphQLResponse{Errors: []graphQLError{request.queryError(err, "invalid_request")}}
+	}
+	if complexity > maxGraphQLComplexity {
+		err := fmt.Errorf("query is too complex: its complexity is %d and the limit is %d", complexity, maxGraphQLComplexity)
+		return graphQLResponse{Errors: []graphQLError{request.queryError(err, "query_too_complex")}}
+	}
+
+	data := execution.execute(root)
+	return graphQLResponse{Data: data, Errors: execution.errors}
+}
+
+// queryError reports a problem with a query as a whole
+func (r *graphRequest) queryError(err error, code string) graphQLError {
+	graphErr := graphQLError{
+		Message:    err.Error(),
+		Extensions: &graphQLErrorExtensions{Code: code, RequestID: r.c.GetString(requestIDKey)},
+	}
+	if syntaxErr, ok := err.(*querySyntaxError); ok {
+		graphErr.Locations = []queryPosition{syntaxErr.position}
+	}
+	return graphErr
+}
+
+// selectOperation picks the operation to run. Only queries are supported.
+func selectOperation(document *queryDocument, operationName string) (*queryOperation, error) {
+	var operation *queryOperation
+	switch {
+	case operationName != "":
+		for _, candidate := range document.operations {
+			if candidate.name == operationName {
+				operation = candidate
+			}
+		}
+		if operation == nil {
+			return nil, fmt.Errorf("unknown operation named %q", operationName)
+		}
+	case len(document.operations) > 1:
+		return nil, fmt.Errorf("operationName is required when the document has more than one operation")
+	default:
+		operation = document.operations[0]
+	}
+	if operation.kind != "query" {
+		return nil, fmt.Errorf("only queries are supported, not %ss", operation.kind)
+	}
+	return operation, nil
+}
+
+// coerceVariables checks the variables sent against those the operation
+// declares, filling in defaults
+func coerceVariables(operation *queryOperation, provided map[string]interface{}) (map[string]interface{}, error) {
+	variables := make(map[string]interface{}, len(operation.variables))
+	for _, variable := range operation.variables {
+		value, exists := provided[variable.name]
+		if !exists && variable.defaultValue != nil {
+			value, exists = variable.defaultValue.value(nil), true
+		}
+		if !exists {
+			if strings.HasSuffix(variable.typ, "!") {
+				return nil, fmt.Errorf("variable $%s of required type %s was not provided", variable.name, variable.typ)
+			}
+			continue
+		}
+		coerced, err := coerceInput(variable.typ, value)
+		if err != nil {
+			return nil, fmt.Errorf("variable $%s %v", variable.name, err)
+		}
+		variables[variable.name] = coerced
+	}
+	return variables, nil
+}
+
+// coerceInput converts an argument or variable value to typ: Int to int,
+// Float to float64, String and ID to string and lists to []interface{}
+func coerceInput(typ string, value interface{}) (interface{}, error) {
+	if strings.HasSuffix(typ, "!") {
+		if value == nil {
+			return nil, fmt.Errorf("must not be null")
+		}
+		typ = strings.TrimSuffix(typ, "!")
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(typ, "[") {
+		itemType := typ[1 : len(typ)-1]
+		items, ok := value.([]interface{})
+		if !ok {
+			// A single value stands for a list of one
+			items = []interface{}{value}
+		}
+		coerced := make([]interface{}, len(items))
+		for i, item := range items {
+			value, err := coerceInput(itemType, item)
+			if err != nil {
+				return nil, err
+			}
+			coerced[i] = value
+		}
+		return coerced, nil
+	}
+
+	number, isNumber := toFloat(value)
+	switch typ {
+	case "Int":
+		if !isNumber || number != math.Trunc(number) || number < math.MinInt32 || number > math.MaxInt32 {
+			return nil, fmt.Errorf("must be an Int")
+		}
+		return int(number), nil
+	case "Float":
+		if !isNumber {
+			return nil, fmt.Errorf("must be a Float")
+		}
+		return number, nil
+	case "String":
+		if text, ok := value.(string); ok {
+			return text, nil
+		}
+		return nil, fmt.Errorf("must be a String")
+	case "ID":
+		if text, ok := value.(string); ok {
+			return text, nil
+		}
+		if isNumber && number == math.Trunc(number) {
+			return fmt.Sprintf("%.0f", number), nil
+		}
+		return nil, fmt.Errorf("must be an ID")
+	case "Boolean":
+		if flag, ok := value.(bool); ok {
+			return flag, nil
+		}
+		return nil, fmt.Errorf("must be a Boolean")
+	}
+	return nil, fmt.Errorf("has unsupported input type %s", typ)
+}
+
+// toFloat reads a number as decoded from JSON or parsed from a query
+func toFloat(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case int64:
+		return float64(number), true
+	case int:
+		return float64(number), true
+	case json.Number:
+		parsed, err := number.Float64()
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+// collectFields gathers the fields selected on object, following fragments
+// and applying @include and @skip. Fields selected more than once under the
+// same key are merged.
+func (e *graphExecution) collectFields(object *graphObject, selections []querySelection, fields []*collectedField, visited map[string]bool) ([]*collectedField, error) {
+	for _, selection := range selections {
+		include, err := e.included(selection.directives)
+		if err != nil {
+			return nil, err
+		}
+		if !include {
+			continue
+		}
+
+		switch {
+		case selection.field != nil:
+			key := selection.field.responseKey()
+			var existing *collectedField
+			for _, field := range fields {
+				if field.key == key {
+					existing = field
+				}
+			}
+			if existing == nil {
+				field := &collectedField{key: key, name: selection.field.name, position: selection.position, selections: selection.field.selections}
+				if err := e.coerceArguments(object, field, selection.field.arguments); err != nil {
+					return nil, err
+				}
+				fields = append(fields, field)
+				continue
+			}
+			if existing.name != selection.field.name {
+				return nil, fmt.Errorf("fields %q conflict because %s and %s are different fields", key, existing.name, selection.field.name)
+			}
+			existing.selections = append(existing.selections, selection.field.selections...)
+		case selection.spread != "":
+			fragment, exists := e.document.fragments[selection.spread]
+			if !exists {
+				return nil, fmt.Errorf("unknown fragment %q", selection.spread)
+			}
+			if visited[fragment.name] {
+				continue
+			}
+			visited[fragment.name] = true
+			if err := e.checkTypeCondition(object, fragment.typeCondition); err != nil {
+				return nil, err
+			}
+			if fields, err = e.collectFields(object, fragment.selections, fields, visited); err != nil {
+				return nil, err
+			}
+		case selection.inline != nil:
+			if selection.inline.typeCondition != "" {
+				if err := e.checkTypeCondition(object, selection.inline.typeCondition); err != nil {
+					return nil, err
+				}
+			}
+			if fields, err = e.collectFields(object, selection.inline.selections, fields, visited); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return fields, nil
+}
+
+// checkTypeCondition fails unless a fragment's type condition is object.
+// The schema has no interfaces or unions, so no other type can match.
+func (e *graphExecution) checkTypeCondition(object *graphObject, typeCondition string) error {
+	if _, exists := e.schema.types[typeCondition]; !exists {
+		return fmt.Errorf("unknown type %q", typeCondition)
+	}
+	if typeCondition != object.name {
+		return fmt.Errorf("a fragment on %s can't be spread on %s", typeCondition, object.name)
+	}
+	return nil
+}
+
+// included applies @include(if:) and @skip(if:)
+func (e *graphExecution) included(directives []queryDirective) (bool, error) {
+	for _, directive := range directives {
+		if directive.name != "include" && directive.name != "skip" {
+			return false, fmt.Errorf("unknown directive @%s", directive.name)
+		}
+		if len(directive.arguments) != 1 || directive.arguments[0].name != "if" {
+			return false, fmt.Errorf("@%s takes a single if argument", directive.name)
+		}
+		condition, err := coerceInput("Boolean!", directive.arguments[0].value.value(e.variables))
+		if err != nil {
+			return false, fmt.Errorf("@%s(if:) %v", directive.name, err)
+		}
+		if condition.(bool) == (directive.name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// coerceArguments checks a field's arguments against its definition on
+// object and fills in defaults. Unknown fields are left for check to report.
+func (e *graphExecution) coerceArguments(object *graphObject, field *collectedField, arguments []queryArgument) error {
+	definition := object.byName[field.name]
+	if definition == nil {
+		if field.name == "__typename" && len(arguments) == 0 {
+			return nil
+		}
+		return fmt.Errorf("cannot query field %q on type %q", field.name, object.name)
+	}
+	field.definition = definition
+	field.arguments = make(map[string]interface{}, len(definition.arguments))
+
+	provided := make(map[string]queryLiteral, len(arguments))
+	for _, argument := range arguments {
+		provided[argument.name] = argument.value
+	}
+	for _, argument := range arguments {
+		known := false
+		for _, defined := range definition.arguments {
+			known = known || defined.name == argument.name
+		}
+		if !known {
+			return fmt.Errorf("unknown argument %q on field %s.%s", argument.name, object.name, field.name)
+		}
+	}
+
+	for _, defined := range definition.arguments {
+		literal, exists := provided[defined.name]
+		if exists && literal.kind == queryLiteralVariable {
+			// A variable that wasn't sent counts as leaving the argument out
+			_, exists = e.variables[literal.raw]
+		}
+		var value interface{}
+		if exists {
+			value = literal.value(e.variables)
+		} else {
+			value = defined.defaultValue
+		}
+		coerced, err := coerceInput(defined.typ, value)
+		if err != nil {
+			return fmt.Errorf("argument %q on field %s.%s %v", defined.name, object.name, field.name, err)
+		}
+		if coerced != nil {
+			field.arguments[defined.name] = coerced
+		}
+	}
+	return nil
+}
+
+// check makes sure each field is selected properly, collecting the fields
+// selected on objects, and returns the query's complexity
+func (e *graphExecution) check(object *graphObject, fields []*collectedField, depth int) (int, error) {
+	if depth > maxGraphQLDepth {
+		return 0, fmt.Errorf("query is too deep: fields can be nested at most %d levels", maxGraphQLDepth)
+	}
+
+	complexity := 0
+	for _, field := range fields {
+		complexity++
+		if field.definition == nil {
+			// __typename
+			if len(field.selections) > 0 {
+				return 0, fmt.Errorf("field \"__typename\" must not have a selection since type \"String!\" has no subfields")
+			}
+			continue
+		}
+
+		field.object = e.schema.types[namedType(field.definition.typ)]
+		if field.object == nil {
+			if len(field.selections) > 0 {
+				return 0, fmt.Errorf("field %q must not have a selection since type %q has no subfields", field.name, field.definition.typ)
+			}
+			continue
+		}
+		if len(field.selections) == 0 {
+			return 0, fmt.Errorf("field %q of type %q must have a selection of subfields", field.name, field.definition.typ)
+		}
+
+		var err error
+		if field.children, err = e.collectFields(field.object, field.selections, nil, make(map[string]bool)); err != nil {
+			return 0, err
+		}
+		childComplexity, err := e.check(field.object, field.children, depth+1)
+		if err != nil {
+			return 0, err
+		}
+		complexity += field.size() * childComplexity
+	}
+	return complexity, nil
+}
+
+// size is how many objects a field is expected to return
+func (f *collectedField) size() int {
+	if !strings.HasPrefix(f.definition.typ, "[") {
+		return 1
+	}
+	switch bound := f.arguments[f.definition.sizeArgument].(type) {
+	case int:
+		return bound
+	case []interface{}:
+		return len(bound)
+	}
+	if f.definition.listSize > 0 {
+		return f.definition.listSize
+	}
+	return 1
+}
+
+// graphWork is a field to resolve on one source object
+type graphWork struct {
+	field  *collectedField
+	object *graphObject
+	source interface{}
+	out    *graphResultObject
+	path   []interface{}
+
+	value interface{}
+	thunk graphThunk
+	err   error
+}
+
+// execute resolves a query a level at a time. Every field at a level is
+// resolved before any loader fetches, so the loaders see all the keys the
+// level needs and fetch them together.
+func (e *graphExecution) execute(root []*collectedField) *graphResultObject {
+	data := newGraphResultObject()
+	level := fieldWork(e.schema.query, root, nil, data, nil)
+	for len(level) > 0 {
+		for _, work := range level {
+			e.resolve(work)
+		}
+		e.request.loaders.dispatch(e.request.ctx)
+
+		var next []*graphWork
+		for _, work := range level {
+			value, err := work.value, work.err
+			if work.thunk != nil {
+				value, err = work.thunk()
+			}
+			if err != nil {
+				e.addError(work, err)
+				continue
+			}
+			typ := "String!"
+			if work.field.definition != nil {
+				typ = work.field.definition.typ
+			}
+			work.out.set(work.field.key, e.complete(work.field, typ, value, work.path, &next))
+		}
+		level = next
+	}
+	return data
+}
+
+// fieldWork returns the work to resolve fields on source, saving their
+// places in out in the order they were selected
+func fieldWork(object *graphObject, fields []*collectedField, source interface{}, out *graphResultObject, path []interface{}) []*graphWork {
+	work := make([]*graphWork, len(fields))
+	for i, field := range fields {
+		out.set(field.key, nil)
+		work[i] = &graphWork{field: field, object: object, source: source, out: out, path: appendPath(path, field.key)}
+	}
+	return work
+}
+
+func (e *graphExecution) resolve(work *graphWork) {
+	if work.field.definition == nil {
+		// __typename
+		work.value = work.object.name
+		return
+	}
+	value, err := work.field.definition.resolve(e.request, work.source, work.field.arguments)
+	if thunk, ok := value.(graphThunk); ok && err == nil {
+		work.thunk = thunk
+		return
+	}
+	work.value, work.err = value, err
+}
+
+// complete turns a resolved value into what's written in the response,
+// queuing the fields selected on objects for the next level
+func (e *graphExecution) complete(field *collectedField, typ string, value interface{}, path []interface{}, next *[]*graphWork) interface{} {
+	typ = strings.TrimSuffix(typ, "!")
+	if isNil(value) {
+		if strings.HasPrefix(typ, "[") {
+			return []interface{}{}
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(typ, "[") {
+		items := reflect.ValueOf(value)
+		completed := make([]interface{}, items.Len())
+		for i := range completed {
+			completed[i] = e.complete(field, typ[1:len(typ)-1], items.Index(i).Interface(), appendPath(path, i), next)
+		}
+		return completed
+	}
+
+	if object := e.schema.types[typ]; object != nil {
+		result := newGraphResultObject()
+		*next = append(*next, fieldWork(object, field.children, value, result, path)...)
+		return result
+	}
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return value
+}
+
+// addError records a resolver's error against its field, which is left null
+func (e *graphExecution) addError(work *graphWork, err error) {
+	_, body := errorResponse(e.request.c, err)
+	e.errors = append(e.errors, graphQLError{
+		Message:    body.Message,
+		Locations:  []queryPosition{work.field.position},
+		Path:       work.path,
+		Extensions: &graphQLErrorExtensions{Code: body.Code, RequestID: body.RequestID},
+	})
+}
+
+// isNil reports whether value is nil, including nil pointers and slices
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// appendPath returns a copy of path with element added, so sibling paths
+// don't share storage
+func appendPath(path []interface{}, element interface{}) []interface{} {
+	copied := make([]interface{}, len(path), len(path)+1)
+	copy(copied, path)
+	return append(copied, element)
+}
+
+// graphResultObject is an object in a response, keeping its fields in the
+// order the query selected them
+type graphResultObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newGraphResultObject() *graphResultObject {
+	return &graphResultObject{values: make(map[string]interface{})}
+}
+
+func (o *graphResultObject) set(key string, value interface{}) {
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *graphResultObject) MarshalJSON() ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		value, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		out.Write(name)
+		out.WriteByte(':')
+		out.Write(value)
+	}
+	out.WriteByte('}')
+	return out.Bytes(), nil
+}
+
+// graphLoaded is a value a loader fetched, or why it couldn't
+type graphLoaded[V any] struct {
+	value V
+	err   error
+}
+
+// graphLoader batches the loads of one kind of record. Keys asked for while
+// a level of a query resolves are fetched together when the level
+// dispatches, and each key is fetched at most once per request.
+type graphLoader[K comparable, V any] struct {
+	fetch   func(ctx context.Context, keys []K) map[K]graphLoaded[V]
+	pending []K
+	loaded  map[K]graphLoaded[V]
+}
+
+func newGraphLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) map[K]graphLoaded[V]) *graphLoader[K, V] {
+	return &graphLoader[K, V]{fetch: fetch, loaded: make(map[K]graphLoaded[V])}
+}
+
+// load asks for key, returning a thunk for its value after dispatch
+func (l *graphLoader[K, V]) load(key K) func() (V, error) {
+	if _, done := l.loaded[key]; !done && !containsKey(l.pending, key) {
+		l.pending = append(l.pending, key)
+	}
+	return func() (V, error) {
+		result, exists := l.loaded[key]
+		if !exists {
+			var zero V
+			return zero, fmt.Errorf("%v wasn't loaded", key)
+		}
+		return result.value, result.err
+	}
+}
+
+// dispatch fetches every pending key
+func (l *graphLoader[K, V]) dispatch(ctx context.Context) {
+	if len(l.pending) == 0 {
+		return
+	}
+	keys := l.pending
+	l.pending = nil
+	results := l.fetch(ctx, keys)
+	for _, key := range keys {
+		result, exists := results[key]
+		if !exists {
+			result.err = fmt.Errorf("%v wasn't found", key)
+		}
+		l.loaded[key] = result
+	}
+}
+
+func containsKey[K comparable](keys []K, key K) bool {
+	for _, candidate := range keys {
+		if candidate == key {
+			return true
+		}
+	}
+	return false
+}
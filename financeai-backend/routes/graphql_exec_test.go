@@ -0,0 +1,264 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// testNodeSchema is a schema with a type that nests without end, for
+// queries as deep and wide as a test needs. resolved counts the nodes
+// resolved.
+func testNodeSchema(resolved *int32) *graphSchema {
+	nodeAt := func(depth int) (interface{}, error) {
+		atomic.AddInt32(resolved, 1)
+		return depth, nil
+	}
+	node := &graphObject{name: "Node"}
+	node.fields = []*graphField{
+		{name: "id", typ: "ID!", resolve: graphValue(func(depth int) interface{} { return strconv.Itoa(depth) })},
+		{
+			name: "child", typ: "Node",
+			resolve: func(r *graphRequest, source interface{}, args map[string]interface{}) (interface{}, error) {
+				return nodeAt(source.(int) + 1)
+			},
+		},
+		{
+			name: "children", typ: "[Node!]", sizeArgument: "first",
+			arguments: []graphArgument{{name: "first", typ: "Int", defaultValue: 10}},
+			resolve: func(r *graphRequest, source interface{}, args map[string]interface{}) (interface{}, error) {
+				children := make([]int, args["first"].(int))
+				for i := range children {
+					nodeAt(source.(int) + 1)
+					children[i] = source.(int) + 1
+				}
+				return children, nil
+			},
+		},
+		{
+			name: "broken", typ: "String",
+			resolve: func(r *graphRequest, source interface{}, args map[string]interface{}) (interface{}, error) {
+				return nil, newUpstreamError("node %d is unavailable", source.(int))
+			},
+		},
+	}
+	query := &graphObject{name: "Query", fields: []*graphField{
+		{
+			name: "node", typ: "Node",
+			resolve: func(r *graphRequest, source interface{}, args map[string]interface{}) (interface{}, error) {
+				return nodeAt(0)
+			},
+		},
+	}}
+	return newGraphSchema(query, node)
+}
+
+// runGraphQL executes a query as the GraphQL route would and returns the
+// response as a client decodes it
+func runGraphQL(t *testing.T, schema *graphSchema, query string, variables map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/graphql", nil)
+	c.Set(requestIDKey, "req_test")
+	request := &graphRequest{c: c, ctx: context.Background(), loaders: newGraphProvider("").newLoaders()}
+
+	encoded, err := json.Marshal(executeGraphQL(schema, request, query, "", variables))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal(encoded, &response); err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+// graphQLErrorCodes lists the extension codes of a response's errors
+func graphQLErrorCodes(response map[string]interface{}) []string {
+	var codes []string
+	errors, _ := response["errors"].([]interface{})
+	for _, err := range errors {
+		extensions, _ := err.(map[string]interface{})["extensions"].(map[string]interface{})
+		code, _ := extensions["code"].(string)
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// nestedQuery selects child levels deep under node
+func nestedQuery(levels int) string {
+	return "{ node { " + strings.Repeat("child { ", levels) + "id" + strings.Repeat(" }", levels) + " } }"
+}
+
+func TestGraphQLDepthLimit(t *testing.T) {
+	var resolved int32
+	schema := testNodeSchema(&resolved)
+
+	// node is the first level and id the last, so nesting child levels
+	// makes a query levels+2 deep
+	deepest := maxGraphQLDepth - 2
+	response := runGraphQL(t, schema, nestedQuery(deepest), nil)
+	if codes := graphQLErrorCodes(response); len(codes) > 0 {
+		t.Fatalf("a query %d levels deep returned errors %v", maxGraphQLDepth, response["errors"])
+	}
+	if response["data"] == nil {
+		t.Error("a query at the depth limit returned no data")
+	}
+
+	atomic.StoreInt32(&resolved, 0)
+	response = runGraphQL(t, schema, nestedQuery(deepest+1), nil)
+	if codes := graphQLErrorCodes(response); len(codes) != 1 || codes[0] != "invalid_request" {
+		t.Errorf("a query past the depth limit returned errors %v, want invalid_request", response["errors"])
+	}
+	if _, exists := response["data"]; exists || atomic.LoadInt32(&resolved) != 0 {
+		t.Errorf("a query past the depth limit ran: data %v, %d nodes resolved", response["data"], resolved)
+	}
+
+	// Fragments count toward depth where they're spread
+	fragmented := "{ node { ...deep } } fragment deep on Node { " + strings.Repeat("child { ", deepest+1) + "id" + strings.Repeat(" }", deepest+1) + " }"
+	if codes := graphQLErrorCodes(runGraphQL(t, schema, fragmented, nil)); len(codes) != 1 || codes[0] != "invalid_request" {
+		t.Errorf("a query too deep through a fragment returned codes %v, want invalid_request", codes)
+	}
+}
+
+func TestGraphQLComplexityLimit(t *testing.T) {
+	// node and each children field count one, and each children field
+	// multiplies what's under it by first: 2 + n(2 + m) for n outer and m
+	// inner children
+	nested := `query ($outer: Int, $inner: Int) { node { children(first: $outer) { id children(first: $inner) { id } } } }`
+	tests := []struct {
+		name         string
+		outer, inner int
+		tooComplex   bool
+	}{
+		{"small", 3, 4, false},
+		{"at the limit", 71, 68, false},
+		{"just over the limit", 71, 69, true},
+		{"far over the limit", 1000, 1000, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if complexity := 2 + test.outer*(2+test.inner); (complexity > maxGraphQLComplexity) != test.tooComplex {
+				t.Fatalf("complexity %d doesn't match the case", complexity)
+			}
+			var resolved int32
+			response := runGraphQL(t, testNodeSchema(&resolved), nested, map[string]interface{}{"outer": test.outer, "inner": test.inner})
+			codes := graphQLErrorCodes(response)
+			if test.tooComplex {
+				if len(codes) != 1 || codes[0] != "query_too_complex" {
+					t.Errorf("returned errors %v, want query_too_complex", response["errors"])
+				}
+				if atomic.LoadInt32(&resolved) != 0 {
+					t.Errorf("resolved %d nodes of a query that's too complex", resolved)
+				}
+				return
+			}
+			if len(codes) > 0 {
+				t.Errorf("returned errors %v", response["errors"])
+			}
+			if want := int32(1 + test.outer*(1+test.inner)); atomic.LoadInt32(&resolved) != want {
+				t.Errorf("resolved %d nodes, want %d", resolved, want)
+			}
+		})
+	}
+
+	// Lists without a size argument count at their default size
+	var resolved int32
+	response := runGraphQL(t, testNodeSchema(&resolved), `{ node { children { children { children { children { id } } } } } }`, nil)
+	if codes := graphQLErrorCodes(response); len(codes) != 1 || codes[0] != "query_too_complex" {
+		t.Errorf("four levels of ten children returned errors %v, want query_too_complex", response["errors"])
+	}
+}
+
+func TestGraphQLFragmentCycles(t *testing.T) {
+	var resolved int32
+	response := runGraphQL(t, testNodeSchema(&resolved), `{ node { ...a } } fragment a on Node { id ...b } fragment b on Node { child { ...a } }`, nil)
+	if codes := graphQLErrorCodes(response); len(codes) != 1 || codes[0] != "invalid_request" {
+		t.Errorf("a fragment cycle returned errors %v, want invalid_request", response["errors"])
+	}
+	if _, exists := response["data"]; exists || atomic.LoadInt32(&resolved) != 0 {
+		t.Errorf("a query with a fragment cycle ran: data %v", response["data"])
+	}
+
+	// The same fragment spread twice at one level is merged, not a cycle
+	response = runGraphQL(t, testNodeSchema(&resolved), `{ node { ...a ...a id } } fragment a on Node { id }`, nil)
+	if codes := graphQLErrorCodes(response); len(codes) > 0 {
+		t.Errorf("a fragment spread twice returned errors %v", response["errors"])
+	}
+}
+
+func TestGraphQLFieldErrors(t *testing.T) {
+	var resolved int32
+	response := runGraphQL(t, testNodeSchema(&resolved), `{ node { id broken } }`, nil)
+	node, _ := response["data"].(map[string]interface{})["node"].(map[string]interface{})
+	if node["id"] != "0" || node["broken"] != nil {
+		t.Errorf("node = %v, want its id with broken left null", node)
+	}
+	errors, _ := response["errors"].([]interface{})
+	if len(errors) != 1 {
+		t.Fatalf("returned errors %v, want one", response["errors"])
+	}
+	err := errors[0].(map[string]interface{})
+	if err["message"] != "node 0 is unavailable" || fmt.Sprint(err["path"]) != "[node broken]" {
+		t.Errorf("error = %v, want node 0 is unavailable at node.broken", err)
+	}
+	if codes := graphQLErrorCodes(response); codes[0] != "upstream_failure" {
+		t.Errorf("error code = %s, want upstream_failure", codes[0])
+	}
+}
+
+func TestGraphQLTransactionsFirstBounds(t *testing.T) {
+	schema := newFinanceGraphSchema()
+	query := `query ($first: Int) { customer(id: "sarah") { firstName transactions(first: $first) { id } } }`
+	tests := []struct {
+		name  string
+		first interface{}
+		valid bool
+	}{
+		{"default", nil, true},
+		{"one", 1, true},
+		{"the maximum", services.MaxTransactionPageLimit, true},
+		{"zero", 0, false},
+		{"negative", -5, false},
+		{"over the maximum", services.MaxTransactionPageLimit + 1, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			variables := map[string]interface{}{}
+			if test.first != nil {
+				variables["first"] = test.first
+			}
+			response := runGraphQL(t, schema, query, variables)
+			customer, _ := response["data"].(map[string]interface{})["customer"].(map[string]interface{})
+			if customer["firstName"] == nil {
+				t.Fatalf("customer = %v, errors %v", customer, response["errors"])
+			}
+
+			transactions, _ := customer["transactions"].([]interface{})
+			codes := graphQLErrorCodes(response)
+			if !test.valid {
+				if len(codes) != 1 || codes[0] != "invalid_request" || customer["transactions"] != nil {
+					t.Errorf("returned transactions %v and errors %v, want null and invalid_request", customer["transactions"], response["errors"])
+				}
+				return
+			}
+			limit, _ := test.first.(int)
+			if limit == 0 {
+				limit = services.DefaultTransactionPageLimit
+			}
+			if len(codes) > 0 || len(transactions) == 0 || len(transactions) > limit {
+				t.Errorf("returned %d transactions and errors %v, want 1 to %d", len(transactions), response["errors"], limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,893 @@
+package routes
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// This file parses GraphQL query documents: operations, fields with aliases
+// and arguments, variables, fragments and directives. Schema definitions
+// aren't accepted; the schema is built in code in graphql_schema.go.
+
+// queryDocument is a parsed GraphQL document
+type queryDocument struct {
+	operations []*queryOperation
+	fragments  map[string]*queryFragment
+}
+
+// queryOperation is a query, mutation or subscription in a document
+type queryOperation struct {
+	kind       string
+	name       string
+	variables  []queryVariable
+	selections []querySelection
+	position   queryPosition
+}
+
+// queryVariable declares a variable an operation takes
+type queryVariable struct {
+	name         string
+	typ          string
+	defaultValue *queryLiteral
+}
+
+// queryFragment is a named fragment
+type queryFragment struct {
+	name          string
+	typeCondition string
+	selections    []querySelection
+}
+
+// querySelection is one entry of a selection set. Exactly one of field,
+// spread and inline is set.
+type querySelection struct {
+	field      *queryField
+	spread     string
+	inline     *queryInlineFragment
+	directives []queryDirective
+	position   queryPosition
+}
+
+// queryField selects a field, under its alias if it has one
+type queryField struct {
+	alias      string
+	name       string
+	arguments  []queryArgument
+	selections []querySelection
+}
+
+// responseKey is the key a field's value is written under
+func (f *queryField) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// queryInlineFragment is a selection set applied when the type matches
+type queryInlineFragment struct {
+	typeCondition string
+	selections    []querySelection
+}
+
+type queryArgument struct {
+	name  string
+	value queryLiteral
+}
+
+type queryDirective struct {
+	name      string
+	arguments []queryArgument
+}
+
+// The kinds of value a query can contain
+const (
+	queryLiteralVariable = "variable"
+	queryLiteralInt      = "int"
+	queryLiteralFloat    = "float"
+	queryLiteralString   = "string"
+	queryLiteralBoolean  = "boolean"
+	queryLiteralNull     = "null"
+	queryLiteralEnum     = "enum"
+	queryLiteralList     = "list"
+	queryLiteralObject   = "object"
+)
+
+// queryLiteral is a value written in a query, or a variable standing for one
+type queryLiteral struct {
+	kind string
+	// raw is the variable name, the number's digits, the string's contents,
+	// true or false, or the enum value
+	raw    string
+	list   []queryLiteral
+	fields []queryArgument
+}
+
+// value returns v as decoded JSON would have it, looking variables up in
+// variables
+func (v queryLiteral) value(variables map[string]interface{}) interface{} {
+	switch v.kind {
+	case queryLiteralVariable:
+		return variables[v.raw]
+	case queryLiteralInt:
+		parsed, _ := strconv.ParseInt(v.raw, 10, 64)
+		return parsed
+	case queryLiteralFloat:
+		parsed, _ := strconv.ParseFloat(v.raw, 64)
+		return parsed
+	case queryLiteralBoolean:
+		return v.raw == "true"
+	case queryLiteralNull:
+		return nil
+	case queryLiteralList:
+		list := make([]interface{}, len(v.list))
+		for i, item := range v.list {
+			list[i] = item.value(variables)
+		}
+		return list
+	case queryLiteralObject:
+		object := make(map[string]interface{}, len(v.fields))
+		for _, field := range v.fields {
+			object[field.name] = field.value.value(variables)
+		}
+		return object
+	}
+	return v.raw
+}
+
+// queryPosition is where something is in a query, counted from 1
+type queryPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// querySyntaxError is a problem parsing a query
+type querySyntaxError struct {
+	message  string
+	position queryPosition
+}
+
+func (e *querySyntaxError) Error() string {
+	return fmt.Sprintf("Syntax Error: %s (line %d, column %d)", e.message, e.position.Line, e.position.Column)
+}
+
+// The kinds of token a query is made of
+const (
+	tokenEOF         = "<EOF>"
+	tokenName        = "Name"
+	tokenInt         = "Int"
+	tokenFloat       = "Float"
+	tokenString      = "String"
+	tokenPunctuation = "Punctuation"
+)
+
+type queryToken struct {
+	kind     string
+	value    string
+	position queryPosition
+}
+
+// queryLexer splits a query into tokens
+type queryLexer struct {
+	source string
+	offset int
+	line   int
+	// lineStart is the offset the current line starts at
+	lineStart int
+}
+
+func (l *queryLexer) position() queryPosition {
+	return queryPosition{Line: l.line, Column: utf8.RuneCountInString(l.source[l.lineStart:l.offset]) + 1}
+}
+
+func (l *queryLexer) errorf(format string, args ...interface{}) error {
+	return &querySyntaxError{message: fmt.Sprintf(format, args...), position: l.position()}
+}
+
+// skipIgnored skips whitespace, commas, comments and byte order marks
+func (l *queryLexer) skipIgnored() {
+	for l.offset < len(l.source) {
+		switch c := l.source[l.offset]; {
+		case c == '\n':
+			l.offset++
+			l.line++
+			l.lineStart = l.offset
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			l.offset++
+		case c == '#':
+			for l.offset < len(l.source) && l.source[l.offset] != '\n' {
+				l.offset++
+			}
+		case strings.HasPrefix(l.source[l.offset:], "\uFEFF"):
+			l.offset += len("\uFEFF")
+		default:
+			return
+		}
+	}
+}
+
+// next reads the next token
+func (l *queryLexer) next() (queryToken, error) {
+	l.skipIgnored()
+	position := l.position()
+	if l.offset >= len(l.source) {
+		return queryToken{kind: tokenEOF, position: position}, nil
+	}
+
+	c := l.source[l.offset]
+	switch {
+	case strings.HasPrefix(l.source[l.offset:], "..."):
+		l.offset += 3
+		return queryToken{kind: tokenPunctuation, value: "...", position: position}, nil
+	case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+		l.offset++
+		return queryToken{kind: tokenPunctuation, value: string(c), position: position}, nil
+	case c == '_' || isLetter(c):
+		start := l.offset
+		for l.offset < len(l.source) && (l.source[l.offset] == '_' || isLetter(l.source[l.offset]) || isDigit(l.source[l.offset])) {
+			l.offset++
+		}
+		return queryToken{kind: tokenName, value: l.source[start:l.offset], position: position}, nil
+	case c == '-' || isDigit(c):
+		return l.readNumber(position)
+	case c == '"':
+		if strings.HasPrefix(l.source[l.offset:], `"""`) {
+			return l.readBlockString(position)
+		}
+		return l.readString(position)
+	}
+	return queryToken{}, l.errorf("unexpected character %q", c)
+}
+
+func (l *queryLexer) readNumber(position queryPosition) (queryToken, error) {
+	start := l.offset
+	kind := tokenInt
+	if l.source[l.offset] == '-' {
+		l.offset++
+	}
+	if !l.readDigits() {
+		return queryToken{}, l.errorf("invalid number")
+	}
+	if l.offset < len(l.source) && l.source[l.offset] == '.' {
+		kind = tokenFloat
+		l.offset++
+		if !l.readDigits() {
+			return queryToken{}, l.errorf("invalid number")
+		}
+	}
+	if l.offset < len(l.source) && (l.source[l.offset] == 'e' || l.source[l.offset] == 'E') {
+		kind = tokenFloat
+		l.offset++
+		if l.offset < len(l.source) && (l.source[l.offset] == '+' || l.source[l.offset] == '-') {
+			l.offset++
+		}
+		if !l.readDigits() {
+			return queryToken{}, l.errorf("invalid number")
+		}
+	}
+	if l.offset < len(l.source) && (l.source[l.offset] == '_' || l.source[l.offset] == '.' || isLetter(l.source[l.offset])) {
+		return queryToken{}, l.errorf("invalid number")
+	}
+	return queryToken{kind: kind, value: l.source[start:l.offset], position: position}, nil
+}
+
+// readDigits reads a run of digits, reporting whether there were any
+func (l *queryLexer) readDigits() bool {
+	start := l.offset
+	for l.offset < len(l.source) && isDigit(l.source[l.offset]) {
+		l.offset++
+	}
+	return l.offset > start
+}
+
+func (l *queryLexer) readString(position queryPosition) (queryToken, error) {
+	l.offset++
+	var value strings.Builder
+	for l.offset < len(l.source) {
+		c := l.source[l.offset]
+		switch {
+		case c == '"':
+			l.offset++
+			return queryToken{kind: tokenString, value: value.String(), position: position}, nil
+		case c == '\n':
+			return queryToken{}, l.errorf("unterminated string")
+		case c == '\\':
+			if l.offset+1 >= len(l.source) {
+				return queryToken{}, l.errorf("unterminated string")
+			}
+			escape := l.source[l.offset+1]
+			l.offset += 2
+			switch escape {
+			case '"', '\\', '/':
+				value.WriteByte(escape)
+			case 'b':
+				value.WriteByte('\b')
+			case 'f':
+				value.WriteByte('\f')
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case 'u':
+				if l.offset+4 > len(l.source) {
+					return queryToken{}, l.errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(l.source[l.offset:l.offset+4], 16, 32)
+				if err != nil {
+					return queryToken{}, l.errorf("invalid unicode escape")
+				}
+				value.WriteRune(rune(code))
+				l.offset += 4
+			default:
+				return queryToken{}, l.errorf("invalid escape \\%c", escape)
+			}
+		default:
+			value.WriteByte(c)
+			l.offset++
+		}
+	}
+	return queryToken{}, l.errorf("unterminated string")
+}
+
+// readBlockString reads a """ string, removing the common indentation and
+// the blank first and last lines as the spec describes
+func (l *queryLexer) readBlockString(position queryPosition) (queryToken, error) {
+	l.offset += 3
+	end := strings.Index(l.source[l.offset:], `"""`)
+	for end >= 0 && l.offset+end > 0 && l.source[l.offset+end-1] == '\\' {
+		next := strings.Index(l.source[l.offset+end+3:], `"""`)
+		if next < 0 {
+			end = -1
+			break
+		}
+		end += 3 + next
+	}
+	if end < 0 {
+		return queryToken{}, l.errorf("unterminated string")
+	}
+	raw := l.source[l.offset : l.offset+end]
+	for _, c := range raw {
+		if c == '\n' {
+			l.line++
+		}
+	}
+	l.offset += end + 3
+	if i := strings.LastIndexByte(raw, '\n'); i >= 0 {
+		l.lineStart = l.offset - len(raw) - 3 + i + 1
+	}
+
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(raw, `\"""`, `"""`), "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && (indent < 0 || len(line)-len(trimmed) < indent) {
+			indent = len(line) - len(trimmed)
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		} else {
+			lines[i] = ""
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return queryToken{kind: tokenString, value: strings.Join(lines, "\n"), position: position}, nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// queryParser builds a document from tokens, looking one token ahead
+type queryParser struct {
+	lexer queryLexer
+	token queryToken
+}
+
+// parseQuery parses a GraphQL query document
+func parseQuery(source string) (*queryDocument, error) {
+	parser := &queryParser{lexer: queryLexer{source: source, line: 1}}
+	if err := parser.advance(); err != nil {
+		return nil, err
+	}
+
+	document := &queryDocument{fragments: make(map[string]*queryFragment)}
+	for parser.token.kind != tokenEOF {
+		switch {
+		case parser.peek(tokenPunctuation, "{"):
+			operation := &queryOperation{kind: "query", position: parser.token.position}
+			selections, err := parser.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			operation.selections = selections
+			document.operations = append(document.operations, operation)
+		case parser.peek(tokenName, "fragment"):
+			fragment, err := parser.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := document.fragments[fragment.name]; exists {
+				return nil, fmt.Errorf("there can be only one fragment named %q", fragment.name)
+			}
+			document.fragments[fragment.name] = fragment
+		case parser.peek(tokenName, "query"), parser.peek(tokenName, "mutation"), parser.peek(tokenName, "subscription"):
+			operation, err := parser.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			document.operations = append(document.operations, operation)
+		default:
+			return nil, parser.unexpected()
+		}
+	}
+	if len(document.operations) == 0 {
+		return nil, fmt.Errorf("the document contains no operations")
+	}
+	if err := document.checkFragmentCycles(); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// checkFragmentCycles fails if a fragment spreads itself, directly or
+// through other fragments. Spreads of unknown fragments are left for
+// execution to report.
+func (d *queryDocument) checkFragmentCycles() error {
+	// A fragment is in progress while its spreads are followed, and done after
+	const (
+		inProgress = 1
+		done       = 2
+	)
+	state := make(map[string]int, len(d.fragments))
+
+	var visit func(name string) error
+	var walk func(selections []querySelection) error
+	walk = func(selections []querySelection) error {
+		for _, selection := range selections {
+			var err error
+			switch {
+			case selection.field != nil:
+				err = walk(selection.field.selections)
+			case selection.inline != nil:
+				err = walk(selection.inline.selections)
+			case selection.spread != "":
+				err = visit(selection.spread)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	visit = func(name string) error {
+		fragment, exists := d.fragments[name]
+		if !exists || state[name] == done {
+			return nil
+		}
+		if state[name] == inProgress {
+			return fmt.Errorf("cannot spread fragment %q within itself", name)
+		}
+		state[name] = inProgress
+		if err := walk(fragment.selections); err != nil {
+			return err
+		}
+		state[name] = done
+		return nil
+	}
+
+	names := make([]string, 0, len(d.fragments))
+	for name := range d.fragments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *queryParser) advance() error {
+	token, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.token = token
+	return nil
+}
+
+// peek reports whether the current token is of kind with value
+func (p *queryParser) peek(kind string, value string) bool {
+	return p.token.kind == kind && p.token.value == value
+}
+
+// skip advances past the current token if it's the punctuation value,
+// reporting whether it was
+func (p *queryParser) skip(value string) (bool, error) {
+	if !p.peek(tokenPunctuation, value) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+// expect advances past the punctuation value, or fails if it isn't next
+func (p *queryParser) expect(value string) error {
+	if !p.peek(tokenPunctuation, value) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+// name reads a name
+func (p *queryParser) name() (string, error) {
+	if p.token.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.token.value
+	return name, p.advance()
+}
+
+func (p *queryParser) unexpected() error {
+	found := p.token.value
+	if p.token.kind == tokenEOF {
+		found = tokenEOF
+	}
+	return &querySyntaxError{message: fmt.Sprintf("unexpected %q", found), position: p.token.position}
+}
+
+func (p *queryParser) parseOperation() (*queryOperation, error) {
+	operation := &queryOperation{kind: p.token.value, position: p.token.position}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.token.kind == tokenName {
+		operation.name = p.token.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if open, err := p.skip("("); err != nil {
+		return nil, err
+	} else if open {
+		for {
+			if closed, err := p.skip(")"); err != nil {
+				return nil, err
+			} else if closed {
+				break
+			}
+			variable, err := p.parseVariableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			operation.variables = append(operation.variables, variable)
+		}
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	operation.selections = selections
+	return operation, nil
+}
+
+func (p *queryParser) parseVariableDefinition() (queryVariable, error) {
+	var variable queryVariable
+	if err := p.expect("$"); err != nil {
+		return variable, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return variable, err
+	}
+	variable.name = name
+	if err := p.expect(":"); err != nil {
+		return variable, err
+	}
+	if variable.typ, err = p.parseType(); err != nil {
+		return variable, err
+	}
+	if hasDefault, err := p.skip("="); err != nil {
+		return variable, err
+	} else if hasDefault {
+		value, err := p.parseValue(true)
+		if err != nil {
+			return variable, err
+		}
+		variable.defaultValue = &value
+	}
+	_, err = p.parseDirectives()
+	return variable, err
+}
+
+// parseType reads a type reference such as [ID!]!, returning it as written
+func (p *queryParser) parseType() (string, error) {
+	var typ string
+	if open, err := p.skip("["); err != nil {
+		return "", err
+	} else if open {
+		item, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		typ = "[" + item + "]"
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		typ = name
+	}
+	if nonNull, err := p.skip("!"); err != nil {
+		return "", err
+	} else if nonNull {
+		typ += "!"
+	}
+	return typ, nil
+}
+
+func (p *queryParser) parseFragment() (*queryFragment, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	position := p.token.position
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, &querySyntaxError{message: `a fragment can't be named "on"`, position: position}
+	}
+	if !p.peek(tokenName, "on") {
+		return nil, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	typeCondition, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &queryFragment{name: name, typeCondition: typeCondition, selections: selections}, nil
+}
+
+func (p *queryParser) parseSelectionSet() ([]querySelection, error) {
+	position := p.token.position
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []querySelection
+	for {
+		if closed, err := p.skip("}"); err != nil {
+			return nil, err
+		} else if closed {
+			break
+		}
+		selection, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	if len(selections) == 0 {
+		return nil, &querySyntaxError{message: "a selection set can't be empty", position: position}
+	}
+	return selections, nil
+}
+
+func (p *queryParser) parseSelection() (querySelection, error) {
+	selection := querySelection{position: p.token.position}
+
+	if spread, err := p.skip("..."); err != nil {
+		return selection, err
+	} else if spread {
+		if p.token.kind == tokenName && p.token.value != "on" {
+			selection.spread = p.token.value
+			if err := p.advance(); err != nil {
+				return selection, err
+			}
+			selection.directives, err = p.parseDirectives()
+			return selection, err
+		}
+		inline := &queryInlineFragment{}
+		if p.peek(tokenName, "on") {
+			if err := p.advance(); err != nil {
+				return selection, err
+			}
+			if inline.typeCondition, err = p.name(); err != nil {
+				return selection, err
+			}
+		}
+		if selection.directives, err = p.parseDirectives(); err != nil {
+			return selection, err
+		}
+		if inline.selections, err = p.parseSelectionSet(); err != nil {
+			return selection, err
+		}
+		selection.inline = inline
+		return selection, nil
+	}
+
+	field := &queryField{}
+	name, err := p.name()
+	if err != nil {
+		return selection, err
+	}
+	if alias, err := p.skip(":"); err != nil {
+		return selection, err
+	} else if alias {
+		field.alias = name
+		if name, err = p.name(); err != nil {
+			return selection, err
+		}
+	}
+	field.name = name
+	if field.arguments, err = p.parseArguments(false); err != nil {
+		return selection, err
+	}
+	if selection.directives, err = p.parseDirectives(); err != nil {
+		return selection, err
+	}
+	if p.peek(tokenPunctuation, "{") {
+		if field.selections, err = p.parseSelectionSet(); err != nil {
+			return selection, err
+		}
+	}
+	selection.field = field
+	return selection, nil
+}
+
+// parseArguments reads an optional parenthesized argument list
+func (p *queryParser) parseArguments(constant bool) ([]queryArgument, error) {
+	if open, err := p.skip("("); err != nil || !open {
+		return nil, err
+	}
+	var arguments []queryArgument
+	for {
+		if closed, err := p.skip(")"); err != nil {
+			return nil, err
+		} else if closed {
+			break
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue(constant)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, queryArgument{name: name, value: value})
+	}
+	if len(arguments) == 0 {
+		return nil, &querySyntaxError{message: "an argument list can't be empty", position: p.token.position}
+	}
+	return arguments, nil
+}
+
+func (p *queryParser) parseDirectives() ([]queryDirective, error) {
+	var directives []queryDirective
+	for p.peek(tokenPunctuation, "@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		arguments, err := p.parseArguments(false)
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, queryDirective{name: name, arguments: arguments})
+	}
+	return directives, nil
+}
+
+// parseValue reads a value. Constant values, such as variable defaults,
+// can't refer to variables.
+func (p *queryParser) parseValue(constant bool) (queryLiteral, error) {
+	token := p.token
+	switch token.kind {
+	case tokenPunctuation:
+		switch token.value {
+		case "$":
+			if constant {
+				return queryLiteral{}, p.unexpected()
+			}
+			if err := p.advance(); err != nil {
+				return queryLiteral{}, err
+			}
+			name, err := p.name()
+			return queryLiteral{kind: queryLiteralVariable, raw: name}, err
+		case "[":
+			if err := p.advance(); err != nil {
+				return queryLiteral{}, err
+			}
+			list := queryLiteral{kind: queryLiteralList, list: []queryLiteral{}}
+			for {
+				if closed, err := p.skip("]"); err != nil {
+					return queryLiteral{}, err
+				} else if closed {
+					return list, nil
+				}
+				item, err := p.parseValue(constant)
+				if err != nil {
+					return queryLiteral{}, err
+				}
+				list.list = append(list.list, item)
+			}
+		case "{":
+			if err := p.advance(); err != nil {
+				return queryLiteral{}, err
+			}
+			object := queryLiteral{kind: queryLiteralObject}
+			for {
+				if closed, err := p.skip("}"); err != nil {
+					return queryLiteral{}, err
+				} else if closed {
+					return object, nil
+				}
+				name, err := p.name()
+				if err != nil {
+					return queryLiteral{}, err
+				}
+				if err := p.expect(":"); err != nil {
+					return queryLiteral{}, err
+				}
+				value, err := p.parseValue(constant)
+				if err != nil {
+					return queryLiteral{}, err
+				}
+				object.fields = append(object.fields, queryArgument{name: name, value: value})
+			}
+		}
+	case tokenInt, tokenFloat, tokenString:
+		kind := map[string]string{tokenInt: queryLiteralInt, tokenFloat: queryLiteralFloat, tokenString: queryLiteralString}[token.kind]
+		return queryLiteral{kind: kind, raw: token.value}, p.advance()
+	case tokenName:
+		kind := queryLiteralEnum
+		switch token.value {
+		case "true", "false":
+			kind = queryLiteralBoolean
+		case "null":
+			kind = queryLiteralNull
+		}
+		return queryLiteral{kind: kind, raw: token.value}, p.advance()
+	}
+	return queryLiteral{}, p.unexpected()
+}
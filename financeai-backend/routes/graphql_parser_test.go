@@ -0,0 +1,176 @@
+package routes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	document, err := parseQuery(`
+		# Recent spending
+		query Spending($id: ID!, $first: Int = 10, $tags: [String!]) {
+			customer(id: $id) {
+				name: firstName
+				accounts { ...accountFields }
+				transactions(first: $first, category: "Food & Dining") @include(if: true) {
+					... on Transaction { id amount }
+				}
+			}
+		}
+
+		fragment accountFields on Account {
+			id
+			balance
+		}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(document.operations) != 1 {
+		t.Fatalf("parsed %d operations, want 1", len(document.operations))
+	}
+	operation := document.operations[0]
+	if operation.kind != "query" || operation.name != "Spending" {
+		t.Errorf("operation is %s %s, want query Spending", operation.kind, operation.name)
+	}
+	wantVariables := []queryVariable{
+		{name: "id", typ: "ID!"},
+		{name: "first", typ: "Int", defaultValue: &queryLiteral{kind: queryLiteralInt, raw: "10"}},
+		{name: "tags", typ: "[String!]"},
+	}
+	if !reflect.DeepEqual(operation.variables, wantVariables) {
+		t.Errorf("variables = %+v, want %+v", operation.variables, wantVariables)
+	}
+
+	customer := operation.selections[0].field
+	if customer == nil || customer.name != "customer" || len(customer.selections) != 3 {
+		t.Fatalf("first selection = %+v, want customer with 3 selections", operation.selections[0])
+	}
+	if argument := customer.arguments[0]; argument.name != "id" || argument.value.kind != queryLiteralVariable || argument.value.raw != "id" {
+		t.Errorf("customer argument = %+v, want id: $id", argument)
+	}
+	if name := customer.selections[0].field; name.alias != "name" || name.name != "firstName" || name.responseKey() != "name" {
+		t.Errorf("aliased field = %+v, want name: firstName", name)
+	}
+	if spread := customer.selections[1].field.selections[0].spread; spread != "accountFields" {
+		t.Errorf("spread = %q, want accountFields", spread)
+	}
+
+	transactions := customer.selections[2]
+	if len(transactions.directives) != 1 || transactions.directives[0].name != "include" {
+		t.Errorf("transactions directives = %+v, want @include", transactions.directives)
+	}
+	if category := transactions.field.arguments[1].value; category.kind != queryLiteralString || category.raw != "Food & Dining" {
+		t.Errorf("category argument = %+v", category)
+	}
+	if inline := transactions.field.selections[0].inline; inline == nil || inline.typeCondition != "Transaction" || len(inline.selections) != 2 {
+		t.Errorf("inline fragment = %+v, want on Transaction with 2 fields", inline)
+	}
+
+	fragment := document.fragments["accountFields"]
+	if fragment == nil || fragment.typeCondition != "Account" || len(fragment.selections) != 2 {
+		t.Errorf("fragment = %+v, want accountFields on Account with 2 fields", fragment)
+	}
+}
+
+func TestParseQueryShorthand(t *testing.T) {
+	document, err := parseQuery(`{ customer(id: "sarah") { id } }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if operation := document.operations[0]; operation.kind != "query" || operation.name != "" {
+		t.Errorf("operation is %s %q, want an anonymous query", operation.kind, operation.name)
+	}
+}
+
+func TestQueryLiteralValues(t *testing.T) {
+	tests := []struct {
+		literal string
+		want    interface{}
+	}{
+		{`42`, int64(42)},
+		{`-7`, int64(-7)},
+		{`1.5e3`, 1500.0},
+		{`"tab\there é"`, "tab\there é"},
+		{"\"\"\"\n    first\n      second\n\"\"\"", "first\n  second"},
+		{`true`, true},
+		{`null`, nil},
+		{`AVALANCHE`, "AVALANCHE"},
+		{`[1, "two", [3]]`, []interface{}{int64(1), "two", []interface{}{int64(3)}}},
+		{`{min: 10, tags: ["food"]}`, map[string]interface{}{"min": int64(10), "tags": []interface{}{"food"}}},
+		{`$first`, 25},
+	}
+	variables := map[string]interface{}{"first": 25}
+	for _, test := range tests {
+		document, err := parseQuery(`{ field(value: ` + test.literal + `) }`)
+		if err != nil {
+			t.Errorf("parsing %s: %v", test.literal, err)
+			continue
+		}
+		got := document.operations[0].selections[0].field.arguments[0].value.value(variables)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s = %#v, want %#v", test.literal, got, test.want)
+		}
+	}
+}
+
+func TestParseQuerySyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		line   int
+		column int
+	}{
+		{"unclosed selection set", "{ customer(id: \"sarah\") { id }", 1, 31},
+		{"empty selection set", "{ customer(id: \"sarah\") { } }", 1, 25},
+		{"missing argument value", "{ customer(id: ) { id } }", 1, 16},
+		{"unterminated string", "{ customer(id: \"sarah) { id } }", 1, 32},
+		{"bad escape", `{ customer(id: "\q") { id } }`, 1, 19},
+		{"invalid number", "{ field(value: 12abc) }", 1, 18},
+		{"unexpected character", "{ customer % }", 1, 12},
+		{"variable in a default", "query ($a: Int = $b) { id }", 1, 18},
+		{"fragment named on", "{ id } fragment on on Customer { id }", 1, 17},
+		{"error on a later line", "{\n  customer(id: \"sarah\") {\n    id:\n  }\n}", 4, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseQuery(test.query)
+			syntaxErr, ok := err.(*querySyntaxError)
+			if !ok {
+				t.Fatalf("parseQuery returned %v, want a syntax error", err)
+			}
+			if syntaxErr.position.Line != test.line || syntaxErr.position.Column != test.column {
+				t.Errorf("error %q is at line %d, column %d, want line %d, column %d", syntaxErr.message, syntaxErr.position.Line, syntaxErr.position.Column, test.line, test.column)
+			}
+		})
+	}
+}
+
+func TestParseQueryDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"no operations", "fragment a on Customer { id }", "no operations"},
+		{"duplicate fragment", "{ id } fragment a on Customer { id } fragment a on Customer { id }", `only one fragment named "a"`},
+		{"fragment spreads itself", "{ id } fragment a on Customer { id ...a }", `cannot spread fragment "a" within itself`},
+		{"fragment cycle", "{ id } fragment a on Customer { ...b } fragment b on Customer { accounts { ...c } } fragment c on Customer { ...a }", "within itself"},
+		{"cycle through an inline fragment", "{ id } fragment a on Customer { ... on Customer { ...a } }", `cannot spread fragment "a" within itself`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseQuery(test.query)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("parseQuery returned %v, want an error containing %q", err, test.want)
+			}
+		})
+	}
+
+	// A fragment spread twice without a cycle is fine
+	if _, err := parseQuery("{ ...a ...b } fragment a on Query { ...c } fragment b on Query { ...c } fragment c on Query { id }"); err != nil {
+		t.Errorf("parseQuery returned %v for fragments sharing a fragment", err)
+	}
+}
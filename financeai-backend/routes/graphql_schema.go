@@ -0,0 +1,385 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"financeai-backend/models"
+	"financeai-backend/services"
+)
+
+// graphProvider fetches the data GraphQL queries resolve: demo customers
+// from mock data and anyone else from Nessie when a key is configured, as the
+// REST routes do
+type graphProvider struct {
+	apiKey string
+	mock   *services.MockDataService
+	nessie *services.NessieService
+}
+
+func newGraphProvider(apiKey string) *graphProvider {
+	return &graphProvider{
+		apiKey: apiKey,
+		mock:   services.NewMockDataService(),
+		nessie: services.NewNessieService(apiKey),
+	}
+}
+
+func (p *graphProvider) useNessie(customerID string) bool {
+	return p.apiKey != "" && !p.mock.HasCustomer(customerID)
+}
+
+// customerTransactions is every transaction of a customer, along with the
+// accounts whose transactions couldn't be fetched
+type customerTransactions struct {
+	transactions []models.Transaction
+	failures     []models.AccountFetchFailure
+}
+
+// graphLoaders batches the provider calls one GraphQL request makes. Each is
+// keyed by customer ID, so however many accounts a query walks, a customer's
+// transactions are fetched once.
+type graphLoaders struct {
+	customers    *graphLoader[string, *models.Customer]
+	accounts     *graphLoader[string, []models.Account]
+	transactions *graphLoader[string, customerTransactions]
+	dashboards   *graphLoader[string, *models.DashboardData]
+	insights     *graphLoader[string, *models.InsightSnapshot]
+}
+
+// newLoaders returns loaders for one request
+func (p *graphProvider) newLoaders() *graphLoaders {
+	return &graphLoaders{
+		customers: newGraphLoader(fetchEach(func(ctx context.Context, customerID string) (*models.Customer, error) {
+			if p.useNessie(customerID) {
+				return p.nessie.GetCustomer(ctx, customerID)
+			}
+			return p.mock.GetCustomer(customerID)
+		})),
+		accounts: newGraphLoader(fetchEach(func(ctx context.Context, customerID string) ([]models.Account, error) {
+			if p.useNessie(customerID) {
+				return p.nessie.GetCustomerAccounts(ctx, customerID)
+			}
+			return p.mock.GetCustomerAccounts(customerID)
+		})),
+		transactions: newGraphLoader(fetchEach(func(ctx context.Context, customerID string) (customerTransactions, error) {
+			var result customerTransactions
+			var err error
+			if p.useNessie(customerID) {
+				result.transactions, result.failures, err = p.nessie.GetAllCustomerTransactions(ctx, customerID, models.TransactionFilter{})
+			} else {
+				result.transactions, err = p.mock.GetAllCustomerTransactions(customerID, models.TransactionFilter{})
+			}
+			return result, err
+		})),
+		dashboards: newGraphLoader(fetchEach(func(ctx context.Context, customerID string) (*models.DashboardData, error) {
+			if p.useNessie(customerID) {
+				return p.nessie.GetDashboardData(ctx, customerID)
+			}
+			return p.mock.GetDashboardData(customerID)
+		})),
+		insights: newGraphLoader(fetchEach(func(ctx context.Context, customerID string) (*models.InsightSnapshot, error) {
+			if p.useNessie(customerID) {
+				return nil, nil
+			}
+			snapshot, err := p.mock.GetLatestInsights(customerID)
+			if errors.Is(err, services.ErrNotFound) && p.mock.HasCustomer(customerID) {
+				// None generated yet
+				return nil, nil
+			}
+			return snapshot, err
+		})),
+	}
+}
+
+// dispatch fetches everything the loaders are waiting on, each loader at
+// the same time
+func (l *graphLoaders) dispatch(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, dispatch := range []func(context.Context){l.customers.dispatch, l.accounts.dispatch, l.transactions.dispatch, l.dashboards.dispatch, l.insights.dispatch} {
+		wg.Add(1)
+		go func(dispatch func(context.Context)) {
+			defer wg.Done()
+			dispatch(ctx)
+		}(dispatch)
+	}
+	wg.Wait()
+}
+
+// fetchEach makes a loader fetch that looks every key up at the same time,
+// for providers without a call that takes many keys
+func fetchEach[V any](fetch func(ctx context.Context, key string) (V, error)) func(ctx context.Context, keys []string) map[string]graphLoaded[V] {
+	return func(ctx context.Context, keys []string) map[string]graphLoaded[V] {
+		results := make([]graphLoaded[V], len(keys))
+		var wg sync.WaitGroup
+		for i, key := range keys {
+			wg.Add(1)
+			go func(i int, key string) {
+				defer wg.Done()
+				value, err := fetch(ctx, key)
+				results[i] = graphLoaded[V]{value: value, err: err}
+			}(i, key)
+		}
+		wg.Wait()
+
+		loaded := make(map[string]graphLoaded[V], len(keys))
+		for i, key := range keys {
+			loaded[key] = results[i]
+		}
+		return loaded
+	}
+}
+
+// graphCustomer is a customer with the ID they were asked for by, which for
+// demo customers is their username
+type graphCustomer struct {
+	id       string
+	customer *models.Customer
+}
+
+// graphAccount is an account with the customer it was listed for
+type graphAccount struct {
+	customerID string
+	account    models.Account
+}
+
+// graphValue resolves a field read straight off its source
+func graphValue[S any](get func(source S) interface{}) graphResolver {
+	return func(r *graphRequest, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return get(source.(S)), nil
+	}
+}
+
+// optionalString is null for an empty string
+func optionalString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// transactionArguments are the arguments fields listing transactions take
+var transactionArguments = []graphArgument{
+	{name: "first", typ: "Int", defaultValue: services.DefaultTransactionPageLimit, description: "How many transactions to return, newest first"},
+	{name: "category", typ: "String", description: "Only this category"},
+	{name: "search", typ: "String", description: "Text to search descriptions and merchants for"},
+}
+
+// resolveTransactions waits for a customer's transactions and returns the
+// newest first of those matching args
+func resolveTransactions(r *graphRequest, customerID string, accountID string, args map[string]interface{}) (interface{}, error) {
+	first, ok := args["first"].(int)
+	if !ok {
+		first = services.DefaultTransactionPageLimit
+	}
+	if first < 1 || first > services.MaxTransactionPageLimit {
+		return nil, services.NewValidationError("first", "first must be between 1 and %d", services.MaxTransactionPageLimit)
+	}
+	filter := models.TransactionFilter{AccountID: accountID}
+	filter.Category, _ = args["category"].(string)
+	filter.Search, _ = args["search"].(string)
+
+	load := r.loaders.transactions.load(customerID)
+	return graphThunk(func() (interface{}, error) {
+		loaded, err := load()
+		if err != nil {
+			return nil, err
+		}
+		for _, failure := range loaded.failures {
+			if failure.AccountID == accountID {
+				return nil, newUpstreamError("failed to fetch transactions for account %s: %s", accountID, failure.Error)
+			}
+		}
+		transactions, err := services.FilterTransactions(loaded.transactions, filter)
+		if err != nil {
+			return nil, err
+		}
+		if len(transactions) > first {
+			transactions = transactions[:first]
+		}
+		return transactions, nil
+	}), nil
+}
+
+// newFinanceGraphSchema builds the GraphQL schema: a customer, their
+// accounts and transactions with merchants, spending aggregates and the
+// latest insights
+func newFinanceGraphSchema() *graphSchema {
+	query := &graphObject{name: "Query", fields: []*graphField{
+		{
+			name: "customer", typ: "Customer", description: "A customer by ID; demo customers by username",
+			arguments: []graphArgument{{name: "id", typ: "ID!"}},
+			resolve: func(r *graphRequest, source interface{}, args map[string]interface{}) (interface{}, error) {
+				id := args["id"].(string)
+				load := r.loaders.customers.load(id)
+				return graphThunk(func() (interface{}, error) {
+					customer, err := load()
+					if err != nil {
+						return nil, err
+					}
+					return graphCustomer{id: id, customer: customer}, nil
+				}), nil
+			},
+		},
+	}}
+
+	customer := &graphObject{name: "Customer", fields: []*graphField{
+		{name: "id", typ: "ID!", resolve: graphValue(func(c graphCustomer) interface{} { return c.id })},
+		{name: "username", typ: "String!", resolve: graphValue(func(c graphCustomer) interface{} { return c.customer.Username })},
+		{name: "firstName", typ: "String!", resolve: graphValue(func(c graphCustomer) interface{} { return c.customer.FirstName })},
+		{name: "lastName", typ: "String!", resolve: graphValue(func(c graphCustomer) interface{} { return c.customer.LastName })},
+		{name: "address", typ: "Address!", resolve: graphValue(func(c graphCustomer) interface{} { return c.customer.Address })},
+		{name: "createdDate", typ: "String!", resolve: graphValue(func(c graphCustomer) interface{} { return c.customer.CreatedDate })},
+		{
+			name: "accounts", typ: "[Account!]", listSize: 5,
+			resolve: func(r *graphRequest, source interface{}, args map[string]interface{}) (interface{}, error) {
+				customerID := source.(graphCustomer).id
+				load := r.loaders.accounts.load(customerID)
+				return graphThunk(func() (interface{}, error) {
+					accounts, err := load()
+					if err != nil {
+						return nil, err
+					}
+					result := make([]graphAccount, len(accounts))
+					for i, account := range accounts {
+						result[i] = graphAccount{customerID: customerID, account: account}
+					}
+					return result, nil
+				}), nil
+			},
+		},
+		{
+			name: "transactions", typ: "[Transaction!]", sizeArgument: "first", listSize: services.DefaultTransactionPageLimit,
+			description: "Transactions across every account, newest first",
+			arguments:   append([]graphArgument{{name: "accountId", typ: "ID", description: "Only this account's transactions"}}, transactionArguments...),
+			resolve: func(r *graphRequest, source interface{}, args map[string]interface{}) (interface{}, error) {
+				accountID, _ := args["accountId"].(string)
+				return resolveTransactions(r, source.(graphCustomer).id, accountID, args)
+			},
+		},
+		{
+			name: "spending", typ: "SpendingData", description: "Spending aggregates in the customer's reporting currency",
+			resolve: func(r *graphRequest, source interface{}, args map[string]interface{}) (interface{}, error) {
+				load := r.loaders.dashboards.load(source.(graphCustomer).id)
+				return graphThunk(func() (interface{}, error) { return load() }), nil
+			},
+		},
+		{
+			name: "insights", typ: "InsightSnapshot", description: "The latest spending insights generated, if any",
+			resolve: func(r *graphRequest, source interface{}, args map[string]interface{}) (interface{}, error) {
+				load := r.loaders.insights.load(source.(graphCustomer).id)
+				return graphThunk(func() (interface{}, error) { return load() }), nil
+			},
+		},
+	}}
+
+	address := &graphObject{name: "Address", fields: []*graphField{
+		{name: "streetNumber", typ: "String!", resolve: graphValue(func(a models.Address) interface{} { return a.StreetNumber })},
+		{name: "streetName", typ: "String!", resolve: graphValue(func(a models.Address) interface{} { return a.StreetName })},
+		{name: "city", typ: "String!", resolve: graphValue(func(a models.Address) interface{} { return a.City })},
+		{name: "state", typ: "String!", resolve: graphValue(func(a models.Address) interface{} { return a.State })},
+		{name: "zip", typ: "String!", resolve: graphValue(func(a models.Address) interface{} { return a.Zip })},
+	}}
+
+	account := &graphObject{name: "Account", fields: []*graphField{
+		{name: "id", typ: "ID!", resolve: graphValue(func(a graphAccount) interface{} { return a.account.ID })},
+		{name: "type", typ: "String!", resolve: graphValue(func(a graphAccount) interface{} { return a.account.Type })},
+		{name: "nickname", typ: "String!", resolve: graphValue(func(a graphAccount) interface{} { return a.account.Nickname })},
+		{name: "rewards", typ: "Int!", resolve: graphValue(func(a graphAccount) interface{} { return a.account.Rewards })},
+		{name: "balance", typ: "Int!", resolve: graphValue(func(a graphAccount) interface{} { return a.account.Balance })},
+		{name: "accountNumber", typ: "String!", resolve: graphValue(func(a graphAccount) interface{} { return a.account.AccountNumber })},
+		{name: "currency", typ: "String", resolve: graphValue(func(a graphAccount) interface{} { return optionalString(a.account.Currency) })},
+		{
+			name: "transactions", typ: "[Transaction!]", sizeArgument: "first", listSize: services.DefaultTransactionPageLimit,
+			description: "The account's transactions, newest first",
+			arguments:   transactionArguments,
+			resolve: func(r *graphRequest, source interface{}, args map[string]interface{}) (interface{}, error) {
+				account := source.(graphAccount)
+				return resolveTransactions(r, account.customerID, account.account.ID, args)
+			},
+		},
+	}}
+
+	transaction := &graphObject{name: "Transaction", fields: []*graphField{
+		{name: "id", typ: "ID!", resolve: graphValue(func(t models.Transaction) interface{} { return t.ID })},
+		{name: "type", typ: "String!", resolve: graphValue(func(t models.Transaction) interface{} { return t.Type })},
+		{name: "amount", typ: "Float!", resolve: graphValue(func(t models.Transaction) interface{} { return t.Amount })},
+		{name: "currency", typ: "String", resolve: graphValue(func(t models.Transaction) interface{} { return optionalString(t.Currency) })},
+		{name: "description", typ: "String!", resolve: graphValue(func(t models.Transaction) interface{} { return t.Description })},
+		{name: "date", typ: "String!", resolve: graphValue(func(t models.Transaction) interface{} { return t.TransactionDate })},
+		{name: "status", typ: "String!", resolve: graphValue(func(t models.Transaction) interface{} { return t.Status })},
+		{name: "category", typ: "String", resolve: graphValue(func(t models.Transaction) interface{} { return optionalString(t.Merchant.Category) })},
+		{name: "accountId", typ: "ID!", resolve: graphValue(func(t models.Transaction) interface{} { return t.AccountID })},
+		{name: "tags", typ: "[String!]!", resolve: graphValue(func(t models.Transaction) interface{} { return t.Tags })},
+		{name: "notes", typ: "String", resolve: graphValue(func(t models.Transaction) interface{} { return optionalString(t.Notes) })},
+		{name: "isTransfer", typ: "Boolean!", resolve: graphValue(func(t models.Transaction) interface{} { return t.IsTransfer })},
+		{
+			name: "merchant", typ: "Merchant", description: "Where the money went, joined by the provider",
+			resolve: graphValue(func(t models.Transaction) interface{} {
+				if t.Merchant.ID == "" && t.Merchant.Name == "" {
+					return nil
+				}
+				return t.Merchant
+			}),
+		},
+	}}
+
+	merchant := &graphObject{name: "Merchant", fields: []*graphField{
+		{name: "id", typ: "ID", resolve: graphValue(func(m models.Merchant) interface{} { return optionalString(m.ID) })},
+		{name: "name", typ: "String!", resolve: graphValue(func(m models.Merchant) interface{} { return m.Name })},
+		{name: "category", typ: "String", resolve: graphValue(func(m models.Merchant) interface{} { return optionalString(m.Category) })},
+		{name: "logoKey", typ: "String", resolve: graphValue(func(m models.Merchant) interface{} { return optionalString(m.LogoKey) })},
+		{name: "geocode", typ: "Geocode", resolve: graphValue(func(m models.Merchant) interface{} { return m.Geocode })},
+	}}
+
+	geocode := &graphObject{name: "Geocode", fields: []*graphField{
+		{name: "lat", typ: "Float!", resolve: graphValue(func(g *models.Geocode) interface{} { return g.Lat })},
+		{name: "lng", typ: "Float!", resolve: graphValue(func(g *models.Geocode) interface{} { return g.Lng })},
+	}}
+
+	spending := &graphObject{name: "SpendingData", fields: []*graphField{
+		{name: "currency", typ: "String", resolve: graphValue(func(d *models.DashboardData) interface{} { return optionalString(d.Currency) })},
+		{name: "locale", typ: "String", resolve: graphValue(func(d *models.DashboardData) interface{} { return optionalString(d.Locale) })},
+		{name: "totalMonthlySpend", typ: "Float!", resolve: graphValue(func(d *models.DashboardData) interface{} { return d.SpendingData.TotalMonthlySpend })},
+		{name: "monthly", typ: "[MonthlySpending!]!", listSize: 12, resolve: graphValue(func(d *models.DashboardData) interface{} { return d.SpendingData.MonthlySpending })},
+		{name: "daily", typ: "[DailySpending!]!", listSize: 31, resolve: graphValue(func(d *models.DashboardData) interface{} { return d.SpendingData.DailySpending })},
+		{name: "categories", typ: "[CategorySpending!]!", listSize: 10, resolve: graphValue(func(d *models.DashboardData) interface{} { return d.SpendingData.CategorySpending })},
+		{
+			name: "partial", typ: "Boolean!", description: "Whether some accounts' transactions couldn't be fetched",
+			resolve: graphValue(func(d *models.DashboardData) interface{} { return d.Partial }),
+		},
+	}}
+
+	monthly := &graphObject{name: "MonthlySpending", fields: []*graphField{
+		{name: "month", typ: "String!", resolve: graphValue(func(m models.MonthlySpending) interface{} { return m.Month })},
+		{name: "amount", typ: "Float!", resolve: graphValue(func(m models.MonthlySpending) interface{} { return m.Amount })},
+	}}
+
+	daily := &graphObject{name: "DailySpending", fields: []*graphField{
+		{name: "day", typ: "String!", resolve: graphValue(func(d models.DailySpending) interface{} { return d.Day })},
+		{name: "amount", typ: "Float!", resolve: graphValue(func(d models.DailySpending) interface{} { return d.Amount })},
+	}}
+
+	category := &graphObject{name: "CategorySpending", fields: []*graphField{
+		{name: "category", typ: "String!", resolve: graphValue(func(c models.CategorySpending) interface{} { return c.Category })},
+		{name: "amount", typ: "Float!", resolve: graphValue(func(c models.CategorySpending) interface{} { return c.Amount })},
+		{name: "color", typ: "String!", resolve: graphValue(func(c models.CategorySpending) interface{} { return c.Color })},
+	}}
+
+	snapshot := &graphObject{name: "InsightSnapshot", fields: []*graphField{
+		{name: "generatedAt", typ: "String!", resolve: graphValue(func(s *models.InsightSnapshot) interface{} { return s.GeneratedAt })},
+		{name: "source", typ: "String!", resolve: graphValue(func(s *models.InsightSnapshot) interface{} { return s.Source })},
+		{name: "insights", typ: "[SpendingInsight!]!", listSize: 5, resolve: graphValue(func(s *models.InsightSnapshot) interface{} { return s.Insights })},
+	}}
+
+	insight := &graphObject{name: "SpendingInsight", fields: []*graphField{
+		{name: "title", typ: "String!", resolve: graphValue(func(i models.SpendingInsight) interface{} { return i.Title })},
+		{name: "description", typ: "String!", resolve: graphValue(func(i models.SpendingInsight) interface{} { return i.Description })},
+		{name: "category", typ: "String!", resolve: graphValue(func(i models.SpendingInsight) interface{} { return i.Category })},
+		{name: "amount", typ: "String!", resolve: graphValue(func(i models.SpendingInsight) interface{} { return i.Amount })},
+		{name: "tip", typ: "String!", resolve: graphValue(func(i models.SpendingInsight) interface{} { return i.Tip })},
+	}}
+
+	return newGraphSchema(query, customer, address, account, transaction, merchant, geocode, spending, monthly, daily, category, snapshot, insight)
+}
@@ -1,7 +1,9 @@
 package routes
 
 import (
+    "fmt"
     "net/http"
+    "time"
 
     "github.com/gin-gonic/gin"
     "financeai-backend/services"
@@ -15,33 +17,36 @@ func RegisterInsightRoutes(rg *gin.RouterGroup, apiKey string) {
     rg.GET("/insights", func(c *gin.Context) {
         customerId := c.Query("customerId")
         if customerId == "" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "customerId required"})
+            c.Error(errCustomerIDRequired)
             return
         }
 
         // Get all transactions for the customer
-        transactions, err := mockService.GetAllCustomerTransactions(customerId)
+        transactions, err := mockService.GetAllCustomerTransactions(customerId, models.TransactionFilter{})
         if err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            c.Error(withLegacyStatus(err, http.StatusInternalServerError))
             return
         }
 
-        // Generate basic insights from transaction data
-        insights := generateInsights(transactions)
+        // Generate basic insights from transaction data, totalled in the
+        // customer's reporting currency
+        cashflow := services.AnalyzeCashflow(mockService.ConvertTransactions(customerId, transactions), 3, time.Now())
+        insights := generateInsights(transactions, cashflow, mockService.MoneyFormatter(customerId))
 
         c.JSON(http.StatusOK, gin.H{
             "customerId":  customerId,
             "transactions": transactions,
             "insights":    insights,
+            "cashflow":    cashflow,
         })
     })
 }
 
 // generateInsights creates basic insights from transaction data
-func generateInsights(transactions []models.Transaction) []map[string]interface{} {
+func generateInsights(transactions []models.Transaction, cashflow models.CashflowSummary, money services.MoneyFormatter) []map[string]interface{} {
     // For now, return mock insights
     // In Phase 2, this will be replaced with OpenAI-powered insights
-    return []map[string]interface{}{
+    insights := []map[string]interface{}{
         {
             "id":      "1",
             "title":   "Spending Analysis",
@@ -64,4 +69,26 @@ func generateInsights(transactions []models.Transaction) []map[string]interface{
             "type":    "savings",
         },
     }
+
+    if cashflow.TotalIncome > 0 {
+        trend := "positive"
+        if cashflow.SavingsRate < 0 {
+            trend = "negative"
+        } else if cashflow.SavingsRate < 20 {
+            trend = "neutral"
+        }
+        message := fmt.Sprintf("You've earned %s and spent %s over the last %d months, a savings rate of %.1f%%", money.Format(cashflow.TotalIncome), money.Format(cashflow.TotalExpenses), len(cashflow.Months), cashflow.SavingsRate)
+        if cashflow.Payroll != nil {
+            message += fmt.Sprintf(". You're paid %s, about %s per paycheck", cashflow.Payroll.Frequency, money.Format(cashflow.Payroll.AverageAmount))
+        }
+        insights = append(insights, map[string]interface{}{
+            "id":      "4",
+            "title":   "Income vs Spending",
+            "message": message,
+            "trend":   trend,
+            "type":    "cashflow",
+        })
+    }
+
+    return insights
 }
@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// RegisterJobRoutes sets up /api/jobs for the background job scheduler
+func RegisterJobRoutes(rg *gin.RouterGroup, apiKey string) {
+	scheduler := services.DefaultScheduler()
+
+	// Every background job with its schedule, next run and last run
+	rg.GET("/jobs", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"jobs": scheduler.Jobs()})
+	})
+
+	// One job with its recent runs, newest first
+	rg.GET("/jobs/:name", func(c *gin.Context) {
+		job, err := scheduler.Job(c.Param("name"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"job": job})
+	})
+
+	// Run a job now. The run happens in the background; poll the job for its result.
+	rg.POST("/jobs/:name/run", func(c *gin.Context) {
+		if _, err := scheduler.Job(c.Param("name")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		run, err := scheduler.Trigger(c.Param("name"))
+		if errors.Is(err, services.ErrJobRunning) {
+			c.Error(err)
+			return
+		}
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"run": run})
+	})
+
+	// Change a job's schedule, e.g. {"schedule": "0 3 * * *"}, or turn it on or off
+	rg.PATCH("/jobs/:name", func(c *gin.Context) {
+		var request struct {
+			Schedule *string `json:"schedule"`
+			Enabled  *bool   `json:"enabled"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if _, err := scheduler.Job(c.Param("name")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		job, err := scheduler.Update(c.Param("name"), request.Schedule, request.Enabled)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"job": job})
+	})
+}
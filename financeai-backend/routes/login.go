@@ -16,15 +16,15 @@ func RegisterLoginRoutes(rg *gin.RouterGroup) {
 			Username string `json:"username"`
 			Password string `json:"password"`
 		}
-		if err := c.BindJSON(&body); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.Error(errInvalidRequestFormat)
 			return
 		}
 
 		// Validate credentials against mock data
 		customer, err := mockService.GetCustomerByCredentials(body.Username, body.Password)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Username or password not found, try again"})
+			c.Error(err)
 			return
 		}
 
@@ -0,0 +1,134 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// RegisterMovementRoutes sets up the endpoints that move money on an account:
+// POST /api/accounts/:id/transfers, /deposits, /withdrawals, /purchases and
+// /bills. Each accepts an Idempotency-Key header so clients can safely retry.
+func RegisterMovementRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+	nessieService := services.NewNessieService(apiKey)
+	idempotency := services.DefaultIdempotencyStore()
+
+	// Demo customers move money in mock data; anyone else in Nessie when a
+	// key is configured
+	useNessie := func(customerId string) bool {
+		return apiKey != "" && !mockService.HasCustomer(customerId)
+	}
+
+	// respond sends a created movement or bill, or reports why it couldn't
+	// be created
+	respond := func(c *gin.Context, field string, result json.RawMessage, replayed bool, err error) {
+		if err != nil {
+			// The legacy routes reported failures other than the movement's
+			// own errors by Nessie's status
+			if !errors.Is(err, services.ErrValidation) && !errors.Is(err, services.ErrConflict) && !errors.Is(err, services.ErrUnknownAccount) {
+				err = withLegacyStatus(err, legacyNessieStatus(err))
+			}
+			c.Error(err)
+			return
+		}
+		if replayed {
+			c.Header(services.IdempotencyReplayedHeader, "true")
+		}
+		c.JSON(http.StatusCreated, gin.H{field: result})
+	}
+
+	// movement handles one kind of money movement
+	movement := func(kind string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			var request struct {
+				CustomerId string `json:"customerId"`
+				services.MovementInput
+			}
+
+			if err := c.ShouldBindJSON(&request); err != nil {
+				c.Error(errInvalidRequestFormat)
+				return
+			}
+
+			if request.CustomerId == "" {
+				c.Error(errCustomerIDRequired)
+				return
+			}
+
+			// Retries are matched on the request as sent, before defaults
+			// like today's date are filled in
+			request.AccountID = c.Param("id")
+			sent := request.MovementInput
+			if err := services.ValidateMovement(kind, &request.MovementInput); err != nil {
+				c.Error(err)
+				return
+			}
+
+			nessie := useNessie(request.CustomerId)
+			if !nessie && !mockService.HasCustomer(request.CustomerId) {
+				c.Error(services.NewNotFoundError("customer not found: %s", request.CustomerId))
+				return
+			}
+
+			// Keys are the customer's across every kind of movement and bill,
+			// so a key reused on another endpoint is a different request
+			fingerprint := []interface{}{kind, request.AccountID, sent}
+			result, replayed, err := idempotency.Do(request.CustomerId, c.GetHeader(services.IdempotencyKeyHeader), fingerprint, func() (interface{}, error) {
+				if nessie {
+					return nessieService.CreateMovement(c.Request.Context(), request.CustomerId, kind, request.MovementInput)
+				}
+				return mockService.CreateMovement(request.CustomerId, kind, request.MovementInput)
+			})
+			respond(c, "movement", result, replayed, err)
+		}
+	}
+
+	rg.POST("/accounts/:id/transfers", movement(services.MovementTransfer))
+	rg.POST("/accounts/:id/deposits", movement(services.MovementDeposit))
+	rg.POST("/accounts/:id/withdrawals", movement(services.MovementWithdrawal))
+	rg.POST("/accounts/:id/purchases", movement(services.MovementPurchase))
+
+	// Schedule a bill payment from the account, once or every month
+	rg.POST("/accounts/:id/bills", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+			services.BillPaymentInput
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		request.AccountID = c.Param("id")
+		sent := request.BillPaymentInput
+		if err := services.ValidateBillPayment(&request.BillPaymentInput); err != nil {
+			c.Error(err)
+			return
+		}
+
+		nessie := useNessie(request.CustomerId)
+		if !nessie && !mockService.HasCustomer(request.CustomerId) {
+			c.Error(services.NewNotFoundError("customer not found: %s", request.CustomerId))
+			return
+		}
+
+		fingerprint := []interface{}{"bill", request.AccountID, sent}
+		result, replayed, err := idempotency.Do(request.CustomerId, c.GetHeader(services.IdempotencyKeyHeader), fingerprint, func() (interface{}, error) {
+			if nessie {
+				return nessieService.CreateBill(c.Request.Context(), request.CustomerId, request.BillPaymentInput)
+			}
+			return mockService.CreateBillPayment(request.CustomerId, request.BillPaymentInput)
+		})
+		respond(c, "bill", result, replayed, err)
+	})
+}
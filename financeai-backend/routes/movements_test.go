@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+func TestMovementIdempotencyKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterRoutes(r, "", "")
+
+	post := func(path string, body string, key string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1"+path, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set(services.IdempotencyKeyHeader, key)
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	deposit := `{"customerId":"sarah","amount":12.34,"description":"Cashback"}`
+	first := post("/accounts/acc1/deposits", deposit, "movement-test")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", first.Code, http.StatusCreated, first.Body)
+	}
+
+	retry := post("/accounts/acc1/deposits", deposit, "movement-test")
+	if retry.Code != http.StatusCreated || retry.Header().Get(services.IdempotencyReplayedHeader) != "true" || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry returned %d, replayed %q: %s", retry.Code, retry.Header().Get(services.IdempotencyReplayedHeader), retry.Body)
+	}
+
+	// The key is the customer's, not the endpoint's
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"another kind of movement", "/accounts/acc1/withdrawals", `{"customerId":"sarah","amount":12.34,"description":"Cashback"}`},
+		{"a bill payment", "/accounts/acc1/bills", `{"customerId":"sarah","payee":"Cashback","amount":12.34}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if recorder := post(test.path, test.body, "movement-test"); recorder.Code != http.StatusUnprocessableEntity {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, http.StatusUnprocessableEntity, recorder.Body)
+			}
+		})
+	}
+
+	// Another customer can use the same key
+	if recorder := post("/accounts/acc3/deposits", `{"customerId":"michael","amount":5}`, "movement-test"); recorder.Code != http.StatusCreated {
+		t.Errorf("another customer using the key returned %d: %s", recorder.Code, recorder.Body)
+	}
+}
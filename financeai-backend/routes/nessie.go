@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// RegisterNessieRoutes sets up /api/nessie for inspecting how Nessie's
+// responses decoded
+func RegisterNessieRoutes(rg *gin.RouterGroup, apiKey string) {
+	report := services.DefaultNessieDecodeReport()
+
+	// Records each Nessie endpoint last returned that couldn't be decoded
+	rg.GET("/nessie/decode-errors", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"endpoints": report.Results()})
+	})
+
+	// Forget the reported errors, such as after fixing the data in Nessie
+	rg.DELETE("/nessie/decode-errors", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"cleared": report.Clear()})
+	})
+}
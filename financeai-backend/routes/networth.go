@@ -0,0 +1,169 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// RegisterNetWorthRoutes sets up /api/networth and the manual account endpoints
+func RegisterNetWorthRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+
+	// Net worth history and breakdown by account type, e.g. ?days=90
+	rg.GET("/networth", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		days := services.DefaultNetWorthDays
+		if value := c.Query("days"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > services.MaxNetWorthDays {
+				c.Error(services.NewValidationError("days", "days must be between 1 and %d", services.MaxNetWorthDays))
+				return
+			}
+			days = parsed
+		}
+
+		summary, err := mockService.GetNetWorth(customerId, days)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"customerId": customerId,
+			"networth":   summary,
+		})
+	})
+
+	// Record today's balance of every account
+	rg.POST("/networth/snapshots", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		snapshots, err := mockService.RecordBalanceSnapshots(request.CustomerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"snapshots": snapshots})
+	})
+
+	// List manual accounts such as vehicles or student loans
+	rg.GET("/networth/accounts", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		accounts, err := mockService.GetManualAccounts(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"accounts": accounts})
+	})
+
+	// Add a manual account; liabilities are entered as the amount owed
+	rg.POST("/networth/accounts", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+			services.ManualAccountInput
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		account, err := mockService.AddManualAccount(request.CustomerId, request.ManualAccountInput)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"account": account})
+	})
+
+	// Update a manual account's name, type or current value
+	rg.PATCH("/networth/accounts/:id", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+			services.ManualAccountInput
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		accounts, err := mockService.GetManualAccounts(request.CustomerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		found := false
+		for _, account := range accounts {
+			found = found || account.ID == c.Param("id")
+		}
+		if !found {
+			c.Error(services.NewNotFoundError("manual account not found: %s", c.Param("id")))
+			return
+		}
+
+		account, err := mockService.UpdateManualAccount(request.CustomerId, c.Param("id"), request.ManualAccountInput)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"account": account})
+	})
+
+	// Remove a manual account and its history
+	rg.DELETE("/networth/accounts/:id", func(c *gin.Context) {
+		customerId := strings.TrimSpace(c.Query("customerId"))
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if err := mockService.DeleteManualAccount(customerId, c.Param("id")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,175 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+	"financeai-backend/models"
+)
+
+// RegisterNotificationRoutes sets up /api/notifications and notification preferences
+func RegisterNotificationRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+
+	// The customer's in-app notifications, newest first. Pass ?unread=true
+	// for unread notifications only.
+	rg.GET("/notifications", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		notifications, err := mockService.GetNotifications(customerId, c.Query("unread") == "true")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		unread := 0
+		for _, notification := range notifications {
+			if !notification.Read {
+				unread++
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"customerId":    customerId,
+			"total":         len(notifications),
+			"unread":        unread,
+			"notifications": notifications,
+		})
+	})
+
+	// Mark a notification as read
+	rg.POST("/notifications/:id/read", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		notification, err := mockService.MarkNotificationRead(request.CustomerId, c.Param("id"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"notification": notification})
+	})
+
+	// Which events the customer is notified about, on which channels, and
+	// the budgets and thresholds that trigger them
+	rg.GET("/notifications/preferences", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		preferences, err := mockService.GetNotificationPreferences(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"preferences": preferences})
+	})
+
+	// Change notification preferences. Fields left out of the body keep
+	// their current values. Turning on the webhook channel returns the
+	// signing secret of the customer's notification webhook, once.
+	rg.PATCH("/notifications/preferences", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		preferences, err := mockService.GetNotificationPreferences(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		// Decode onto the current preferences so only the fields sent change
+		if err := json.NewDecoder(c.Request.Body).Decode(preferences); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		updated, err := mockService.SetNotificationPreferences(customerId, *preferences)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"preferences": updated})
+	})
+
+	// Check the customer's budgets, transactions, balances, bills and goals
+	// now and send notifications for anything new
+	rg.POST("/notifications/evaluate", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		events, err := mockService.EvaluateNotifications(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		notifications, _ := mockService.GetNotifications(customerId, true)
+		c.JSON(http.StatusOK, gin.H{
+			"customerId": customerId,
+			"events":     events,
+			"unread":     len(notifications),
+		})
+	})
+
+	// Send a test notification on every channel the customer has turned on
+	rg.POST("/notifications/test", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		notification, err := mockService.SendTestNotification(customerId)
+		if notification == nil && err != nil {
+			c.Error(err)
+			return
+		}
+		if err != nil {
+			// Some channels failed; say so alongside what was sent
+			status, body := errorResponse(c, err)
+			if legacyShape(c) {
+				c.JSON(status, struct {
+					legacyError
+					Notification *models.Notification `json:"notification"`
+				}{body.legacy(), notification})
+				return
+			}
+			c.JSON(status, struct {
+				apiError
+				Notification *models.Notification `json:"notification"`
+			}{body, notification})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"notification": notification})
+	})
+}
@@ -0,0 +1,610 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPISchema is the subset of OpenAPI 3.0 schema objects the spec uses
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	AllOf                []*openAPISchema          `json:"allOf,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool                      `json:"exclusiveMinimum,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+// openAPIParameter is a path or query parameter of an operation
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Style       string         `json:"style,omitempty"`
+	Explode     *bool          `json:"explode,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+// openAPIMediaType is the schema of a body in one content type
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema,omitempty"`
+}
+
+// openAPIRequestBody is an operation's request body
+type openAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+// openAPIResponse is one of an operation's responses
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+// openAPIOperationObject is an operation as written in the document
+type openAPIOperationObject struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Tags        []string                   `json:"tags"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+	Deprecated  bool                       `json:"deprecated,omitempty"`
+}
+
+// openAPIDocument is the OpenAPI 3.0 description of the API
+type openAPIDocument struct {
+	OpenAPI    string                                        `json:"openapi"`
+	Info       map[string]string                             `json:"info"`
+	Servers    []map[string]string                           `json:"servers"`
+	Paths      map[string]map[string]*openAPIOperationObject `json:"paths"`
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+// Content types operations respond with other than JSON
+const (
+	contentTypeJSON   = "application/json"
+	contentTypePDF    = "application/pdf"
+	contentTypeBinary = "application/octet-stream"
+	contentTypeText   = "text/plain"
+)
+
+// apiOperation describes one endpoint: what it accepts and what it returns.
+// Paths are written the way gin registers them, relative to /api.
+type apiOperation struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	// Params are the query and header parameters; path parameters are read
+	// from Path
+	Params []openAPIParameter
+	// Body is the JSON request body, nil for operations without one
+	Body *openAPISchema
+	// Multipart also accepts the body as multipart/form-data
+	Multipart bool
+	// Status is the status of a successful response
+	Status int
+	// Response is the JSON body of a successful response. Nil with a JSON
+	// content type means there's no body.
+	Response *openAPISchema
+	// Content is the successful response's content type; JSON if empty
+	Content string
+}
+
+// openAPIPath writes a gin path's parameters the OpenAPI way: /bills/{id}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pathParameters returns the parameters in a gin path, in order
+func pathParameters(path string) []openAPIParameter {
+	var params []openAPIParameter
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			params = append(params, openAPIParameter{Name: segment[1:], In: "path", Required: true, Schema: stringSchema()})
+		}
+	}
+	return params
+}
+
+// operationID names an operation after its method and path, such as
+// getBillsCalendar or postAccountsByIdTransfers
+func operationID(method string, path string) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, ":") {
+			id += "By"
+			segment = segment[1:]
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '.' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+// schemaRegistry turns Go types into schemas, keeping each named struct as a
+// component so the document and the models describe the same shapes
+type schemaRegistry struct {
+	names      map[reflect.Type]string
+	components map[string]*openAPISchema
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		names:      make(map[reflect.Type]string),
+		components: make(map[string]*openAPISchema),
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf describes how values of t marshal to JSON. Pointers, slices and
+// maps marshal nil as null, so they're nullable.
+func (r *schemaRegistry) schemaOf(t reflect.Type) *openAPISchema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(r.schemaOf(t.Elem()))
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return integerSchema()
+	case reflect.Float32, reflect.Float64:
+		return numberSchema()
+	case reflect.String:
+		return stringSchema()
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return nullable(arrayOf(r.schemaOf(t.Elem())))
+	case reflect.Map:
+		return nullable(&openAPISchema{Type: "object", AdditionalProperties: r.schemaOf(t.Elem())})
+	case reflect.Interface:
+		return &openAPISchema{}
+	case reflect.Struct:
+		if t == timeType {
+			return dateTimeSchema()
+		}
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		return r.component(t)
+	}
+	return &openAPISchema{}
+}
+
+// component returns a reference to the component for a named struct,
+// describing it the first time it's seen
+func (r *schemaRegistry) component(t reflect.Type) *openAPISchema {
+	name, exists := r.names[t]
+	if !exists {
+		name = t.Name()
+		if _, taken := r.components[name]; taken {
+			// Another package has a type of the same name
+			pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+		}
+		r.names[t] = name
+		r.components[name] = &openAPISchema{Type: "object"}
+		*r.components[name] = *r.structSchema(t)
+	}
+	return &openAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+// structSchema describes a struct's JSON object. Fields without omitempty
+// are always written, so they're required; embedded structs are flattened.
+func (r *schemaRegistry) structSchema(t reflect.Type) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := r.structSchema(field.Type)
+			for property, propertySchema := range embedded.Properties {
+				schema.Properties[property] = propertySchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = r.schemaOf(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// requestSchema describes what a request may send to be decoded into a t.
+// Unlike responses nothing is required by default, and structs are written
+// inline since clients send only part of them.
+func (r *schemaRegistry) requestSchema(t reflect.Type) *openAPISchema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(r.requestSchema(t.Elem()))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return r.schemaOf(t)
+		}
+		return nullable(arrayOf(r.requestSchema(t.Elem())))
+	case reflect.Map:
+		return nullable(mapOf(r.requestSchema(t.Elem())))
+	case reflect.Struct:
+		if t == timeType {
+			return dateTimeSchema()
+		}
+		schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+				continue
+			}
+			name, _, _ := strings.Cut(tag, ",")
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				for property, propertySchema := range r.requestSchema(field.Type).Properties {
+					schema.Properties[property] = propertySchema
+				}
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			schema.Properties[name] = r.requestSchema(field.Type)
+		}
+		return schema
+	}
+	return r.schemaOf(t)
+}
+
+// body describes a JSON request body decoded into each of values, as the
+// handlers do with an anonymous struct embedding an input type
+func (r *schemaRegistry) body(values ...interface{}) *openAPISchema {
+	schema := object()
+	for _, value := range values {
+		for property, propertySchema := range r.requestSchema(reflect.TypeOf(value)).Properties {
+			schema.Properties[property] = propertySchema
+		}
+	}
+	return schema
+}
+
+// response describes a value the API responds with
+func (r *schemaRegistry) response(value interface{}) *openAPISchema {
+	return r.schemaOf(reflect.TypeOf(value))
+}
+
+// resolve follows a schema's reference to its component
+func (r *schemaRegistry) resolve(schema *openAPISchema) *openAPISchema {
+	for schema != nil && schema.Ref != "" {
+		schema = r.components[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+func stringSchema() *openAPISchema  { return &openAPISchema{Type: "string"} }
+func integerSchema() *openAPISchema { return &openAPISchema{Type: "integer"} }
+func numberSchema() *openAPISchema  { return &openAPISchema{Type: "number"} }
+func booleanSchema() *openAPISchema { return &openAPISchema{Type: "boolean"} }
+
+func dateTimeSchema() *openAPISchema {
+	return &openAPISchema{Type: "string", Format: "date-time"}
+}
+
+// dateSchema is a YYYY-MM-DD date
+func dateSchema() *openAPISchema {
+	return &openAPISchema{Type: "string", Format: "date"}
+}
+
+// monthSchema is a YYYY-MM month
+func monthSchema() *openAPISchema {
+	return &openAPISchema{Type: "string", Format: "month", Pattern: `^\d{4}-(0[1-9]|1[0-2])$`}
+}
+
+func enumSchema(values ...string) *openAPISchema {
+	return &openAPISchema{Type: "string", Enum: values}
+}
+
+func arrayOf(items *openAPISchema) *openAPISchema {
+	return &openAPISchema{Type: "array", Items: items}
+}
+
+func mapOf(values *openAPISchema) *openAPISchema {
+	return &openAPISchema{Type: "object", AdditionalProperties: values}
+}
+
+// nullable allows null as well. References can't carry other keywords, so
+// they're wrapped in allOf.
+func nullable(schema *openAPISchema) *openAPISchema {
+	if schema.Ref != "" {
+		return &openAPISchema{AllOf: []*openAPISchema{schema}, Nullable: true}
+	}
+	copied := *schema
+	copied.Nullable = true
+	return &copied
+}
+
+// between limits a number or integer schema to [min, max]
+func between(schema *openAPISchema, min float64, max float64) *openAPISchema {
+	schema.Minimum = &min
+	schema.Maximum = &max
+	return schema
+}
+
+// positive limits a number or integer schema to values above zero
+func positive(schema *openAPISchema) *openAPISchema {
+	zero := 0.0
+	schema.Minimum = &zero
+	schema.ExclusiveMinimum = true
+	return schema
+}
+
+// atLeast limits a number or integer schema to min and above
+func atLeast(schema *openAPISchema, min float64) *openAPISchema {
+	schema.Minimum = &min
+	return schema
+}
+
+// nonEmpty requires a string to have at least one character
+func nonEmpty(schema *openAPISchema) *openAPISchema {
+	one := 1
+	schema.MinLength = &one
+	return schema
+}
+
+func describe(schema *openAPISchema, description string) *openAPISchema {
+	schema.Description = description
+	return schema
+}
+
+// apiField is a property of an object schema built by hand
+type apiField struct {
+	name     string
+	schema   *openAPISchema
+	required bool
+}
+
+func field(name string, schema *openAPISchema) apiField {
+	return apiField{name: name, schema: schema, required: true}
+}
+
+func optionalField(name string, schema *openAPISchema) apiField {
+	return apiField{name: name, schema: schema}
+}
+
+// object builds an object schema from fields
+func object(fields ...apiField) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+	for _, f := range fields {
+		schema.Properties[f.name] = f.schema
+		if f.required {
+			schema.Required = append(schema.Required, f.name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// with replaces or adds an object schema's properties, for constraints the
+// Go type can't express
+func (s *openAPISchema) with(fields ...apiField) *openAPISchema {
+	for _, f := range fields {
+		s.Properties[f.name] = f.schema
+		if f.required && !containsString(s.Required, f.name) {
+			s.Required = append(s.Required, f.name)
+			sort.Strings(s.Required)
+		}
+	}
+	return s
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func queryParam(name string, schema *openAPISchema, description string) openAPIParameter {
+	return openAPIParameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func requiredQueryParam(name string, schema *openAPISchema, description string) openAPIParameter {
+	return openAPIParameter{Name: name, In: "query", Description: description, Required: true, Schema: schema}
+}
+
+// customerQuery is the customerId parameter most endpoints take
+func customerQuery() openAPIParameter {
+	return requiredQueryParam("customerId", nonEmpty(stringSchema()), "The customer, by ID or demo username")
+}
+
+// apiSpec is the API description shared by the validation middleware and
+// the document served at /api/openapi.json
+type apiSpec struct {
+	registry   *schemaRegistry
+	operations []apiOperation
+	// byRoute indexes operations by method and gin path
+	byRoute map[string]*apiOperation
+	// errorSchema describes the body of error responses
+	errorSchema *openAPISchema
+	document    openAPIDocument
+}
+
+var (
+	defaultAPISpec     *apiSpec
+	defaultAPISpecOnce sync.Once
+)
+
+// loadAPISpec returns the API description, building it the first time
+func loadAPISpec() *apiSpec {
+	defaultAPISpecOnce.Do(func() {
+		registry := newSchemaRegistry()
+		defaultAPISpec = newAPISpec(registry, apiOperations(registry))
+	})
+	return defaultAPISpec
+}
+
+// newAPISpec indexes operations and writes them as an OpenAPI document
+func newAPISpec(registry *schemaRegistry, operations []apiOperation) *apiSpec {
+	errorSchema := registry.schemaOf(reflect.TypeOf(apiError{}))
+	spec := &apiSpec{registry: registry, operations: operations, byRoute: make(map[string]*apiOperation), errorSchema: errorSchema}
+
+	document := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: map[string]string{
+			"title":       "FinSights API",
+			"version":     "1.0.0",
+			"description": "Accounts, transactions, budgets and insights for FinSights customers",
+		},
+		Servers: []map[string]string{
+			{"url": "/api/v1"},
+			{"url": "/api", "description": "Legacy routes, deprecated and sunset on " + legacySunsetAt.Format("2006-01-02") + ". Their errors are a bare {\"error\"} body with the statuses from before v1."},
+		},
+		Paths: make(map[string]map[string]*openAPIOperationObject),
+	}
+	for i := range operations {
+		operation := &operations[i]
+		spec.byRoute[operation.Method+" "+operation.Path] = operation
+
+		object := &openAPIOperationObject{
+			OperationID: operationID(operation.Method, operation.Path),
+			Summary:     operation.Summary,
+			Tags:        []string{operation.Tag},
+			Parameters:  append(pathParameters(operation.Path), operation.Params...),
+			Responses: map[string]openAPIResponse{
+				"default": {
+					Description: "Error",
+					Content:     map[string]openAPIMediaType{contentTypeJSON: {Schema: errorSchema}},
+				},
+			},
+		}
+		if operation.Body != nil {
+			object.RequestBody = &openAPIRequestBody{
+				Required: true,
+				Content:  map[string]openAPIMediaType{contentTypeJSON: {Schema: operation.Body}},
+			}
+			if operation.Multipart {
+				object.RequestBody.Content["multipart/form-data"] = openAPIMediaType{Schema: operation.Body}
+			}
+		}
+		success := openAPIResponse{Description: http.StatusText(operation.Status)}
+		switch {
+		case operation.Content != "" && operation.Content != contentTypeJSON:
+			success.Content = map[string]openAPIMediaType{operation.Content: {Schema: &openAPISchema{Type: "string", Format: "binary"}}}
+		case operation.Response != nil:
+			success.Content = map[string]openAPIMediaType{contentTypeJSON: {Schema: operation.Response}}
+		}
+		object.Responses[strconv.Itoa(operation.Status)] = success
+
+		path := openAPIPath(operation.Path)
+		if document.Paths[path] == nil {
+			document.Paths[path] = make(map[string]*openAPIOperationObject)
+		}
+		document.Paths[path][strings.ToLower(operation.Method)] = object
+	}
+	document.Components.Schemas = registry.components
+	spec.document = document
+	return spec
+}
+
+// operation returns the operation for a request's method and route
+func (s *apiSpec) operation(method string, route string) *apiOperation {
+	return s.byRoute[method+" "+route]
+}
+
+// checkAPIDescription logs the problems apiDescriptionProblems finds
+func checkAPIDescription(routes gin.RoutesInfo, basePaths ...string) {
+	for _, problem := range apiDescriptionProblems(routes, basePaths...) {
+		fmt.Printf("OpenAPI Contract Error: %s\n", problem)
+	}
+}
+
+// apiDescriptionProblems lists routes under each of basePaths that the API
+// description doesn't cover, and operations it describes that aren't
+// registered under each. A route belongs to the longest base path it's under,
+// so /api/v1 routes aren't mistaken for legacy /api ones.
+func apiDescriptionProblems(routes gin.RoutesInfo, basePaths ...string) []string {
+	spec := loadAPISpec()
+	var problems []string
+	registered := make(map[string]map[string]bool, len(basePaths))
+	for _, basePath := range basePaths {
+		registered[basePath] = make(map[string]bool)
+	}
+	for _, route := range routes {
+		basePath := ""
+		for _, candidate := range basePaths {
+			if strings.HasPrefix(route.Path, candidate+"/") && len(candidate) > len(basePath) {
+				basePath = candidate
+			}
+		}
+		if basePath == "" {
+			continue
+		}
+		path := strings.TrimPrefix(route.Path, basePath)
+		registered[basePath][route.Method+" "+path] = true
+		if spec.operation(route.Method, path) == nil {
+			problems = append(problems, fmt.Sprintf("%s %s is not in the API description", route.Method, route.Path))
+		}
+	}
+	for _, basePath := range basePaths {
+		for _, operation := range spec.operations {
+			if !registered[basePath][operation.Method+" "+operation.Path] {
+				problems = append(problems, fmt.Sprintf("%s %s%s is described but not registered", operation.Method, basePath, operation.Path))
+			}
+		}
+	}
+	return problems
+}
+
+// RegisterOpenAPIRoutes serves the API description at openapi.json under rg,
+// such as /api/v1/openapi.json
+func RegisterOpenAPIRoutes(rg *gin.RouterGroup) {
+	spec := loadAPISpec()
+
+	rg.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec.document)
+	})
+}
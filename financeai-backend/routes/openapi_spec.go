@@ -0,0 +1,690 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+
+	"financeai-backend/models"
+	"financeai-backend/services"
+)
+
+// apiOperations lists every endpoint under /api. Request and response bodies
+// are described from the same services and models types the handlers decode
+// and encode, so changing a model changes the document with it.
+func apiOperations(r *schemaRegistry) []apiOperation {
+	// customerBody is a JSON body naming the customer alongside an input type
+	customerBody := func(values ...interface{}) *openAPISchema {
+		return r.body(values...).with(field("customerId", nonEmpty(stringSchema())))
+	}
+	chatHistory := optionalField("history", nullable(arrayOf(r.body(services.ChatMessage{}))))
+	transactionResponse := object(field("transaction", r.response(&models.Transaction{})))
+
+	return []apiOperation{
+		// Accounts
+		{
+			Method: http.MethodGet, Path: "/accounts", Tag: "Accounts",
+			Summary: "List a customer's accounts",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: object(field("accounts", r.response([]models.Account{}))),
+		},
+		{
+			Method: http.MethodGet, Path: "/customer", Tag: "Accounts",
+			Summary: "Get a customer's profile",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: r.response(models.Customer{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/transactions", Tag: "Accounts",
+			Summary: "List a page of a customer's transactions",
+			Params: []openAPIParameter{
+				customerQuery(),
+				queryParam("startDate", dateSchema(), "First day to include"),
+				queryParam("endDate", dateSchema(), "Last day to include"),
+				queryParam("accountId", stringSchema(), "Only this account's transactions"),
+				queryParam("category", stringSchema(), "Only this category"),
+				queryParam("merchant", stringSchema(), "Only merchants matching this name"),
+				queryParam("minAmount", numberSchema(), "Smallest amount to include"),
+				queryParam("maxAmount", numberSchema(), "Largest amount to include"),
+				queryParam("status", stringSchema(), "Only transactions with this status"),
+				queryParam("tag", arrayOf(stringSchema()), "Only transactions with this tag; repeatable"),
+				queryParam("transfers", enumSchema("include", "exclude", "only"), "Whether transfers between the customer's accounts are listed"),
+				queryParam("q", stringSchema(), "Text to search descriptions and merchants for"),
+				queryParam("sort", stringSchema(), "Field to sort by"),
+				queryParam("order", stringSchema(), "asc or desc"),
+				queryParam("cursor", stringSchema(), "Cursor of the page to fetch, from the previous page"),
+				queryParam("limit", atLeast(integerSchema(), 1), "Transactions per page. Without a limit or cursor every match is returned."),
+			},
+			Status: http.StatusOK, Response: r.response(models.TransactionPage{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/dashboard", Tag: "Accounts",
+			Summary: "Get the dashboard: accounts, transactions and spending in the reporting currency",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: r.response(models.DashboardData{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/demo-customers", Tag: "Accounts",
+			Summary: "List the demo customers' usernames",
+			Status:  http.StatusOK, Response: object(field("customers", r.response([]string{}))),
+		},
+		{
+			Method: http.MethodPost, Path: "/login", Tag: "Accounts",
+			Summary: "Check a demo customer's username and password",
+			Body: object(
+				optionalField("username", stringSchema()),
+				optionalField("password", stringSchema()),
+			),
+			Status: http.StatusOK,
+			Response: object(
+				field("username", stringSchema()),
+				field("firstName", stringSchema()),
+				field("lastName", stringSchema()),
+			),
+		},
+
+		// Annotations and splits
+		{
+			Method: http.MethodPatch, Path: "/transactions/:id", Tag: "Transactions",
+			Summary:   "Change a transaction's tags and notes, or attach a receipt with a multipart upload",
+			Body:      customerBody(services.TransactionAnnotationUpdate{}),
+			Multipart: true,
+			Status:    http.StatusOK, Response: transactionResponse,
+		},
+		{
+			Method: http.MethodGet, Path: "/transactions/:id/attachments/:attachmentId", Tag: "Transactions",
+			Summary: "Download a transaction's attachment",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Content: contentTypeBinary,
+		},
+		{
+			Method: http.MethodDelete, Path: "/transactions/:id/attachments/:attachmentId", Tag: "Transactions",
+			Summary: "Delete a transaction's attachment",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: transactionResponse,
+		},
+		{
+			Method: http.MethodPut, Path: "/transactions/:id/splits", Tag: "Transactions",
+			Summary: "Split a transaction across categories",
+			Body: customerBody().with(
+				field("splits", arrayOf(r.body(models.TransactionSplit{}))),
+			),
+			Status: http.StatusOK, Response: transactionResponse,
+		},
+		{
+			Method: http.MethodDelete, Path: "/transactions/:id/splits", Tag: "Transactions",
+			Summary: "Remove a transaction's splits",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: transactionResponse,
+		},
+		{
+			Method: http.MethodGet, Path: "/search", Tag: "Transactions",
+			Summary: "Search a customer's transactions",
+			Params: []openAPIParameter{
+				customerQuery(),
+				requiredQueryParam("q", nonEmpty(stringSchema()), "Text to search for"),
+				queryParam("limit", atLeast(integerSchema(), 1), "Most results to return"),
+			},
+			Status: http.StatusOK,
+			Response: object(
+				field("query", stringSchema()),
+				field("total", integerSchema()),
+				field("results", r.response([]models.SearchResult{})),
+			),
+		},
+
+		// Money movements
+		movementOperation(r, "/accounts/:id/transfers", "Transfer money to another account"),
+		movementOperation(r, "/accounts/:id/deposits", "Deposit money into an account"),
+		movementOperation(r, "/accounts/:id/withdrawals", "Withdraw money from an account"),
+		movementOperation(r, "/accounts/:id/purchases", "Pay a merchant from an account"),
+		{
+			Method: http.MethodPost, Path: "/accounts/:id/bills", Tag: "Money movements",
+			Summary: "Schedule a bill payment from an account, once or every month",
+			Params:  []openAPIParameter{idempotencyKeyHeader()},
+			Body:    customerBody(services.BillPaymentInput{}),
+			Status:  http.StatusCreated, Response: object(field("bill", r.response(models.Bill{}))),
+		},
+
+		// Insights
+		{
+			Method: http.MethodGet, Path: "/insights", Tag: "Insights",
+			Summary: "Get basic insights and cashflow from a customer's transactions",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK,
+			Response: object(
+				field("customerId", stringSchema()),
+				field("transactions", r.response([]models.Transaction{})),
+				field("insights", arrayOf(object(
+					field("id", stringSchema()),
+					field("title", stringSchema()),
+					field("message", stringSchema()),
+					field("trend", enumSchema("positive", "neutral", "negative")),
+					field("type", stringSchema()),
+				))),
+				field("cashflow", r.response(models.CashflowSummary{})),
+			),
+		},
+		{
+			Method: http.MethodPost, Path: "/ai-insights", Tag: "Insights",
+			Summary: "Generate spending insights against a budget",
+			Body: object(
+				field("customerId", nonEmpty(stringSchema())),
+				optionalField("budgetData", nullable(mapOf(numberSchema()))),
+			),
+			Status: http.StatusOK,
+			Response: object(
+				field("customerId", stringSchema()),
+				field("insights", r.response([]models.SpendingInsight{})),
+			),
+		},
+		{
+			Method: http.MethodGet, Path: "/ai-insights", Tag: "Insights",
+			Summary: "Get the insights last generated for a customer",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK,
+			Response: object(
+				field("customerId", stringSchema()),
+				field("insights", r.response([]models.SpendingInsight{})),
+				field("generatedAt", dateTimeSchema()),
+				field("source", stringSchema()),
+			),
+		},
+		{
+			Method: http.MethodGet, Path: "/cashflow", Tag: "Insights",
+			Summary: "Get monthly income against expenses",
+			Params: []openAPIParameter{
+				customerQuery(),
+				queryParam("months", between(integerSchema(), 1, MaxCashflowMonths), "Months to include"),
+			},
+			Status: http.StatusOK,
+			Response: object(
+				field("customerId", stringSchema()),
+				field("currency", stringSchema()),
+				field("cashflow", r.response(models.CashflowSummary{})),
+			),
+		},
+		{
+			Method: http.MethodGet, Path: "/reports/monthly", Tag: "Insights",
+			Summary: "Render a printable monthly statement",
+			Params: []openAPIParameter{
+				customerQuery(),
+				queryParam("month", monthSchema(), "Month to report on, YYYY-MM; this month if left out"),
+				budgetQuery(),
+			},
+			Status: http.StatusOK, Content: contentTypePDF,
+		},
+
+		// Chat
+		{
+			Method: http.MethodPost, Path: "/chat", Tag: "Chat",
+			Summary: "Ask the assistant a question about the customer's finances",
+			Body: object(
+				optionalField("message", stringSchema()),
+				field("username", nonEmpty(stringSchema())),
+				chatHistory,
+			),
+			Status: http.StatusOK,
+			Response: object(
+				field("response", stringSchema()),
+				field("username", stringSchema()),
+			),
+		},
+		{
+			Method: http.MethodPost, Path: "/chat/insight", Tag: "Chat",
+			Summary: "Ask the assistant to explain an insight",
+			Body: object(
+				optionalField("insight", r.body(models.SpendingInsight{})),
+				field("username", nonEmpty(stringSchema())),
+				chatHistory,
+			),
+			Status: http.StatusOK,
+			Response: object(
+				field("response", stringSchema()),
+				field("insight", r.response(models.SpendingInsight{})),
+				field("username", stringSchema()),
+			),
+		},
+
+		// Net worth
+		{
+			Method: http.MethodGet, Path: "/networth", Tag: "Net worth",
+			Summary: "Get net worth today and its history",
+			Params: []openAPIParameter{
+				customerQuery(),
+				queryParam("days", between(integerSchema(), 1, services.MaxNetWorthDays), "Days of history"),
+			},
+			Status: http.StatusOK,
+			Response: object(
+				field("customerId", stringSchema()),
+				field("networth", r.response(&models.NetWorthSummary{})),
+			),
+		},
+		{
+			Method: http.MethodPost, Path: "/networth/snapshots", Tag: "Net worth",
+			Summary: "Record today's balance of every account",
+			Body:    customerBody(),
+			Status:  http.StatusCreated, Response: object(field("snapshots", r.response([]models.BalanceSnapshot{}))),
+		},
+		{
+			Method: http.MethodGet, Path: "/networth/accounts", Tag: "Net worth",
+			Summary: "List manually tracked assets and liabilities",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: object(field("accounts", r.response([]models.ManualAccount{}))),
+		},
+		{
+			Method: http.MethodPost, Path: "/networth/accounts", Tag: "Net worth",
+			Summary: "Track an asset or liability by hand",
+			Body:    customerBody(services.ManualAccountInput{}),
+			Status:  http.StatusCreated, Response: object(field("account", r.response(&models.ManualAccount{}))),
+		},
+		{
+			Method: http.MethodPatch, Path: "/networth/accounts/:id", Tag: "Net worth",
+			Summary: "Change a manually tracked account",
+			Body:    customerBody(services.ManualAccountInput{}),
+			Status:  http.StatusOK, Response: object(field("account", r.response(&models.ManualAccount{}))),
+		},
+		{
+			Method: http.MethodDelete, Path: "/networth/accounts/:id", Tag: "Net worth",
+			Summary: "Stop tracking a manual account",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusNoContent,
+		},
+
+		// Debts
+		{
+			Method: http.MethodGet, Path: "/debts", Tag: "Debts",
+			Summary: "List liabilities and their terms",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK,
+			Response: object(
+				field("debts", r.response([]models.Debt{})),
+				field("minimumPayment", numberSchema()),
+			),
+		},
+		{
+			Method: http.MethodPut, Path: "/debts/:accountId/terms", Tag: "Debts",
+			Summary: "Record the APR, minimum payment and due day of a liability",
+			Body:    customerBody(services.DebtTermsInput{}),
+			Status:  http.StatusOK, Response: object(field("debt", r.response(&models.Debt{}))),
+		},
+		{
+			Method: http.MethodGet, Path: "/debts/plan", Tag: "Debts",
+			Summary: "Simulate paying off debts with one strategy",
+			Params: append(payoffQuery(),
+				queryParam("strategy", enumSchema(services.StrategyAvalanche, services.StrategySnowball, services.StrategyCustom), "Order extra payments are made in"),
+			),
+			Status: http.StatusOK, Response: object(field("plan", r.response(&models.PayoffPlan{}))),
+		},
+		{
+			Method: http.MethodGet, Path: "/debts/compare", Tag: "Debts",
+			Summary: "Compare payoff strategies side by side",
+			Params:  payoffQuery(),
+			Status:  http.StatusOK,
+			Response: object(
+				field("recommended", stringSchema()),
+				field("comparison", arrayOf(object(
+					field("strategy", stringSchema()),
+					field("months", integerSchema()),
+					field("payoffDate", dateTimeSchema()),
+					field("totalInterest", numberSchema()),
+					field("totalPaid", numberSchema()),
+					field("interestVsRecommended", numberSchema()),
+				))),
+				field("plans", r.response([]models.PayoffPlan{})),
+			),
+		},
+
+		// Bills
+		{
+			Method: http.MethodGet, Path: "/bills", Tag: "Bills",
+			Summary: "List bills",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: object(field("bills", r.response([]models.Bill{}))),
+		},
+		{
+			Method: http.MethodPost, Path: "/bills", Tag: "Bills",
+			Summary: "Add a bill",
+			Body:    customerBody(services.BillInput{}),
+			Status:  http.StatusCreated, Response: object(field("bill", r.response(&models.Bill{}))),
+		},
+		{
+			Method: http.MethodPatch, Path: "/bills/:id", Tag: "Bills",
+			Summary: "Change a bill",
+			Body:    customerBody(services.BillInput{}),
+			Status:  http.StatusOK, Response: object(field("bill", r.response(&models.Bill{}))),
+		},
+		{
+			Method: http.MethodDelete, Path: "/bills/:id", Tag: "Bills",
+			Summary: "Delete a bill",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusNoContent,
+		},
+		{
+			Method: http.MethodGet, Path: "/bills/recurring", Tag: "Bills",
+			Summary: "List recurring charges found in the customer's transactions",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: object(field("recurring", r.response([]models.RecurringCharge{}))),
+		},
+		{
+			Method: http.MethodGet, Path: "/bills/upcoming", Tag: "Bills",
+			Summary: "List bills due soon",
+			Params: []openAPIParameter{
+				customerQuery(),
+				queryParam("days", between(integerSchema(), 1, services.MaxUpcomingBillDays), "Days ahead to look"),
+			},
+			Status: http.StatusOK,
+			Response: object(
+				field("customerId", stringSchema()),
+				field("days", integerSchema()),
+				field("bills", r.response([]models.UpcomingBill{})),
+				field("total", numberSchema()),
+				field("flagged", integerSchema()),
+			),
+		},
+		{
+			Method: http.MethodGet, Path: "/bills/calendar", Tag: "Bills",
+			Summary: "List a month's bills grouped by due date",
+			Params: []openAPIParameter{
+				customerQuery(),
+				queryParam("month", monthSchema(), "Month to list, YYYY-MM; this month if left out"),
+			},
+			Status: http.StatusOK,
+			Response: object(
+				field("customerId", stringSchema()),
+				field("month", stringSchema()),
+				field("days", arrayOf(object(
+					field("date", dateSchema()),
+					field("bills", r.response([]models.UpcomingBill{})),
+					field("total", numberSchema()),
+				))),
+			),
+		},
+
+		// Alerts
+		{
+			Method: http.MethodGet, Path: "/alerts", Tag: "Alerts",
+			Summary: "List unusual activity alerts",
+			Params: []openAPIParameter{
+				customerQuery(),
+				queryParam("includeDismissed", booleanSchema(), "Also list dismissed alerts"),
+			},
+			Status: http.StatusOK,
+			Response: object(
+				field("customerId", stringSchema()),
+				field("total", integerSchema()),
+				field("alerts", r.response([]models.Alert{})),
+			),
+		},
+		{
+			Method: http.MethodPost, Path: "/alerts/:id/dismiss", Tag: "Alerts",
+			Summary: "Dismiss an alert with a verdict of expected, fraud or ignore",
+			Body: customerBody(services.AlertDismissal{}).with(
+				optionalField("verdict", describe(stringSchema(), "expected, fraud or ignore; ignore if left out")),
+			),
+			Status: http.StatusOK, Response: object(field("alert", r.response(&models.Alert{}))),
+		},
+
+		// Goals
+		{
+			Method: http.MethodGet, Path: "/goals", Tag: "Goals",
+			Summary: "List savings goals and their progress",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: object(field("goals", r.response([]models.Goal{}))),
+		},
+		{
+			Method: http.MethodPost, Path: "/goals", Tag: "Goals",
+			Summary: "Add a savings goal",
+			Body:    customerBody(services.GoalInput{}),
+			Status:  http.StatusCreated, Response: object(field("goal", r.response(&models.Goal{}))),
+		},
+		{
+			Method: http.MethodDelete, Path: "/goals/:id", Tag: "Goals",
+			Summary: "Delete a savings goal",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusNoContent,
+		},
+
+		// Notifications
+		{
+			Method: http.MethodGet, Path: "/notifications", Tag: "Notifications",
+			Summary: "List notifications",
+			Params: []openAPIParameter{
+				customerQuery(),
+				queryParam("unread", booleanSchema(), "Only unread notifications"),
+			},
+			Status: http.StatusOK,
+			Response: object(
+				field("customerId", stringSchema()),
+				field("total", integerSchema()),
+				field("unread", integerSchema()),
+				field("notifications", r.response([]models.Notification{})),
+			),
+		},
+		{
+			Method: http.MethodPost, Path: "/notifications/:id/read", Tag: "Notifications",
+			Summary: "Mark a notification as read",
+			Body:    customerBody(),
+			Status:  http.StatusOK, Response: object(field("notification", r.response(&models.Notification{}))),
+		},
+		{
+			Method: http.MethodGet, Path: "/notifications/preferences", Tag: "Notifications",
+			Summary: "Get notification preferences",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: object(field("preferences", r.response(&models.NotificationPreferences{}))),
+		},
+		{
+			Method: http.MethodPatch, Path: "/notifications/preferences", Tag: "Notifications",
+			Summary: "Change notification preferences; fields left out keep their values",
+			Params:  []openAPIParameter{customerQuery()},
+			Body:    r.body(models.NotificationPreferences{}),
+			Status:  http.StatusOK, Response: object(field("preferences", r.response(&models.NotificationPreferences{}))),
+		},
+		{
+			Method: http.MethodPost, Path: "/notifications/evaluate", Tag: "Notifications",
+			Summary: "Check the customer's data for events to notify about",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK,
+			Response: object(
+				field("customerId", stringSchema()),
+				field("events", r.response([]models.Event{})),
+				field("unread", integerSchema()),
+			),
+		},
+		{
+			Method: http.MethodPost, Path: "/notifications/test", Tag: "Notifications",
+			Summary: "Send a test notification on every channel the customer has turned on",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: object(field("notification", r.response(&models.Notification{}))),
+		},
+
+		// Currency
+		{
+			Method: http.MethodGet, Path: "/currencies", Tag: "Currency",
+			Summary: "List the currencies amounts can be converted between",
+			Status:  http.StatusOK, Response: object(field("rates", r.response(models.ExchangeRateTable{}))),
+		},
+		{
+			Method: http.MethodGet, Path: "/currency/preferences", Tag: "Currency",
+			Summary: "Get the customer's reporting currency and locale",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: object(field("preferences", r.response(&models.CurrencyPreferences{}))),
+		},
+		{
+			Method: http.MethodPatch, Path: "/currency/preferences", Tag: "Currency",
+			Summary: "Change the reporting currency or locale; fields left out keep their values",
+			Params:  []openAPIParameter{customerQuery()},
+			Body:    r.body(models.CurrencyPreferences{}),
+			Status:  http.StatusOK, Response: object(field("preferences", r.response(&models.CurrencyPreferences{}))),
+		},
+
+		// Webhooks
+		{
+			Method: http.MethodGet, Path: "/webhooks", Tag: "Webhooks",
+			Summary: "List webhook subscriptions and the events they can subscribe to",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK,
+			Response: object(
+				field("webhooks", r.response([]models.WebhookSubscription{})),
+				field("eventTypes", r.response(services.WebhookEventTypes)),
+			),
+		},
+		{
+			Method: http.MethodPost, Path: "/webhooks", Tag: "Webhooks",
+			Summary: "Subscribe a URL to events",
+			Body:    customerBody(services.WebhookInput{}),
+			Status:  http.StatusCreated, Response: object(field("webhook", r.response(&models.WebhookSubscription{}))),
+		},
+		{
+			Method: http.MethodPatch, Path: "/webhooks/:id", Tag: "Webhooks",
+			Summary: "Change a webhook subscription",
+			Body:    customerBody(services.WebhookInput{}),
+			Status:  http.StatusOK, Response: object(field("webhook", r.response(&models.WebhookSubscription{}))),
+		},
+		{
+			Method: http.MethodDelete, Path: "/webhooks/:id", Tag: "Webhooks",
+			Summary: "Delete a webhook subscription",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusNoContent,
+		},
+		{
+			Method: http.MethodGet, Path: "/webhooks/:id/deliveries", Tag: "Webhooks",
+			Summary: "List a subscription's recent deliveries",
+			Params: []openAPIParameter{
+				customerQuery(),
+				queryParam("limit", between(integerSchema(), 1, 200), "Most deliveries to return"),
+			},
+			Status: http.StatusOK, Response: object(field("deliveries", r.response([]models.WebhookDelivery{}))),
+		},
+		{
+			Method: http.MethodPost, Path: "/webhooks/:id/test", Tag: "Webhooks",
+			Summary: "Send a test event to a subscription",
+			Body:    customerBody(),
+			Status:  http.StatusOK, Response: object(field("delivery", r.response(&models.WebhookDelivery{}))),
+		},
+		{
+			Method: http.MethodGet, Path: "/webhooks/dead-letters", Tag: "Webhooks",
+			Summary: "List deliveries that ran out of retries",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK, Response: object(field("deliveries", r.response([]models.WebhookDelivery{}))),
+		},
+		{
+			Method: http.MethodPost, Path: "/webhooks/dead-letters/:deliveryId/replay", Tag: "Webhooks",
+			Summary: "Deliver a dead-lettered event again",
+			Body:    customerBody(),
+			Status:  http.StatusOK, Response: object(field("delivery", r.response(&models.WebhookDelivery{}))),
+		},
+
+		// Operations
+		{
+			Method: http.MethodGet, Path: "/jobs", Tag: "Operations",
+			Summary: "List background jobs",
+			Status:  http.StatusOK, Response: object(field("jobs", r.response([]models.Job{}))),
+		},
+		{
+			Method: http.MethodGet, Path: "/jobs/:name", Tag: "Operations",
+			Summary: "Get a background job and its recent runs",
+			Status:  http.StatusOK, Response: object(field("job", r.response(&models.Job{}))),
+		},
+		{
+			Method: http.MethodPost, Path: "/jobs/:name/run", Tag: "Operations",
+			Summary: "Run a job now, in the background",
+			Status:  http.StatusAccepted, Response: object(field("run", r.response(&models.JobRun{}))),
+		},
+		{
+			Method: http.MethodPatch, Path: "/jobs/:name", Tag: "Operations",
+			Summary: "Change a job's schedule or turn it on or off",
+			Body: object(
+				optionalField("schedule", nullable(stringSchema())),
+				optionalField("enabled", nullable(booleanSchema())),
+			),
+			Status: http.StatusOK, Response: object(field("job", r.response(&models.Job{}))),
+		},
+		{
+			Method: http.MethodGet, Path: "/cache/stats", Tag: "Operations",
+			Summary: "Get response cache statistics",
+			Status:  http.StatusOK, Response: object(field("stats", r.response(models.CacheStats{}))),
+		},
+		{
+			Method: http.MethodPost, Path: "/cache/invalidate", Tag: "Operations",
+			Summary: "Drop everything cached for a customer",
+			Params:  []openAPIParameter{customerQuery()},
+			Status:  http.StatusOK,
+			Response: object(
+				field("customerId", stringSchema()),
+				field("removed", integerSchema()),
+			),
+		},
+		{
+			Method: http.MethodGet, Path: "/nessie/decode-errors", Tag: "Operations",
+			Summary: "List Nessie records that couldn't be decoded, by endpoint",
+			Status:  http.StatusOK, Response: object(field("endpoints", r.response([]models.NessieDecodeResult{}))),
+		},
+		{
+			Method: http.MethodDelete, Path: "/nessie/decode-errors", Tag: "Operations",
+			Summary: "Clear the Nessie decode report",
+			Status:  http.StatusOK, Response: object(field("cleared", integerSchema())),
+		},
+		// GraphQL
+		{
+			Method: http.MethodPost, Path: "/graphql", Tag: "GraphQL",
+			Summary: "Run a GraphQL query over a customer's accounts, transactions, spending and insights",
+			Body:    r.body(graphQLRequest{}).with(field("query", nonEmpty(stringSchema()))),
+			Status:  http.StatusOK, Response: r.response(graphQLResponse{}),
+		},
+		{
+			Method: http.MethodGet, Path: "/graphql/schema", Tag: "GraphQL",
+			Summary: "Get the GraphQL schema in SDL",
+			Status:  http.StatusOK, Content: contentTypeText,
+		},
+		{
+			Method: http.MethodGet, Path: "/openapi.json", Tag: "Operations",
+			Summary: "Get this API description",
+			Status:  http.StatusOK, Response: object(),
+		},
+	}
+}
+
+// movementOperation describes one of the money movement endpoints, which all
+// take the same body
+func movementOperation(r *schemaRegistry, path string, summary string) apiOperation {
+	return apiOperation{
+		Method: http.MethodPost, Path: path, Tag: "Money movements",
+		Summary: summary,
+		Params:  []openAPIParameter{idempotencyKeyHeader()},
+		Body: r.body(services.MovementInput{}).with(
+			field("customerId", nonEmpty(stringSchema())),
+			optionalField("medium", describe(stringSchema(), fmt.Sprintf("%s or %s; %s if left out", services.MovementMediumBalance, services.MovementMediumRewards, services.MovementMediumBalance))),
+		),
+		Status: http.StatusCreated, Response: object(field("movement", r.response(models.MoneyMovement{}))),
+	}
+}
+
+// idempotencyKeyHeader lets clients retry a money movement safely
+func idempotencyKeyHeader() openAPIParameter {
+	return openAPIParameter{
+		Name:        services.IdempotencyKeyHeader,
+		In:          "header",
+		Description: fmt.Sprintf("Retries with the same key return the first response, marked with %s", services.IdempotencyReplayedHeader),
+		Schema:      stringSchema(),
+	}
+}
+
+// payoffQuery is the customer, budget and custom order the payoff endpoints
+// share
+func payoffQuery() []openAPIParameter {
+	return []openAPIParameter{
+		customerQuery(),
+		queryParam("monthlyBudget", positive(numberSchema()), "Total to pay toward debts each month"),
+		queryParam("order", arrayOf(stringSchema()), "Account IDs in the order to pay them off, for the custom strategy; repeatable or comma separated"),
+	}
+}
+
+// budgetQuery is a report's budget by category, such as budget[foodDining]=300
+func budgetQuery() openAPIParameter {
+	explode := true
+	return openAPIParameter{
+		Name: "budget", In: "query", Style: "deepObject", Explode: &explode,
+		Description: "Monthly budget for each category",
+		Schema:      mapOf(numberSchema()),
+	}
+}
@@ -0,0 +1,306 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// contractCall is one request the contract test makes. Path and Body can
+// use values saved from earlier responses, written as {name}.
+type contractCall struct {
+	Method      string
+	Path        string
+	Body        string
+	ContentType string
+	Header      map[string]string
+	// Save records values from the response for later calls, by name and
+	// dotted path into the JSON body, such as bill._id or alerts.0._id
+	Save map[string]string
+}
+
+// placeholders matches the {name} values in a contractCall
+var placeholders = regexp.MustCompile(`\{[a-zA-Z]+\}`)
+
+// lookupJSON follows a dotted path of object keys and array indexes
+func lookupJSON(value interface{}, path string) (string, bool) {
+	for _, part := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			value = node[part]
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", false
+			}
+			value = node[index]
+		default:
+			return "", false
+		}
+	}
+	text, ok := value.(string)
+	return text, ok && text != ""
+}
+
+// attachmentUpload is a multipart body attaching a receipt to a transaction
+func attachmentUpload(t *testing.T, customerID string) (string, string) {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("customerId", customerID)
+	form.WriteField("notes", "Receipt attached")
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="attachments"; filename="receipt.png"`)
+	header.Set("Content-Type", "image/png")
+	file, err := form.CreatePart(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte("\x89PNG\r\n\x1a\n"))
+	form.Close()
+	return body.String(), form.FormDataContentType()
+}
+
+// TestAPIMatchesDescription calls every operation in the API description
+// against the demo customers and checks each response against it
+func TestAPIMatchesDescription(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	services.ConfigureAttachmentStorage(services.NewLocalAttachmentStorage(t.TempDir()))
+	scheduler := services.DefaultScheduler()
+	scheduler.SetStatePath(filepath.Join(t.TempDir(), "scheduler.json"))
+	if _, err := scheduler.Job(services.JobRecomputeAggregates); err != nil {
+		if err := services.RegisterDefaultJobs(scheduler, services.JobsConfig{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The webhook endpoint turns away the first delivery, so it's
+	// dead-lettered and can be replayed
+	var hookRequests int32
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hookRequests, 1) == 1 {
+			w.WriteHeader(http.StatusGone)
+		}
+	}))
+	defer hook.Close()
+
+	// Record the route each request matched, to find its operation
+	var route string
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		route = c.FullPath()
+		c.Next()
+	})
+	RegisterRoutes(r, "", "")
+
+	for _, problem := range apiDescriptionProblems(r.Routes(), "/api/v1", "/api") {
+		t.Error(problem)
+	}
+
+	upload, uploadType := attachmentUpload(t, "sarah")
+	// Splits have to add up to the transaction, so split off $10
+	split, err := services.NewMockDataService().GetTransaction("sarah", "txn2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := map[string]string{
+		"hook":      hook.URL,
+		"nextMonth": time.Now().AddDate(0, 1, 0).Format(services.BillDateLayout),
+		"nextYear":  time.Now().AddDate(1, 0, 0).Format(services.BillDateLayout),
+		"month":     time.Now().Format(services.ReportMonthLayout),
+		"remainder": strconv.FormatFloat(split.Amount+10, 'f', 2, 64),
+	}
+	idempotent := func(key string) map[string]string {
+		return map[string]string{services.IdempotencyKeyHeader: key}
+	}
+
+	calls := []contractCall{
+		// Accounts
+		{Method: http.MethodGet, Path: "/accounts?customerId=sarah"},
+		{Method: http.MethodGet, Path: "/customer?customerId=sarah"},
+		{Method: http.MethodGet, Path: "/transactions?customerId=sarah&limit=5&sort=amount&order=asc&transfers=exclude"},
+		{Method: http.MethodGet, Path: "/dashboard?customerId=michael"},
+		{Method: http.MethodGet, Path: "/demo-customers"},
+		{Method: http.MethodPost, Path: "/login", Body: `{"username":"sarah","password":"password123"}`},
+
+		// Annotations and splits
+		{Method: http.MethodPatch, Path: "/transactions/txn1", Body: `{"customerId":"sarah","addTags":["coffee"],"notes":"Morning run"}`},
+		{Method: http.MethodPatch, Path: "/transactions/txn1", Body: upload, ContentType: uploadType, Save: map[string]string{"attachment": "transaction.attachments.0.id"}},
+		{Method: http.MethodGet, Path: "/transactions/txn1/attachments/{attachment}?customerId=sarah"},
+		{Method: http.MethodDelete, Path: "/transactions/txn1/attachments/{attachment}?customerId=sarah"},
+		{Method: http.MethodPut, Path: "/transactions/txn2/splits", Body: `{"customerId":"sarah","splits":[{"category":"Transportation","amount":-10},{"category":"Entertainment","amount":{remainder}}]}`},
+		{Method: http.MethodDelete, Path: "/transactions/txn2/splits?customerId=sarah"},
+		{Method: http.MethodGet, Path: "/search?customerId=sarah&q=coffee&limit=5"},
+
+		// Money movements
+		{Method: http.MethodPost, Path: "/accounts/acc1/transfers", Body: `{"customerId":"sarah","payeeId":"acc2","amount":50}`, Header: idempotent("contract-transfer")},
+		{Method: http.MethodPost, Path: "/accounts/acc1/deposits", Body: `{"customerId":"sarah","amount":200,"description":"Paycheck"}`},
+		{Method: http.MethodPost, Path: "/accounts/acc1/withdrawals", Body: `{"customerId":"sarah","amount":20}`},
+		{Method: http.MethodPost, Path: "/accounts/acc1/purchases", Body: `{"customerId":"sarah","merchantId":"starbucks","amount":5}`},
+		{Method: http.MethodPost, Path: "/accounts/acc1/bills", Body: `{"customerId":"sarah","payee":"City Water","amount":42.50,"paymentDate":"{nextMonth}"}`},
+
+		// Insights
+		{Method: http.MethodGet, Path: "/insights?customerId=sarah"},
+		{Method: http.MethodPost, Path: "/ai-insights", Body: `{"customerId":"sarah","budgetData":{"Food & Dining":200}}`},
+		{Method: http.MethodGet, Path: "/ai-insights?customerId=sarah"},
+		{Method: http.MethodGet, Path: "/cashflow?customerId=sarah&months=3"},
+		{Method: http.MethodGet, Path: "/reports/monthly?customerId=sarah&month={month}&" + url.QueryEscape("budget[Food & Dining]") + "=300"},
+
+		// Chat
+		{Method: http.MethodPost, Path: "/chat", Body: `{"username":"sarah","message":"How much did I spend on food?"}`},
+		{Method: http.MethodPost, Path: "/chat/insight", Body: `{"username":"sarah","insight":{"category":"Food & Dining","message":"You spent more on food this month"}}`},
+
+		// Net worth
+		{Method: http.MethodGet, Path: "/networth?customerId=michael&days=30"},
+		{Method: http.MethodPost, Path: "/networth/snapshots", Body: `{"customerId":"michael"}`},
+		{Method: http.MethodPost, Path: "/networth/accounts", Body: `{"customerId":"michael","name":"Car","type":"vehicle","balance":12000}`, Save: map[string]string{"manual": "account._id"}},
+		{Method: http.MethodGet, Path: "/networth/accounts?customerId=michael"},
+		{Method: http.MethodPatch, Path: "/networth/accounts/{manual}", Body: `{"customerId":"michael","balance":11500}`},
+		{Method: http.MethodDelete, Path: "/networth/accounts/{manual}?customerId=michael"},
+
+		// Debts
+		{Method: http.MethodGet, Path: "/debts?customerId=michael"},
+		{Method: http.MethodPut, Path: "/debts/acc5/terms", Body: `{"customerId":"michael","apr":22.9,"minimumPayment":35,"dueDay":15}`},
+		{Method: http.MethodGet, Path: "/debts/plan?customerId=michael&monthlyBudget=600&strategy=avalanche"},
+		{Method: http.MethodGet, Path: "/debts/compare?customerId=michael&monthlyBudget=600"},
+
+		// Bills
+		{Method: http.MethodPost, Path: "/bills", Body: `{"customerId":"sarah","name":"Gym","category":"Health & Fitness","amount":40,"accountId":"acc1","frequency":"monthly","dueDate":"{nextMonth}"}`, Save: map[string]string{"bill": "bill._id"}},
+		{Method: http.MethodGet, Path: "/bills?customerId=sarah"},
+		{Method: http.MethodPatch, Path: "/bills/{bill}", Body: `{"customerId":"sarah","amount":45}`},
+		{Method: http.MethodGet, Path: "/bills/recurring?customerId=sarah"},
+		{Method: http.MethodGet, Path: "/bills/upcoming?customerId=sarah&days=45"},
+		{Method: http.MethodGet, Path: "/bills/calendar?customerId=sarah&month={month}"},
+		{Method: http.MethodDelete, Path: "/bills/{bill}?customerId=sarah"},
+
+		// Alerts
+		{Method: http.MethodGet, Path: "/alerts?customerId=sarah", Save: map[string]string{"alert": "alerts.0._id"}},
+		{Method: http.MethodPost, Path: "/alerts/{alert}/dismiss", Body: `{"customerId":"sarah","verdict":"expected"}`},
+
+		// Goals
+		{Method: http.MethodPost, Path: "/goals", Body: `{"customerId":"sarah","name":"Trip","accountId":"acc2","targetAmount":5000,"targetDate":"{nextYear}"}`, Save: map[string]string{"goal": "goal._id"}},
+		{Method: http.MethodGet, Path: "/goals?customerId=sarah"},
+		{Method: http.MethodDelete, Path: "/goals/{goal}?customerId=sarah"},
+
+		// Notifications
+		{Method: http.MethodPost, Path: "/notifications/evaluate?customerId=sarah"},
+		{Method: http.MethodPost, Path: "/notifications/test?customerId=sarah"},
+		{Method: http.MethodGet, Path: "/notifications?customerId=sarah&unread=true", Save: map[string]string{"notification": "notifications.0._id"}},
+		{Method: http.MethodPost, Path: "/notifications/{notification}/read", Body: `{"customerId":"sarah"}`},
+		{Method: http.MethodGet, Path: "/notifications/preferences?customerId=sarah"},
+		{Method: http.MethodPatch, Path: "/notifications/preferences?customerId=sarah", Body: `{"large_transaction_threshold":750}`},
+
+		// Currency
+		{Method: http.MethodGet, Path: "/currencies"},
+		{Method: http.MethodGet, Path: "/currency/preferences?customerId=sarah"},
+		{Method: http.MethodPatch, Path: "/currency/preferences?customerId=sarah", Body: `{"locale":"en-US"}`},
+
+		// Webhooks
+		{Method: http.MethodPost, Path: "/webhooks", Body: `{"customerId":"sarah","url":"{hook}","events":["digest.weekly"]}`, Save: map[string]string{"webhook": "webhook._id"}},
+		{Method: http.MethodGet, Path: "/webhooks?customerId=sarah"},
+		{Method: http.MethodPatch, Path: "/webhooks/{webhook}", Body: `{"customerId":"sarah","active":true}`},
+		{Method: http.MethodPost, Path: "/webhooks/{webhook}/test", Body: `{"customerId":"sarah"}`},
+		{Method: http.MethodGet, Path: "/webhooks/dead-letters?customerId=sarah", Save: map[string]string{"delivery": "deliveries.0._id"}},
+		{Method: http.MethodPost, Path: "/webhooks/dead-letters/{delivery}/replay", Body: `{"customerId":"sarah"}`},
+		{Method: http.MethodGet, Path: "/webhooks/{webhook}/deliveries?customerId=sarah&limit=10"},
+		{Method: http.MethodDelete, Path: "/webhooks/{webhook}?customerId=sarah"},
+
+		// Operations
+		{Method: http.MethodGet, Path: "/jobs"},
+		{Method: http.MethodGet, Path: "/jobs/" + services.JobRecomputeAggregates},
+		{Method: http.MethodPatch, Path: "/jobs/" + services.JobRecomputeAggregates, Body: `{"enabled":true}`},
+		{Method: http.MethodPost, Path: "/jobs/" + services.JobRecomputeAggregates + "/run"},
+		{Method: http.MethodGet, Path: "/cache/stats"},
+		{Method: http.MethodPost, Path: "/cache/invalidate?customerId=sarah"},
+		{Method: http.MethodGet, Path: "/nessie/decode-errors"},
+		{Method: http.MethodDelete, Path: "/nessie/decode-errors"},
+
+		// GraphQL
+		{Method: http.MethodPost, Path: "/graphql", Body: `{"query":"{ customer(id: \"sarah\") { firstName accounts { id balance } transactions(first: 3) { id amount } } }"}`},
+		{Method: http.MethodGet, Path: "/graphql/schema"},
+		{Method: http.MethodGet, Path: "/openapi.json"},
+	}
+
+	spec := loadAPISpec()
+	called := make(map[*apiOperation]bool)
+	for _, call := range calls {
+		missing := ""
+		fill := func(text string) string {
+			return placeholders.ReplaceAllStringFunc(text, func(placeholder string) string {
+				value, exists := saved[strings.Trim(placeholder, "{}")]
+				if !exists {
+					missing = placeholder
+				}
+				return value
+			})
+		}
+		path, body := fill(call.Path), fill(call.Body)
+		if missing != "" {
+			t.Errorf("%s %s: no %s saved from an earlier response", call.Method, call.Path, missing)
+			continue
+		}
+
+		request := httptest.NewRequest(call.Method, "/api/v1"+path, strings.NewReader(body))
+		if body != "" {
+			contentType := call.ContentType
+			if contentType == "" {
+				contentType = contentTypeJSON
+			}
+			request.Header.Set("Content-Type", contentType)
+		}
+		for name, value := range call.Header {
+			request.Header.Set(name, value)
+		}
+		route = ""
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, request)
+
+		operation := spec.operation(call.Method, strings.TrimPrefix(route, "/api/v1"))
+		if operation == nil {
+			t.Errorf("%s %s matched %q, which isn't in the API description", call.Method, path, route)
+			continue
+		}
+		called[operation] = true
+
+		if recorder.Code != operation.Status {
+			t.Errorf("%s %s responded %d, want %d: %s", call.Method, path, recorder.Code, operation.Status, recorder.Body.String())
+		}
+		for _, problem := range spec.validateResponse(operation, recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body.Bytes()) {
+			t.Errorf("%s %s responded %d: %s", call.Method, path, recorder.Code, problem.Message)
+		}
+
+		if len(call.Save) > 0 {
+			var response interface{}
+			json.Unmarshal(recorder.Body.Bytes(), &response)
+			for name, field := range call.Save {
+				value, ok := lookupJSON(response, field)
+				if !ok {
+					t.Errorf("%s %s: response has no %s", call.Method, path, field)
+					continue
+				}
+				saved[name] = value
+			}
+		}
+	}
+
+	for i := range spec.operations {
+		operation := &spec.operations[i]
+		if !called[operation] {
+			t.Errorf("%s %s is described but the contract test doesn't call it", operation.Method, operation.Path)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package routes
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+	"financeai-backend/models"
+)
+
+// RegisterReportRoutes sets up /api/reports
+func RegisterReportRoutes(rg *gin.RouterGroup, apiKey string) {
+	aiService := services.NewOpenAIService(apiKey)
+	mockService := services.NewMockDataService()
+	reportService := services.NewReportService()
+
+	// Render a printable monthly statement, e.g. ?month=2026-09&budget[foodDining]=300
+	rg.GET("/reports/monthly", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		month := time.Now().UTC()
+		if value := c.Query("month"); value != "" {
+			parsed, err := time.Parse(services.ReportMonthLayout, value)
+			if err != nil {
+				c.Error(services.NewValidationError("month", "month must be in YYYY-MM format"))
+				return
+			}
+			month = parsed
+		}
+
+		budgetData := make(map[string]float64)
+		for key, value := range c.QueryMap("budget") {
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				c.Error(services.NewValidationError("budget["+key+"]", "invalid budget for %s", key))
+				return
+			}
+			budgetData[key] = amount
+		}
+
+		dashboardData, err := mockService.GetDashboardData(customerId)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusInternalServerError))
+			return
+		}
+		mockService.RememberBudgets(customerId, budgetData)
+
+		// Generate AI insights for the report period only
+		monthTransactions := services.MonthTransactions(dashboardData.Transactions, month)
+		var monthSpend float64
+		for _, transaction := range monthTransactions {
+			if services.IsExpense(transaction) {
+				monthSpend += math.Abs(transaction.Amount)
+			}
+		}
+		money := services.NewMoneyFormatter(dashboardData.Currency, dashboardData.Locale)
+		insights, err := aiService.GenerateInsights(monthTransactions, monthSpend, budgetData, money)
+		if err != nil {
+			fmt.Printf("AI Insights Error: %v\n", err)
+			insights = []models.SpendingInsight{}
+		}
+
+		report := reportService.BuildMonthlyReport(dashboardData, month, budgetData, insights)
+		pdfBytes, err := reportService.RenderMonthlyReportPDF(report)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusInternalServerError))
+			return
+		}
+
+		filename := fmt.Sprintf("finsights-%s-%s.pdf", customerId, report.Month)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, "application/pdf", pdfBytes)
+	})
+}
@@ -2,16 +2,64 @@ package routes
 
 import (
     "github.com/gin-gonic/gin"
+    "financeai-backend/services"
 )
 
 func RegisterRoutes(r *gin.Engine, apiKey string, openAIKey string) {
-    // group API under /api
-    api := r.Group("/api")
+    // /api/v1 is the current API
+    v1 := r.Group("/api/v1")
+    v1.Use(APIVersion())
+    registerAPIRoutes(v1, apiKey, openAIKey)
+    basePaths := []string{v1.BasePath()}
+
+    // /api is the legacy API, the same routes answering in the old shapes
+    // until it's sunset
+    if legacyAPIEnabled {
+        legacy := r.Group("/api")
+        legacy.Use(LegacyAPI(legacy.BasePath(), v1.BasePath()))
+        registerAPIRoutes(legacy, apiKey, openAIKey)
+        basePaths = append(basePaths, legacy.BasePath())
+    }
+
+    // Unknown paths get the same error envelope as everything else
+    r.NoRoute(RequestID(), func(c *gin.Context) {
+        abortWithError(c, services.NewNotFoundError("route not found: %s %s", c.Request.Method, c.Request.URL.Path))
+    })
+
+    // Warn when the description and the registered routes have drifted apart
+    checkAPIDescription(r.Routes(), basePaths...)
+}
+
+// registerAPIRoutes registers every API route on rg
+func registerAPIRoutes(rg *gin.RouterGroup, apiKey string, openAIKey string) {
+    // Every request gets an ID and is checked against the OpenAPI description
+    // before handlers run. Errors handlers report with c.Error are answered
+    // in one envelope by HandleErrors.
+    rg.Use(RequestID(), ValidateRequests(rg.BasePath()), HandleErrors())
     {
-        RegisterLoginRoutes(api)
-        RegisterAccountRoutes(api, apiKey)
-        RegisterInsightRoutes(api, apiKey)
-        RegisterAIInsightRoutes(api, openAIKey)
-        RegisterChatbotRoutes(api, openAIKey)
+        RegisterLoginRoutes(rg)
+        RegisterAccountRoutes(rg, apiKey)
+        RegisterInsightRoutes(rg, apiKey)
+        RegisterAIInsightRoutes(rg, openAIKey)
+        RegisterChatbotRoutes(rg, openAIKey)
+        RegisterReportRoutes(rg, openAIKey)
+        RegisterSearchRoutes(rg, apiKey)
+        RegisterSplitRoutes(rg, apiKey)
+        RegisterAnnotationRoutes(rg, apiKey)
+        RegisterCashflowRoutes(rg, apiKey)
+        RegisterNetWorthRoutes(rg, apiKey)
+        RegisterDebtRoutes(rg, apiKey)
+        RegisterBillRoutes(rg, apiKey)
+        RegisterAlertRoutes(rg, apiKey)
+        RegisterGoalRoutes(rg, apiKey)
+        RegisterNotificationRoutes(rg, apiKey)
+        RegisterWebhookRoutes(rg, apiKey)
+        RegisterJobRoutes(rg, apiKey)
+        RegisterCacheRoutes(rg, apiKey)
+        RegisterMovementRoutes(rg, apiKey)
+        RegisterNessieRoutes(rg, apiKey)
+        RegisterCurrencyRoutes(rg, apiKey)
+        RegisterGraphQLRoutes(rg, apiKey)
+        RegisterOpenAPIRoutes(rg)
     }
 }
@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+	"financeai-backend/models"
+)
+
+// RegisterSearchRoutes sets up /api/search
+func RegisterSearchRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+	searchService := services.NewSearchService()
+
+	// Full-text search over a customer's transactions, e.g. ?q=starbux
+	rg.GET("/search", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		query := strings.TrimSpace(c.Query("q"))
+		if query == "" {
+			c.Error(services.NewValidationError("q", "q required"))
+			return
+		}
+
+		limit := 20
+		if value := c.Query("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				c.Error(services.NewValidationError("limit", "limit must be a positive integer"))
+				return
+			}
+			limit = parsed
+		}
+
+		transactions, err := mockService.GetAllCustomerTransactions(customerId, models.TransactionFilter{})
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusInternalServerError))
+			return
+		}
+
+		// Pick up any transactions added or imported since the last search
+		index := searchService.Index(customerId)
+		index.Sync(transactions)
+
+		results, total := index.Search(query, limit)
+		c.JSON(http.StatusOK, gin.H{
+			"query":   query,
+			"total":   total,
+			"results": results,
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+	"financeai-backend/models"
+)
+
+// RegisterSplitRoutes sets up /api/transactions/:id/splits
+func RegisterSplitRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+
+	// Split a transaction across categories; splits must sum to its amount
+	rg.PUT("/transactions/:id/splits", func(c *gin.Context) {
+		var request struct {
+			CustomerId string                    `json:"customerId"`
+			Splits     []models.TransactionSplit `json:"splits"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if _, err := mockService.GetTransaction(request.CustomerId, c.Param("id")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		transaction, err := mockService.SetTransactionSplits(request.CustomerId, c.Param("id"), request.Splits)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"transaction": transaction})
+	})
+
+	// Remove a transaction's splits
+	rg.DELETE("/transactions/:id/splits", func(c *gin.Context) {
+		customerId := strings.TrimSpace(c.Query("customerId"))
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		transaction, err := mockService.ClearTransactionSplits(customerId, c.Param("id"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"transaction": transaction})
+	})
+}
@@ -0,0 +1,361 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// checkResponses turns on checking responses against the API description
+var checkResponses bool
+
+// ConfigureContractChecks turns checking every JSON response against the API
+// description on or off. Responses that don't match are logged, not
+// changed, so it's safe to leave on outside production.
+func ConfigureContractChecks(enabled bool) {
+	checkResponses = enabled
+}
+
+// ValidateRequests checks the parameters and body of each request to a
+// route in the API description before its handler runs, responding 400 with
+// every problem found. basePath is the path of the group the routes are
+// registered on.
+func ValidateRequests(basePath string) gin.HandlerFunc {
+	spec := loadAPISpec()
+
+	return func(c *gin.Context) {
+		operation := spec.operation(c.Request.Method, strings.TrimPrefix(c.FullPath(), basePath))
+		if operation == nil {
+			c.Next()
+			return
+		}
+
+		if err := spec.validateRequest(c, operation); err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		// The description is of the v1 shapes, so legacy responses aren't
+		// checked against it
+		if !checkResponses || legacyShape(c) {
+			c.Next()
+			return
+		}
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		for _, problem := range spec.validateResponse(operation, writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes()) {
+			fmt.Printf("OpenAPI Contract Error: %s %s responded %d: %s\n", c.Request.Method, c.FullPath(), writer.Status(), problem.Message)
+		}
+	}
+}
+
+// validateRequest checks a request against its operation, returning a
+// services.ValidationError listing every problem found
+func (s *apiSpec) validateRequest(c *gin.Context, operation *apiOperation) error {
+	var problems []services.FieldError
+	for _, param := range operation.Params {
+		if param.In == "query" {
+			s.validateQueryParam(c, param, &problems)
+		}
+	}
+
+	if operation.Body != nil && !(operation.Multipart && strings.HasPrefix(c.ContentType(), "multipart/form-data")) {
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return errInvalidRequestFormat
+		}
+		// Put the body back for the handler to decode
+		c.Request.Body = io.NopCloser(bytes.NewReader(data))
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var body interface{}
+		if err := decoder.Decode(&body); err != nil {
+			return errInvalidRequestFormat
+		}
+		s.validateValue(operation.Body, body, "", &problems)
+	}
+
+	if len(problems) > 0 {
+		return &services.ValidationError{Fields: problems}
+	}
+	return nil
+}
+
+// validateQueryParam checks one query parameter. Empty values are treated
+// as left out, as the handlers do.
+func (s *apiSpec) validateQueryParam(c *gin.Context, param openAPIParameter, problems *[]services.FieldError) {
+	schema := param.Schema
+	switch {
+	case param.Style == "deepObject":
+		for key, value := range c.QueryMap(param.Name) {
+			s.validateValue(schema.AdditionalProperties, queryValue(schema.AdditionalProperties, value), param.Name+"["+key+"]", problems)
+		}
+	case schema.Type == "array":
+		values := c.QueryArray(param.Name)
+		if len(values) == 0 && param.Required {
+			*problems = append(*problems, services.FieldError{Field: param.Name, Message: param.Name + " required"})
+		}
+		for _, value := range values {
+			s.validateValue(schema.Items, queryValue(schema.Items, value), param.Name, problems)
+		}
+	default:
+		value := c.Query(param.Name)
+		if value == "" {
+			if param.Required {
+				*problems = append(*problems, services.FieldError{Field: param.Name, Message: param.Name + " required"})
+			}
+			return
+		}
+		s.validateValue(schema, queryValue(schema, value), param.Name, problems)
+	}
+}
+
+// queryValue converts a query string value to what it would be in JSON, so
+// parameters are checked the same way as bodies. Values that don't convert
+// are left as strings and fail the type check.
+func queryValue(schema *openAPISchema, value string) interface{} {
+	switch schema.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(value)
+		}
+	case "boolean":
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return value
+}
+
+// validateValue checks a decoded JSON value against a schema, adding a
+// problem for each place it doesn't match. path names the value in
+// messages, such as splits[0].amount.
+func (s *apiSpec) validateValue(schema *openAPISchema, value interface{}, path string, problems *[]services.FieldError) {
+	if schema == nil {
+		return
+	}
+	if value == nil {
+		if !schema.Nullable && (schema.Type != "" || schema.Ref != "") {
+			addProblem(problems, path, "must not be null")
+		}
+		return
+	}
+	if schema.Ref != "" {
+		s.validateValue(s.registry.resolve(schema), value, path, problems)
+		return
+	}
+	for _, part := range schema.AllOf {
+		s.validateValue(part, value, path, problems)
+	}
+
+	switch schema.Type {
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			addProblem(problems, path, "must be a string")
+			return
+		}
+		validateString(schema, text, path, problems)
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if ok && schema.Type == "integer" {
+			_, err := number.Int64()
+			ok = err == nil
+		}
+		if !ok && schema.Type == "integer" {
+			addProblem(problems, path, "must be an integer")
+			return
+		}
+		if !ok {
+			addProblem(problems, path, "must be a number")
+			return
+		}
+		parsed, _ := number.Float64()
+		validateNumber(schema, parsed, path, problems)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			addProblem(problems, path, "must be true or false")
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			addProblem(problems, path, "must be an array")
+			return
+		}
+		for i, item := range items {
+			s.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case "object":
+		properties, ok := value.(map[string]interface{})
+		if !ok {
+			addProblem(problems, path, "must be an object")
+			return
+		}
+		for _, name := range schema.Required {
+			if _, exists := properties[name]; !exists {
+				*problems = append(*problems, services.FieldError{Field: joinPath(path, name), Message: joinPath(path, name) + " required"})
+			}
+		}
+		// Check properties in order so the first problem reported is stable
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if propertySchema, known := schema.Properties[name]; known {
+				s.validateValue(propertySchema, properties[name], joinPath(path, name), problems)
+			} else if schema.AdditionalProperties != nil {
+				s.validateValue(schema.AdditionalProperties, properties[name], joinPath(path, name), problems)
+			}
+		}
+	}
+}
+
+// patterns caches compiled schema patterns
+var patterns sync.Map
+
+func validateString(schema *openAPISchema, text string, path string, problems *[]services.FieldError) {
+	if len(schema.Enum) > 0 && !containsString(schema.Enum, text) {
+		addProblem(problems, path, "must be one of "+strings.Join(schema.Enum, ", "))
+		return
+	}
+	if schema.MinLength != nil && utf8.RuneCountInString(text) < *schema.MinLength {
+		if *schema.MinLength == 1 {
+			addProblem(problems, path, "must not be empty")
+		} else {
+			addProblem(problems, path, fmt.Sprintf("must be at least %d characters", *schema.MinLength))
+		}
+		return
+	}
+	switch schema.Format {
+	case "date":
+		if _, err := time.Parse(services.BillDateLayout, text); err != nil {
+			addProblem(problems, path, "must be in YYYY-MM-DD format")
+			return
+		}
+	case "month":
+		if _, err := time.Parse(services.ReportMonthLayout, text); err != nil {
+			addProblem(problems, path, "must be in YYYY-MM format")
+			return
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, text); err != nil {
+			addProblem(problems, path, "must be an RFC 3339 timestamp")
+			return
+		}
+	}
+	if schema.Pattern != "" {
+		compiled, exists := patterns.Load(schema.Pattern)
+		if !exists {
+			compiled, _ = patterns.LoadOrStore(schema.Pattern, regexp.MustCompile(schema.Pattern))
+		}
+		if !compiled.(*regexp.Regexp).MatchString(text) {
+			addProblem(problems, path, "must match "+schema.Pattern)
+		}
+	}
+}
+
+func validateNumber(schema *openAPISchema, number float64, path string, problems *[]services.FieldError) {
+	switch {
+	case schema.Minimum != nil && schema.Maximum != nil:
+		if number < *schema.Minimum || number > *schema.Maximum {
+			addProblem(problems, path, fmt.Sprintf("must be between %v and %v", *schema.Minimum, *schema.Maximum))
+		}
+	case schema.Minimum != nil && schema.ExclusiveMinimum:
+		if number <= *schema.Minimum {
+			addProblem(problems, path, fmt.Sprintf("must be greater than %v", *schema.Minimum))
+		}
+	case schema.Minimum != nil:
+		if number < *schema.Minimum {
+			addProblem(problems, path, fmt.Sprintf("must be at least %v", *schema.Minimum))
+		}
+	case schema.Maximum != nil:
+		if number > *schema.Maximum {
+			addProblem(problems, path, fmt.Sprintf("must be at most %v", *schema.Maximum))
+		}
+	}
+}
+
+// addProblem records that the value at path is invalid
+func addProblem(problems *[]services.FieldError, path string, message string) {
+	field := path
+	if field == "" {
+		field = "body"
+	}
+	*problems = append(*problems, services.FieldError{Field: field, Message: field + " " + message})
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// recordingWriter keeps a copy of the response body so it can be checked
+// against the API description after the handler has written it
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// validateResponse checks a response against its operation: successful
+// responses against the operation's response, errors against apiError
+func (s *apiSpec) validateResponse(operation *apiOperation, status int, contentType string, body []byte) []services.FieldError {
+	var problems []services.FieldError
+	var schema *openAPISchema
+	switch {
+	case status >= http.StatusBadRequest:
+		schema = s.errorSchema
+	case status != operation.Status:
+		addProblem(&problems, "status", fmt.Sprintf("is %d, want %d", status, operation.Status))
+		return problems
+	case operation.Content != "" && operation.Content != contentTypeJSON:
+		if !strings.HasPrefix(contentType, operation.Content) && operation.Content != contentTypeBinary {
+			addProblem(&problems, "Content-Type", fmt.Sprintf("is %s, want %s", contentType, operation.Content))
+		}
+		return problems
+	case operation.Response == nil:
+		if len(body) > 0 {
+			addProblem(&problems, "body", "should be empty")
+		}
+		return problems
+	default:
+		schema = operation.Response
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		addProblem(&problems, "body", "is not JSON")
+		return problems
+	}
+	s.validateValue(schema, value, "", &problems)
+	return problems
+}
@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// APIVersionHeader names the response shape a request was answered in.
+// Clients of the legacy routes can send it set to v1 to get the v1 shape
+// while keeping their paths, so they can move over one call at a time.
+const APIVersionHeader = "API-Version"
+
+// The response shapes the API answers in
+const (
+	apiVersionV1     = "v1"
+	apiVersionLegacy = "legacy"
+)
+
+// apiVersionKey is where the response shape is kept on the gin context
+const apiVersionKey = "apiVersion"
+
+// When the legacy /api routes were deprecated and when they'll be removed
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunsetAt     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// legacyAPIEnabled keeps the legacy /api routes registered alongside /api/v1
+var legacyAPIEnabled = true
+
+// ConfigureLegacyAPI turns the legacy /api routes on or off. They're on by
+// default; turn them off once clients have moved to /api/v1.
+func ConfigureLegacyAPI(enabled bool) {
+	legacyAPIEnabled = enabled
+}
+
+// APIVersion answers requests in the v1 response shape
+func APIVersion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, apiVersionV1)
+		c.Header(APIVersionHeader, apiVersionV1)
+		c.Next()
+	}
+}
+
+// LegacyAPI marks the routes under basePath as deprecated in favour of the
+// same routes under successorPath, with Deprecation, Sunset and Link
+// headers. They answer in the legacy response shape unless the client asks
+// for v1 with the API-Version header: errors are a bare {"error"} body, with
+// the fields at fault under details for invalid requests, and keep the
+// statuses they had before v1.
+func LegacyAPI(basePath string, successorPath string) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", legacyDeprecatedAt.Unix())
+	sunset := legacySunsetAt.Format(http.TimeFormat)
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		c.Header("Sunset", sunset)
+		successor := successorPath + strings.TrimPrefix(c.Request.URL.Path, basePath)
+		c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+
+		version := apiVersionLegacy
+		if strings.EqualFold(c.GetHeader(APIVersionHeader), apiVersionV1) {
+			version = apiVersionV1
+		}
+		c.Set(apiVersionKey, version)
+		c.Header(APIVersionHeader, version)
+		c.Next()
+	}
+}
+
+// legacyShape reports whether a request is answered in the legacy response
+// shape
+func legacyShape(c *gin.Context) bool {
+	return c.GetString(apiVersionKey) == apiVersionLegacy
+}
+
+// legacyStatusError is an error the legacy routes reported with a different
+// status than its kind has in v1, from before errors had kinds
+type legacyStatusError struct {
+	error
+	status int
+}
+
+func (e *legacyStatusError) Unwrap() error {
+	return e.error
+}
+
+// withLegacyStatus has the legacy routes report err with status. v1 routes
+// report it by its kind as usual.
+func withLegacyStatus(err error, status int) error {
+	return &legacyStatusError{error: err, status: status}
+}
+
+// legacyNessieStatus is the status the legacy routes reported a failure to
+// load customers and accounts with: Nessie's own 404s as a 404, its other
+// statuses as a 502 and anything else, such as a missing demo customer, as
+// a 500
+func legacyNessieStatus(err error) int {
+	var apiErr *services.NessieAPIError
+	if !errors.As(err, &apiErr) {
+		return http.StatusInternalServerError
+	}
+	if apiErr.StatusCode == http.StatusNotFound {
+		return http.StatusNotFound
+	}
+	return http.StatusBadGateway
+}
@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLegacyErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterRoutes(r, "", "")
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		version    string
+		status     int
+		wantFields []string
+	}{
+		{"missing customer on a data route", http.MethodGet, "/api/accounts?customerId=nobody", "", "", http.StatusInternalServerError, []string{"error"}},
+		{"missing customer with the failed accounts", http.MethodGet, "/api/transactions?customerId=nobody", "", "", http.StatusInternalServerError, []string{"error", "failed_accounts"}},
+		{"missing customer asking for v1", http.MethodGet, "/api/accounts?customerId=nobody", "", "v1", http.StatusNotFound, []string{"code", "message", "requestId"}},
+		{"missing customer on v1", http.MethodGet, "/api/v1/accounts?customerId=nobody", "", "", http.StatusNotFound, []string{"code", "message", "requestId"}},
+		{"missing customer on a record route", http.MethodGet, "/api/goals?customerId=nobody", "", "", http.StatusNotFound, []string{"error"}},
+		{"missing customer on a POST", http.MethodPost, "/api/ai-insights", `{"customerId": "nobody"}`, "", http.StatusInternalServerError, []string{"error"}},
+		{"invalid request", http.MethodGet, "/api/accounts", "", "", http.StatusBadRequest, []string{"details", "error"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			request.Header.Set("Content-Type", "application/json")
+			if test.version != "" {
+				request.Header.Set(APIVersionHeader, test.version)
+			}
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, test.status, recorder.Body)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			var fields []string
+			for field := range body {
+				fields = append(fields, field)
+			}
+			if !sameFields(fields, test.wantFields) {
+				t.Errorf("body has fields %v, want %v", fields, test.wantFields)
+			}
+			if recorder.Header().Get(RequestIDHeader) == "" {
+				t.Error("response has no request ID header")
+			}
+		})
+	}
+}
+
+// sameFields reports whether got and want hold the same field names
+func sameFields(got []string, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	wanted := map[string]bool{}
+	for _, field := range want {
+		wanted[field] = true
+	}
+	for _, field := range got {
+		if !wanted[field] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,214 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"financeai-backend/services"
+)
+
+// RegisterWebhookRoutes sets up /api/webhooks for integrators
+func RegisterWebhookRoutes(rg *gin.RouterGroup, apiKey string) {
+	mockService := services.NewMockDataService()
+
+	// List a customer's webhook subscriptions. Secrets aren't included.
+	rg.GET("/webhooks", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		webhooks, err := mockService.GetWebhooks(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"webhooks":   webhooks,
+			"eventTypes": services.WebhookEventTypes,
+		})
+	})
+
+	// Subscribe a URL to events. The signing secret is generated unless one
+	// is given, and is only shown in this response.
+	rg.POST("/webhooks", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+			services.WebhookInput
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if _, err := mockService.GetWebhooks(request.CustomerId); err != nil {
+			c.Error(err)
+			return
+		}
+
+		webhook, err := mockService.AddWebhook(request.CustomerId, request.WebhookInput)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"webhook": webhook})
+	})
+
+	// Change a webhook's URL, events or active flag. Send "secret": "" to
+	// rotate the signing secret.
+	rg.PATCH("/webhooks/:id", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+			services.WebhookInput
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if _, err := mockService.GetWebhook(request.CustomerId, c.Param("id")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		webhook, err := mockService.UpdateWebhook(request.CustomerId, c.Param("id"), request.WebhookInput)
+		if err != nil {
+			c.Error(withLegacyStatus(err, http.StatusBadRequest))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"webhook": webhook})
+	})
+
+	// Remove a webhook subscription
+	rg.DELETE("/webhooks/:id", func(c *gin.Context) {
+		customerId := strings.TrimSpace(c.Query("customerId"))
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if err := mockService.DeleteWebhook(customerId, c.Param("id")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	// A webhook's delivery log with every attempt, newest first, e.g. ?limit=20
+	rg.GET("/webhooks/:id/deliveries", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		limit := 50
+		if value := c.Query("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > 200 {
+				c.Error(services.NewValidationError("limit", "limit must be between 1 and 200"))
+				return
+			}
+			limit = parsed
+		}
+
+		deliveries, err := mockService.GetWebhookDeliveries(customerId, c.Param("id"), limit)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+	})
+
+	// Send a signed test event to a webhook and return the first attempt
+	rg.POST("/webhooks/:id/test", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		delivery, err := mockService.SendTestWebhook(request.CustomerId, c.Param("id"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"delivery": delivery})
+	})
+
+	// Deliveries that ran out of retries or were rejected by the endpoint
+	rg.GET("/webhooks/dead-letters", func(c *gin.Context) {
+		customerId := c.Query("customerId")
+		if customerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		deliveries, err := mockService.GetWebhookDeadLetters(customerId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+	})
+
+	// Send a dead-lettered delivery again
+	rg.POST("/webhooks/dead-letters/:deliveryId/replay", func(c *gin.Context) {
+		var request struct {
+			CustomerId string `json:"customerId"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(errInvalidRequestFormat)
+			return
+		}
+
+		if request.CustomerId == "" {
+			c.Error(errCustomerIDRequired)
+			return
+		}
+
+		if _, err := mockService.GetWebhookDelivery(request.CustomerId, c.Param("deliveryId")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		delivery, err := mockService.ReplayWebhookDelivery(request.CustomerId, c.Param("deliveryId"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"delivery": delivery})
+	})
+}
@@ -6,6 +6,24 @@ import (
 	"financeai-backend/models"
 )
 
+// budgetCategoryKeys maps spending categories to the keys used in budgetData
+var budgetCategoryKeys = map[string]string{
+	"Food & Dining":  "foodDining",
+	"Transportation": "transportation",
+	"Entertainment":  "entertainment",
+	"Shopping":       "shopping",
+	"Healthcare":     "healthcare",
+}
+
+// budgetForCategory returns the budget set for a category, or 0 if none
+func budgetForCategory(budgetData map[string]float64, category string) float64 {
+	key, exists := budgetCategoryKeys[category]
+	if !exists {
+		return 0
+	}
+	return budgetData[key]
+}
+
 type OpenAIService struct {
 	APIKey string
 }
@@ -16,43 +34,76 @@ func NewOpenAIService(apiKey string) *OpenAIService  {
 	}
 }
 
-func (ai *OpenAIService) GenerateInsights(transactions []models.Transaction, monthlySpending float64, budgetData map[string]float64) ([]models.SpendingInsight, error) {
+// GenerateInsights builds spending insights from transactions, writing
+// amounts with money
+func (ai *OpenAIService) GenerateInsights(transactions []models.Transaction, monthlySpending float64, budgetData map[string]float64, money MoneyFormatter) ([]models.SpendingInsight, error) {
 	// For now, let's use the fallback insights to ensure it works
 	// TODO: Implement OpenAI API call later
 	spendingByCategory := make(map[string]float64)
 	totalSpent := 0.0
+	incomeByType := make(map[string]float64)
+	totalIncome := 0.0
 	
-	for _, txn := range transactions {
-		if txn.Amount < 0 { // Only count expenses
-			spendingByCategory[txn.Merchant.Category] += math.Abs(txn.Amount)
+	for _, txn := range ClassifyIncome(transactions) {
+		if IsExpense(txn) { // Only count expenses, not transfers
+			// Split transactions count toward each of their categories
+			for _, allocation := range TransactionAllocations(txn) {
+				spendingByCategory[allocation.Category] += math.Abs(allocation.Amount)
+			}
 			totalSpent += math.Abs(txn.Amount)
+		} else if IsIncome(txn) {
+			incomeByType[txn.IncomeType] += txn.Amount
+			totalIncome += txn.Amount
 		}
 	}
 
 	// Create realistic insights based on spending data and budget
-	insights := ai.createFallbackInsights(spendingByCategory, totalSpent, budgetData)
+	insights := ai.createFallbackInsights(spendingByCategory, totalSpent, budgetData, money)
+	if totalIncome > 0 {
+		insights = append(insights, ai.createCashflowInsight(incomeByType, totalIncome, totalSpent, money))
+	}
 	return insights, nil
 }
 
-func (ai *OpenAIService) createFallbackInsights(spendingByCategory map[string]float64, totalSpent float64, budgetData map[string]float64) []models.SpendingInsight {
+// createCashflowInsight compares income with spending for the same period
+func (ai *OpenAIService) createCashflowInsight(incomeByType map[string]float64, totalIncome float64, totalSpent float64, money MoneyFormatter) models.SpendingInsight {
+	net := totalIncome - totalSpent
+	rate := savingsRate(totalIncome, net)
+	payroll := incomeByType[IncomeTypePayroll]
+
+	if net < 0 {
+		return models.SpendingInsight{
+			Title:       "Spending More Than You Earn",
+			Description: fmt.Sprintf("You spent %s against %s of income, a shortfall of %s.", money.Format(totalSpent), money.Format(totalIncome), money.Format(-net)),
+			Category:    "Income",
+			Amount:      money.Format(net),
+			Tip:         "Review your largest categories and pause one subscription or recurring purchase until income covers spending again.",
+		}
+	}
+	if rate < 20 {
+		return models.SpendingInsight{
+			Title:       "Savings Rate Check",
+			Description: fmt.Sprintf("You kept %.1f%% of your %s income (%s from paychecks). Aim for 20%% to build your savings faster.", rate, money.Format(totalIncome), money.Format(payroll)),
+			Category:    "Income",
+			Amount:      fmt.Sprintf("%.1f%% saved", rate),
+			Tip:         "Schedule an automatic transfer to savings the day after each paycheck lands.",
+		}
+	}
+	return models.SpendingInsight{
+		Title:       "Healthy Savings Rate",
+		Description: fmt.Sprintf("You kept %.1f%% of your %s income, saving %s this period.", rate, money.Format(totalIncome), money.Format(net)),
+		Category:    "Income",
+		Amount:      fmt.Sprintf("%.1f%% saved", rate),
+		Tip:         fmt.Sprintf("Consider moving %s of this surplus into a high-yield savings account.", money.Format(net*0.5)),
+	}
+}
+
+func (ai *OpenAIService) createFallbackInsights(spendingByCategory map[string]float64, totalSpent float64, budgetData map[string]float64, money MoneyFormatter) []models.SpendingInsight {
 	insights := []models.SpendingInsight{}
 
 	// Helper function to get budget for a category
 	getBudget := func(category string) float64 {
-		switch category {
-		case "Food & Dining":
-			return budgetData["foodDining"]
-		case "Transportation":
-			return budgetData["transportation"]
-		case "Entertainment":
-			return budgetData["entertainment"]
-		case "Shopping":
-			return budgetData["shopping"]
-		case "Healthcare":
-			return budgetData["healthcare"]
-		default:
-			return 0
-		}
+		return budgetForCategory(budgetData, category)
 	}
 
 	// Food spending insight with budget analysis
@@ -63,27 +114,27 @@ func (ai *OpenAIService) createFallbackInsights(spendingByCategory map[string]fl
 			if overBudget > 0 {
 				insights = append(insights, models.SpendingInsight{
 					Title:       "Food Budget Alert",
-					Description: fmt.Sprintf("You're $%.2f over your food budget! You've spent $%.2f vs your $%.2f budget. Try cooking 3 more meals at home this week to save $%.2f.", overBudget, foodSpent, foodBudget, overBudget*0.3),
+					Description: fmt.Sprintf("You're %s over your food budget! You've spent %s vs your %s budget. Try cooking 3 more meals at home this week to save %s.", money.Format(overBudget), money.Format(foodSpent), money.Format(foodBudget), money.Format(overBudget*0.3)),
 					Category:    "Food & Dining",
-					Amount:      fmt.Sprintf("$%.2f over budget", overBudget),
-					Tip:         "Meal prep 3 lunches this Sunday to save $15-20 this week. Use your campus dining plan for 2 meals daily.",
+					Amount:      fmt.Sprintf("%s over budget", money.Format(overBudget)),
+					Tip:         fmt.Sprintf("Meal prep 3 lunches this Sunday to save %s-%s this week. Use your campus dining plan for 2 meals daily.", money.Whole(15), money.Whole(20)),
 				})
 			} else {
 				underBudget := foodBudget - foodSpent
 				insights = append(insights, models.SpendingInsight{
 					Title:       "Great Food Budgeting!",
-					Description: fmt.Sprintf("You're doing well with food spending! You've spent $%.2f vs your $%.2f budget, saving $%.2f.", foodSpent, foodBudget, underBudget),
+					Description: fmt.Sprintf("You're doing well with food spending! You've spent %s vs your %s budget, saving %s.", money.Format(foodSpent), money.Format(foodBudget), money.Format(underBudget)),
 					Category:    "Food & Dining",
-					Amount:      fmt.Sprintf("$%.2f under budget", underBudget),
-					Tip:         fmt.Sprintf("Keep up the good work! Consider putting the extra $%.2f into your emergency fund.", underBudget*0.5),
+					Amount:      fmt.Sprintf("%s under budget", money.Format(underBudget)),
+					Tip:         fmt.Sprintf("Keep up the good work! Consider putting the extra %s into your emergency fund.", money.Format(underBudget*0.5)),
 				})
 			}
 		} else {
 			insights = append(insights, models.SpendingInsight{
 				Title:       "Food Spending Alert",
-				Description: fmt.Sprintf("You've spent $%.2f on food this month. Consider cooking more meals at home or using your campus dining plan.", foodSpent),
+				Description: fmt.Sprintf("You've spent %s on food this month. Consider cooking more meals at home or using your campus dining plan.", money.Format(foodSpent)),
 				Category:    "Food & Dining",
-				Amount:      fmt.Sprintf("$%.2f", foodSpent),
+				Amount:      money.Format(foodSpent),
 				Tip:         "Try meal prepping on Sundays to save money and time during the week.",
 			})
 		}
@@ -97,27 +148,27 @@ func (ai *OpenAIService) createFallbackInsights(spendingByCategory map[string]fl
 			if overBudget > 0 {
 				insights = append(insights, models.SpendingInsight{
 					Title:       "Transportation Over Budget",
-					Description: fmt.Sprintf("You're $%.2f over your transportation budget! You've spent $%.2f vs your $%.2f budget. Try using campus shuttles 4 more times this month to save $%.2f.", overBudget, transportSpent, transportBudget, overBudget*0.4),
+					Description: fmt.Sprintf("You're %s over your transportation budget! You've spent %s vs your %s budget. Try using campus shuttles 4 more times this month to save %s.", money.Format(overBudget), money.Format(transportSpent), money.Format(transportBudget), money.Format(overBudget*0.4)),
 					Category:    "Transportation",
-					Amount:      fmt.Sprintf("$%.2f over budget", overBudget),
-					Tip:         "Use the campus shuttle 3 times this week instead of rideshare. Look into a student bus pass for $20/month.",
+					Amount:      fmt.Sprintf("%s over budget", money.Format(overBudget)),
+					Tip:         fmt.Sprintf("Use the campus shuttle 3 times this week instead of rideshare. Look into a student bus pass for %s/month.", money.Whole(20)),
 				})
 			} else {
 				underBudget := transportBudget - transportSpent
 				insights = append(insights, models.SpendingInsight{
 					Title:       "Smart Transportation!",
-					Description: fmt.Sprintf("Great job with transportation costs! You've spent $%.2f vs your $%.2f budget, saving $%.2f.", transportSpent, transportBudget, underBudget),
+					Description: fmt.Sprintf("Great job with transportation costs! You've spent %s vs your %s budget, saving %s.", money.Format(transportSpent), money.Format(transportBudget), money.Format(underBudget)),
 					Category:    "Transportation",
-					Amount:      fmt.Sprintf("$%.2f under budget", underBudget),
-					Tip:         fmt.Sprintf("Keep using campus shuttles and carpooling. Consider investing the extra $%.2f in your savings.", underBudget*0.6),
+					Amount:      fmt.Sprintf("%s under budget", money.Format(underBudget)),
+					Tip:         fmt.Sprintf("Keep using campus shuttles and carpooling. Consider investing the extra %s in your savings.", money.Format(underBudget*0.6)),
 				})
 			}
 		} else {
 			insights = append(insights, models.SpendingInsight{
 				Title:       "Transportation Savings",
-				Description: fmt.Sprintf("Your transportation costs are $%.2f this month. Consider using campus shuttles or carpooling.", transportSpent),
+				Description: fmt.Sprintf("Your transportation costs are %s this month. Consider using campus shuttles or carpooling.", money.Format(transportSpent)),
 				Category:    "Transportation",
-				Amount:      fmt.Sprintf("$%.2f", transportSpent),
+				Amount:      money.Format(transportSpent),
 				Tip:         "Look into student bus passes or bike sharing programs on campus.",
 			})
 		}
@@ -131,27 +182,27 @@ func (ai *OpenAIService) createFallbackInsights(spendingByCategory map[string]fl
 			if overBudget > 0 {
 				insights = append(insights, models.SpendingInsight{
 					Title:       "Entertainment Over Budget",
-					Description: fmt.Sprintf("You're $%.2f over your entertainment budget! You've spent $%.2f vs your $%.2f budget. Try 2 free campus events this month to save $%.2f.", overBudget, entertainmentSpent, entertainmentBudget, overBudget*0.5),
+					Description: fmt.Sprintf("You're %s over your entertainment budget! You've spent %s vs your %s budget. Try 2 free campus events this month to save %s.", money.Format(overBudget), money.Format(entertainmentSpent), money.Format(entertainmentBudget), money.Format(overBudget*0.5)),
 					Category:    "Entertainment",
-					Amount:      fmt.Sprintf("$%.2f over budget", overBudget),
+					Amount:      fmt.Sprintf("%s over budget", money.Format(overBudget)),
 					Tip:         "Check your campus calendar for free movie nights and concerts. Host a game night at home instead of going out.",
 				})
 			} else {
 				underBudget := entertainmentBudget - entertainmentSpent
 				insights = append(insights, models.SpendingInsight{
 					Title:       "Entertainment Budget Success!",
-					Description: fmt.Sprintf("Excellent entertainment budgeting! You've spent $%.2f vs your $%.2f budget, saving $%.2f.", entertainmentSpent, entertainmentBudget, underBudget),
+					Description: fmt.Sprintf("Excellent entertainment budgeting! You've spent %s vs your %s budget, saving %s.", money.Format(entertainmentSpent), money.Format(entertainmentBudget), money.Format(underBudget)),
 					Category:    "Entertainment",
-					Amount:      fmt.Sprintf("$%.2f under budget", underBudget),
-					Tip:         fmt.Sprintf("You're doing great! Consider treating yourself to one nice activity with the extra $%.2f.", underBudget*0.3),
+					Amount:      fmt.Sprintf("%s under budget", money.Format(underBudget)),
+					Tip:         fmt.Sprintf("You're doing great! Consider treating yourself to one nice activity with the extra %s.", money.Format(underBudget*0.3)),
 				})
 			}
 		} else {
 			insights = append(insights, models.SpendingInsight{
 				Title:       "Entertainment Budget",
-				Description: fmt.Sprintf("You've spent $%.2f on entertainment. Look for free campus events and activities.", entertainmentSpent),
+				Description: fmt.Sprintf("You've spent %s on entertainment. Look for free campus events and activities.", money.Format(entertainmentSpent)),
 				Category:    "Entertainment",
-				Amount:      fmt.Sprintf("$%.2f", entertainmentSpent),
+				Amount:      money.Format(entertainmentSpent),
 				Tip:         "Check your campus calendar for free movie nights, concerts, and social events.",
 			})
 		}
@@ -164,29 +215,29 @@ func (ai *OpenAIService) createFallbackInsights(spendingByCategory map[string]fl
 		if overallOverBudget > 0 {
 			insights = append(insights, models.SpendingInsight{
 				Title:       "Overall Budget Alert",
-				Description: fmt.Sprintf("You're $%.2f over your total monthly budget! You've spent $%.2f vs your $%.2f budget. Focus on your highest spending category to get back on track.", overallOverBudget, totalSpent, totalBudget),
+				Description: fmt.Sprintf("You're %s over your total monthly budget! You've spent %s vs your %s budget. Focus on your highest spending category to get back on track.", money.Format(overallOverBudget), money.Format(totalSpent), money.Format(totalBudget)),
 				Category:    "Savings",
-				Amount:      fmt.Sprintf("$%.2f over budget", overallOverBudget),
+				Amount:      fmt.Sprintf("%s over budget", money.Format(overallOverBudget)),
 				Tip:         "Try the 50/30/20 rule: 50% needs, 30% wants, 20% savings. Cut back on your highest spending category by 20% next month.",
 			})
 		} else {
 			underBudget := totalBudget - totalSpent
 			insights = append(insights, models.SpendingInsight{
 				Title:       "Budget Success!",
-				Description: fmt.Sprintf("Congratulations! You're $%.2f under your total monthly budget! You've spent $%.2f vs your $%.2f budget.", underBudget, totalSpent, totalBudget),
+				Description: fmt.Sprintf("Congratulations! You're %s under your total monthly budget! You've spent %s vs your %s budget.", money.Format(underBudget), money.Format(totalSpent), money.Format(totalBudget)),
 				Category:    "Savings",
-				Amount:      fmt.Sprintf("$%.2f under budget", underBudget),
-				Tip:         fmt.Sprintf("Great job! Consider putting $%.2f into your emergency fund and $%.2f into a fun activity.", underBudget*0.7, underBudget*0.3),
+				Amount:      fmt.Sprintf("%s under budget", money.Format(underBudget)),
+				Tip:         fmt.Sprintf("Great job! Consider putting %s into your emergency fund and %s into a fun activity.", money.Format(underBudget*0.7), money.Format(underBudget*0.3)),
 			})
 		}
 	} else {
 		// General savings tip if no budget data
 		insights = append(insights, models.SpendingInsight{
 			Title:       "Emergency Fund",
-			Description: fmt.Sprintf("With your current spending of $%.2f, try to save at least $50-100 per month for emergencies.", totalSpent),
+			Description: fmt.Sprintf("With your current spending of %s, try to save at least %s-%s per month for emergencies.", money.Format(totalSpent), money.Whole(50), money.Whole(100)),
 			Category:    "Savings",
-			Amount:      "$50-100",
-			Tip:         "Set up automatic transfers to a savings account each month, even if it's just $25.",
+			Amount:      fmt.Sprintf("%s-%s", money.Whole(50), money.Whole(100)),
+			Tip:         fmt.Sprintf("Set up automatic transfers to a savings account each month, even if it's just %s.", money.Whole(25)),
 		})
 	}
 
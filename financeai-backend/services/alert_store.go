@@ -0,0 +1,141 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Alert feedback verdicts
+const (
+	// AlertVerdictExpected means the customer recognizes the activity. The
+	// merchant and location stop counting as unusual.
+	AlertVerdictExpected = "expected"
+	// AlertVerdictFraud means the customer doesn't recognize the charge
+	AlertVerdictFraud = "fraud"
+	// AlertVerdictIgnore dismisses the alert without teaching the detector anything
+	AlertVerdictIgnore = "ignore"
+)
+
+// AlertDismissal describes a customer dismissing an alert
+type AlertDismissal struct {
+	Verdict string `json:"verdict"`
+	Note    string `json:"note"`
+}
+
+// AlertStore holds the feedback customers give when dismissing alerts, keyed
+// by customer and alert ID
+type AlertStore struct {
+	mu        sync.RWMutex
+	dismissed map[string]map[string]models.Alert
+	merchants map[string]map[string]bool
+	locations map[string]map[string]bool
+}
+
+// defaultAlertStore is shared by the data providers so dismissed alerts stay
+// dismissed everywhere
+var defaultAlertStore = NewAlertStore()
+
+// NewAlertStore creates an empty alert store
+func NewAlertStore() *AlertStore {
+	return &AlertStore{
+		dismissed: make(map[string]map[string]models.Alert),
+		merchants: make(map[string]map[string]bool),
+		locations: make(map[string]map[string]bool),
+	}
+}
+
+// Dismiss records the customer's feedback on an alert. When the verdict is
+// expected, the transaction's merchant and location are remembered so they
+// aren't flagged as unusual again.
+func (s *AlertStore) Dismiss(customerID string, alert models.Alert, transaction *models.Transaction, dismissal AlertDismissal, now time.Time) (*models.Alert, error) {
+	verdict := strings.ToLower(strings.TrimSpace(dismissal.Verdict))
+	if verdict == "" {
+		verdict = AlertVerdictIgnore
+	}
+	if verdict != AlertVerdictExpected && verdict != AlertVerdictFraud && verdict != AlertVerdictIgnore {
+		return nil, NewValidationError("verdict", "verdict must be one of expected, fraud or ignore")
+	}
+	if len(dismissal.Note) > MaxNotesLength {
+		return nil, NewValidationError("note", "note must be at most %d characters", MaxNotesLength)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	feedback := models.AlertFeedback{
+		Verdict:     verdict,
+		Note:        strings.TrimSpace(dismissal.Note),
+		DismissedAt: now,
+	}
+	alert.Dismissed = true
+	alert.Feedback = &feedback
+	if _, exists := s.dismissed[customerID]; !exists {
+		s.dismissed[customerID] = make(map[string]models.Alert)
+	}
+	s.dismissed[customerID][alert.ID] = alert
+
+	if verdict == AlertVerdictExpected && transaction != nil {
+		if key := recurringKey(*transaction); key != "" {
+			if _, exists := s.merchants[customerID]; !exists {
+				s.merchants[customerID] = make(map[string]bool)
+			}
+			s.merchants[customerID][key] = true
+		}
+		if transaction.Location != nil {
+			if _, exists := s.locations[customerID]; !exists {
+				s.locations[customerID] = make(map[string]bool)
+			}
+			s.locations[customerID][locationKey(transaction.Location)] = true
+		}
+	}
+	return &alert, nil
+}
+
+// AllowList returns the merchants and locations a customer has marked as expected
+func (s *AlertStore) AllowList(customerID string) AnomalyAllowList {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	allowed := AnomalyAllowList{
+		Merchants: make(map[string]bool),
+		Locations: make(map[string]bool),
+	}
+	for key := range s.merchants[customerID] {
+		allowed.Merchants[key] = true
+	}
+	for key := range s.locations[customerID] {
+		allowed.Locations[key] = true
+	}
+	return allowed
+}
+
+// Apply merges freshly detected alerts with dismissed ones. Dismissed alerts
+// are kept as they were when dismissed, since marking activity as expected
+// can stop it from being detected again, and are only included when
+// includeDismissed is set.
+func (s *AlertStore) Apply(customerID string, alerts []models.Alert, includeDismissed bool) []models.Alert {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []models.Alert{}
+	for _, alert := range alerts {
+		if _, dismissed := s.dismissed[customerID][alert.ID]; !dismissed {
+			result = append(result, alert)
+		}
+	}
+	if includeDismissed {
+		for _, alert := range s.dismissed[customerID] {
+			result = append(result, alert)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].Date.Equal(result[j].Date) {
+			return result[i].Date.After(result[j].Date)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
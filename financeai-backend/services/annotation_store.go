@@ -0,0 +1,274 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+const (
+	// MaxAttachmentSize is the largest attachment that can be uploaded
+	MaxAttachmentSize = 10 << 20
+	// MaxTransactionTags caps how many tags a transaction can carry
+	MaxTransactionTags = 20
+	// MaxTagLength caps the length of a single tag
+	MaxTagLength = 40
+	// MaxNotesLength caps the length of transaction notes
+	MaxNotesLength = 2000
+)
+
+// allowedAttachmentTypes lists the content types accepted for receipts
+var allowedAttachmentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/heic":      ".heic",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// TransactionAnnotationUpdate describes a change to a transaction's user
+// fields. Nil fields are left unchanged.
+type TransactionAnnotationUpdate struct {
+	Tags       *[]string `json:"tags"`
+	AddTags    []string  `json:"addTags"`
+	RemoveTags []string  `json:"removeTags"`
+	Notes      *string   `json:"notes"`
+}
+
+// transactionAnnotation is the user-editable data kept for one transaction
+type transactionAnnotation struct {
+	Tags        []string
+	Notes       string
+	Attachments []models.Attachment
+}
+
+// AnnotationStore holds user tags, notes and attachments on transactions,
+// keyed by customer and transaction ID
+type AnnotationStore struct {
+	mu          sync.RWMutex
+	annotations map[string]map[string]*transactionAnnotation
+	storage     AttachmentStorage
+}
+
+// defaultAnnotationStore is shared by the data providers so edits made
+// through one endpoint show up everywhere
+var defaultAnnotationStore = NewAnnotationStore(NewLocalAttachmentStorage(""))
+
+// NewAnnotationStore creates an empty annotation store backed by storage
+func NewAnnotationStore(storage AttachmentStorage) *AnnotationStore {
+	return &AnnotationStore{
+		annotations: make(map[string]map[string]*transactionAnnotation),
+		storage:     storage,
+	}
+}
+
+// ConfigureAttachmentStorage sets where the shared annotation store keeps
+// attachment files
+func ConfigureAttachmentStorage(storage AttachmentStorage) {
+	defaultAnnotationStore.mu.Lock()
+	defer defaultAnnotationStore.mu.Unlock()
+	defaultAnnotationStore.storage = storage
+}
+
+// NormalizeTags lowercases and trims tags, joins words with dashes and drops
+// duplicates, so "Work Trip" and "work-trip" are the same tag
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, tag := range tags {
+		normalized := normalizeTag(tag)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		if len(normalized) > MaxTagLength {
+			return nil, NewValidationError("tags", "tag %q is longer than %d characters", normalized, MaxTagLength)
+		}
+		seen[normalized] = true
+		result = append(result, normalized)
+	}
+	if len(result) > MaxTransactionTags {
+		return nil, NewValidationError("tags", "a transaction can have at most %d tags", MaxTransactionTags)
+	}
+	return result, nil
+}
+
+func normalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+}
+
+// annotation returns the annotation for a transaction, creating it if needed.
+// Callers must hold the write lock.
+func (s *AnnotationStore) annotation(customerID string, transactionID string) *transactionAnnotation {
+	if _, exists := s.annotations[customerID]; !exists {
+		s.annotations[customerID] = make(map[string]*transactionAnnotation)
+	}
+	annotation, exists := s.annotations[customerID][transactionID]
+	if !exists {
+		annotation = &transactionAnnotation{}
+		s.annotations[customerID][transactionID] = annotation
+	}
+	return annotation
+}
+
+// Update applies a change to a transaction's tags and notes
+func (s *AnnotationStore) Update(customerID string, transactionID string, update TransactionAnnotationUpdate) error {
+	if update.Notes != nil && len(*update.Notes) > MaxNotesLength {
+		return NewValidationError("notes", "notes must be at most %d characters", MaxNotesLength)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	annotation := s.annotation(customerID, transactionID)
+
+	tags := annotation.Tags
+	if update.Tags != nil {
+		tags = *update.Tags
+	}
+	remove := make(map[string]bool)
+	for _, tag := range update.RemoveTags {
+		remove[normalizeTag(tag)] = true
+	}
+	var kept []string
+	for _, tag := range append(append([]string{}, tags...), update.AddTags...) {
+		if !remove[normalizeTag(tag)] {
+			kept = append(kept, tag)
+		}
+	}
+	normalized, err := NormalizeTags(kept)
+	if err != nil {
+		return err
+	}
+
+	annotation.Tags = normalized
+	if update.Notes != nil {
+		annotation.Notes = strings.TrimSpace(*update.Notes)
+	}
+	return nil
+}
+
+// attachmentKey is the storage key for an attachment's contents
+func attachmentKey(customerID string, transactionID string, attachment models.Attachment) string {
+	return fmt.Sprintf("%s/%s/%s%s", safeKeyPart(customerID), safeKeyPart(transactionID), attachment.ID, allowedAttachmentTypes[attachment.ContentType])
+}
+
+// safeKeyPart strips anything from an ID that could change the storage path
+func safeKeyPart(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '.' {
+			return '_'
+		}
+		return r
+	}, value)
+}
+
+// AddAttachment stores a file and attaches it to a transaction
+func (s *AnnotationStore) AddAttachment(customerID string, transactionID string, filename string, contentType string, r io.Reader) (*models.Attachment, error) {
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if _, allowed := allowedAttachmentTypes[contentType]; !allowed {
+		return nil, NewValidationError("file", "unsupported attachment type: %s", contentType)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate attachment id: %v", err)
+	}
+	attachment := models.Attachment{
+		ID:          hex.EncodeToString(id),
+		Filename:    filepath.Base(filename),
+		ContentType: contentType,
+		UploadedAt:  time.Now(),
+	}
+
+	s.mu.RLock()
+	storage := s.storage
+	s.mu.RUnlock()
+
+	key := attachmentKey(customerID, transactionID, attachment)
+	size, err := storage.Save(key, io.LimitReader(r, MaxAttachmentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if size > MaxAttachmentSize {
+		storage.Delete(key)
+		return nil, NewValidationError("file", "attachment is larger than %d MB", MaxAttachmentSize>>20)
+	}
+	attachment.Size = size
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	annotation := s.annotation(customerID, transactionID)
+	annotation.Attachments = append(annotation.Attachments, attachment)
+	return &attachment, nil
+}
+
+// findAttachment looks up an attachment's metadata. Callers must hold a lock.
+func (s *AnnotationStore) findAttachment(customerID string, transactionID string, attachmentID string) (int, *transactionAnnotation, error) {
+	annotation, exists := s.annotations[customerID][transactionID]
+	if exists {
+		for i, attachment := range annotation.Attachments {
+			if attachment.ID == attachmentID {
+				return i, annotation, nil
+			}
+		}
+	}
+	return -1, nil, NewNotFoundError("attachment not found: %s", attachmentID)
+}
+
+// OpenAttachment returns an attachment's metadata and a reader for its contents
+func (s *AnnotationStore) OpenAttachment(customerID string, transactionID string, attachmentID string) (*models.Attachment, io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	index, annotation, err := s.findAttachment(customerID, transactionID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachment := annotation.Attachments[index]
+	reader, err := s.storage.Open(attachmentKey(customerID, transactionID, attachment))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &attachment, reader, nil
+}
+
+// DeleteAttachment removes an attachment and its stored contents
+func (s *AnnotationStore) DeleteAttachment(customerID string, transactionID string, attachmentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index, annotation, err := s.findAttachment(customerID, transactionID, attachmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.storage.Delete(attachmentKey(customerID, transactionID, annotation.Attachments[index])); err != nil {
+		return err
+	}
+	annotation.Attachments = append(annotation.Attachments[:index], annotation.Attachments[index+1:]...)
+	return nil
+}
+
+// Apply returns a copy of transactions with their tags, notes and attachments attached
+func (s *AnnotationStore) Apply(customerID string, transactions []models.Transaction) []models.Transaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.Transaction, len(transactions))
+	copy(result, transactions)
+	for i := range result {
+		annotation, exists := s.annotations[customerID][result[i].ID]
+		if !exists {
+			continue
+		}
+		result[i].Tags = append([]string(nil), annotation.Tags...)
+		result[i].Notes = annotation.Notes
+		result[i].Attachments = append([]models.Attachment(nil), annotation.Attachments...)
+	}
+	return result
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Anomaly signal and alert types
+const (
+	SignalMerchantAmount = "merchant_amount"
+	SignalCategoryAmount = "category_amount"
+	SignalFirstMerchant  = "first_time_merchant"
+	SignalUnusualTime    = "unusual_time"
+	SignalUnusualPlace   = "unusual_location"
+	SignalDuplicate      = "duplicate_charge"
+	SignalCategorySpike  = "category_spike"
+
+	AlertTypeTransaction   = "transaction"
+	AlertTypeCategorySpike = "category_spike"
+)
+
+// AnomalyDetector scores transactions against the customer's earlier history
+type AnomalyDetector struct {
+	// FlagThreshold is the combined score at which a transaction is flagged
+	FlagThreshold float64
+	// Lookback is how far back transactions are scored; older ones are only history
+	Lookback time.Duration
+	// DuplicateWindow is how close together two identical charges must be
+	DuplicateWindow time.Duration
+	// MinHistory is how many earlier transactions a signal needs to compare against
+	MinHistory int
+	// ZScoreThreshold is how many standard deviations above normal an amount must be
+	ZScoreThreshold float64
+	// SpikeRatio is how far above its trailing average a category must run
+	SpikeRatio float64
+	// SpikeMinimum is the smallest increase that counts as a spike
+	SpikeMinimum float64
+	// Money formats amounts in alert reasons
+	Money MoneyFormatter
+}
+
+// NewAnomalyDetector creates an anomaly detector with default thresholds
+func NewAnomalyDetector() *AnomalyDetector {
+	return &AnomalyDetector{
+		FlagThreshold:   0.6,
+		Lookback:        30 * 24 * time.Hour,
+		DuplicateWindow: 2 * time.Hour,
+		MinHistory:      3,
+		ZScoreThreshold: 3,
+		SpikeRatio:      1.5,
+		SpikeMinimum:    100,
+		Money:           NewMoneyFormatter(DefaultCurrency, DefaultLocale),
+	}
+}
+
+// AnomalyAllowList holds what the customer has told us is expected, from
+// dismissed alerts
+type AnomalyAllowList struct {
+	Merchants map[string]bool
+	Locations map[string]bool
+}
+
+// locationKey identifies a location for comparisons
+func locationKey(location *models.Location) string {
+	country := location.Country
+	if country == "" {
+		country = "US"
+	}
+	return strings.ToUpper(country + "|" + location.State)
+}
+
+// meanAndDeviation returns the mean and standard deviation of values
+func meanAndDeviation(values []float64) (float64, float64) {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// zScore measures how far amount is above the typical value. The deviation
+// has a floor so a merchant that always charges the same amount doesn't
+// flag a one-cent difference.
+func zScore(amount float64, history []float64) (float64, float64) {
+	mean, deviation := meanAndDeviation(history)
+	deviation = math.Max(deviation, math.Max(mean*0.1, 1))
+	return (amount - mean) / deviation, mean
+}
+
+// ScoreTransaction returns the signals that make transaction look unusual
+// compared to history, the customer's earlier spending
+func (d *AnomalyDetector) ScoreTransaction(transaction models.Transaction, history []models.Transaction, home models.Address, allowed AnomalyAllowList) []models.AnomalySignal {
+	signals := []models.AnomalySignal{}
+	amount := math.Abs(transaction.Amount)
+	key := recurringKey(transaction)
+	category := transactionCategory(transaction)
+	name := transaction.Merchant.Name
+	if name == "" {
+		name = transaction.Description
+	}
+
+	var merchantAmounts, categoryAmounts []float64
+	nightCount := 0
+	seenLocations := make(map[string]bool)
+	for _, previous := range history {
+		previousAmount := math.Abs(previous.Amount)
+		if key != "" && recurringKey(previous) == key {
+			merchantAmounts = append(merchantAmounts, previousAmount)
+		}
+		if transactionCategory(previous) == category {
+			categoryAmounts = append(categoryAmounts, previousAmount)
+		}
+		if previous.TransactionDate.Hour() < 5 {
+			nightCount++
+		}
+		if previous.Location != nil {
+			seenLocations[locationKey(previous.Location)] = true
+		}
+
+		// Same merchant and amount moments apart is usually a double charge
+		gap := transaction.TransactionDate.Sub(previous.TransactionDate)
+		sameMerchant := key == recurringKey(previous) && (key != "" || previous.Description == transaction.Description)
+		if sameMerchant && toCents(previousAmount) == toCents(amount) && gap >= 0 && gap <= d.DuplicateWindow && len(signals) == 0 {
+			signals = append(signals, models.AnomalySignal{
+				Type:   SignalDuplicate,
+				Score:  0.7,
+				Reason: fmt.Sprintf("Charged %s by %s twice within %d minutes", d.Money.Format(amount), name, int(gap.Minutes())),
+			})
+		}
+	}
+
+	if len(merchantAmounts) >= d.MinHistory && !allowed.Merchants[key] {
+		if z, mean := zScore(amount, merchantAmounts); z >= d.ZScoreThreshold {
+			signals = append(signals, models.AnomalySignal{
+				Type:   SignalMerchantAmount,
+				Score:  0.6 + math.Min(0.4, (z-d.ZScoreThreshold)/10),
+				Reason: fmt.Sprintf("%s is much more than your usual %s at %s", d.Money.Format(amount), d.Money.Format(mean), name),
+			})
+		}
+	}
+
+	if len(categoryAmounts) >= d.MinHistory+2 {
+		if z, mean := zScore(amount, categoryAmounts); z >= d.ZScoreThreshold {
+			signals = append(signals, models.AnomalySignal{
+				Type:   SignalCategoryAmount,
+				Score:  0.45 + math.Min(0.35, (z-d.ZScoreThreshold)/10),
+				Reason: fmt.Sprintf("%s is unusually large for %s, where you typically spend %s", d.Money.Format(amount), category, d.Money.Format(mean)),
+			})
+		}
+	}
+
+	if key != "" && len(merchantAmounts) == 0 && len(history) >= d.MinHistory && !allowed.Merchants[key] && amount >= 100 {
+		score := 0.3
+		if amount >= 500 {
+			score = 0.45
+		}
+		signals = append(signals, models.AnomalySignal{
+			Type:   SignalFirstMerchant,
+			Score:  score,
+			Reason: fmt.Sprintf("First purchase at %s", name),
+		})
+	}
+
+	// Late-night purchases stand out for customers who rarely make them
+	if hour := transaction.TransactionDate.Hour(); hour < 5 && len(history) >= d.MinHistory && float64(nightCount) < float64(len(history))*0.1 {
+		signals = append(signals, models.AnomalySignal{
+			Type:   SignalUnusualTime,
+			Score:  0.35,
+			Reason: fmt.Sprintf("Made at %s, when you rarely spend", transaction.TransactionDate.Format("3:04 AM")),
+		})
+	}
+
+	if location := transaction.Location; location != nil && !seenLocations[locationKey(location)] && !allowed.Locations[locationKey(location)] {
+		place := strings.Trim(strings.Join([]string{location.City, location.State}, ", "), ", ")
+		switch {
+		case location.Country != "" && !strings.EqualFold(location.Country, "US"):
+			signals = append(signals, models.AnomalySignal{
+				Type:   SignalUnusualPlace,
+				Score:  0.55,
+				Reason: fmt.Sprintf("First purchase in %s", strings.Trim(place+", "+location.Country, ", ")),
+			})
+		case location.State != "" && !strings.EqualFold(location.State, home.State):
+			signals = append(signals, models.AnomalySignal{
+				Type:   SignalUnusualPlace,
+				Score:  0.4,
+				Reason: fmt.Sprintf("Made in %s, away from home in %s", place, home.State),
+			})
+		}
+	}
+
+	return signals
+}
+
+// combineSignals merges signal scores as independent evidence, so two weak
+// signals together outweigh either alone
+func combineSignals(signals []models.AnomalySignal) float64 {
+	unexplained := 1.0
+	for _, signal := range signals {
+		unexplained *= 1 - signal.Score
+	}
+	return math.Round((1-unexplained)*100) / 100
+}
+
+// Detect scores each expense from the lookback window against everything
+// before it, and checks each category's recent spending against its trailing
+// average. It returns the alerts that cross the flag threshold.
+func (d *AnomalyDetector) Detect(customer models.Customer, transactions []models.Transaction, allowed AnomalyAllowList, now time.Time) []models.Alert {
+	var expenses []models.Transaction
+	for _, transaction := range transactions {
+		if IsExpense(transaction) {
+			expenses = append(expenses, transaction)
+		}
+	}
+	sort.SliceStable(expenses, func(i, j int) bool {
+		return expenses[i].TransactionDate.Before(expenses[j].TransactionDate)
+	})
+
+	alerts := []models.Alert{}
+	since := now.Add(-d.Lookback)
+	for i, transaction := range expenses {
+		if transaction.TransactionDate.Before(since) {
+			continue
+		}
+		signals := d.ScoreTransaction(transaction, expenses[:i], customer.Address, allowed)
+		score := combineSignals(signals)
+		if len(signals) == 0 || score < d.FlagThreshold {
+			continue
+		}
+
+		sort.SliceStable(signals, func(a, b int) bool {
+			return signals[a].Score > signals[b].Score
+		})
+		reasons := make([]string, len(signals))
+		for j, signal := range signals {
+			reasons[j] = signal.Reason
+		}
+		alerts = append(alerts, models.Alert{
+			ID:            "txn:" + transaction.ID,
+			CustomerID:    customer.ID,
+			Type:          AlertTypeTransaction,
+			TransactionID: transaction.ID,
+			Category:      transactionCategory(transaction),
+			Amount:        roundCents(math.Abs(transaction.Amount)),
+			Score:         score,
+			Reason:        strings.Join(reasons, "; "),
+			Signals:       signals,
+			Date:          transaction.TransactionDate,
+		})
+	}
+
+	alerts = append(alerts, d.categorySpikes(customer, expenses, now)...)
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].Date.After(alerts[j].Date)
+	})
+	return alerts
+}
+
+// categorySpikes compares each category's spending over the lookback window
+// with its average over the windows before it. It needs at least two full
+// earlier windows of history, with spending in the category in each. Like
+// other signals, spikes are only alerted on once they cross the flag
+// threshold. Spike alerts are keyed by month, so dismissing one quiets that
+// category until the next month.
+func (d *AnomalyDetector) categorySpikes(customer models.Customer, expenses []models.Transaction, now time.Time) []models.Alert {
+	if len(expenses) == 0 {
+		return nil
+	}
+	earliest := expenses[0].TransactionDate
+	periods := int(now.Sub(earliest) / d.Lookback)
+	if periods > 3 {
+		periods = 3
+	}
+	if periods < 2 {
+		return nil
+	}
+
+	current := make(map[string]float64)
+	previous := make(map[string]float64)
+	activePeriods := make(map[string]map[int]bool)
+	currentStart := now.Add(-d.Lookback)
+	historyStart := currentStart.Add(-time.Duration(periods) * d.Lookback)
+	for _, transaction := range expenses {
+		date := transaction.TransactionDate
+		if date.Before(historyStart) || date.After(now) {
+			continue
+		}
+		for _, allocation := range TransactionAllocations(transaction) {
+			if date.Before(currentStart) {
+				previous[allocation.Category] += math.Abs(allocation.Amount)
+				if _, exists := activePeriods[allocation.Category]; !exists {
+					activePeriods[allocation.Category] = make(map[int]bool)
+				}
+				activePeriods[allocation.Category][int(currentStart.Sub(date)/d.Lookback)] = true
+			} else {
+				current[allocation.Category] += math.Abs(allocation.Amount)
+			}
+		}
+	}
+
+	var alerts []models.Alert
+	for category, spent := range current {
+		// A category needs spending in every earlier window to have a baseline
+		average := previous[category] / float64(periods)
+		if len(activePeriods[category]) < periods || spent < average*d.SpikeRatio || spent-average < d.SpikeMinimum {
+			continue
+		}
+		ratio := spent / average
+		signal := models.AnomalySignal{
+			Type:   SignalCategorySpike,
+			Score:  math.Round(math.Min(1, 0.5+(ratio-d.SpikeRatio)/3)*100) / 100,
+			Reason: fmt.Sprintf("You've spent %s on %s in the last %d days, %.1f× your usual %s", d.Money.Format(spent), category, int(d.Lookback.Hours()/24), ratio, d.Money.Format(average)),
+		}
+		if signal.Score < d.FlagThreshold {
+			continue
+		}
+		alerts = append(alerts, models.Alert{
+			ID:         "spike:" + strings.ReplaceAll(strings.ToLower(category), " ", "-") + ":" + now.Format(ReportMonthLayout),
+			CustomerID: customer.ID,
+			Type:       AlertTypeCategorySpike,
+			Category:   category,
+			Amount:     roundCents(spent),
+			Score:      signal.Score,
+			Reason:     signal.Reason,
+			Signals:    []models.AnomalySignal{signal},
+			Date:       startOfDay(now),
+		})
+	}
+	return alerts
+}
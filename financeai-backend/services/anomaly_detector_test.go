@@ -0,0 +1,201 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"financeai-backend/models"
+)
+
+var anomalyTestNow = time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+// anomalyPurchase is an expense at a merchant, hours after noon on a day
+// before anomalyTestNow
+func anomalyPurchase(id string, merchantID string, category string, amount float64, daysAgo int, hours float64) models.Transaction {
+	date := anomalyTestNow.AddDate(0, 0, -daysAgo).Add(time.Duration(hours * float64(time.Hour)))
+	return models.Transaction{
+		ID:              id,
+		Amount:          -amount,
+		TransactionDate: date,
+		Merchant:        models.Merchant{ID: merchantID, Name: merchantID, Category: category},
+	}
+}
+
+// coffeeHistory is five identical coffees at midday, all at home
+func coffeeHistory() []models.Transaction {
+	var history []models.Transaction
+	for i := 0; i < 5; i++ {
+		history = append(history, anomalyPurchase("coffee"+string(rune('1'+i)), "starbucks", "Food & Dining", 5, 10-i, 0))
+	}
+	return history
+}
+
+func signalTypes(signals []models.AnomalySignal) []string {
+	types := []string{}
+	for _, signal := range signals {
+		types = append(types, signal.Type)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func TestZScoreFloors(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  float64
+		history []float64
+		want    float64
+	}{
+		{"spread history", 20, []float64{5, 10, 15}, (20 - 10) / math.Sqrt(50.0/3)},
+		{"floor at a tenth of the mean", 26, []float64{20, 20, 20}, 3},
+		{"floor at a dollar", 8, []float64{5, 5, 5}, 3},
+		{"below the mean", 4, []float64{5, 5, 5}, -1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if z, _ := zScore(test.amount, test.history); math.Abs(z-test.want) > 1e-9 {
+				t.Errorf("zScore(%v, %v) = %v, want %v", test.amount, test.history, z, test.want)
+			}
+		})
+	}
+}
+
+func TestScoreTransactionSignals(t *testing.T) {
+	home := models.Address{State: "VA"}
+	tests := []struct {
+		name        string
+		transaction models.Transaction
+		allowed     AnomalyAllowList
+		want        []string
+	}{
+		{"usual purchase", anomalyPurchase("t", "starbucks", "Food & Dining", 5.5, 0, 0), AnomalyAllowList{}, []string{}},
+		{"just under the floored z-score", anomalyPurchase("t", "starbucks", "Food & Dining", 7.99, 0, 0), AnomalyAllowList{}, []string{}},
+		{"at the floored z-score", anomalyPurchase("t", "starbucks", "Food & Dining", 8, 0, 0), AnomalyAllowList{}, []string{SignalCategoryAmount, SignalMerchantAmount}},
+		{"expected merchant", anomalyPurchase("t", "starbucks", "Food & Dining", 8, 0, 0), AnomalyAllowList{Merchants: map[string]bool{"starbucks": true}}, []string{SignalCategoryAmount}},
+		{"duplicate within the window", anomalyPurchase("t", "starbucks", "Food & Dining", 5, 6, 1.5), AnomalyAllowList{}, []string{SignalDuplicate}},
+		{"same charge outside the window", anomalyPurchase("t", "starbucks", "Food & Dining", 5, 6, 3), AnomalyAllowList{}, []string{}},
+		{"first purchase at a merchant", anomalyPurchase("t", "best-buy", "Shopping", 150, 0, 0), AnomalyAllowList{}, []string{SignalFirstMerchant}},
+		{"small first purchase", anomalyPurchase("t", "best-buy", "Shopping", 40, 0, 0), AnomalyAllowList{}, []string{}},
+		{"expected first purchase", anomalyPurchase("t", "best-buy", "Shopping", 150, 0, 0), AnomalyAllowList{Merchants: map[string]bool{"best-buy": true}}, []string{}},
+		{"late at night", anomalyPurchase("t", "starbucks", "Food & Dining", 5, 0, -10), AnomalyAllowList{}, []string{SignalUnusualTime}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := signalTypes(NewAnomalyDetector().ScoreTransaction(test.transaction, coffeeHistory(), home, test.allowed))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("signals = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScoreTransactionLocations(t *testing.T) {
+	home := models.Address{State: "VA"}
+	history := coffeeHistory()
+	history[0].Location = &models.Location{City: "Richmond", State: "VA"}
+	history[1].Location = &models.Location{City: "Austin", State: "TX"}
+
+	tests := []struct {
+		name      string
+		location  models.Location
+		allowed   AnomalyAllowList
+		wantScore float64 // 0 for no location signal
+	}{
+		{"home state", models.Location{City: "Norfolk", State: "VA"}, AnomalyAllowList{}, 0},
+		{"state seen before", models.Location{City: "Dallas", State: "TX"}, AnomalyAllowList{}, 0},
+		{"new state", models.Location{City: "Denver", State: "CO"}, AnomalyAllowList{}, 0.4},
+		{"abroad", models.Location{City: "Paris", Country: "FR"}, AnomalyAllowList{}, 0.55},
+		{"expected place abroad", models.Location{City: "Paris", Country: "FR"}, AnomalyAllowList{Locations: map[string]bool{"FR|": true}}, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transaction := anomalyPurchase("t", "starbucks", "Food & Dining", 5, 0, 0)
+			transaction.Location = &test.location
+			var score float64
+			for _, signal := range NewAnomalyDetector().ScoreTransaction(transaction, history, home, test.allowed) {
+				if signal.Type == SignalUnusualPlace {
+					score = signal.Score
+				}
+			}
+			if score != test.wantScore {
+				t.Errorf("location score = %v, want %v", score, test.wantScore)
+			}
+		})
+	}
+}
+
+func TestDetectHonoursExpectedDismissals(t *testing.T) {
+	customer := models.Customer{ID: "c1", Address: models.Address{State: "VA"}}
+	trip := anomalyPurchase("trip", "louvre", "Entertainment", 600, 1, 0)
+	trip.Location = &models.Location{City: "Paris", Country: "FR"}
+	transactions := append(coffeeHistory(),
+		anomalyPurchase("gadget", "best-buy", "Shopping", 150, 2, 0),
+		trip,
+	)
+	detector := NewAnomalyDetector()
+
+	// A first purchase alone stays under the flag threshold; a big one abroad doesn't
+	alerts := detector.Detect(customer, transactions, AnomalyAllowList{}, anomalyTestNow)
+	if len(alerts) != 1 || alerts[0].TransactionID != "trip" || alerts[0].Score != 0.75 {
+		t.Fatalf("alerts = %+v, want only the trip scored 0.75", alerts)
+	}
+
+	store := NewAlertStore()
+	if _, err := store.Dismiss(customer.ID, alerts[0], &trip, AlertDismissal{Verdict: AlertVerdictExpected}, anomalyTestNow); err != nil {
+		t.Fatal(err)
+	}
+	allowed := store.AllowList(customer.ID)
+	if !allowed.Merchants["louvre"] || !allowed.Locations["FR|"] {
+		t.Errorf("allow list = %+v, want the merchant and country", allowed)
+	}
+	if alerts := detector.Detect(customer, transactions, allowed, anomalyTestNow); len(alerts) != 0 {
+		t.Errorf("alerts after marking the trip expected = %+v", alerts)
+	}
+}
+
+func TestCategorySpikes(t *testing.T) {
+	customer := models.Customer{ID: "c1"}
+	// history spends perWindow on shopping in each of the three windows
+	// before the current one
+	history := func(perWindow float64) []models.Transaction {
+		var transactions []models.Transaction
+		for window := 1; window <= 3; window++ {
+			transactions = append(transactions, anomalyPurchase("old"+string(rune('0'+window)), "target", "Shopping", perWindow, window*30+10, 0))
+		}
+		return transactions
+	}
+
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+		wantScore    float64 // 0 for no alert
+	}{
+		{"well above the average", append(history(200), anomalyPurchase("now", "target", "Shopping", 500, 5, 0)), 0.83},
+		{"above the ratio but under the flag threshold", append(history(200), anomalyPurchase("now", "target", "Shopping", 340, 5, 0)), 0},
+		{"under the ratio", append(history(200), anomalyPurchase("now", "target", "Shopping", 290, 5, 0)), 0},
+		{"increase under the minimum", append(history(40), anomalyPurchase("now", "target", "Shopping", 120, 5, 0)), 0},
+		{"a window without spending", append(history(200)[1:], anomalyPurchase("first", "target", "Shopping", 10, 100, 0), anomalyPurchase("now", "target", "Shopping", 500, 5, 0)), 0},
+		{"too little history", []models.Transaction{anomalyPurchase("old", "target", "Shopping", 100, 40, 0), anomalyPurchase("now", "target", "Shopping", 500, 5, 0)}, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sort.SliceStable(test.transactions, func(i, j int) bool {
+				return test.transactions[i].TransactionDate.Before(test.transactions[j].TransactionDate)
+			})
+			alerts := NewAnomalyDetector().categorySpikes(customer, test.transactions, anomalyTestNow)
+			var score float64
+			if len(alerts) > 0 {
+				score = alerts[0].Score
+			}
+			if len(alerts) > 1 || score != test.wantScore {
+				t.Errorf("alerts = %+v, want one scored %v", alerts, test.wantScore)
+			}
+			if len(alerts) == 1 && alerts[0].ID != "spike:shopping:2026-03" {
+				t.Errorf("alert ID = %q, want it keyed by month", alerts[0].ID)
+			}
+		})
+	}
+}
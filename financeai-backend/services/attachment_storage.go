@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultAttachmentDir is where attachments are stored when no directory is configured
+const DefaultAttachmentDir = "data/attachments"
+
+// AttachmentStorage stores the contents of transaction attachments
+type AttachmentStorage interface {
+	Save(key string, r io.Reader) (int64, error)
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// LocalAttachmentStorage stores attachments as files under a root directory
+type LocalAttachmentStorage struct {
+	Root string
+}
+
+// NewLocalAttachmentStorage creates a storage rooted at dir, or at
+// DefaultAttachmentDir if dir is empty
+func NewLocalAttachmentStorage(dir string) *LocalAttachmentStorage {
+	if dir == "" {
+		dir = DefaultAttachmentDir
+	}
+	return &LocalAttachmentStorage{Root: dir}
+}
+
+// path resolves a key to a file path, refusing keys that escape the root
+func (l *LocalAttachmentStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if cleaned == "." || filepath.IsAbs(cleaned) || strings.HasPrefix(cleaned, "..") {
+		return "", fmt.Errorf("invalid attachment key: %s", key)
+	}
+	return filepath.Join(l.Root, cleaned), nil
+}
+
+// Save writes the contents of r to key, returning the number of bytes written
+func (l *LocalAttachmentStorage) Save(key string, r io.Reader) (int64, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create attachment directory: %v", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create attachment: %v", err)
+	}
+	written, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, fmt.Errorf("failed to write attachment: %v", err)
+	}
+	return written, nil
+}
+
+// Open returns a reader for the attachment stored at key
+func (l *LocalAttachmentStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open attachment: %v", err)
+	}
+	return file, nil
+}
+
+// Delete removes the attachment stored at key
+func (l *LocalAttachmentStorage) Delete(key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete attachment: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Bill sources
+const (
+	// BillSourceManual marks bills the customer entered
+	BillSourceManual = "manual"
+	// BillSourceDetected marks bills found from recurring charges
+	BillSourceDetected = "detected"
+
+	// BillDateLayout is the format bill due dates are entered in
+	BillDateLayout = "2006-01-02"
+
+	// DefaultUpcomingBillDays is how far ahead upcoming bills look by default
+	DefaultUpcomingBillDays = 30
+	// MaxUpcomingBillDays caps how far ahead upcoming bills can look
+	MaxUpcomingBillDays = 365
+)
+
+// billFrequencies lists the schedules a manual bill can repeat on
+var billFrequencies = map[string]bool{
+	"once":      true,
+	"weekly":    true,
+	"biweekly":  true,
+	"monthly":   true,
+	"quarterly": true,
+	"yearly":    true,
+}
+
+// BillInput describes a bill to create or change. Nil fields are left
+// unchanged on update.
+type BillInput struct {
+	Name      *string  `json:"name"`
+	Category  *string  `json:"category"`
+	Amount    *float64 `json:"amount"`
+	AccountID *string  `json:"accountId"`
+	Frequency *string  `json:"frequency"`
+	DueDate   *string  `json:"dueDate"`
+	AutoPay   *bool    `json:"autoPay"`
+}
+
+// BillStore holds the bills customers enter by hand, keyed by customer
+type BillStore struct {
+	mu        sync.RWMutex
+	bills     map[string]map[string]*models.Bill
+	merchants *MerchantRegistry
+	nextID    int
+}
+
+// defaultBillStore is shared by the data providers so bills entered through
+// one endpoint show up in every calendar
+var defaultBillStore = NewBillStore(defaultMerchantRegistry)
+
+// NewBillStore creates an empty bill store that matches bill names to
+// merchants with registry
+func NewBillStore(registry *MerchantRegistry) *BillStore {
+	return &BillStore{
+		bills:     make(map[string]map[string]*models.Bill),
+		merchants: registry,
+	}
+}
+
+// applyBillInput copies the set fields of input onto bill and validates the result
+func (s *BillStore) applyBillInput(bill *models.Bill, input BillInput) error {
+	if input.Name != nil {
+		bill.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Category != nil {
+		bill.Category = strings.TrimSpace(*input.Category)
+	}
+	if input.Amount != nil {
+		bill.Amount = roundCents(*input.Amount)
+	}
+	if input.AccountID != nil {
+		bill.AccountID = strings.TrimSpace(*input.AccountID)
+	}
+	if input.Frequency != nil {
+		bill.Frequency = strings.ToLower(strings.TrimSpace(*input.Frequency))
+	}
+	if input.DueDate != nil {
+		dueDate, err := time.Parse(BillDateLayout, *input.DueDate)
+		if err != nil {
+			return NewValidationError("dueDate", "dueDate must be in YYYY-MM-DD format")
+		}
+		bill.DueDate = dueDate
+	}
+	if input.AutoPay != nil {
+		bill.AutoPay = *input.AutoPay
+	}
+
+	if bill.Name == "" {
+		return NewValidationError("name", "name required")
+	}
+	if bill.Amount <= 0 || math.IsInf(bill.Amount, 0) {
+		return NewValidationError("amount", "amount must be greater than 0")
+	}
+	if bill.AccountID == "" {
+		return NewValidationError("accountId", "accountId required")
+	}
+	if !billFrequencies[bill.Frequency] {
+		return NewValidationError("frequency", "unsupported frequency: %s", bill.Frequency)
+	}
+	if bill.DueDate.IsZero() {
+		return NewValidationError("dueDate", "dueDate required")
+	}
+
+	// Match the bill to a merchant so it replaces the detected charge
+	if entry, exists := s.merchants.Resolve(bill.Name); exists {
+		bill.MerchantID = entry.ID
+		if bill.Category == "" {
+			bill.Category = entry.Category
+		}
+	} else {
+		bill.MerchantID = "unresolved:" + strings.ReplaceAll(NormalizeMerchantString(bill.Name), " ", "-")
+	}
+	if bill.Category == "" {
+		bill.Category = "Other"
+	}
+	return nil
+}
+
+// Bills returns a customer's bills, soonest first
+func (s *BillStore) Bills(customerID string) []models.Bill {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bills := make([]models.Bill, 0, len(s.bills[customerID]))
+	for _, bill := range s.bills[customerID] {
+		bills = append(bills, *bill)
+	}
+	sort.Slice(bills, func(i, j int) bool {
+		if !bills[i].DueDate.Equal(bills[j].DueDate) {
+			return bills[i].DueDate.Before(bills[j].DueDate)
+		}
+		return bills[i].ID < bills[j].ID
+	})
+	return bills
+}
+
+// Add creates a bill. Frequency defaults to monthly.
+func (s *BillStore) Add(customerID string, input BillInput) (*models.Bill, error) {
+	bill := models.Bill{CustomerID: customerID, Frequency: "monthly"}
+	if err := s.applyBillInput(&bill, input); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	bill.ID = fmt.Sprintf("bill%d", s.nextID)
+	if _, exists := s.bills[customerID]; !exists {
+		s.bills[customerID] = make(map[string]*models.Bill)
+	}
+	s.bills[customerID][bill.ID] = &bill
+	return &bill, nil
+}
+
+// Update changes a bill
+func (s *BillStore) Update(customerID string, billID string, input BillInput) (*models.Bill, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.bills[customerID][billID]
+	if !exists {
+		return nil, NewNotFoundError("bill not found: %s", billID)
+	}
+	bill := *existing
+	if err := s.applyBillInput(&bill, input); err != nil {
+		return nil, err
+	}
+	*existing = bill
+	return &bill, nil
+}
+
+// Delete removes a bill
+func (s *BillStore) Delete(customerID string, billID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.bills[customerID][billID]; !exists {
+		return NewNotFoundError("bill not found: %s", billID)
+	}
+	delete(s.bills[customerID], billID)
+	return nil
+}
+
+// occurrences lists the dates a schedule falls on in [start, end). Monthly
+// and longer schedules keep the anchor's day of month, moving to the last day
+// of short months.
+func occurrences(anchor time.Time, frequency string, start time.Time, end time.Time) []time.Time {
+	var dates []time.Time
+	anchor = startOfDay(anchor)
+	add := func(date time.Time) {
+		if !date.Before(start) && date.Before(end) {
+			dates = append(dates, date)
+		}
+	}
+
+	if frequency == "once" {
+		add(anchor)
+		return dates
+	}
+	if frequency == "semimonthly" {
+		for date := anchor; date.Before(end); date = nextOccurrence(frequency, date) {
+			add(date)
+		}
+		return dates
+	}
+
+	anchorMonth := time.Date(anchor.Year(), anchor.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for n := 0; ; n++ {
+		var date time.Time
+		switch frequency {
+		case "weekly":
+			date = anchor.AddDate(0, 0, 7*n)
+		case "biweekly":
+			date = anchor.AddDate(0, 0, 14*n)
+		case "monthly":
+			date = dueDate(anchorMonth.AddDate(0, n, 0), anchor.Day())
+		case "quarterly":
+			date = dueDate(anchorMonth.AddDate(0, 3*n, 0), anchor.Day())
+		case "yearly":
+			date = dueDate(anchorMonth.AddDate(n, 0, 0), anchor.Day())
+		default:
+			return dates
+		}
+		if !date.Before(end) {
+			return dates
+		}
+		add(date)
+	}
+}
+
+// BuildBillCalendar lists every bill due in [start, end), combining manual
+// bills with detected recurring charges. A manual bill replaces a recurring
+// charge from the same merchant. Bills paid from asset accounts get the
+// account's projected balance after payment, counting expected paychecks and
+// the other bills due before them, and are flagged when that goes negative.
+func BuildBillCalendar(accounts []models.Account, bills []models.Bill, recurring []models.RecurringCharge, payroll *models.PayrollCadence, start time.Time, end time.Time, now time.Time) []models.UpcomingBill {
+	accountsByID := make(map[string]models.Account, len(accounts))
+	for _, account := range accounts {
+		accountsByID[account.ID] = account
+	}
+
+	// Project from today even when the window starts later, so earlier bills
+	// still draw the balance down
+	today := startOfDay(now)
+	projectFrom := start
+	if today.Before(start) {
+		projectFrom = today
+	}
+
+	var upcoming []models.UpcomingBill
+	add := func(bill models.UpcomingBill, anchor time.Time) {
+		bill.AccountName = accountsByID[bill.AccountID].Nickname
+		for _, date := range occurrences(anchor, bill.Frequency, projectFrom, end) {
+			bill.DueDate = date
+			upcoming = append(upcoming, bill)
+		}
+	}
+
+	manualMerchants := make(map[string]bool, len(bills))
+	for _, bill := range bills {
+		manualMerchants[bill.MerchantID] = true
+		add(models.UpcomingBill{
+			BillID:    bill.ID,
+			Source:    BillSourceManual,
+			Name:      bill.Name,
+			Category:  bill.Category,
+			Amount:    bill.Amount,
+			Frequency: bill.Frequency,
+			AccountID: bill.AccountID,
+			AutoPay:   bill.AutoPay,
+		}, bill.DueDate)
+	}
+	for _, charge := range recurring {
+		if manualMerchants[charge.MerchantID] {
+			continue
+		}
+		add(models.UpcomingBill{
+			BillID:    charge.ID,
+			Source:    BillSourceDetected,
+			Name:      charge.Name,
+			Category:  charge.Category,
+			Amount:    charge.LastAmount,
+			Frequency: charge.Frequency,
+			AccountID: charge.AccountID,
+			AutoPay:   true,
+		}, charge.NextDueDate)
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		if !upcoming[i].DueDate.Equal(upcoming[j].DueDate) {
+			return upcoming[i].DueDate.Before(upcoming[j].DueDate)
+		}
+		return upcoming[i].Amount > upcoming[j].Amount
+	})
+
+	// Expected paychecks, by date, for the account they land in
+	var paychecks []time.Time
+	if payroll != nil && payroll.NextExpectedDate != nil {
+		paychecks = occurrences(*payroll.NextExpectedDate, payroll.Frequency, today, end)
+	}
+
+	balances := make(map[string]float64, len(accounts))
+	for _, account := range accounts {
+		balances[account.ID] = float64(account.Balance)
+	}
+	nextPaycheck := 0
+	result := []models.UpcomingBill{}
+	for _, bill := range upcoming {
+		// Paychecks arriving on or before the due date are available to pay it
+		for nextPaycheck < len(paychecks) && !paychecks[nextPaycheck].After(bill.DueDate) {
+			balances[payroll.AccountID] += payroll.AverageAmount
+			nextPaycheck++
+		}
+
+		account, known := accountsByID[bill.AccountID]
+		if known && ClassifyAccount(account.Type) == AccountClassAsset && !bill.DueDate.Before(today) {
+			balances[bill.AccountID] = roundCents(balances[bill.AccountID] - bill.Amount)
+			projected := balances[bill.AccountID]
+			bill.ProjectedBalance = &projected
+			if projected < 0 {
+				bill.InsufficientFunds = true
+				bill.Shortfall = roundCents(math.Min(-projected, bill.Amount))
+			}
+		}
+
+		if !bill.DueDate.Before(start) {
+			result = append(result, bill)
+		}
+	}
+	return result
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Cache backends
+const (
+	CacheBackendMemory = "memory"
+	CacheBackendDisk   = "disk"
+
+	// DefaultCacheDir is where the disk cache keeps entries when no directory is configured
+	DefaultCacheDir = "data/cache"
+	// DefaultCacheSize is how many entries the memory cache holds by default
+	DefaultCacheSize = 1000
+)
+
+// Cache stores encoded values for a limited time
+type Cache interface {
+	// Get returns the value stored at key, if it's there and hasn't expired
+	Get(key string) ([]byte, bool)
+	// Set stores value at key for ttl, or until evicted if ttl is 0
+	Set(key string, value []byte, ttl time.Duration)
+	// DeletePrefix removes every entry whose key starts with prefix and
+	// returns how many it removed
+	DeletePrefix(prefix string) int
+	// Prune removes expired entries and returns how many it removed
+	Prune() int
+	Stats() models.CacheStats
+}
+
+// cacheCounters tracks how a cache is being used
+type cacheCounters struct {
+	hits, misses, sets, evictions int64
+}
+
+// stats fills in the counters of a cache's stats
+func (c cacheCounters) stats(backend string, entries int) models.CacheStats {
+	stats := models.CacheStats{
+		Backend:   backend,
+		Entries:   entries,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Sets:      c.sets,
+		Evictions: c.evictions,
+	}
+	if lookups := c.hits + c.misses; lookups > 0 {
+		stats.HitRate = float64(c.hits) / float64(lookups)
+	}
+	return stats
+}
+
+// memoryEntry is a value held by the memory cache
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// expired reports whether the entry's TTL has passed
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// MemoryCache is an in-memory cache that evicts the least recently used
+// entry once it holds Capacity entries
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+	counters cacheCounters
+}
+
+// NewMemoryCache creates a memory cache holding up to capacity entries, or
+// DefaultCacheSize if capacity isn't positive
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity <= 0 {
+		capacity = DefaultCacheSize
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored at key and marks it recently used
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.entries[key]
+	if !exists {
+		c.counters.misses++
+		return nil, false
+	}
+	entry := element.Value.(*memoryEntry)
+	if entry.expired(time.Now()) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		c.counters.misses++
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	c.counters.hits++
+	return entry.value, true
+}
+
+// Set stores value at key, evicting the least recently used entry if the cache is full
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	c.counters.sets++
+	if element, exists := c.entries[key]; exists {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+		c.counters.evictions++
+	}
+}
+
+// DeletePrefix removes every entry whose key starts with prefix
+func (c *MemoryCache) DeletePrefix(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, element := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.order.Remove(element)
+			delete(c.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Prune removes expired entries
+func (c *MemoryCache) Prune() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	removed := 0
+	for key, element := range c.entries {
+		if element.Value.(*memoryEntry).expired(now) {
+			c.order.Remove(element)
+			delete(c.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Stats returns the cache's size and hit and miss counts
+func (c *MemoryCache) Stats() models.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counters.stats(CacheBackendMemory, len(c.entries))
+}
+
+// diskEntry is how the disk cache stores a value
+type diskEntry struct {
+	Key       string    `json:"key"`
+	Value     []byte    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DiskCache keeps entries as files under a directory, so they survive restarts
+type DiskCache struct {
+	mu       sync.Mutex
+	dir      string
+	counters cacheCounters
+}
+
+// NewDiskCache creates a disk cache under dir, or DefaultCacheDir if dir is empty
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if dir == "" {
+		dir = DefaultCacheDir
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// path returns the file a key is stored in
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// read loads the entry stored in a file
+func (c *DiskCache) read(path string) (*diskEntry, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Get returns the value stored at key
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(key)
+	entry, ok := c.read(path)
+	if !ok || entry.Key != key {
+		c.counters.misses++
+		return nil, false
+	}
+	if !entry.ExpiresAt.IsZero() && time.Now().After(entry.ExpiresAt) {
+		os.Remove(path)
+		c.counters.misses++
+		return nil, false
+	}
+	c.counters.hits++
+	return entry.Value, true
+}
+
+// Set writes value to key's file, replacing it atomically
+func (c *DiskCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := diskEntry{Key: key, Value: value}
+	if ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(ttl)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	path := c.path(key)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		fmt.Printf("Cache Error: failed to write entry: %v\n", err)
+		return
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		fmt.Printf("Cache Error: failed to save entry: %v\n", err)
+		return
+	}
+	c.counters.sets++
+}
+
+// each calls fn with every stored entry and the file it's in
+func (c *DiskCache) each(fn func(path string, entry *diskEntry)) {
+	paths, _ := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	for _, path := range paths {
+		if entry, ok := c.read(path); ok {
+			fn(path, entry)
+		}
+	}
+}
+
+// DeletePrefix removes every entry whose key starts with prefix
+func (c *DiskCache) DeletePrefix(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	c.each(func(path string, entry *diskEntry) {
+		if strings.HasPrefix(entry.Key, prefix) && os.Remove(path) == nil {
+			removed++
+		}
+	})
+	return removed
+}
+
+// Prune removes expired entries
+func (c *DiskCache) Prune() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	removed := 0
+	c.each(func(path string, entry *diskEntry) {
+		if !entry.ExpiresAt.IsZero() && now.After(entry.ExpiresAt) && os.Remove(path) == nil {
+			removed++
+		}
+	})
+	return removed
+}
+
+// Stats returns the cache's size and hit and miss counts
+func (c *DiskCache) Stats() models.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	paths, _ := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	return c.counters.stats(CacheBackendDisk, len(paths))
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+// cacheBackends returns a fresh memory and disk cache for tests that apply to both
+func cacheBackends(t *testing.T) map[string]Cache {
+	t.Helper()
+	disk, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]Cache{CacheBackendMemory: NewMemoryCache(10), CacheBackendDisk: disk}
+}
+
+func TestCacheExpiresEntries(t *testing.T) {
+	for backend, cache := range cacheBackends(t) {
+		t.Run(backend, func(t *testing.T) {
+			cache.Set("short", []byte("a"), 20*time.Millisecond)
+			cache.Set("forever", []byte("b"), 0)
+			if value, ok := cache.Get("short"); !ok || string(value) != "a" {
+				t.Fatalf("Get before expiry = %q, %v", value, ok)
+			}
+
+			time.Sleep(30 * time.Millisecond)
+			if _, ok := cache.Get("short"); ok {
+				t.Error("an expired entry was returned")
+			}
+			if _, ok := cache.Get("forever"); !ok {
+				t.Error("an entry without a TTL expired")
+			}
+
+			cache.Set("pruned", []byte("c"), time.Millisecond)
+			time.Sleep(5 * time.Millisecond)
+			if removed := cache.Prune(); removed != 1 {
+				t.Errorf("Prune removed %d entries, want 1", removed)
+			}
+			stats := cache.Stats()
+			if stats.Entries != 1 || stats.Hits != 2 || stats.Misses != 1 || stats.Sets != 3 {
+				t.Errorf("stats = %+v, want 1 entry, 2 hits, 1 miss and 3 sets", stats)
+			}
+		})
+	}
+}
+
+func TestCacheDeletePrefix(t *testing.T) {
+	for backend, cache := range cacheBackends(t) {
+		t.Run(backend, func(t *testing.T) {
+			cache.Set("data|c1|a", []byte("1"), 0)
+			cache.Set("data|c1|b", []byte("2"), 0)
+			cache.Set("data|c10|a", []byte("3"), 0)
+			if removed := cache.DeletePrefix("data|c1|"); removed != 2 {
+				t.Errorf("DeletePrefix removed %d entries, want 2", removed)
+			}
+			if _, ok := cache.Get("data|c10|a"); !ok {
+				t.Error("an entry outside the prefix was removed")
+			}
+		})
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", []byte("1"), 0)
+	cache.Set("b", []byte("2"), 0)
+	cache.Get("a")
+	cache.Set("c", []byte("3"), 0)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("the least recently used entry wasn't evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+
+	// Replacing an entry doesn't count towards the capacity
+	cache.Set("c", []byte("4"), 0)
+	if value, _ := cache.Get("c"); string(value) != "4" || cache.Stats().Entries != 2 || cache.Stats().Evictions != 1 {
+		t.Errorf("after replacing c: value %q, stats %+v", value, cache.Stats())
+	}
+}
+
+func TestDiskCacheSurvivesRestarts(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Set("key", []byte("value"), time.Hour)
+
+	second, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := second.Get("key"); !ok || string(value) != "value" {
+		t.Errorf("Get after a restart = %q, %v", value, ok)
+	}
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Income types assigned to money coming into a customer's accounts
+const (
+	IncomeTypePayroll    = "payroll"
+	IncomeTypeRefund     = "refund"
+	IncomeTypeInterest   = "interest"
+	IncomeTypeTransferIn = "transfer_in"
+	IncomeTypeOther      = "other"
+)
+
+// incomeRules classify inflows by description, checked in order
+var incomeRules = []struct {
+	incomeType string
+	pattern    *regexp.Regexp
+}{
+	{IncomeTypePayroll, regexp.MustCompile(`(?i)(payroll|direct dep|salary|paycheck|wages|pension|soc sec|ssa treas|\badp\b|gusto)`)},
+	{IncomeTypeInterest, regexp.MustCompile(`(?i)(interest|dividend)`)},
+	{IncomeTypeRefund, regexp.MustCompile(`(?i)(refund|return|reversal|credit adj|cash ?back|reimburse)`)},
+	{IncomeTypeTransferIn, regexp.MustCompile(`(?i)(zelle|venmo|cash app|paypal|transfer from|wire in|mobile deposit)`)},
+}
+
+// IsExpense reports whether a transaction is spending, i.e. an outflow that
+// isn't a transfer between the customer's own accounts
+func IsExpense(transaction models.Transaction) bool {
+	return transaction.Amount < 0 && !transaction.IsTransfer
+}
+
+// IsIncome reports whether a transaction is income, i.e. an inflow that
+// isn't a transfer between the customer's own accounts
+func IsIncome(transaction models.Transaction) bool {
+	return transaction.Amount > 0 && !transaction.IsTransfer
+}
+
+// ClassifyIncome sets IncomeType on every income transaction
+func ClassifyIncome(transactions []models.Transaction) []models.Transaction {
+	result := make([]models.Transaction, len(transactions))
+	copy(result, transactions)
+	for i := range result {
+		result[i].IncomeType = ""
+		if !IsIncome(result[i]) {
+			continue
+		}
+		result[i].IncomeType = IncomeTypeOther
+		for _, rule := range incomeRules {
+			if rule.pattern.MatchString(result[i].Description) {
+				result[i].IncomeType = rule.incomeType
+				break
+			}
+		}
+	}
+	return result
+}
+
+// AnalyzeCashflow totals income and expenses for each of the last months
+// calendar months up to and including now's month
+func AnalyzeCashflow(transactions []models.Transaction, months int, now time.Time) models.CashflowSummary {
+	if months < 1 {
+		months = 1
+	}
+	now = now.UTC()
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(months - 1), 0)
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+
+	summary := models.CashflowSummary{
+		Months:       make([]models.MonthlyCashflow, months),
+		IncomeByType: make(map[string]float64),
+	}
+	index := make(map[string]int, months)
+	for i := range summary.Months {
+		month := first.AddDate(0, i, 0).Format(ReportMonthLayout)
+		summary.Months[i].Month = month
+		index[month] = i
+	}
+
+	classified := ClassifyIncome(transactions)
+	for _, transaction := range classified {
+		date := transaction.TransactionDate.UTC()
+		if date.Before(first) || !date.Before(end) {
+			continue
+		}
+		month := &summary.Months[index[date.Format(ReportMonthLayout)]]
+		switch {
+		case IsIncome(transaction):
+			month.Income += transaction.Amount
+			summary.IncomeByType[transaction.IncomeType] += transaction.Amount
+		case IsExpense(transaction):
+			month.Expenses += math.Abs(transaction.Amount)
+		}
+	}
+
+	for i := range summary.Months {
+		month := &summary.Months[i]
+		month.Net = month.Income - month.Expenses
+		month.SavingsRate = savingsRate(month.Income, month.Net)
+		summary.TotalIncome += month.Income
+		summary.TotalExpenses += month.Expenses
+	}
+	summary.Net = summary.TotalIncome - summary.TotalExpenses
+	summary.SavingsRate = savingsRate(summary.TotalIncome, summary.Net)
+	summary.Payroll = DetectPayrollCadence(classified)
+
+	return summary
+}
+
+// savingsRate returns net as a percentage of income, or 0 with no income
+func savingsRate(income float64, net float64) float64 {
+	if income <= 0 {
+		return 0
+	}
+	return math.Round(net/income*1000) / 10
+}
+
+// DetectPayrollCadence works out how often the customer's main payer pays
+// them. It needs at least two payroll deposits from the same payer.
+func DetectPayrollCadence(transactions []models.Transaction) *models.PayrollCadence {
+	byPayer := make(map[string][]models.Transaction)
+	for _, transaction := range transactions {
+		if transaction.IncomeType != IncomeTypePayroll {
+			continue
+		}
+		payer := transaction.Merchant.Name
+		if payer == "" {
+			payer = transaction.Description
+		}
+		byPayer[payer] = append(byPayer[payer], transaction)
+	}
+
+	// The main payer is the one with the most deposits
+	var payer string
+	for name, deposits := range byPayer {
+		if len(deposits) > len(byPayer[payer]) || (len(deposits) == len(byPayer[payer]) && name < payer) {
+			payer = name
+		}
+	}
+	deposits := byPayer[payer]
+	if len(deposits) < 2 {
+		return nil
+	}
+
+	sort.Slice(deposits, func(i, j int) bool {
+		return deposits[i].TransactionDate.Before(deposits[j].TransactionDate)
+	})
+
+	var total float64
+	intervals := make([]float64, 0, len(deposits)-1)
+	for i, deposit := range deposits {
+		total += deposit.Amount
+		if i > 0 {
+			intervals = append(intervals, deposit.TransactionDate.Sub(deposits[i-1].TransactionDate).Hours()/24)
+		}
+	}
+
+	cadence := &models.PayrollCadence{
+		Payer:         payer,
+		AverageAmount: math.Round(total/float64(len(deposits))*100) / 100,
+		Occurrences:   len(deposits),
+		AccountID:     deposits[len(deposits)-1].AccountID,
+		LastDate:      deposits[len(deposits)-1].TransactionDate,
+		Frequency:     cadenceFrequency(intervals),
+	}
+	if next := nextOccurrence(cadence.Frequency, cadence.LastDate); !next.IsZero() {
+		cadence.NextExpectedDate = &next
+	}
+	return cadence
+}
+
+// cadenceFrequency names the schedule implied by the days between payments
+func cadenceFrequency(intervals []float64) string {
+	sorted := append([]float64(nil), intervals...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	allBiweekly := true
+	for _, interval := range intervals {
+		if math.Abs(interval-14) > 1 {
+			allBiweekly = false
+			break
+		}
+	}
+
+	switch {
+	case median >= 5 && median <= 9:
+		return "weekly"
+	case allBiweekly:
+		return "biweekly"
+	case median >= 12 && median <= 18:
+		return "semimonthly"
+	case median >= 27 && median <= 33:
+		return "monthly"
+	default:
+		return "irregular"
+	}
+}
+
+// nextOccurrence predicts the next payment date for a schedule
+func nextOccurrence(frequency string, last time.Time) time.Time {
+	switch frequency {
+	case "weekly":
+		return last.AddDate(0, 0, 7)
+	case "biweekly":
+		return last.AddDate(0, 0, 14)
+	case "semimonthly":
+		// Assume the common 1st and 15th schedule
+		if last.Day() < 15 {
+			return time.Date(last.Year(), last.Month(), 15, last.Hour(), last.Minute(), 0, 0, last.Location())
+		}
+		return time.Date(last.Year(), last.Month()+1, 1, last.Hour(), last.Minute(), 0, 0, last.Location())
+	case "monthly":
+		return last.AddDate(0, 1, 0)
+	default:
+		return time.Time{}
+	}
+}
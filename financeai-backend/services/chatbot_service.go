@@ -84,24 +84,24 @@ func (c *ChatbotService) GenerateResponse(userMessage string, customerData *mode
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to make request: %v", err)
+		return "", newUpstreamError("failed to make request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %v", err)
+		return "", newUpstreamError("failed to read response: %v", err)
 	}
 
 	// Parse response
 	var chatResp ChatResponse
 	if err := json.Unmarshal(body, &chatResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %v", err)
+		return "", newUpstreamError("failed to parse response: %v", err)
 	}
 
 	if len(chatResp.Choices) == 0 {
-		return "", fmt.Errorf("no response from OpenAI")
+		return "", newUpstreamError("no response from OpenAI")
 	}
 
 	return chatResp.Choices[0].Message.Content, nil
@@ -113,6 +113,7 @@ func (c *ChatbotService) createSystemPrompt(customerData *models.DashboardData,
 	foodSpent := 0.0
 	transportSpent := 0.0
 	entertainmentSpent := 0.0
+	money := NewMoneyFormatter(customerData.Currency, customerData.Locale)
 	
 	for _, category := range customerData.SpendingData.CategorySpending {
 		switch category.Category {
@@ -129,10 +130,11 @@ func (c *ChatbotService) createSystemPrompt(customerData *models.DashboardData,
 
 User Information:
 - Name: %s %s
-- Total Monthly Spending: $%.2f
-- Food & Dining: $%.2f
-- Transportation: $%.2f
-- Entertainment: $%.2f
+- Total Monthly Spending: %s
+- Food & Dining: %s
+- Transportation: %s
+- Entertainment: %s
+- Currency: %s (write amounts the way the %s locale does)
 
 Context: %s
 
@@ -151,10 +153,12 @@ Guidelines:
 Remember: This user is a college student, so focus on budget-friendly solutions and student-specific financial tips.`, 
 		customerData.Customer.FirstName, 
 		customerData.Customer.LastName,
-		totalSpent,
-		foodSpent,
-		transportSpent,
-		entertainmentSpent,
+		money.Format(totalSpent),
+		money.Format(foodSpent),
+		money.Format(transportSpent),
+		money.Format(entertainmentSpent),
+		money.Currency,
+		money.Locale,
 		context)
 
 	return systemPrompt
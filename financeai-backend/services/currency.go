@@ -0,0 +1,420 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"financeai-backend/models"
+)
+
+// DefaultLocale is the locale amounts are formatted for unless a customer picks another
+const DefaultLocale = "en-US"
+
+// moneyLocale describes how a locale writes amounts of money
+type moneyLocale struct {
+	decimal string
+	group   string
+	// symbolAfter writes the symbol after the amount, as in "12,50 €"
+	symbolAfter bool
+	// home is the locale's own currency, written with its bare symbol
+	home string
+}
+
+// moneyLocales lists the locales amounts can be formatted for
+var moneyLocales = map[string]moneyLocale{
+	"en-US": {decimal: ".", group: ",", home: "USD"},
+	"en-GB": {decimal: ".", group: ",", home: "GBP"},
+	"en-CA": {decimal: ".", group: ",", home: "CAD"},
+	"en-AU": {decimal: ".", group: ",", home: "AUD"},
+	"en-IN": {decimal: ".", group: ",", home: "INR"},
+	"fr-CA": {decimal: ",", group: " ", symbolAfter: true, home: "CAD"},
+	"fr-FR": {decimal: ",", group: " ", symbolAfter: true, home: "EUR"},
+	"de-DE": {decimal: ",", group: ".", symbolAfter: true, home: "EUR"},
+	"es-ES": {decimal: ",", group: ".", symbolAfter: true, home: "EUR"},
+	"it-IT": {decimal: ",", group: ".", symbolAfter: true, home: "EUR"},
+	"es-MX": {decimal: ".", group: ",", home: "MXN"},
+	"pt-BR": {decimal: ",", group: ".", home: "BRL"},
+	"de-CH": {decimal: ".", group: "'", home: "CHF"},
+	"ja-JP": {decimal: ".", group: ",", home: "JPY"},
+}
+
+// currencySymbols are the symbols currencies are written with outside their
+// home locale. Currencies without one are written with their code.
+var currencySymbols = map[string]string{
+	"USD": "US$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CAD": "CA$",
+	"AUD": "A$",
+	"MXN": "MX$",
+	"INR": "₹",
+	"BRL": "R$",
+	"CHF": "CHF",
+}
+
+// homeSymbols are the bare symbols currencies are written with in their
+// home locale
+var homeSymbols = map[string]string{
+	"USD": "$",
+	"CAD": "$",
+	"AUD": "$",
+	"MXN": "$",
+}
+
+// currencyDecimals lists currencies that don't use two decimal places
+var currencyDecimals = map[string]int{
+	"JPY": 0,
+}
+
+// normalizeLocale returns the supported locale matching tag, accepting
+// either case and underscores, or "" if it isn't supported
+func normalizeLocale(tag string) string {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	for locale := range moneyLocales {
+		if strings.EqualFold(locale, tag) {
+			return locale
+		}
+	}
+	return ""
+}
+
+// MoneyFormatter writes amounts in one currency the way a locale does
+type MoneyFormatter struct {
+	Currency string
+	Locale   string
+}
+
+// NewMoneyFormatter creates a formatter, falling back to the default
+// currency and locale for empty or unsupported ones
+func NewMoneyFormatter(currency string, locale string) MoneyFormatter {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if locale = normalizeLocale(locale); locale == "" {
+		locale = DefaultLocale
+	}
+	return MoneyFormatter{Currency: strings.ToUpper(currency), Locale: locale}
+}
+
+// Format writes an amount with the currency's usual decimal places, such as
+// "$1,234.50" or "1.234,50 €"
+func (f MoneyFormatter) Format(amount float64) string {
+	decimals, exists := currencyDecimals[f.Currency]
+	if !exists {
+		decimals = 2
+	}
+	return f.format(amount, decimals)
+}
+
+// Whole writes an amount rounded to a whole number, for round figures in
+// advice such as "save $25"
+func (f MoneyFormatter) Whole(amount float64) string {
+	return f.format(amount, 0)
+}
+
+// symbol returns what the currency is written with in the formatter's locale
+func (f MoneyFormatter) symbol(locale moneyLocale) string {
+	if f.Currency == locale.home {
+		if symbol, exists := homeSymbols[f.Currency]; exists {
+			return symbol
+		}
+	}
+	if symbol, exists := currencySymbols[f.Currency]; exists {
+		return symbol
+	}
+	return f.Currency
+}
+
+func (f MoneyFormatter) format(amount float64, decimals int) string {
+	locale, exists := moneyLocales[f.Locale]
+	if !exists {
+		locale = moneyLocales[DefaultLocale]
+	}
+
+	// Round halves away from zero, as roundCents does, rather than to even
+	scale := math.Pow10(decimals)
+	digits := strconv.FormatFloat(math.Round(math.Abs(amount)*scale)/scale, 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(locale.group)
+		}
+		grouped.WriteRune(digit)
+	}
+	number := grouped.String()
+	if fraction != "" {
+		number += locale.decimal + fraction
+	}
+
+	sign := ""
+	if amount < 0 && strings.Trim(digits, "0.") != "" {
+		sign = "-"
+	}
+	symbol := f.symbol(locale)
+	if locale.symbolAfter {
+		return sign + number + " " + symbol
+	}
+	// Letter symbols like CHF need a space before the number
+	if last := []rune(symbol); unicode.IsLetter(last[len(last)-1]) {
+		return sign + symbol + " " + number
+	}
+	return sign + symbol + number
+}
+
+// CurrencyService keeps each customer's reporting currency and locale and
+// converts their amounts into it
+type CurrencyService struct {
+	mu          sync.RWMutex
+	rates       *ExchangeRates
+	preferences map[string]models.CurrencyPreferences
+}
+
+// defaultCurrencyService is shared by the data providers so a customer's
+// reporting currency applies everywhere
+var defaultCurrencyService = NewCurrencyService(defaultExchangeRates)
+
+// DefaultCurrencyService returns the currency service shared by the API
+func DefaultCurrencyService() *CurrencyService {
+	return defaultCurrencyService
+}
+
+// NewCurrencyService creates a currency service converting with rates
+func NewCurrencyService(rates *ExchangeRates) *CurrencyService {
+	return &CurrencyService{
+		rates:       rates,
+		preferences: make(map[string]models.CurrencyPreferences),
+	}
+}
+
+// DefaultCurrencyPreferences returns the preferences a customer starts with
+func DefaultCurrencyPreferences(customerID string) models.CurrencyPreferences {
+	return models.CurrencyPreferences{
+		CustomerID:        customerID,
+		ReportingCurrency: DefaultCurrency,
+		Locale:            DefaultLocale,
+	}
+}
+
+// Rates returns the exchange rates the service converts with
+func (s *CurrencyService) Rates() *ExchangeRates {
+	return s.rates
+}
+
+// ValidateCurrency checks a currency code can be converted and returns it
+// upper-cased
+func (s *CurrencyService) ValidateCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !s.rates.Known(currency) {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return currency, nil
+}
+
+// Preferences returns a customer's currency preferences
+func (s *CurrencyService) Preferences(customerID string) models.CurrencyPreferences {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if preferences, exists := s.preferences[customerID]; exists {
+		return preferences
+	}
+	return DefaultCurrencyPreferences(customerID)
+}
+
+// SetPreferences validates and stores a customer's currency preferences.
+// Empty fields keep their current value.
+func (s *CurrencyService) SetPreferences(customerID string, preferences models.CurrencyPreferences) (*models.CurrencyPreferences, error) {
+	current := s.Preferences(customerID)
+	preferences.CustomerID = customerID
+	if preferences.ReportingCurrency == "" {
+		preferences.ReportingCurrency = current.ReportingCurrency
+	}
+	currency, err := s.ValidateCurrency(preferences.ReportingCurrency)
+	if err != nil {
+		return nil, err
+	}
+	preferences.ReportingCurrency = currency
+	if preferences.Locale == "" {
+		preferences.Locale = current.Locale
+	}
+	locale := normalizeLocale(preferences.Locale)
+	if locale == "" {
+		return nil, NewValidationError("locale", "unsupported locale: %s", preferences.Locale)
+	}
+	preferences.Locale = locale
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.preferences[customerID] = preferences
+	return &preferences, nil
+}
+
+// Formatter returns a formatter for amounts in a customer's reporting currency
+func (s *CurrencyService) Formatter(customerID string) MoneyFormatter {
+	preferences := s.Preferences(customerID)
+	return NewMoneyFormatter(preferences.ReportingCurrency, preferences.Locale)
+}
+
+// Convert converts an amount from one currency to a customer's reporting
+// currency at the rate on a day, returning the rate used. Amounts that can't
+// be converted are returned unchanged with a rate of 0.
+func (s *CurrencyService) Convert(customerID string, amount float64, from string, on time.Time) (float64, float64) {
+	if from == "" {
+		from = DefaultCurrency
+	}
+	rate, err := s.rates.Rate(from, s.Preferences(customerID).ReportingCurrency, on)
+	if err != nil {
+		return amount, 0
+	}
+	return roundCents(amount * rate), rate
+}
+
+// ConvertTransactions returns copies of transactions with their amounts and
+// splits in the customer's reporting currency at the rate on the day they
+// posted. Converted transactions keep their original amount and currency;
+// ones that can't be converted are left in their own currency.
+func (s *CurrencyService) ConvertTransactions(customerID string, transactions []models.Transaction) []models.Transaction {
+	reporting := s.Preferences(customerID).ReportingCurrency
+	converted := make([]models.Transaction, len(transactions))
+	for i, transaction := range transactions {
+		from := transaction.Currency
+		if from == "" {
+			from = DefaultCurrency
+		}
+		transaction.Currency = from
+		if from != reporting {
+			if rate, err := s.rates.Rate(from, reporting, transaction.TransactionDate); err == nil {
+				transaction.OriginalAmount = transaction.Amount
+				transaction.OriginalCurrency = from
+				transaction.ExchangeRate = rate
+				transaction.Currency = reporting
+				transaction.Amount = roundCents(transaction.Amount * rate)
+				if len(transaction.Splits) > 0 {
+					// The last split takes what rounding left over, so the
+					// splits still add up to the converted amount
+					splits := make([]models.TransactionSplit, len(transaction.Splits))
+					remaining := toCents(transaction.Amount)
+					for j, split := range transaction.Splits {
+						if j == len(splits)-1 {
+							split.Amount = float64(remaining) / 100
+						} else {
+							split.Amount = roundCents(split.Amount * rate)
+							remaining -= toCents(split.Amount)
+						}
+						splits[j] = split
+					}
+					transaction.Splits = splits
+				}
+			}
+		}
+		converted[i] = transaction
+	}
+	return converted
+}
+
+// ConvertAccounts returns copies of accounts with their balances in the
+// customer's reporting currency at today's rate
+func (s *CurrencyService) ConvertAccounts(customerID string, accounts []models.Account) []models.Account {
+	reporting := s.Preferences(customerID).ReportingCurrency
+	now := time.Now()
+	converted := make([]models.Account, len(accounts))
+	for i, account := range accounts {
+		from := account.Currency
+		if from == "" {
+			from = DefaultCurrency
+		}
+		account.Currency = from
+		if from != reporting {
+			if rate, err := s.rates.Rate(from, reporting, now); err == nil {
+				account.OriginalBalance = account.Balance
+				account.OriginalCurrency = from
+				account.Currency = reporting
+				account.Balance = int(math.Round(float64(account.Balance) * rate))
+			}
+		}
+		converted[i] = account
+	}
+	return converted
+}
+
+// ConvertManualAccounts returns copies of manual accounts with their balances
+// in the customer's reporting currency at today's rate
+func (s *CurrencyService) ConvertManualAccounts(customerID string, accounts []models.ManualAccount) []models.ManualAccount {
+	reporting := s.Preferences(customerID).ReportingCurrency
+	now := time.Now()
+	converted := make([]models.ManualAccount, len(accounts))
+	for i, account := range accounts {
+		if account.Currency != reporting {
+			var rate float64
+			if account.Balance, rate = s.Convert(customerID, account.Balance, account.Currency, now); rate != 0 {
+				account.Currency = reporting
+			}
+		}
+		converted[i] = account
+	}
+	return converted
+}
+
+// ConvertSpendingData returns spending data given in a currency converted to
+// the customer's reporting currency at today's rate
+func (s *CurrencyService) ConvertSpendingData(customerID string, data models.SpendingData, from string) models.SpendingData {
+	if from == s.Preferences(customerID).ReportingCurrency {
+		return data
+	}
+	now := time.Now()
+	convert := func(amount float64) float64 {
+		converted, _ := s.Convert(customerID, amount, from, now)
+		return converted
+	}
+	converted := data
+	converted.MonthlySpending = make([]models.MonthlySpending, len(data.MonthlySpending))
+	for i, month := range data.MonthlySpending {
+		month.Amount = convert(month.Amount)
+		converted.MonthlySpending[i] = month
+	}
+	converted.DailySpending = make([]models.DailySpending, len(data.DailySpending))
+	for i, day := range data.DailySpending {
+		day.Amount = convert(day.Amount)
+		converted.DailySpending[i] = day
+	}
+	converted.CategorySpending = make([]models.CategorySpending, len(data.CategorySpending))
+	for i, category := range data.CategorySpending {
+		category.Amount = convert(category.Amount)
+		converted.CategorySpending[i] = category
+	}
+	converted.RecentTransactions = make([]models.RecentTransaction, len(data.RecentTransactions))
+	for i, transaction := range data.RecentTransactions {
+		transaction.Amount, _ = s.Convert(customerID, transaction.Amount, from, transaction.Date)
+		converted.RecentTransactions[i] = transaction
+	}
+	converted.TotalMonthlySpend = convert(data.TotalMonthlySpend)
+	return converted
+}
+
+// stampCurrencies fills in the currency of accounts that don't say, and of
+// transactions from the account they're on
+func stampCurrencies(accounts []models.Account, transactions []models.Transaction) {
+	currencies := make(map[string]string, len(accounts))
+	for i := range accounts {
+		if accounts[i].Currency == "" {
+			accounts[i].Currency = DefaultCurrency
+		}
+		currencies[accounts[i].ID] = accounts[i].Currency
+	}
+	for i := range transactions {
+		if transactions[i].Currency != "" {
+			continue
+		}
+		if currency, exists := currencies[transactions[i].AccountID]; exists {
+			transactions[i].Currency = currency
+		} else {
+			transactions[i].Currency = DefaultCurrency
+		}
+	}
+}
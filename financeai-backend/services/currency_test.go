@@ -0,0 +1,138 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// testExchangeRates is a table with a gap between days and a currency that
+// only has one day
+func testExchangeRates(t *testing.T) *ExchangeRates {
+	t.Helper()
+	rates := NewExchangeRates(DefaultCurrency)
+	err := rates.Load(strings.NewReader(`date,currency,rate
+# EUR moves, GBP is only known on the first day
+2026-03-01,EUR,0.90
+2026-03-01,GBP,0.80
+2026-03-03,EUR,0.92
+2026-03-01,JPY,150
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rates
+}
+
+func TestExchangeRatesRate(t *testing.T) {
+	rates := testExchangeRates(t)
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 15, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name     string
+		from, to string
+		on       time.Time
+		want     float64
+	}{
+		{"same currency", "EUR", "eur", day(2), 1},
+		{"that day", "USD", "EUR", day(3), 0.92},
+		{"latest day before", "USD", "EUR", day(2), 0.90},
+		{"after the table ends", "USD", "EUR", day(20), 0.92},
+		{"before the table starts", "USD", "EUR", day(1).AddDate(0, -1, 0), 0.90},
+		{"back to the base", "EUR", "USD", day(3), 1 / 0.92},
+		{"between two others", "EUR", "GBP", day(3), 0.80 / 0.92},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rate, err := rates.Rate(test.from, test.to, test.on)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(rate-test.want) > 1e-9 {
+				t.Errorf("rate = %v, want %v", rate, test.want)
+			}
+		})
+	}
+
+	if _, err := rates.Rate("USD", "XYZ", day(3)); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("an unknown currency returned %v, want ErrUnknownCurrency", err)
+	}
+}
+
+func TestMoneyFormatterFormat(t *testing.T) {
+	tests := []struct {
+		currency, locale string
+		amount           float64
+		want             string
+	}{
+		{"USD", "en-US", 1234.5, "$1,234.50"},
+		{"USD", "en-US", -0.5, "-$0.50"},
+		{"USD", "en-US", -0.001, "$0.00"},
+		{"EUR", "de-DE", 1234.5, "1.234,50 €"},
+		{"EUR", "fr-FR", 1234567.891, "1 234 567,89 €"},
+		{"JPY", "ja-JP", 1234.5, "¥1,235"},
+		{"CHF", "de-CH", 99.9, "CHF 99.90"},
+		{"USD", "en-GB", 10, "US$10.00"},
+		{"SEK", "en-US", 10, "SEK 10.00"},
+		{"", "xx-XX", 3, "$3.00"},
+	}
+	for _, test := range tests {
+		if got := NewMoneyFormatter(test.currency, test.locale).Format(test.amount); got != test.want {
+			t.Errorf("%s in %s: Format(%v) = %q, want %q", test.currency, test.locale, test.amount, got, test.want)
+		}
+	}
+}
+
+func TestConvertTransactionsKeepsSplitsAddingUp(t *testing.T) {
+	rates := NewExchangeRates(DefaultCurrency)
+	if err := rates.Load(strings.NewReader("2026-03-01,EUR,0.9137\n")); err != nil {
+		t.Fatal(err)
+	}
+	service := NewCurrencyService(rates)
+	if _, err := service.SetPreferences("c1", models.CurrencyPreferences{ReportingCurrency: "EUR"}); err != nil {
+		t.Fatal(err)
+	}
+
+	transaction := models.Transaction{
+		ID:              "t1",
+		Amount:          -100,
+		TransactionDate: time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC),
+		Splits: []models.TransactionSplit{
+			{Category: "Groceries", Amount: -33.33},
+			{Category: "Household", Amount: -33.33},
+			{Category: "Gifts", Amount: -33.34},
+		},
+	}
+	converted := service.ConvertTransactions("c1", []models.Transaction{transaction})[0]
+	if converted.Currency != "EUR" || converted.Amount != -91.37 || converted.OriginalAmount != -100 {
+		t.Fatalf("converted to %v %s from %v", converted.Amount, converted.Currency, converted.OriginalAmount)
+	}
+	var total int64
+	for _, split := range converted.Splits {
+		total += toCents(split.Amount)
+	}
+	if total != toCents(converted.Amount) {
+		t.Errorf("splits %+v add up to %d cents, want %d", converted.Splits, total, toCents(converted.Amount))
+	}
+	if transaction.Splits[0].Amount != -33.33 {
+		t.Error("converting changed the original splits")
+	}
+}
+
+func TestTransactionCreatedEventFormatsAmount(t *testing.T) {
+	transaction := models.Transaction{ID: "t1", Amount: -1234.5, Description: "Supermarkt", TransactionDate: time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)}
+	event := TransactionCreatedEvent("c1", transaction, NewMoneyFormatter("EUR", "de-DE"))
+	if !strings.HasPrefix(event.Message, "1.234,50 € at Supermarkt") {
+		t.Errorf("message = %q, want the amount in euros", event.Message)
+	}
+
+	// A transaction in its own currency is written in that currency
+	transaction.Currency = "JPY"
+	event = TransactionCreatedEvent("c1", transaction, NewMoneyFormatter("EUR", "de-DE"))
+	if !strings.HasPrefix(event.Message, "1.235 ¥ at") {
+		t.Errorf("message = %q, want the amount in yen", event.Message)
+	}
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Payoff strategies
+const (
+	// StrategyAvalanche pays extra toward the highest APR first
+	StrategyAvalanche = "avalanche"
+	// StrategySnowball pays extra toward the smallest balance first
+	StrategySnowball = "snowball"
+	// StrategyCustom pays extra in an order the customer chooses
+	StrategyCustom = "custom"
+
+	// MaxPayoffMonths caps how long a payoff simulation can run
+	MaxPayoffMonths = 600
+)
+
+// DebtTermsInput describes the terms of a liability account
+type DebtTermsInput struct {
+	APR            float64 `json:"apr"`
+	MinimumPayment float64 `json:"minimumPayment"`
+	DueDay         int     `json:"dueDay"`
+}
+
+// DebtStore holds the APR, minimum payment and due day customers record for
+// their liability accounts, keyed by customer and account ID
+type DebtStore struct {
+	mu    sync.RWMutex
+	terms map[string]map[string]DebtTermsInput
+}
+
+// defaultDebtStore is shared by the data providers so terms recorded through
+// one endpoint are used by every plan
+var defaultDebtStore = NewDebtStore()
+
+// NewDebtStore creates an empty debt store
+func NewDebtStore() *DebtStore {
+	return &DebtStore{
+		terms: make(map[string]map[string]DebtTermsInput),
+	}
+}
+
+// ValidateDebtTerms checks that an APR, minimum payment and due day are usable
+func ValidateDebtTerms(terms DebtTermsInput) error {
+	if terms.APR < 0 || terms.APR > 100 {
+		return NewValidationError("apr", "apr must be between 0 and 100")
+	}
+	if terms.MinimumPayment <= 0 {
+		return NewValidationError("minimumPayment", "minimumPayment must be greater than 0")
+	}
+	if terms.DueDay < 1 || terms.DueDay > 31 {
+		return NewValidationError("dueDay", "dueDay must be between 1 and 31")
+	}
+	return nil
+}
+
+// SetTerms records the terms of a liability account
+func (s *DebtStore) SetTerms(customerID string, accountID string, terms DebtTermsInput) error {
+	if err := ValidateDebtTerms(terms); err != nil {
+		return err
+	}
+	terms.MinimumPayment = roundCents(terms.MinimumPayment)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.terms[customerID]; !exists {
+		s.terms[customerID] = make(map[string]DebtTermsInput)
+	}
+	s.terms[customerID][accountID] = terms
+	return nil
+}
+
+// Debts lists a customer's liabilities, from bank accounts and manual
+// accounts, with any terms recorded for them
+func (s *DebtStore) Debts(customerID string, accounts []models.Account, manual []models.ManualAccount) []models.Debt {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	debts := []models.Debt{}
+	add := func(debt models.Debt) {
+		if terms, exists := s.terms[customerID][debt.AccountID]; exists {
+			debt.APR = terms.APR
+			debt.MinimumPayment = terms.MinimumPayment
+			debt.DueDay = terms.DueDay
+			debt.Configured = true
+		}
+		debts = append(debts, debt)
+	}
+
+	for _, account := range accounts {
+		if ClassifyAccount(account.Type) != AccountClassLiability {
+			continue
+		}
+		add(models.Debt{
+			AccountID: account.ID,
+			Name:      account.Nickname,
+			Type:      account.Type,
+			Balance:   amountOwed(float64(account.Balance), false),
+		})
+	}
+	for _, account := range manual {
+		if !account.IsLiability {
+			continue
+		}
+		add(models.Debt{
+			AccountID: account.ID,
+			Name:      account.Name,
+			Type:      account.Type,
+			Balance:   account.Balance,
+			Manual:    true,
+		})
+	}
+	return debts
+}
+
+// payoffOrder sorts debts into the order extra payments go to. Custom orders
+// list account IDs; debts left out follow in avalanche order.
+func payoffOrder(debts []models.Debt, strategy string, custom []string) ([]models.Debt, error) {
+	ordered := append([]models.Debt(nil), debts...)
+	avalanche := func(i, j int) bool {
+		if ordered[i].APR != ordered[j].APR {
+			return ordered[i].APR > ordered[j].APR
+		}
+		return ordered[i].Balance < ordered[j].Balance
+	}
+
+	switch strategy {
+	case StrategyAvalanche:
+		sort.SliceStable(ordered, avalanche)
+	case StrategySnowball:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			if ordered[i].Balance != ordered[j].Balance {
+				return ordered[i].Balance < ordered[j].Balance
+			}
+			return ordered[i].APR > ordered[j].APR
+		})
+	case StrategyCustom:
+		if len(custom) == 0 {
+			return nil, NewValidationError("order", "custom strategy needs an order of account IDs")
+		}
+		rank := make(map[string]int, len(custom))
+		for i, accountID := range custom {
+			if _, duplicate := rank[accountID]; duplicate {
+				return nil, NewValidationError("order", "account %s appears twice in order", accountID)
+			}
+			rank[accountID] = i
+		}
+		known := make(map[string]bool, len(debts))
+		for _, debt := range debts {
+			known[debt.AccountID] = true
+		}
+		for _, accountID := range custom {
+			if !known[accountID] {
+				return nil, NewValidationError("order", "unknown debt in order: %s", accountID)
+			}
+		}
+		sort.SliceStable(ordered, avalanche)
+		sort.SliceStable(ordered, func(i, j int) bool {
+			ri, iRanked := rank[ordered[i].AccountID]
+			rj, jRanked := rank[ordered[j].AccountID]
+			if iRanked && jRanked {
+				return ri < rj
+			}
+			return iRanked && !jRanked
+		})
+	default:
+		return nil, NewValidationError("strategy", "unknown strategy: %s", strategy)
+	}
+	return ordered, nil
+}
+
+// dueDate returns a debt's due date in the given month, moved to the last day
+// of short months
+func dueDate(month time.Time, dueDay int) time.Time {
+	lastDay := time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if dueDay > lastDay {
+		dueDay = lastDay
+	}
+	return time.Date(month.Year(), month.Month(), dueDay, 0, 0, 0, 0, time.UTC)
+}
+
+// MinimumPaymentTotal sums the minimum payments across debts with a balance
+func MinimumPaymentTotal(debts []models.Debt) float64 {
+	var total float64
+	for _, debt := range debts {
+		if debt.Balance > 0 {
+			total += debt.MinimumPayment
+		}
+	}
+	return roundCents(total)
+}
+
+// SimulatePayoff pays down debts month by month from the month after now.
+// Each month interest accrues, every debt gets its minimum payment, and the
+// rest of monthlyBudget goes to debts in strategy order. Money freed up by a
+// paid-off debt rolls to the next one. A zero budget means minimums only.
+// Paid-off debts are left out, and so are debts whose terms aren't set,
+// which the plan lists as skipped. Amounts in errors are written with money.
+func SimulatePayoff(debts []models.Debt, strategy string, monthlyBudget float64, custom []string, money MoneyFormatter, now time.Time) (*models.PayoffPlan, error) {
+	var active []models.Debt
+	var skipped []models.SkippedDebt
+	left := make(map[string]bool)
+	for _, debt := range debts {
+		switch {
+		case debt.Balance <= 0:
+			left[debt.AccountID] = true
+		case !debt.Configured:
+			left[debt.AccountID] = true
+			skipped = append(skipped, models.SkippedDebt{
+				AccountID: debt.AccountID,
+				Name:      debt.Name,
+				Reason:    "set the APR, minimum payment and due day to include it",
+			})
+		default:
+			active = append(active, debt)
+		}
+	}
+	if len(active) == 0 {
+		if len(skipped) > 0 {
+			return nil, NewValidationError("", "set the APR, minimum payment and due day for %s first", skipped[0].Name)
+		}
+		return nil, NewValidationError("", "no debts with a balance to pay off")
+	}
+
+	// A custom order can still name the debts left out
+	var order []string
+	for _, accountID := range custom {
+		if !left[accountID] {
+			order = append(order, accountID)
+		}
+	}
+
+	ordered, err := payoffOrder(active, strategy, order)
+	if err != nil {
+		return nil, err
+	}
+
+	minimums := MinimumPaymentTotal(ordered)
+	if monthlyBudget == 0 {
+		monthlyBudget = minimums
+	}
+	if toCents(monthlyBudget) < toCents(minimums) {
+		return nil, NewValidationError("monthlyBudget", "monthly budget of %s doesn't cover the %s in minimum payments", money.Format(monthlyBudget), money.Format(minimums))
+	}
+
+	plan := &models.PayoffPlan{
+		Strategy:      strategy,
+		MonthlyBudget: roundCents(monthlyBudget),
+		Order:         make([]string, len(ordered)),
+		Debts:         make([]models.DebtPayoff, len(ordered)),
+		Schedule:      []models.PayoffMonth{},
+		Skipped:       skipped,
+	}
+	balances := make([]float64, len(ordered))
+	for i, debt := range ordered {
+		plan.Order[i] = debt.AccountID
+		plan.Debts[i] = models.DebtPayoff{AccountID: debt.AccountID, Name: debt.Name}
+		balances[i] = roundCents(debt.Balance)
+	}
+
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	remaining := len(ordered)
+	for month := 1; remaining > 0; month++ {
+		if month > MaxPayoffMonths {
+			return nil, NewValidationError("monthlyBudget", "a monthly budget of %s doesn't pay off these debts within %d years", money.Format(monthlyBudget), MaxPayoffMonths/12)
+		}
+		monthStart := start.AddDate(0, month, 0)
+		scheduled := models.PayoffMonth{Month: monthStart.Format(ReportMonthLayout)}
+		payments := make([]models.DebtPayment, len(ordered))
+
+		// Interest accrues, then minimums are paid
+		available := plan.MonthlyBudget
+		for i, debt := range ordered {
+			if balances[i] <= 0 {
+				continue
+			}
+			interest := roundCents(balances[i] * debt.APR / 100 / 12)
+			balances[i] = roundCents(balances[i] + interest)
+			payment := math.Min(debt.MinimumPayment, balances[i])
+			balances[i] = roundCents(balances[i] - payment)
+			available = roundCents(available - payment)
+			payments[i] = models.DebtPayment{
+				AccountID: debt.AccountID,
+				DueDate:   dueDate(monthStart, debt.DueDay),
+				Payment:   payment,
+				Interest:  interest,
+			}
+			plan.Debts[i].InterestPaid = roundCents(plan.Debts[i].InterestPaid + interest)
+		}
+
+		// Whatever is left goes to debts in strategy order
+		for i := range ordered {
+			if available <= 0 {
+				break
+			}
+			if balances[i] <= 0 {
+				continue
+			}
+			extra := math.Min(available, balances[i])
+			balances[i] = roundCents(balances[i] - extra)
+			available = roundCents(available - extra)
+			payments[i].Payment = roundCents(payments[i].Payment + extra)
+		}
+
+		for i := range ordered {
+			if payments[i].AccountID == "" {
+				continue
+			}
+			payments[i].Principal = roundCents(payments[i].Payment - payments[i].Interest)
+			payments[i].Balance = balances[i]
+			scheduled.Payments = append(scheduled.Payments, payments[i])
+			scheduled.TotalPayment = roundCents(scheduled.TotalPayment + payments[i].Payment)
+			scheduled.TotalInterest = roundCents(scheduled.TotalInterest + payments[i].Interest)
+			scheduled.RemainingBalance = roundCents(scheduled.RemainingBalance + balances[i])
+
+			if balances[i] <= 0 {
+				plan.Debts[i].PayoffDate = payments[i].DueDate
+				plan.Debts[i].Months = month
+				remaining--
+				if payments[i].DueDate.After(plan.PayoffDate) {
+					plan.PayoffDate = payments[i].DueDate
+				}
+			}
+		}
+
+		plan.Schedule = append(plan.Schedule, scheduled)
+		plan.TotalPaid = roundCents(plan.TotalPaid + scheduled.TotalPayment)
+		plan.TotalInterest = roundCents(plan.TotalInterest + scheduled.TotalInterest)
+		plan.Months = month
+	}
+
+	return plan, nil
+}
+
+// ComparePayoffStrategies simulates avalanche and snowball, plus custom when
+// an order is given, with the same budget
+func ComparePayoffStrategies(debts []models.Debt, monthlyBudget float64, custom []string, money MoneyFormatter, now time.Time) ([]models.PayoffPlan, error) {
+	strategies := []string{StrategyAvalanche, StrategySnowball}
+	if len(custom) > 0 {
+		strategies = append(strategies, StrategyCustom)
+	}
+
+	plans := make([]models.PayoffPlan, 0, len(strategies))
+	for _, strategy := range strategies {
+		plan, err := SimulatePayoff(debts, strategy, monthlyBudget, custom, money, now)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, *plan)
+	}
+	return plans, nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// payoffDebts are a high-APR card with a large balance and a low-APR loan
+// with a small one, so avalanche and snowball order them differently
+func payoffDebts() []models.Debt {
+	return []models.Debt{
+		{AccountID: "card", Name: "Card", Balance: 1000, APR: 24, MinimumPayment: 50, DueDay: 15, Configured: true},
+		{AccountID: "loan", Name: "Loan", Balance: 300, APR: 6, MinimumPayment: 25, DueDay: 1, Configured: true},
+	}
+}
+
+var payoffStart = time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+func TestSimulatePayoffWithoutInterest(t *testing.T) {
+	debts := []models.Debt{{AccountID: "loan", Name: "Loan", Balance: 1000, MinimumPayment: 100, DueDay: 5, Configured: true}}
+	plan, err := SimulatePayoff(debts, StrategyAvalanche, 0, nil, NewMoneyFormatter("", ""), payoffStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDate := time.Date(2027, time.January, 5, 0, 0, 0, 0, time.UTC)
+	if plan.Months != 10 || plan.TotalInterest != 0 || plan.TotalPaid != 1000 || !plan.PayoffDate.Equal(wantDate) {
+		t.Errorf("plan took %d months to %s, paying %v with %v interest; want 10 months to %s, 1000 with none",
+			plan.Months, plan.PayoffDate.Format(BillDateLayout), plan.TotalPaid, plan.TotalInterest, wantDate.Format(BillDateLayout))
+	}
+	if first := plan.Schedule[0]; first.Month != "2026-04" || first.RemainingBalance != 900 {
+		t.Errorf("first month is %s with %v left, want 2026-04 with 900", first.Month, first.RemainingBalance)
+	}
+}
+
+func TestSimulatePayoffStrategies(t *testing.T) {
+	plans, err := ComparePayoffStrategies(payoffDebts(), 175, nil, NewMoneyFormatter("", ""), payoffStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	avalanche, snowball := plans[0], plans[1]
+	if !reflect.DeepEqual(avalanche.Order, []string{"card", "loan"}) || !reflect.DeepEqual(snowball.Order, []string{"loan", "card"}) {
+		t.Errorf("avalanche order %v and snowball order %v", avalanche.Order, snowball.Order)
+	}
+	if avalanche.TotalInterest >= snowball.TotalInterest {
+		t.Errorf("avalanche paid %v interest, snowball %v; want avalanche to pay less", avalanche.TotalInterest, snowball.TotalInterest)
+	}
+	for _, plan := range plans {
+		// Everything paid is the balances plus the interest they accrued
+		if toCents(plan.TotalPaid) != toCents(1300+plan.TotalInterest) {
+			t.Errorf("%s paid %v with %v interest on 1300 of debt", plan.Strategy, plan.TotalPaid, plan.TotalInterest)
+		}
+		if last := plan.Schedule[len(plan.Schedule)-1]; last.RemainingBalance != 0 {
+			t.Errorf("%s ends with %v left", plan.Strategy, last.RemainingBalance)
+		}
+		for _, month := range plan.Schedule {
+			if month.TotalPayment > 175 {
+				t.Errorf("%s pays %v in %s, over the budget", plan.Strategy, month.TotalPayment, month.Month)
+			}
+		}
+	}
+
+	// The loan is cleared first under snowball, by its due date
+	if snowball.Debts[0].AccountID != "loan" || snowball.Debts[0].Months >= snowball.Debts[1].Months {
+		t.Errorf("snowball paid off %+v", snowball.Debts)
+	}
+}
+
+func TestSimulatePayoffCustomOrder(t *testing.T) {
+	plan, err := SimulatePayoff(payoffDebts(), StrategyCustom, 175, []string{"loan"}, NewMoneyFormatter("", ""), payoffStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(plan.Order, []string{"loan", "card"}) {
+		t.Errorf("order = %v, want the named debt first", plan.Order)
+	}
+	if _, err := SimulatePayoff(payoffDebts(), StrategyCustom, 175, []string{"mortgage"}, NewMoneyFormatter("", ""), payoffStart); !errors.Is(err, ErrValidation) {
+		t.Errorf("an unknown debt in the order returned %v, want a validation error", err)
+	}
+}
+
+func TestSimulatePayoffSkipsDebts(t *testing.T) {
+	debts := append(payoffDebts(),
+		models.Debt{AccountID: "old-card", Name: "Paid-off Card", Balance: 0},
+		models.Debt{AccountID: "store-card", Name: "Store Card", Balance: 400},
+	)
+	plan, err := SimulatePayoff(debts, StrategyCustom, 175, []string{"store-card", "loan"}, NewMoneyFormatter("", ""), payoffStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(plan.Order, []string{"loan", "card"}) {
+		t.Errorf("order = %v, want only the configured debts", plan.Order)
+	}
+	if len(plan.Skipped) != 1 || plan.Skipped[0].AccountID != "store-card" {
+		t.Errorf("skipped = %+v, want the store card with a balance and no terms", plan.Skipped)
+	}
+
+	// Nothing to simulate is still an error
+	_, err = SimulatePayoff(debts[2:], StrategyAvalanche, 0, nil, NewMoneyFormatter("", ""), payoffStart)
+	if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), "Store Card") {
+		t.Errorf("only unconfigured debts returned %v, want a validation error naming the store card", err)
+	}
+	_, err = SimulatePayoff(debts[2:3], StrategyAvalanche, 0, nil, NewMoneyFormatter("", ""), payoffStart)
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("only paid-off debts returned %v, want a validation error", err)
+	}
+}
+
+func TestSimulatePayoffBudgetErrors(t *testing.T) {
+	money := NewMoneyFormatter("EUR", "de-DE")
+	_, err := SimulatePayoff(payoffDebts(), StrategyAvalanche, 60, nil, money, payoffStart)
+	if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), "60,00 € doesn't cover the 75,00 €") {
+		t.Errorf("a budget under the minimums returned %v", err)
+	}
+
+	// Minimums that never outpace the interest can't pay the debt off
+	debts := []models.Debt{{AccountID: "card", Name: "Card", Balance: 10000, APR: 30, MinimumPayment: 200, DueDay: 1, Configured: true}}
+	_, err = SimulatePayoff(debts, StrategyAvalanche, 0, nil, money, payoffStart)
+	if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), "200,00 €") {
+		t.Errorf("a budget that never pays off returned %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The kinds of error services return. Every error a handler might report
+// wraps one of them, so the API can answer with the right status without
+// matching on messages.
+var (
+	// ErrNotFound means a customer or record doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized means credentials were missing or wrong
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrValidation means the request asked for something invalid
+	ErrValidation = errors.New("invalid request")
+	// ErrConflict means the request clashes with the current state, such as
+	// work already in progress
+	ErrConflict = errors.New("conflict")
+	// ErrUpstream means a provider such as Nessie, OpenAI or a mail server
+	// failed
+	ErrUpstream = errors.New("upstream failure")
+)
+
+// kindError is an error of one kind with its own message, optionally
+// wrapping the error it was made from
+type kindError struct {
+	kind    error
+	message string
+	wrapped error
+}
+
+func (e *kindError) Error() string {
+	return e.message
+}
+
+func (e *kindError) Unwrap() []error {
+	if e.wrapped == nil {
+		return []error{e.kind}
+	}
+	return []error{e.kind, e.wrapped}
+}
+
+// newKindError creates an error of a kind, formatting its message like
+// fmt.Errorf. An error wrapped with %w can still be found with errors.Is
+// and errors.As.
+func newKindError(kind error, format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	return &kindError{kind: kind, message: err.Error(), wrapped: errors.Unwrap(err)}
+}
+
+// NewNotFoundError creates an ErrNotFound with a message
+func NewNotFoundError(format string, args ...interface{}) error {
+	return newKindError(ErrNotFound, format, args...)
+}
+
+func newUnauthorizedError(format string, args ...interface{}) error {
+	return newKindError(ErrUnauthorized, format, args...)
+}
+
+func newConflictError(format string, args ...interface{}) error {
+	return newKindError(ErrConflict, format, args...)
+}
+
+func newUpstreamError(format string, args ...interface{}) error {
+	return newKindError(ErrUpstream, format, args...)
+}
+
+// FieldError is a problem with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is an ErrValidation listing the fields at fault
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return ErrValidation.Error()
+	}
+	return e.Fields[0].Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// NewValidationError creates an ErrValidation for one field. The field may
+// be empty when the problem isn't with a single field.
+func NewValidationError(field string, format string, args ...interface{}) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: fmt.Sprintf(format, args...)}}}
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Event types
+const (
+	EventBudgetThreshold    = "budget.threshold"
+	EventLargeTransaction   = "transaction.large"
+	EventLowBalance         = "balance.low"
+	EventBillUpcoming       = "bill.upcoming"
+	EventGoalMilestone      = "goal.milestone"
+	EventNotificationTest   = "notification.test"
+	EventTransactionCreated = "transaction.created"
+	EventBudgetExceeded     = "budget.exceeded"
+	EventInsightGenerated   = "insight.generated"
+	EventWebhookTest        = "webhook.test"
+	EventWeeklyDigest       = "digest.weekly"
+	eventSubscribeWildcard  = "*"
+)
+
+// EventHandler reacts to a published event
+type EventHandler func(event models.Event)
+
+// EventBus fans events out to the handlers subscribed to their type
+type EventBus struct {
+	mu        sync.RWMutex
+	handlers  map[string][]EventHandler
+	published map[string]bool
+}
+
+// defaultEventBus connects event producers to notifications and other subscribers
+var defaultEventBus = NewEventBus()
+
+// NewEventBus creates an event bus with no subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{
+		handlers:  make(map[string][]EventHandler),
+		published: make(map[string]bool),
+	}
+}
+
+// DefaultEventBus returns the event bus shared by the services
+func DefaultEventBus() *EventBus {
+	return defaultEventBus
+}
+
+// Subscribe registers handler for events of eventType, or every event if
+// eventType is "*"
+func (b *EventBus) Subscribe(eventType string, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish delivers an event to its subscribers in the order they subscribed,
+// filling in the ID and time if they're missing
+func (b *EventBus) Publish(event models.Event) models.Event {
+	if event.ID == "" {
+		event.ID = newEventID()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := append(append([]EventHandler(nil), b.handlers[event.Type]...), b.handlers[eventSubscribeWildcard]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+	return event
+}
+
+// PublishOnce publishes an event unless one with the same customer and key
+// was published before. It reports whether the event was published.
+func (b *EventBus) PublishOnce(event models.Event) (models.Event, bool) {
+	if event.Key != "" {
+		key := event.CustomerID + "|" + event.Key
+		b.mu.Lock()
+		if b.published[key] {
+			b.mu.Unlock()
+			return event, false
+		}
+		b.published[key] = true
+		b.mu.Unlock()
+	}
+	return b.Publish(event), true
+}
+
+// newEventID returns a random event ID
+func newEventID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("evt_%d", time.Now().UnixNano())
+	}
+	return "evt_" + hex.EncodeToString(id)
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+const (
+	// DefaultCurrency is the currency amounts are in when nothing says otherwise
+	DefaultCurrency = "USD"
+	// DefaultExchangeRatesFile is where the exchange rate table is read from
+	DefaultExchangeRatesFile = "rates/exchange_rates.csv"
+)
+
+// ErrUnknownCurrency is returned for currencies the rate table doesn't have
+var ErrUnknownCurrency = newKindError(ErrValidation, "unknown currency")
+
+// ExchangeRates is a table of daily exchange rates against a base currency,
+// loaded from a local file so conversions don't depend on a rate service
+type ExchangeRates struct {
+	mu    sync.RWMutex
+	base  string
+	dates []time.Time
+	// rates holds, for each day, units of each currency per unit of base
+	rates map[time.Time]map[string]float64
+}
+
+// defaultExchangeRates is shared by everything that converts amounts. It
+// only knows the base currency until ConfigureExchangeRates loads a table.
+var defaultExchangeRates = NewExchangeRates(DefaultCurrency)
+
+// DefaultExchangeRates returns the exchange rate table shared by the API
+func DefaultExchangeRates() *ExchangeRates {
+	return defaultExchangeRates
+}
+
+// ConfigureExchangeRates loads the shared exchange rate table from path
+func ConfigureExchangeRates(path string) error {
+	return defaultExchangeRates.LoadFile(path)
+}
+
+// NewExchangeRates creates a rate table that only knows base
+func NewExchangeRates(base string) *ExchangeRates {
+	return &ExchangeRates{
+		base:  base,
+		rates: make(map[time.Time]map[string]float64),
+	}
+}
+
+// LoadFile replaces the table with the rates in a CSV file of
+// date,currency,rate rows, where rate is units of currency per unit of the
+// base currency. Lines starting with # are comments.
+func (r *ExchangeRates) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open exchange rates: %v", err)
+	}
+	defer file.Close()
+	return r.Load(file)
+}
+
+// Load replaces the table with the rates read from CSV, as described on LoadFile
+func (r *ExchangeRates) Load(reader io.Reader) error {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = 3
+	csvReader.TrimLeadingSpace = true
+
+	rates := make(map[time.Time]map[string]float64)
+	for first := true; ; first = false {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read exchange rates: %v", err)
+		}
+		if first && strings.EqualFold(record[0], "date") {
+			continue
+		}
+		line, _ := csvReader.FieldPos(0)
+		date, err := time.Parse(BillDateLayout, record[0])
+		if err != nil {
+			return fmt.Errorf("exchange rates line %d: invalid date %q", line, record[0])
+		}
+		currency := strings.ToUpper(record[1])
+		if len(currency) != 3 {
+			return fmt.Errorf("exchange rates line %d: invalid currency %q", line, record[1])
+		}
+		rate, err := strconv.ParseFloat(record[2], 64)
+		if err != nil || rate <= 0 {
+			return fmt.Errorf("exchange rates line %d: invalid rate %q", line, record[2])
+		}
+		if _, exists := rates[date]; !exists {
+			rates[date] = make(map[string]float64)
+		}
+		rates[date][currency] = rate
+	}
+
+	dates := make([]time.Time, 0, len(rates))
+	for date := range rates {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rates = rates
+	r.dates = dates
+	return nil
+}
+
+// Known reports whether the table can convert a currency
+func (r *ExchangeRates) Known(currency string) bool {
+	currency = strings.ToUpper(currency)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if currency == r.base {
+		return true
+	}
+	for _, date := range r.dates {
+		if _, exists := r.rates[date][currency]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLocked returns units of currency per unit of base on a day: the rate
+// from that day or the latest day before it, or the earliest rate for days
+// before the table starts. Callers must hold the read lock.
+func (r *ExchangeRates) rateLocked(currency string, on time.Time) (float64, error) {
+	if currency == r.base {
+		return 1, nil
+	}
+	day := startOfDay(on)
+	// First day after on; everything before it is on or before on
+	next := sort.Search(len(r.dates), func(i int) bool {
+		return r.dates[i].After(day)
+	})
+	for i := next - 1; i >= 0; i-- {
+		if rate, exists := r.rates[r.dates[i]][currency]; exists {
+			return rate, nil
+		}
+	}
+	for i := next; i < len(r.dates); i++ {
+		if rate, exists := r.rates[r.dates[i]][currency]; exists {
+			return rate, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+}
+
+// Rate returns how many units of to one unit of from was worth on a day
+func (r *ExchangeRates) Rate(from string, to string, on time.Time) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fromRate, err := r.rateLocked(from, on)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := r.rateLocked(to, on)
+	if err != nil {
+		return 0, err
+	}
+	return toRate / fromRate, nil
+}
+
+// Table describes the loaded rates
+func (r *ExchangeRates) Table() models.ExchangeRateTable {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	table := models.ExchangeRateTable{Base: r.base, Currencies: []string{r.base}, Days: len(r.dates)}
+	seen := map[string]bool{r.base: true}
+	for _, date := range r.dates {
+		for currency := range r.rates[date] {
+			if !seen[currency] {
+				seen[currency] = true
+				table.Currencies = append(table.Currencies, currency)
+			}
+		}
+	}
+	sort.Strings(table.Currencies)
+	if len(r.dates) > 0 {
+		table.FirstDate = r.dates[0]
+		table.LastDate = r.dates[len(r.dates)-1]
+	}
+	return table
+}
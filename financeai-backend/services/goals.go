@@ -0,0 +1,113 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// GoalInput describes a savings goal to create
+type GoalInput struct {
+	Name         string  `json:"name"`
+	AccountID    string  `json:"accountId"`
+	TargetAmount float64 `json:"targetAmount"`
+	TargetDate   string  `json:"targetDate"`
+}
+
+// GoalStore holds customers' savings goals
+type GoalStore struct {
+	mu     sync.RWMutex
+	goals  map[string]map[string]*models.Goal
+	nextID int
+}
+
+// defaultGoalStore is shared by the data providers so goals show up everywhere
+var defaultGoalStore = NewGoalStore()
+
+// NewGoalStore creates an empty goal store
+func NewGoalStore() *GoalStore {
+	return &GoalStore{
+		goals: make(map[string]map[string]*models.Goal),
+	}
+}
+
+// Add creates a savings goal
+func (s *GoalStore) Add(customerID string, input GoalInput, now time.Time) (*models.Goal, error) {
+	goal := models.Goal{
+		CustomerID:   customerID,
+		Name:         strings.TrimSpace(input.Name),
+		AccountID:    strings.TrimSpace(input.AccountID),
+		TargetAmount: roundCents(input.TargetAmount),
+		CreatedAt:    now,
+	}
+	if goal.Name == "" {
+		return nil, NewValidationError("name", "name required")
+	}
+	if goal.AccountID == "" {
+		return nil, NewValidationError("accountId", "accountId required")
+	}
+	if goal.TargetAmount <= 0 || math.IsInf(goal.TargetAmount, 0) {
+		return nil, NewValidationError("targetAmount", "targetAmount must be greater than 0")
+	}
+	if input.TargetDate != "" {
+		targetDate, err := time.Parse(BillDateLayout, input.TargetDate)
+		if err != nil {
+			return nil, NewValidationError("targetDate", "targetDate must be in YYYY-MM-DD format")
+		}
+		goal.TargetDate = &targetDate
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	goal.ID = fmt.Sprintf("goal%d", s.nextID)
+	if _, exists := s.goals[customerID]; !exists {
+		s.goals[customerID] = make(map[string]*models.Goal)
+	}
+	s.goals[customerID][goal.ID] = &goal
+	return &goal, nil
+}
+
+// Delete removes a savings goal
+func (s *GoalStore) Delete(customerID string, goalID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.goals[customerID][goalID]; !exists {
+		return NewNotFoundError("goal not found: %s", goalID)
+	}
+	delete(s.goals[customerID], goalID)
+	return nil
+}
+
+// Goals returns a customer's goals with their progress toward the target,
+// measured by the balance of the account each is saved in
+func (s *GoalStore) Goals(customerID string, accounts []models.Account) []models.Goal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	balances := make(map[string]float64, len(accounts))
+	for _, account := range accounts {
+		balances[account.ID] = float64(account.Balance)
+	}
+
+	goals := make([]models.Goal, 0, len(s.goals[customerID]))
+	for _, goal := range s.goals[customerID] {
+		progress := *goal
+		progress.CurrentAmount = math.Max(0, balances[goal.AccountID])
+		progress.Progress = math.Round(progress.CurrentAmount/goal.TargetAmount*1000) / 10
+		goals = append(goals, progress)
+	}
+	sort.Slice(goals, func(i, j int) bool {
+		if !goals[i].CreatedAt.Equal(goals[j].CreatedAt) {
+			return goals[i].CreatedAt.Before(goals[j].CreatedAt)
+		}
+		return goals[i].ID < goals[j].ID
+	})
+	return goals
+}
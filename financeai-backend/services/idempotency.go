@@ -0,0 +1,124 @@
+package services
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header clients send idempotency keys in
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotencyReplayedHeader is set on responses replayed from an earlier request
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+	// idempotencyKeyRetention is how long a key's result is remembered
+	idempotencyKeyRetention = 24 * time.Hour
+	// maxIdempotencyKeyLength caps the keys clients can send
+	maxIdempotencyKeyLength = 255
+)
+
+var (
+	// ErrIdempotencyKeyReused is returned when a key comes back with a different request
+	ErrIdempotencyKeyReused = newKindError(ErrConflict, "Idempotency-Key was already used for a different request")
+	// ErrIdempotencyKeyInFlight is returned when a key's first request hasn't finished
+	ErrIdempotencyKeyInFlight = newKindError(ErrConflict, "a request with this Idempotency-Key is still being processed")
+	// ErrIdempotencyKeyTooLong is returned for keys over maxIdempotencyKeyLength
+	ErrIdempotencyKeyTooLong = newKindError(ErrValidation, "Idempotency-Key must be at most 255 characters")
+)
+
+// idempotentResult is what the first request with a key returned
+type idempotentResult struct {
+	fingerprint string
+	result      json.RawMessage
+	done        bool
+	createdAt   time.Time
+}
+
+// IdempotencyStore remembers the results of requests made with an
+// idempotency key, so a client retrying after a timeout gets the original
+// result instead of moving money twice
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	results map[string]*idempotentResult
+}
+
+// defaultIdempotencyStore is shared by the data providers so a retry is
+// recognised whichever route it reaches
+var defaultIdempotencyStore = NewIdempotencyStore()
+
+// DefaultIdempotencyStore returns the idempotency store shared by the API
+func DefaultIdempotencyStore() *IdempotencyStore {
+	return defaultIdempotencyStore
+}
+
+// NewIdempotencyStore creates an empty idempotency store
+func NewIdempotencyStore() *IdempotencyStore {
+	return &IdempotencyStore{
+		results: make(map[string]*idempotentResult),
+	}
+}
+
+// Do runs fn once per key within scope, such as a customer. Repeating a key
+// with the same request returns the first result with replayed set instead of
+// running fn again. Failed requests aren't remembered, so they can be retried
+// with the same key. An empty key always runs fn.
+func (s *IdempotencyStore) Do(scope string, key string, request interface{}, fn func() (interface{}, error)) (json.RawMessage, bool, error) {
+	if key == "" {
+		return marshalResult(fn())
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, false, ErrIdempotencyKeyTooLong
+	}
+	fingerprint := cacheHash(request)
+	storeKey := scope + "|" + key
+
+	s.mu.Lock()
+	if existing, exists := s.results[storeKey]; exists && time.Since(existing.createdAt) < idempotencyKeyRetention {
+		s.mu.Unlock()
+		if existing.fingerprint != fingerprint {
+			return nil, false, ErrIdempotencyKeyReused
+		}
+		if !existing.done {
+			return nil, false, ErrIdempotencyKeyInFlight
+		}
+		return existing.result, true, nil
+	}
+	entry := &idempotentResult{fingerprint: fingerprint, createdAt: time.Now()}
+	s.results[storeKey] = entry
+	s.mu.Unlock()
+
+	result, _, err := marshalResult(fn())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		delete(s.results, storeKey)
+		return nil, false, err
+	}
+	entry.result = result
+	entry.done = true
+	return result, false, nil
+}
+
+// marshalResult encodes a result for storing and returning
+func marshalResult(value interface{}, err error) (json.RawMessage, bool, error) {
+	if err != nil {
+		return nil, false, err
+	}
+	result, err := json.Marshal(value)
+	return result, false, err
+}
+
+// Prune forgets keys older than the retention period and returns how many it removed
+func (s *IdempotencyStore) Prune() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := 0
+	for key, entry := range s.results {
+		if entry.done && time.Since(entry.createdAt) >= idempotencyKeyRetention {
+			delete(s.results, key)
+			removed++
+		}
+	}
+	return removed
+}
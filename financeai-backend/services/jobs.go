@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Background job names
+const (
+	JobSyncTransactions    = "sync-transactions"
+	JobRecomputeAggregates = "recompute-aggregates"
+	JobRegenerateInsights  = "regenerate-insights"
+	JobSendDigests         = "send-digests"
+	JobCleanup             = "cleanup"
+)
+
+const (
+	// readNotificationRetention is how long read in-app notifications are kept
+	readNotificationRetention = 30 * 24 * time.Hour
+	// webhookDeliveryRetention is how long successful webhook deliveries are kept
+	webhookDeliveryRetention = 7 * 24 * time.Hour
+)
+
+// Insight snapshot sources
+const (
+	InsightSourceOnDemand  = "on_demand"
+	InsightSourceScheduled = "scheduled"
+)
+
+// JobsConfig configures the default background jobs
+type JobsConfig struct {
+	OpenAIKey string
+	// NessieKey and NessieCustomerIDs make the sync job read from Nessie
+	// instead of the demo data
+	NessieKey         string
+	NessieCustomerIDs []string
+}
+
+// InsightStore keeps the latest insights generated for each customer
+type InsightStore struct {
+	mu        sync.RWMutex
+	snapshots map[string]models.InsightSnapshot
+}
+
+// defaultInsightStore is shared so scheduled insights show up in the API
+var defaultInsightStore = NewInsightStore()
+
+// NewInsightStore creates an empty insight store
+func NewInsightStore() *InsightStore {
+	return &InsightStore{
+		snapshots: make(map[string]models.InsightSnapshot),
+	}
+}
+
+// Save replaces a customer's latest insights
+func (s *InsightStore) Save(snapshot models.InsightSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[snapshot.CustomerID] = snapshot
+}
+
+// Latest returns a customer's latest insights, if any were generated
+func (s *InsightStore) Latest(customerID string) (*models.InsightSnapshot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshot, exists := s.snapshots[customerID]
+	return &snapshot, exists
+}
+
+// TransactionSyncer remembers which transactions it has seen so each sync
+// can tell which ones are new
+type TransactionSyncer struct {
+	mu   sync.Mutex
+	seen map[string]map[string]bool
+}
+
+// NewTransactionSyncer creates a syncer that hasn't seen any transactions
+func NewTransactionSyncer() *TransactionSyncer {
+	return &TransactionSyncer{
+		seen: make(map[string]map[string]bool),
+	}
+}
+
+// Observe records a customer's transactions and returns the ones not seen
+// before. The first sync for a customer only records a baseline, so starting
+// up doesn't announce every existing transaction as new.
+func (s *TransactionSyncer) Observe(customerID string, transactions []models.Transaction) []models.Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen, synced := s.seen[customerID]
+	if !synced {
+		seen = make(map[string]bool, len(transactions))
+		s.seen[customerID] = seen
+	}
+	var created []models.Transaction
+	for _, transaction := range transactions {
+		if seen[transaction.ID] {
+			continue
+		}
+		seen[transaction.ID] = true
+		if synced {
+			created = append(created, transaction)
+		}
+	}
+	return created
+}
+
+// TransactionCreatedEvent describes a new transaction for subscribers, with
+// the amount written the way money formats the customer's amounts
+func TransactionCreatedEvent(customerID string, transaction models.Transaction, money MoneyFormatter) models.Event {
+	name := transaction.Merchant.Name
+	if name == "" {
+		name = transaction.Description
+	}
+	if transaction.Currency != "" {
+		money.Currency = transaction.Currency
+	}
+	return models.Event{
+		Type:       EventTransactionCreated,
+		CustomerID: customerID,
+		Key:        "txn:" + transaction.ID,
+		Title:      fmt.Sprintf("New transaction at %s", name),
+		Message:    fmt.Sprintf("%s at %s on %s.", money.Format(math.Abs(transaction.Amount)), name, transaction.TransactionDate.Format("Jan 2")),
+		Data: map[string]interface{}{
+			"transactionId": transaction.ID,
+			"accountId":     transaction.AccountID,
+			"amount":        transaction.Amount,
+			"description":   transaction.Description,
+			"merchant":      name,
+			"category":      transactionCategory(transaction),
+			"date":          transaction.TransactionDate.Format(BillDateLayout),
+		},
+	}
+}
+
+// RegisterDefaultJobs registers the API's background jobs with scheduler
+func RegisterDefaultJobs(scheduler *Scheduler, config JobsConfig) error {
+	mockService := NewMockDataService()
+	customers := mockService.GetAvailableCustomers()
+
+	// fetch lists a customer's transactions from Nessie when it's configured,
+	// or from the demo data
+	fetch := func(ctx context.Context, customerID string) ([]models.Transaction, error) {
+		return mockService.GetAllCustomerTransactions(customerID, models.TransactionFilter{})
+	}
+	syncCustomers := customers
+	if config.NessieKey != "" && len(config.NessieCustomerIDs) > 0 {
+		nessieService := NewNessieService(config.NessieKey)
+		fetch = func(ctx context.Context, customerID string) ([]models.Transaction, error) {
+			transactions, failures, err := nessieService.GetAllCustomerTransactions(ctx, customerID, models.TransactionFilter{})
+			if err == nil && len(failures) > 0 {
+				err = fmt.Errorf("%d accounts failed, first %s: %s", len(failures), failures[0].AccountID, failures[0].Error)
+			}
+			return transactions, err
+		}
+		syncCustomers = config.NessieCustomerIDs
+	}
+	syncer := NewTransactionSyncer()
+	ai := NewOpenAIService(config.OpenAIKey)
+
+	// forEach runs fn for every customer, collecting failures into one error
+	forEach := func(ctx context.Context, customerIDs []string, fn func(customerID string) error) error {
+		var failures []string
+		for _, customerID := range customerIDs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(customerID); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", customerID, err))
+			}
+		}
+		if len(failures) > 0 {
+			return fmt.Errorf("%d of %d customers failed: %s", len(failures), len(customerIDs), strings.Join(failures, "; "))
+		}
+		return nil
+	}
+
+	definitions := []JobDefinition{
+		{
+			Name:        JobSyncTransactions,
+			Description: "Fetch transactions and publish transaction.created for new ones",
+			Schedule:    "@every 15m",
+			Timeout:     5 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				created := 0
+				err := forEach(ctx, syncCustomers, func(customerID string) error {
+					transactions, err := fetch(ctx, customerID)
+					if err != nil && len(transactions) == 0 {
+						return err
+					}
+					// Record what did load even if some accounts failed
+					newTransactions := syncer.Observe(customerID, transactions)
+					if len(newTransactions) > 0 {
+						defaultResponseCache.Invalidate(customerID)
+					}
+					for _, transaction := range newTransactions {
+						if _, published := defaultEventBus.PublishOnce(TransactionCreatedEvent(customerID, transaction, defaultCurrencyService.Formatter(customerID))); published {
+							created++
+						}
+					}
+					return err
+				})
+				return fmt.Sprintf("synced %d customers, %d new transactions", len(syncCustomers), created), err
+			},
+		},
+		{
+			Name:        JobRecomputeAggregates,
+			Description: "Record balance snapshots for net worth and re-check budgets, balances, bills and goals for notifications",
+			Schedule:    "0 * * * *",
+			Timeout:     5 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				snapshots, events := 0, 0
+				err := forEach(ctx, customers, func(customerID string) error {
+					recorded, err := mockService.RecordBalanceSnapshots(customerID)
+					if err != nil {
+						return err
+					}
+					published, err := mockService.EvaluateNotifications(customerID)
+					if err != nil {
+						return err
+					}
+					snapshots += len(recorded)
+					events += len(published)
+					return nil
+				})
+				return fmt.Sprintf("recorded %d balance snapshots, published %d events", snapshots, events), err
+			},
+		},
+		{
+			Name:        JobRegenerateInsights,
+			Description: "Regenerate every customer's spending insights against their saved budgets",
+			Schedule:    "0 2 * * *",
+			Timeout:     10 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				generated := 0
+				err := forEach(ctx, customers, func(customerID string) error {
+					preferences, err := mockService.GetNotificationPreferences(customerID)
+					if err != nil {
+						return err
+					}
+					insights, err := mockService.GenerateInsights(ai, customerID, preferences.Budgets)
+					if err != nil {
+						return err
+					}
+					mockService.SaveInsights(customerID, insights, InsightSourceScheduled)
+					mockService.PublishInsights(customerID, insights)
+					generated += len(insights)
+					return nil
+				})
+				return fmt.Sprintf("generated %d insights for %d customers", generated, len(customers)), err
+			},
+		},
+		{
+			Name:        JobSendDigests,
+			Description: "Send each customer a weekly summary of spending, income, bills and alerts",
+			Schedule:    "0 8 * * 1",
+			Timeout:     5 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				sent := 0
+				err := forEach(ctx, customers, func(customerID string) error {
+					published, err := mockService.SendWeeklyDigest(customerID)
+					if err != nil {
+						return err
+					}
+					if published {
+						sent++
+					}
+					return nil
+				})
+				return fmt.Sprintf("sent %d digests", sent), err
+			},
+		},
+		{
+			Name:        JobCleanup,
+			Description: "Remove read notifications after 30 days, successful webhook deliveries after 7, and expired cache entries and idempotency keys. Login is stateless, so there are no sessions to expire.",
+			Schedule:    "30 3 * * *",
+			Timeout:     time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				now := time.Now()
+				notifications := defaultNotificationService.Inbox().Prune(now.Add(-readNotificationRetention))
+				deliveries := defaultWebhookService.Prune(now.Add(-webhookDeliveryRetention))
+				entries := defaultResponseCache.Prune()
+				keys := defaultIdempotencyStore.Prune()
+				return fmt.Sprintf("removed %d notifications, %d webhook deliveries, %d expired cache entries and %d idempotency keys", notifications, deliveries, entries, keys), nil
+			},
+		},
+	}
+
+	for _, definition := range definitions {
+		if err := scheduler.Register(definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"financeai-backend/models"
+)
+
+// MerchantEntry is a canonical merchant in the registry
+type MerchantEntry struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	LogoKey  string   `json:"logo_key"`
+	Aliases  []string `json:"aliases"`
+}
+
+// defaultMerchants seeds the registry with well-known merchants. Aliases are
+// matched after normalization, so they are lowercase without punctuation.
+var defaultMerchants = []MerchantEntry{
+	{ID: "starbucks", Name: "Starbucks", Category: "Food & Dining", Aliases: []string{"starbucks", "starbucks coffee", "sbux"}},
+	{ID: "blue-bottle", Name: "Blue Bottle Coffee", Category: "Food & Dining", Aliases: []string{"blue bottle", "blue bottle coffee"}},
+	{ID: "whole-foods", Name: "Whole Foods", Category: "Food & Dining", Aliases: []string{"whole foods", "whole foods market", "wholefds", "wfm"}},
+	{ID: "publix", Name: "Publix", Category: "Food & Dining", Aliases: []string{"publix", "publix super market", "publix super markets"}},
+	{ID: "trader-joes", Name: "Trader Joe's", Category: "Food & Dining", Aliases: []string{"trader joes"}},
+	{ID: "cheesecake-factory", Name: "The Cheesecake Factory", Category: "Food & Dining", Aliases: []string{"cheesecake factory", "the cheesecake factory"}},
+	{ID: "mcdonalds", Name: "McDonald's", Category: "Food & Dining", Aliases: []string{"mcdonalds"}},
+	{ID: "chipotle", Name: "Chipotle", Category: "Food & Dining", Aliases: []string{"chipotle", "chipotle mexican grill"}},
+	{ID: "doordash", Name: "DoorDash", Category: "Food & Dining", Aliases: []string{"doordash", "dd doordash"}},
+	{ID: "uber", Name: "Uber", Category: "Transportation", Aliases: []string{"uber", "uber trip", "uber rides"}},
+	{ID: "uber-eats", Name: "Uber Eats", Category: "Food & Dining", Aliases: []string{"uber eats", "ubereats"}},
+	{ID: "lyft", Name: "Lyft", Category: "Transportation", Aliases: []string{"lyft", "lyft ride"}},
+	{ID: "shell", Name: "Shell", Category: "Transportation", Aliases: []string{"shell", "shell oil", "shell service station"}},
+	{ID: "chevron", Name: "Chevron", Category: "Transportation", Aliases: []string{"chevron"}},
+	{ID: "exxon", Name: "ExxonMobil", Category: "Transportation", Aliases: []string{"exxon", "exxonmobil", "mobil"}},
+	{ID: "amazon", Name: "Amazon", Category: "Shopping", Aliases: []string{"amazon", "amazon com", "amzn", "amzn mktp", "amzn mktp us", "amazon mktplace"}},
+	{ID: "target", Name: "Target", Category: "Shopping", Aliases: []string{"target", "target com"}},
+	{ID: "best-buy", Name: "Best Buy", Category: "Shopping", Aliases: []string{"best buy", "bestbuy", "best buy com"}},
+	{ID: "urban-outfitters", Name: "Urban Outfitters", Category: "Shopping", Aliases: []string{"urban outfitters", "urban outfitter"}},
+	{ID: "walmart", Name: "Walmart", Category: "Shopping", Aliases: []string{"walmart", "wal mart", "wm supercenter", "walmart com"}},
+	{ID: "netflix", Name: "Netflix", Category: "Entertainment", Aliases: []string{"netflix", "netflix com"}},
+	{ID: "spotify", Name: "Spotify", Category: "Entertainment", Aliases: []string{"spotify", "spotify usa"}},
+	{ID: "hulu", Name: "Hulu", Category: "Entertainment", Aliases: []string{"hulu", "hulu com"}},
+	{ID: "amc", Name: "AMC Theaters", Category: "Entertainment", Aliases: []string{"amc", "amc theaters", "amc theatres"}},
+	{ID: "cvs", Name: "CVS Pharmacy", Category: "Healthcare", Aliases: []string{"cvs", "cvs pharmacy"}},
+	{ID: "walgreens", Name: "Walgreens", Category: "Healthcare", Aliases: []string{"walgreens"}},
+	{ID: "equinox", Name: "Equinox", Category: "Other", Aliases: []string{"equinox"}},
+	{ID: "comcast", Name: "Comcast Xfinity", Category: "Utilities", Aliases: []string{"comcast", "xfinity", "comcast xfinity"}},
+	{ID: "verizon", Name: "Verizon", Category: "Utilities", Aliases: []string{"verizon", "verizon wireless", "vzwrlss"}},
+	{ID: "t-mobile", Name: "T-Mobile", Category: "Utilities", Aliases: []string{"t mobile", "tmobile"}},
+	{ID: "pge", Name: "PG&E", Category: "Utilities", Aliases: []string{"pg e", "pge", "pacific gas electric"}},
+}
+
+// genericDescriptions are descriptions that name a kind of business rather
+// than a merchant, mapped to the category they imply
+var genericDescriptions = map[string]string{
+	"grocery store":     "Food & Dining",
+	"restaurant":        "Food & Dining",
+	"restaurant dinner": "Food & Dining",
+	"coffee shop":       "Food & Dining",
+	"gas station":       "Transportation",
+	"online shopping":   "Shopping",
+	"movie tickets":     "Entertainment",
+	"pharmacy":          "Healthcare",
+	"gym membership":    "Other",
+}
+
+// merchantFillerWords are words a description adds after a merchant's name
+// that don't name another business, e.g. "Netflix Subscription"
+var merchantFillerWords = map[string]bool{
+	"autopay": true, "bill": true, "charge": true, "com": true, "online": true,
+	"order": true, "payment": true, "purchase": true, "recurring": true, "subscription": true,
+}
+
+var (
+	// processorPrefixPattern matches payment processor prefixes such as "SQ *",
+	// "TST* " or "PAYPAL *" that precede the real merchant name
+	processorPrefixPattern = regexp.MustCompile(`^(sq|squ|tst|pp|paypal|sp|ddbr|in|py|iz|bt|cke|gglpay|google|apl|apple pay|fs)\s*\*\s*`)
+	// cardPrefixPattern matches card network noise at the start of a description
+	cardPrefixPattern = regexp.MustCompile(`^(pos|debit|debit card|checkcard|purchase|card purchase|recurring|ach|visa)\s+(purchase\s+)?`)
+	// referenceSuffixPattern matches "*AB12CD" style order references
+	referenceSuffixPattern = regexp.MustCompile(`\*\s*[a-z0-9]*\d[a-z0-9]*\b`)
+	// storeNumberPattern matches store numbers such as "#1234", "store 12" or "1234"
+	storeNumberPattern = regexp.MustCompile(`(#\s*\d+|\b(store|str|no)\s*\d+|\b\d{2,}\b)`)
+	// nonWordPattern matches anything other than letters, digits and spaces
+	nonWordPattern = regexp.MustCompile(`[^a-z0-9 ]+`)
+	// legalSuffixPattern matches company suffixes at the end of a name
+	legalSuffixPattern = regexp.MustCompile(`\s+(inc|llc|ltd|corp|co)$`)
+)
+
+// MerchantRegistry maps raw merchant strings to canonical merchants
+type MerchantRegistry struct {
+	mu        sync.RWMutex
+	merchants map[string]MerchantEntry
+	aliases   map[string]string // normalized alias -> merchant ID
+}
+
+// defaultMerchantRegistry is shared by the data providers so every source
+// resolves merchants the same way
+var defaultMerchantRegistry = NewMerchantRegistry()
+
+// NewMerchantRegistry creates a registry seeded with the default merchants
+func NewMerchantRegistry() *MerchantRegistry {
+	registry := &MerchantRegistry{
+		merchants: make(map[string]MerchantEntry),
+		aliases:   make(map[string]string),
+	}
+	for _, entry := range defaultMerchants {
+		registry.Register(entry)
+	}
+	return registry
+}
+
+// Register adds or replaces a canonical merchant and its aliases
+func (r *MerchantRegistry) Register(entry MerchantEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry.LogoKey == "" {
+		entry.LogoKey = entry.ID
+	}
+	r.merchants[entry.ID] = entry
+	r.aliases[NormalizeMerchantString(entry.Name)] = entry.ID
+	for _, alias := range entry.Aliases {
+		r.aliases[NormalizeMerchantString(alias)] = entry.ID
+	}
+}
+
+// RegisterAlias maps an additional raw string to an existing merchant
+func (r *MerchantRegistry) RegisterAlias(alias string, merchantID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.merchants[merchantID]; !exists {
+		return false
+	}
+	r.aliases[NormalizeMerchantString(alias)] = merchantID
+	return true
+}
+
+// Merchant returns the merchant registered with an ID
+func (r *MerchantRegistry) Merchant(merchantID string) (MerchantEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, exists := r.merchants[merchantID]
+	return entry, exists
+}
+
+// Merchants returns all registered merchants sorted by ID
+func (r *MerchantRegistry) Merchants() []MerchantEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]MerchantEntry, 0, len(r.merchants))
+	for _, entry := range r.merchants {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// NormalizeMerchantString reduces a raw merchant string such as
+// "SQ *BLUE BOTTLE 1234" to a comparable form ("blue bottle")
+func NormalizeMerchantString(raw string) string {
+	value := strings.ToLower(strings.TrimSpace(raw))
+
+	// Prefixes can stack, e.g. "POS PURCHASE SQ *BLUE BOTTLE"
+	for {
+		stripped := cardPrefixPattern.ReplaceAllString(value, "")
+		stripped = processorPrefixPattern.ReplaceAllString(stripped, "")
+		stripped = strings.TrimSpace(stripped)
+		if stripped == value {
+			break
+		}
+		value = stripped
+	}
+
+	value = referenceSuffixPattern.ReplaceAllString(value, " ")
+	value = storeNumberPattern.ReplaceAllString(value, " ")
+	value = strings.NewReplacer("'", "", "’", "").Replace(value)
+	value = nonWordPattern.ReplaceAllString(value, " ")
+	value = strings.Join(strings.Fields(value), " ")
+	value = legalSuffixPattern.ReplaceAllString(value, "")
+	return value
+}
+
+// Resolve finds the canonical merchant for a raw string. An exact alias match
+// wins; otherwise the longest alias the string starts with is used, once
+// processor prefixes and store numbers are gone. A one-word alias only counts
+// when nothing but filler words follow it, so "AMC DENTAL" isn't AMC Theaters.
+func (r *MerchantRegistry) Resolve(raw string) (MerchantEntry, bool) {
+	normalized := NormalizeMerchantString(raw)
+	if normalized == "" {
+		return MerchantEntry{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if id, exists := r.aliases[normalized]; exists {
+		return r.merchants[id], true
+	}
+
+	words := strings.Fields(normalized)
+	for size := len(words) - 1; size > 1; size-- {
+		if id, exists := r.aliases[strings.Join(words[:size], " ")]; exists {
+			return r.merchants[id], true
+		}
+	}
+	if id, exists := r.aliases[words[0]]; exists && onlyFillerWords(words[1:]) {
+		return r.merchants[id], true
+	}
+
+	return MerchantEntry{}, false
+}
+
+// onlyFillerWords reports whether every word is a merchantFillerWords entry
+func onlyFillerWords(words []string) bool {
+	for _, word := range words {
+		if !merchantFillerWords[word] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsGenericDescription reports whether a description names a kind of
+// business ("Gas Station") rather than a merchant
+func IsGenericDescription(description string) bool {
+	_, exists := genericDescriptions[NormalizeMerchantString(description)]
+	return exists
+}
+
+// EnrichMerchant fills in a transaction's merchant from the registry. The
+// merchant name is tried first, then the description; transactions that
+// don't resolve keep their merchant with a cleaned-up name.
+func (r *MerchantRegistry) EnrichMerchant(transaction *models.Transaction) {
+	candidates := []string{transaction.Merchant.Name, transaction.Description}
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if entry, exists := r.Resolve(candidate); exists {
+			transaction.MerchantID = entry.ID
+			transaction.Merchant = models.Merchant{
+				ID:       entry.ID,
+				Name:     entry.Name,
+				Category: entry.Category,
+				LogoKey:  entry.LogoKey,
+				// Keep where the provider says this location is
+				Geocode: transaction.Merchant.Geocode,
+			}
+			return
+		}
+	}
+
+	// Unknown merchant: keep what the provider sent, but don't promote a
+	// generic description like "Grocery Store" to a merchant name
+	if transaction.Merchant.Category == "" {
+		if category, exists := genericDescriptions[NormalizeMerchantString(transaction.Description)]; exists {
+			transaction.Merchant.Category = category
+		}
+	}
+	if transaction.Merchant.Name == "" && !IsGenericDescription(transaction.Description) {
+		transaction.Merchant.Name = titleCase(NormalizeMerchantString(transaction.Description))
+	}
+	if transaction.Merchant.ID == "" && transaction.Merchant.Name != "" {
+		transaction.Merchant.ID = "unresolved:" + strings.ReplaceAll(NormalizeMerchantString(transaction.Merchant.Name), " ", "-")
+	}
+}
+
+// titleCase capitalizes the first letter of each word
+func titleCase(value string) string {
+	words := strings.Fields(value)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
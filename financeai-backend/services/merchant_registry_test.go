@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+
+	"financeai-backend/models"
+)
+
+func TestNormalizeMerchantString(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"SQ *BLUE BOTTLE 1234", "blue bottle"},
+		{"POS PURCHASE TST* STARBUCKS #5521", "starbucks"},
+		{"AMZN Mktp US*2K4LM8Q02", "amzn mktp us"},
+		{"Trader Joe's #552", "trader joes"},
+		{"SHELL OIL 57444", "shell oil"},
+		{"Acme Widgets, Inc.", "acme widgets"},
+		{"  ", ""},
+	}
+	for _, test := range tests {
+		if got := NormalizeMerchantString(test.raw); got != test.want {
+			t.Errorf("NormalizeMerchantString(%q) = %q, want %q", test.raw, got, test.want)
+		}
+	}
+}
+
+func TestMerchantRegistryResolve(t *testing.T) {
+	registry := NewMerchantRegistry()
+	tests := []struct {
+		raw    string
+		wantID string // "" when the string shouldn't resolve
+	}{
+		{"SQ *BLUE BOTTLE 1234", "blue-bottle"},
+		{"Blue Bottle Coffee Oakland", "blue-bottle"},
+		{"CHECKCARD UBER TRIP HELP.UBER.COM", "uber"},
+		{"Amazon Purchase", "amazon"},
+		{"Netflix Subscription", "netflix"},
+		{"WALGREENS #1234", "walgreens"},
+		{"UBER EATS PENDING", "uber-eats"},
+		{"SHELL'S SEAFOOD", ""},
+		{"AMC DENTAL", ""},
+		{"MOBIL HOME PARK", ""},
+		{"Joe's Diner Shell Road", ""},
+		{"Grocery Store", ""},
+	}
+	for _, test := range tests {
+		entry, exists := registry.Resolve(test.raw)
+		if exists != (test.wantID != "") || entry.ID != test.wantID {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", test.raw, entry.ID, exists, test.wantID)
+		}
+	}
+}
+
+func TestEnrichMerchantKeepsUnresolvedNames(t *testing.T) {
+	registry := NewMerchantRegistry()
+
+	transaction := models.Transaction{Description: "AMC DENTAL 0042"}
+	registry.EnrichMerchant(&transaction)
+	if transaction.MerchantID != "" || transaction.Merchant.Name != "Amc Dental" || transaction.Merchant.ID != "unresolved:amc-dental" {
+		t.Errorf("merchant = %+v, want an unresolved Amc Dental", transaction.Merchant)
+	}
+
+	transaction = models.Transaction{Description: "Gas Station"}
+	registry.EnrichMerchant(&transaction)
+	if transaction.Merchant.Name != "" || transaction.Merchant.Category != "Transportation" {
+		t.Errorf("merchant = %+v, want no name in Transportation", transaction.Merchant)
+	}
+}
@@ -3,21 +3,65 @@ package services
 import (
 	"financeai-backend/models"
 	"fmt"
+	"io"
 	"math/rand"
+	"sync"
 	"time"
 )
 
+var (
+	mockCustomersOnce sync.Once
+	mockCustomers     map[string]*models.DashboardData
+)
+
 // MockDataService provides realistic mock financial data
 type MockDataService struct {
-	customers map[string]*models.DashboardData
+	customers   map[string]*models.DashboardData
+	merchants   *MerchantRegistry
+	splits      *SplitStore
+	annotations *AnnotationStore
+	networth    *NetWorthStore
+	debts       *DebtStore
+	bills       *BillStore
+	alerts      *AlertStore
+	goals       *GoalStore
+	events      *EventBus
+	notifier    *NotificationService
+	webhooks    *WebhookService
+	insights    *InsightStore
+	cache       *ResponseCache
+	ledger      *LedgerStore
+	currency    *CurrencyService
 }
 
-// NewMockDataService creates a new mock data service
+// NewMockDataService creates a new mock data service. The demo data is
+// generated once and shared, so every service instance (one per route group)
+// sees the same transactions and the same user edits.
 func NewMockDataService() *MockDataService {
 	service := &MockDataService{
-		customers: make(map[string]*models.DashboardData),
+		merchants:   defaultMerchantRegistry,
+		splits:      defaultSplitStore,
+		annotations: defaultAnnotationStore,
+		networth:    defaultNetWorthStore,
+		debts:       defaultDebtStore,
+		bills:       defaultBillStore,
+		alerts:      defaultAlertStore,
+		goals:       defaultGoalStore,
+		events:      defaultEventBus,
+		notifier:    defaultNotificationService,
+		webhooks:    defaultWebhookService,
+		insights:    defaultInsightStore,
+		cache:       defaultResponseCache,
+		ledger:      defaultLedgerStore,
+		currency:    defaultCurrencyService,
 	}
-	service.initializeMockData()
+	mockCustomersOnce.Do(func() {
+		service.customers = make(map[string]*models.DashboardData)
+		service.initializeMockData()
+		mockCustomers = service.customers
+		service.subscribeNotificationChecks()
+	})
+	service.customers = mockCustomers
 	return service
 }
 
@@ -282,6 +326,278 @@ func (m *MockDataService) initializeMockData() {
 			TotalMonthlySpend: 800,
 		},
 	}
+
+	// Add income and money moved between each customer's own accounts, then
+	// pair up transfers and classify income so neither counts as spending
+	detector := NewTransferDetector()
+	for customerID, data := range m.customers {
+		data.Transactions = append(data.Transactions, m.generateIncome(customerID)...)
+		data.Transactions = append(data.Transactions, m.generateRecurringCharges(customerID)...)
+		data.Transactions = append(data.Transactions, m.generateUnusualActivity(customerID)...)
+		data.Transactions = append(data.Transactions, m.generateTransfers(customerID)...)
+		data.Transactions = ClassifyIncome(detector.Detect(data.Accounts, data.Transactions))
+		stampCurrencies(data.Accounts, data.Transactions)
+	}
+
+	m.seedManualAccounts()
+	m.seedBills()
+}
+
+// generateRecurringCharges creates three months of subscription and utility
+// charges for a demo customer
+func (m *MockDataService) generateRecurringCharges(customerID string) []models.Transaction {
+	type series struct {
+		accountID   string
+		description string
+		amounts     [3]float64
+		daysAgo     int
+	}
+	var schedule []series
+	switch customerID {
+	case "sarah":
+		schedule = []series{
+			{"acc1", "Spotify USA", [3]float64{11.99, 11.99, 11.99}, 9},
+			{"acc1", "Verizon Wireless", [3]float64{85.00, 85.00, 85.00}, 21},
+		}
+	case "michael":
+		schedule = []series{
+			{"acc3", "Comcast Xfinity", [3]float64{89.99, 89.99, 89.99}, 17},
+			{"acc3", "State Farm Insurance", [3]float64{142.50, 142.50, 142.50}, 4},
+			{"acc3", "PG&E", [3]float64{168.20, 141.85, 155.40}, 12},
+		}
+	case "robert":
+		schedule = []series{
+			{"acc6", "Medicare Part B Premium", [3]float64{174.70, 174.70, 174.70}, 16},
+			{"acc6", "Hulu", [3]float64{7.99, 7.99, 7.99}, 25},
+		}
+	case "emma":
+		schedule = []series{
+			{"acc8", "Spotify USA", [3]float64{5.99, 5.99, 5.99}, 11},
+			{"acc8", "T-Mobile", [3]float64{45.00, 45.00, 45.00}, 6},
+		}
+	}
+
+	var charges []models.Transaction
+	for _, recurring := range schedule {
+		for monthsAgo, amount := range recurring.amounts {
+			transaction := models.Transaction{
+				ID:              fmt.Sprintf("rec%d", len(charges)+1),
+				Type:            "deposit",
+				Amount:          -amount,
+				Description:     recurring.description,
+				TransactionDate: time.Now().AddDate(0, -monthsAgo, -recurring.daysAgo),
+				Status:          "completed",
+				AccountID:       recurring.accountID,
+			}
+			m.merchants.EnrichMerchant(&transaction)
+			charges = append(charges, transaction)
+		}
+	}
+	return charges
+}
+
+// generateUnusualActivity creates spending for a demo customer that the
+// anomaly detector should flag, along with the history it stands out from
+func (m *MockDataService) generateUnusualActivity(customerID string) []models.Transaction {
+	var transactions []models.Transaction
+	charge := func(accountID string, amount float64, description string, date time.Time, location *models.Location) {
+		transaction := models.Transaction{
+			ID:              fmt.Sprintf("act%d", len(transactions)+1),
+			Type:            "deposit",
+			Amount:          -amount,
+			Description:     description,
+			TransactionDate: date,
+			Status:          "completed",
+			AccountID:       accountID,
+			Location:        location,
+		}
+		m.merchants.EnrichMerchant(&transaction)
+		transactions = append(transactions, transaction)
+	}
+
+	now := time.Now()
+	lateNight := time.Date(now.Year(), now.Month(), now.Day()-3, 2, 47, 0, 0, now.Location())
+	switch customerID {
+	case "sarah":
+		// Charged twice in the middle of the night at a new store
+		home := &models.Location{City: "San Jose", State: "CA", Country: "US"}
+		charge("acc1", 499.99, "Best Buy #1423", lateNight, home)
+		charge("acc1", 499.99, "Best Buy #1423", lateNight.Add(8*time.Minute), home)
+	case "michael":
+		// A purchase abroad
+		charge("acc3", 186.40, "Senor Frogs Cancun", now.AddDate(0, 0, -6), &models.Location{City: "Cancun", State: "QR", Country: "MX"})
+	case "robert":
+		// A weekly grocery run, then one far larger than usual
+		for weeksAgo, amount := range []float64{92.15, 101.40, 88.70, 97.25, 94.80} {
+			charge("acc6", amount, "Publix Super Market", now.AddDate(0, 0, -7*(weeksAgo+2)), nil)
+		}
+		charge("acc6", 486.30, "Publix Super Market", now.AddDate(0, 0, -2), nil)
+	case "emma":
+		// Clothes shopping well above the usual month
+		charge("acc8", 45.00, "Urban Outfitters", now.AddDate(0, 0, -70), nil)
+		charge("acc8", 52.50, "Urban Outfitters", now.AddDate(0, 0, -44), nil)
+		for _, daysAgo := range []int{4, 9, 15} {
+			charge("acc8", 118.00, "Urban Outfitters", now.AddDate(0, 0, -daysAgo), nil)
+		}
+	}
+	return transactions
+}
+
+// seedBills adds the bills demo customers entered by hand
+func (m *MockDataService) seedBills() {
+	now := time.Now()
+	seeds := []struct {
+		customerID string
+		name       string
+		category   string
+		amount     float64
+		accountID  string
+		frequency  string
+		dueDate    time.Time
+	}{
+		{"sarah", "Rent", "Housing", 1650, "acc1", "monthly", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)},
+		{"michael", "Sunshine Daycare", "Childcare", 1100, "acc3", "monthly", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)},
+		{"robert", "Property Tax", "Housing", 2350, "acc6", "yearly", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 14)},
+		{"emma", "Spring Tuition", "Education", 2400, "acc8", "once", now.AddDate(0, 0, 20)},
+	}
+	for _, seed := range seeds {
+		name, category, amount, accountID, frequency := seed.name, seed.category, seed.amount, seed.accountID, seed.frequency
+		dueDate := seed.dueDate.Format(BillDateLayout)
+		m.bills.Add(seed.customerID, BillInput{
+			Name:      &name,
+			Category:  &category,
+			Amount:    &amount,
+			AccountID: &accountID,
+			Frequency: &frequency,
+			DueDate:   &dueDate,
+		})
+	}
+}
+
+// seedManualAccounts adds the assets and debts demo customers track by hand,
+// along with the terms of their debts
+func (m *MockDataService) seedManualAccounts() {
+	seeds := []struct {
+		customerID  string
+		name        string
+		accountType string
+		balance     float64
+		terms       *DebtTermsInput
+	}{
+		{"michael", "2021 Honda Odyssey", "vehicle", 28500, nil},
+		{"michael", "Odyssey Auto Loan", "auto_loan", 14250, &DebtTermsInput{APR: 6.4, MinimumPayment: 425, DueDay: 5}},
+		{"robert", "Home", "real_estate", 315000, nil},
+		{"emma", "Federal Student Loan", "student_loan", 18750, &DebtTermsInput{APR: 5.5, MinimumPayment: 190, DueDay: 28}},
+	}
+	for _, seed := range seeds {
+		name, accountType, balance := seed.name, seed.accountType, seed.balance
+		account, err := m.networth.AddManualAccount(seed.customerID, ManualAccountInput{Name: &name, Type: &accountType, Balance: &balance}, time.Now())
+		if err == nil && seed.terms != nil {
+			m.debts.SetTerms(seed.customerID, account.ID, *seed.terms)
+		}
+	}
+
+	// Family Rewards Card
+	m.debts.SetTerms("michael", "acc5", DebtTermsInput{APR: 24.99, MinimumPayment: 35, DueDay: 18})
+}
+
+// generateIncome creates paychecks, interest and refunds for a demo customer
+// over the last three months
+func (m *MockDataService) generateIncome(customerID string) []models.Transaction {
+	var income []models.Transaction
+	deposit := func(accountID string, amount float64, date time.Time, description string) {
+		income = append(income, models.Transaction{
+			ID:              fmt.Sprintf("inc%d", len(income)+1),
+			Type:            "deposit",
+			Amount:          amount,
+			Description:     description,
+			TransactionDate: date,
+			Status:          "completed",
+			AccountID:       accountID,
+		})
+	}
+
+	now := time.Now()
+	monthStart := func(monthsAgo int, day int) time.Time {
+		return time.Date(now.Year(), now.Month()-time.Month(monthsAgo), day, 9, 0, 0, 0, now.Location())
+	}
+
+	switch customerID {
+	case "sarah":
+		// Biweekly salary
+		for payday := now.AddDate(0, 0, -3); payday.After(now.AddDate(0, -3, 0)); payday = payday.AddDate(0, 0, -14) {
+			deposit("acc1", 2150, payday, "Brightline Software Payroll Direct Dep")
+		}
+		deposit("acc2", 18.42, monthStart(0, 1), "Interest Payment")
+		deposit("acc1", 24.99, now.AddDate(0, 0, -2), "Amazon Refund")
+	case "michael":
+		// Paid on the 1st and 15th
+		for monthsAgo := 0; monthsAgo < 3; monthsAgo++ {
+			for _, day := range []int{1, 15} {
+				if payday := monthStart(monthsAgo, day); payday.Before(now) {
+					deposit("acc3", 3400, payday, "Austin Medical Group Payroll")
+				}
+			}
+		}
+		deposit("acc4", 31.75, monthStart(0, 1), "Interest Payment")
+	case "robert":
+		// Monthly pension and social security
+		for monthsAgo := 0; monthsAgo < 3; monthsAgo++ {
+			if payday := monthStart(monthsAgo, 3); payday.Before(now) {
+				deposit("acc6", 1850, payday, "State Teachers Pension Payment")
+				deposit("acc6", 1420, monthStart(monthsAgo, 3).Add(time.Hour), "SSA Treas 310 Soc Sec")
+			}
+		}
+		deposit("acc7", 52.10, monthStart(0, 1), "Interest Payment")
+	case "emma":
+		// Weekly campus job and a transfer from family
+		for payday := now.AddDate(0, 0, -5); payday.After(now.AddDate(0, -3, 0)); payday = payday.AddDate(0, 0, -7) {
+			deposit("acc8", 185, payday, "Campus Dining Services Payroll")
+		}
+		deposit("acc8", 300, now.AddDate(0, 0, -12), "Zelle Transfer From Lisa Davis")
+	}
+
+	return income
+}
+
+// generateTransfers creates transfers between a demo customer's accounts
+func (m *MockDataService) generateTransfers(customerID string) []models.Transaction {
+	transfer := func(id string, fromAccount string, toAccount string, amount float64, daysAgo int, outDescription string, inDescription string) []models.Transaction {
+		date := time.Now().AddDate(0, 0, -daysAgo)
+		return []models.Transaction{
+			{
+				ID:              id + "-out",
+				Type:            "transfer",
+				Amount:          -amount,
+				Description:     outDescription,
+				TransactionDate: date,
+				Status:          "completed",
+				AccountID:       fromAccount,
+			},
+			{
+				ID:              id + "-in",
+				Type:            "transfer",
+				Amount:          amount,
+				Description:     inDescription,
+				TransactionDate: date.Add(24 * time.Hour),
+				Status:          "completed",
+				AccountID:       toAccount,
+			},
+		}
+	}
+
+	switch customerID {
+	case "sarah":
+		return transfer("xfer1", "acc1", "acc2", 500, 6, "Transfer to Emergency Fund", "Transfer from Primary Checking")
+	case "michael":
+		return append(
+			transfer("xfer2", "acc3", "acc5", 300, 10, "Credit Card Payment", "Payment Thank You"),
+			transfer("xfer3", "acc3", "acc4", 250, 4, "Transfer to Kids College Fund", "Transfer from Family Checking")...,
+		)
+	case "robert":
+		return transfer("xfer4", "acc7", "acc6", 400, 8, "Transfer to Retirement Checking", "Transfer from Travel Fund")
+	}
+	return nil
 }
 
 // generateTransactions creates realistic transaction data
@@ -464,17 +780,33 @@ func (m *MockDataService) generateTransactions(customerID string) []models.Trans
 		transactions[i].Amount = transactions[i].Amount * (0.8 + rand.Float64()*0.4)
 		// Randomize dates
 		transactions[i].TransactionDate = time.Now().AddDate(0, 0, -rand.Intn(30))
+		// Resolve merchants the same way live provider data is
+		m.merchants.EnrichMerchant(&transactions[i])
 	}
 
 	return transactions
 }
 
-// GetDashboardData returns mock dashboard data for a customer
+// GetDashboardData returns mock dashboard data for a customer, with amounts
+// in their reporting currency. It's cached until the customer's transactions
+// or currency preferences are edited.
 func (m *MockDataService) GetDashboardData(customerID string) (*models.DashboardData, error) {
-	if data, exists := m.customers[customerID]; exists {
-		return data, nil
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
 	}
-	return nil, fmt.Errorf("customer not found: %s", customerID)
+	return cached(m.cache, CacheNamespaceDashboard, customerID, nil, dashboardCacheTTL, func() (*models.DashboardData, error) {
+		// Copy so split transactions can be reflected without touching the seed data
+		result := *data
+		result.Accounts = m.reportingAccounts(customerID, data)
+		result.Transactions = m.reportingTransactions(customerID, data)
+		result.SpendingData = m.currency.ConvertSpendingData(customerID, data.SpendingData, DefaultCurrency)
+		result.SpendingData.CategorySpending = applySplitsToCategorySpending(result.SpendingData.CategorySpending, result.Transactions)
+		preferences := m.currency.Preferences(customerID)
+		result.Currency = preferences.ReportingCurrency
+		result.Locale = preferences.Locale
+		return &result, nil
+	})
 }
 
 // GetCustomer returns mock customer data
@@ -482,23 +814,716 @@ func (m *MockDataService) GetCustomer(customerID string) (*models.Customer, erro
 	if data, exists := m.customers[customerID]; exists {
 		return &data.Customer, nil
 	}
-	return nil, fmt.Errorf("customer not found: %s", customerID)
+	return nil, NewNotFoundError("customer not found: %s", customerID)
 }
 
 // GetCustomerAccounts returns mock account data
 func (m *MockDataService) GetCustomerAccounts(customerID string) ([]models.Account, error) {
 	if data, exists := m.customers[customerID]; exists {
-		return data.Accounts, nil
+		return m.customerAccounts(customerID, data), nil
 	}
-	return nil, fmt.Errorf("customer not found: %s", customerID)
+	return nil, NewNotFoundError("customer not found: %s", customerID)
 }
 
-// GetAllCustomerTransactions returns mock transaction data
-func (m *MockDataService) GetAllCustomerTransactions(customerID string) ([]models.Transaction, error) {
+// GetAllCustomerTransactions returns mock transaction data matching filter
+func (m *MockDataService) GetAllCustomerTransactions(customerID string, filter models.TransactionFilter) ([]models.Transaction, error) {
 	if data, exists := m.customers[customerID]; exists {
-		return data.Transactions, nil
+		return FilterTransactions(m.applyUserEdits(customerID, data.Transactions), filter)
+	}
+	return nil, NewNotFoundError("customer not found: %s", customerID)
+}
+
+// GetTransaction returns a single mock transaction
+func (m *MockDataService) GetTransaction(customerID string, transactionID string) (*models.Transaction, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	for _, transaction := range m.applyUserEdits(customerID, data.Transactions) {
+		if transaction.ID == transactionID {
+			return &transaction, nil
+		}
+	}
+	return nil, NewNotFoundError("transaction not found: %s", transactionID)
+}
+
+// SetTransactionSplits divides a transaction across categories
+func (m *MockDataService) SetTransactionSplits(customerID string, transactionID string, splits []models.TransactionSplit) (*models.Transaction, error) {
+	transaction, err := m.GetTransaction(customerID, transactionID)
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("customer not found: %s", customerID)
+	if err := m.splits.Set(customerID, *transaction, splits); err != nil {
+		return nil, err
+	}
+	m.cache.Invalidate(customerID)
+	return m.GetTransaction(customerID, transactionID)
+}
+
+// UpdateTransaction changes a transaction's tags and notes
+func (m *MockDataService) UpdateTransaction(customerID string, transactionID string, update TransactionAnnotationUpdate) (*models.Transaction, error) {
+	if _, err := m.GetTransaction(customerID, transactionID); err != nil {
+		return nil, err
+	}
+	if err := m.annotations.Update(customerID, transactionID, update); err != nil {
+		return nil, err
+	}
+	m.cache.Invalidate(customerID)
+	return m.GetTransaction(customerID, transactionID)
+}
+
+// AddTransactionAttachment stores a receipt or other file on a transaction
+func (m *MockDataService) AddTransactionAttachment(customerID string, transactionID string, filename string, contentType string, r io.Reader) (*models.Attachment, error) {
+	if _, err := m.GetTransaction(customerID, transactionID); err != nil {
+		return nil, err
+	}
+	attachment, err := m.annotations.AddAttachment(customerID, transactionID, filename, contentType, r)
+	if err != nil {
+		return nil, err
+	}
+	m.cache.Invalidate(customerID)
+	return attachment, nil
+}
+
+// OpenTransactionAttachment returns an attachment and a reader for its contents
+func (m *MockDataService) OpenTransactionAttachment(customerID string, transactionID string, attachmentID string) (*models.Attachment, io.ReadCloser, error) {
+	return m.annotations.OpenAttachment(customerID, transactionID, attachmentID)
+}
+
+// DeleteTransactionAttachment removes an attachment from a transaction
+func (m *MockDataService) DeleteTransactionAttachment(customerID string, transactionID string, attachmentID string) error {
+	if err := m.annotations.DeleteAttachment(customerID, transactionID, attachmentID); err != nil {
+		return err
+	}
+	m.cache.Invalidate(customerID)
+	return nil
+}
+
+// applyUserEdits adds the transactions from a customer's transfers, deposits,
+// withdrawals and purchases, and attaches the splits, tags, notes and
+// attachments users have added
+func (m *MockDataService) applyUserEdits(customerID string, transactions []models.Transaction) []models.Transaction {
+	return m.annotations.Apply(customerID, m.splits.Apply(customerID, m.ledger.ApplyTransactions(customerID, transactions)))
+}
+
+// customerAccounts returns a customer's accounts with the balances their
+// transfers, deposits, withdrawals and purchases left them
+func (m *MockDataService) customerAccounts(customerID string, data *models.DashboardData) []models.Account {
+	return m.ledger.ApplyBalances(customerID, data.Accounts)
+}
+
+// reportingAccounts returns a customer's accounts with their balances in the
+// customer's reporting currency, for totals across accounts
+func (m *MockDataService) reportingAccounts(customerID string, data *models.DashboardData) []models.Account {
+	return m.currency.ConvertAccounts(customerID, m.customerAccounts(customerID, data))
+}
+
+// reportingTransactions returns a customer's edited transactions with their
+// amounts in the customer's reporting currency, for totals across accounts
+func (m *MockDataService) reportingTransactions(customerID string, data *models.DashboardData) []models.Transaction {
+	return m.currency.ConvertTransactions(customerID, m.applyUserEdits(customerID, data.Transactions))
+}
+
+// ConvertTransactions returns transactions with their amounts in a
+// customer's reporting currency
+func (m *MockDataService) ConvertTransactions(customerID string, transactions []models.Transaction) []models.Transaction {
+	return m.currency.ConvertTransactions(customerID, transactions)
+}
+
+// MoneyFormatter returns a formatter for amounts in a customer's reporting
+// currency and locale
+func (m *MockDataService) MoneyFormatter(customerID string) MoneyFormatter {
+	return m.currency.Formatter(customerID)
+}
+
+// GetCurrencyPreferences returns a customer's reporting currency and locale
+func (m *MockDataService) GetCurrencyPreferences(customerID string) (*models.CurrencyPreferences, error) {
+	if _, exists := m.customers[customerID]; !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	preferences := m.currency.Preferences(customerID)
+	return &preferences, nil
+}
+
+// SetCurrencyPreferences changes a customer's reporting currency or locale.
+// Cached responses were built in the old currency, so they're dropped.
+func (m *MockDataService) SetCurrencyPreferences(customerID string, preferences models.CurrencyPreferences) (*models.CurrencyPreferences, error) {
+	updated, err := m.currency.SetPreferences(customerID, preferences)
+	if err != nil {
+		return nil, err
+	}
+	m.cache.Invalidate(customerID)
+	return updated, nil
+}
+
+// GetExchangeRates describes the exchange rates amounts are converted with
+func (m *MockDataService) GetExchangeRates() models.ExchangeRateTable {
+	return m.currency.Rates().Table()
+}
+
+// ClearTransactionSplits returns a split transaction to its single category
+func (m *MockDataService) ClearTransactionSplits(customerID string, transactionID string) (*models.Transaction, error) {
+	if _, err := m.GetTransaction(customerID, transactionID); err != nil {
+		return nil, err
+	}
+	m.splits.Clear(customerID, transactionID)
+	m.cache.Invalidate(customerID)
+	return m.GetTransaction(customerID, transactionID)
+}
+
+// CreateMovement makes a transfer, deposit, withdrawal or purchase on one of
+// a customer's accounts. Its transactions and the new balances show up
+// everywhere the customer's data is read.
+func (m *MockDataService) CreateMovement(customerID string, kind string, input MovementInput) (*models.MoneyMovement, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	if err := ValidateMovement(kind, &input); err != nil {
+		return nil, err
+	}
+	if err := checkMovementAccounts(data.Accounts, input); err != nil {
+		return nil, err
+	}
+	var merchant *MerchantEntry
+	if kind == MovementPurchase {
+		entry, exists := m.merchants.Merchant(input.MerchantID)
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownMerchant, input.MerchantID)
+		}
+		merchant = &entry
+	}
+
+	movement, transactions, err := m.ledger.Record(data.Accounts, models.MoneyMovement{
+		Kind:        kind,
+		CustomerID:  customerID,
+		AccountID:   input.AccountID,
+		PayeeID:     input.PayeeID,
+		MerchantID:  input.MerchantID,
+		Medium:      input.Medium,
+		Amount:      input.Amount,
+		Description: input.Description,
+		Date:        input.Date,
+		CreatedAt:   time.Now(),
+	}, merchant)
+	if err != nil {
+		return nil, err
+	}
+	m.cache.Invalidate(customerID)
+	for _, transaction := range transactions {
+		m.events.PublishOnce(TransactionCreatedEvent(customerID, transaction, m.currency.Formatter(customerID)))
+	}
+	return &movement, nil
+}
+
+// CreateBillPayment schedules a bill to be paid from one of a customer's
+// accounts. Demo bill payments become manual bills, so they show up on the
+// bill calendar.
+func (m *MockDataService) CreateBillPayment(customerID string, input BillPaymentInput) (*models.Bill, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	if err := ValidateBillPayment(&input); err != nil {
+		return nil, err
+	}
+	if _, exists := findAccount(data.Accounts, input.AccountID); !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAccount, input.AccountID)
+	}
+
+	name := input.Nickname
+	if name == "" {
+		name = input.Payee
+	}
+	frequency := billPaymentFrequency(input)
+	autoPay := true
+	return m.bills.Add(customerID, BillInput{
+		Name:      &name,
+		Amount:    &input.Amount,
+		AccountID: &input.AccountID,
+		Frequency: &frequency,
+		DueDate:   &input.PaymentDate,
+		AutoPay:   &autoPay,
+	})
+}
+
+// GetNetWorth returns a customer's net worth history over the last days days
+func (m *MockDataService) GetNetWorth(customerID string, days int) (*models.NetWorthSummary, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	summary := m.networth.BuildNetWorth(customerID, m.reportingAccounts(customerID, data), m.reportingTransactions(customerID, data), days, time.Now())
+	return &summary, nil
+}
+
+// RecordBalanceSnapshots captures today's balance of each of a customer's accounts
+func (m *MockDataService) RecordBalanceSnapshots(customerID string) ([]models.BalanceSnapshot, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	return m.networth.RecordBalances(customerID, m.customerAccounts(customerID, data), time.Now()), nil
+}
+
+// GetManualAccounts returns the assets and debts a customer tracks by hand
+func (m *MockDataService) GetManualAccounts(customerID string) ([]models.ManualAccount, error) {
+	if _, exists := m.customers[customerID]; !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	return m.networth.ManualAccounts(customerID), nil
+}
+
+// AddManualAccount adds an asset or debt the customer tracks by hand
+func (m *MockDataService) AddManualAccount(customerID string, input ManualAccountInput) (*models.ManualAccount, error) {
+	if _, exists := m.customers[customerID]; !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	return m.networth.AddManualAccount(customerID, input, time.Now())
+}
+
+// UpdateManualAccount changes a manual account's name, type or value
+func (m *MockDataService) UpdateManualAccount(customerID string, accountID string, input ManualAccountInput) (*models.ManualAccount, error) {
+	return m.networth.UpdateManualAccount(customerID, accountID, input, time.Now())
+}
+
+// DeleteManualAccount removes a manual account
+func (m *MockDataService) DeleteManualAccount(customerID string, accountID string) error {
+	return m.networth.DeleteManualAccount(customerID, accountID)
+}
+
+// GetDebts returns a customer's liabilities with the terms recorded for them
+func (m *MockDataService) GetDebts(customerID string) ([]models.Debt, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	return m.debts.Debts(customerID, m.reportingAccounts(customerID, data), m.currency.ConvertManualAccounts(customerID, m.networth.ManualAccounts(customerID))), nil
+}
+
+// SetDebtTerms records the APR, minimum payment and due day of a liability
+func (m *MockDataService) SetDebtTerms(customerID string, accountID string, terms DebtTermsInput) (*models.Debt, error) {
+	debt, err := m.findDebt(customerID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.debts.SetTerms(customerID, accountID, terms); err != nil {
+		return nil, err
+	}
+	return m.findDebt(customerID, debt.AccountID)
+}
+
+// findDebt looks up one of a customer's liabilities
+func (m *MockDataService) findDebt(customerID string, accountID string) (*models.Debt, error) {
+	debts, err := m.GetDebts(customerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, debt := range debts {
+		if debt.AccountID == accountID {
+			return &debt, nil
+		}
+	}
+	return nil, NewNotFoundError("debt not found: %s", accountID)
+}
+
+// PlanDebtPayoff simulates paying off a customer's debts with one strategy
+func (m *MockDataService) PlanDebtPayoff(customerID string, strategy string, monthlyBudget float64, order []string) (*models.PayoffPlan, error) {
+	debts, err := m.GetDebts(customerID)
+	if err != nil {
+		return nil, err
+	}
+	return SimulatePayoff(debts, strategy, monthlyBudget, order, m.currency.Formatter(customerID), time.Now())
+}
+
+// CompareDebtPayoff simulates each payoff strategy with the same budget
+func (m *MockDataService) CompareDebtPayoff(customerID string, monthlyBudget float64, order []string) ([]models.PayoffPlan, error) {
+	debts, err := m.GetDebts(customerID)
+	if err != nil {
+		return nil, err
+	}
+	return ComparePayoffStrategies(debts, monthlyBudget, order, m.currency.Formatter(customerID), time.Now())
+}
+
+// GetRecurringCharges returns the subscriptions and other charges that repeat
+// on a customer's accounts
+func (m *MockDataService) GetRecurringCharges(customerID string) ([]models.RecurringCharge, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	return DetectRecurringCharges(m.reportingTransactions(customerID, data), time.Now()), nil
+}
+
+// GetBills returns the bills a customer entered by hand
+func (m *MockDataService) GetBills(customerID string) ([]models.Bill, error) {
+	if _, exists := m.customers[customerID]; !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	return m.bills.Bills(customerID), nil
+}
+
+// validateBillAccount checks that a bill is paid from one of the customer's accounts
+func (m *MockDataService) validateBillAccount(customerID string, input BillInput) error {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return NewNotFoundError("customer not found: %s", customerID)
+	}
+	if input.AccountID == nil {
+		return nil
+	}
+	for _, account := range data.Accounts {
+		if account.ID == *input.AccountID {
+			return nil
+		}
+	}
+	return NewValidationError("accountId", "unknown account: %s", *input.AccountID)
+}
+
+// AddBill adds a bill such as rent, a utility or tuition
+func (m *MockDataService) AddBill(customerID string, input BillInput) (*models.Bill, error) {
+	if err := m.validateBillAccount(customerID, input); err != nil {
+		return nil, err
+	}
+	return m.bills.Add(customerID, input)
+}
+
+// UpdateBill changes a bill
+func (m *MockDataService) UpdateBill(customerID string, billID string, input BillInput) (*models.Bill, error) {
+	if err := m.validateBillAccount(customerID, input); err != nil {
+		return nil, err
+	}
+	return m.bills.Update(customerID, billID, input)
+}
+
+// DeleteBill removes a bill
+func (m *MockDataService) DeleteBill(customerID string, billID string) error {
+	return m.bills.Delete(customerID, billID)
+}
+
+// GetBillCalendar returns the manual and detected bills due in [start, end)
+func (m *MockDataService) GetBillCalendar(customerID string, start time.Time, end time.Time) ([]models.UpcomingBill, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	now := time.Now()
+	transactions := m.reportingTransactions(customerID, data)
+	return BuildBillCalendar(
+		m.reportingAccounts(customerID, data),
+		m.bills.Bills(customerID),
+		DetectRecurringCharges(transactions, now),
+		DetectPayrollCadence(transactions),
+		start, end, now,
+	), nil
+}
+
+// GetAlerts returns unusual transactions and spending spikes flagged for a
+// customer, leaving out dismissed alerts unless includeDismissed is set
+func (m *MockDataService) GetAlerts(customerID string, includeDismissed bool) ([]models.Alert, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	transactions := m.reportingTransactions(customerID, data)
+	detector := NewAnomalyDetector()
+	detector.Money = m.currency.Formatter(customerID)
+	alerts := detector.Detect(data.Customer, transactions, m.alerts.AllowList(customerID), time.Now())
+	return m.alerts.Apply(customerID, alerts, includeDismissed), nil
+}
+
+// DismissAlert dismisses an alert and stores the customer's feedback on it
+func (m *MockDataService) DismissAlert(customerID string, alertID string, dismissal AlertDismissal) (*models.Alert, error) {
+	alerts, err := m.GetAlerts(customerID, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, alert := range alerts {
+		if alert.ID != alertID {
+			continue
+		}
+		var transaction *models.Transaction
+		if alert.TransactionID != "" {
+			transaction, _ = m.GetTransaction(customerID, alert.TransactionID)
+		}
+		return m.alerts.Dismiss(customerID, alert, transaction, dismissal, time.Now())
+	}
+	return nil, NewNotFoundError("alert not found: %s", alertID)
+}
+
+// GetGoals returns a customer's savings goals and their progress
+func (m *MockDataService) GetGoals(customerID string) ([]models.Goal, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	return m.goals.Goals(customerID, m.reportingAccounts(customerID, data)), nil
+}
+
+// AddGoal creates a savings goal tracked against one of the customer's accounts
+func (m *MockDataService) AddGoal(customerID string, input GoalInput) (*models.Goal, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	known := false
+	for _, account := range data.Accounts {
+		known = known || account.ID == input.AccountID
+	}
+	if !known {
+		return nil, NewValidationError("accountId", "unknown account: %s", input.AccountID)
+	}
+	goal, err := m.goals.Add(customerID, input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, progress := range m.goals.Goals(customerID, m.reportingAccounts(customerID, data)) {
+		if progress.ID == goal.ID {
+			return &progress, nil
+		}
+	}
+	return goal, nil
+}
+
+// DeleteGoal removes a savings goal
+func (m *MockDataService) DeleteGoal(customerID string, goalID string) error {
+	return m.goals.Delete(customerID, goalID)
+}
+
+// GetNotificationPreferences returns how a customer wants to be notified
+func (m *MockDataService) GetNotificationPreferences(customerID string) (*models.NotificationPreferences, error) {
+	if _, exists := m.customers[customerID]; !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	preferences := m.notifier.Preferences(customerID)
+	return &preferences, nil
+}
+
+// SetNotificationPreferences replaces how a customer wants to be notified
+func (m *MockDataService) SetNotificationPreferences(customerID string, preferences models.NotificationPreferences) (*models.NotificationPreferences, error) {
+	if _, exists := m.customers[customerID]; !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	return m.notifier.SetPreferences(customerID, preferences)
+}
+
+// RememberBudgets keeps the budgets a customer sent for insights or a report
+// for their budget notifications, until they set budgets of their own
+func (m *MockDataService) RememberBudgets(customerID string, budgets map[string]float64) {
+	if _, exists := m.customers[customerID]; exists {
+		m.notifier.RememberBudgets(customerID, budgets)
+	}
+}
+
+// GetNotifications returns a customer's in-app notifications, newest first
+func (m *MockDataService) GetNotifications(customerID string, unreadOnly bool) ([]models.Notification, error) {
+	if _, exists := m.customers[customerID]; !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	return m.notifier.Inbox().List(customerID, unreadOnly), nil
+}
+
+// MarkNotificationRead marks an in-app notification as read
+func (m *MockDataService) MarkNotificationRead(customerID string, notificationID string) (*models.Notification, error) {
+	return m.notifier.Inbox().MarkRead(customerID, notificationID)
+}
+
+// EvaluateNotifications checks a customer's budgets, transactions, balances,
+// bills and goals and publishes an event for each new condition worth
+// notifying about. It returns the events published.
+func (m *MockDataService) EvaluateNotifications(customerID string) ([]models.Event, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	now := time.Now()
+	preferences := m.notifier.Preferences(customerID)
+	bills, err := m.GetBillCalendar(customerID, startOfDay(now), startOfDay(now).AddDate(0, 0, preferences.BillReminderDays+1))
+	if err != nil {
+		return nil, err
+	}
+
+	events := EvaluateNotificationEvents(NotificationSnapshot{
+		Accounts:     m.reportingAccounts(customerID, data),
+		Transactions: m.reportingTransactions(customerID, data),
+		Bills:        bills,
+		Goals:        m.goals.Goals(customerID, m.reportingAccounts(customerID, data)),
+		Preferences:  preferences,
+		Money:        m.currency.Formatter(customerID),
+		Now:          now,
+	})
+	published := make([]models.Event, 0, len(events))
+	for _, event := range events {
+		if event, ok := m.events.PublishOnce(event); ok {
+			published = append(published, event)
+		}
+	}
+	return published, nil
+}
+
+// subscribeNotificationChecks re-checks a demo customer for notifications
+// whenever one of their transactions is created, so budgets crossed by a
+// purchase notify straight away rather than when someone next asks
+func (m *MockDataService) subscribeNotificationChecks() {
+	m.events.Subscribe(EventTransactionCreated, func(event models.Event) {
+		if !m.HasCustomer(event.CustomerID) {
+			return
+		}
+		if _, err := m.EvaluateNotifications(event.CustomerID); err != nil {
+			fmt.Printf("Notification Error: %v\n", err)
+		}
+	})
+}
+
+// SendTestNotification sends a test notification on every channel the
+// customer has turned on
+func (m *MockDataService) SendTestNotification(customerID string) (*models.Notification, error) {
+	if _, exists := m.customers[customerID]; !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	event := m.events.Publish(models.Event{
+		Type:       EventNotificationTest,
+		CustomerID: customerID,
+		Title:      "FinSights test notification",
+		Message:    "Notifications are set up. You'll hear from us here when something needs your attention.",
+	})
+	return m.notifier.HandleAndWait(event)
+}
+
+// GenerateInsights generates a customer's spending insights against
+// budgetData. Insights are built from the transactions alone, so they're
+// cached until the transactions change or different budgets are asked for.
+func (m *MockDataService) GenerateInsights(ai *OpenAIService, customerID string, budgetData map[string]float64) ([]models.SpendingInsight, error) {
+	dashboardData, err := m.GetDashboardData(customerID)
+	if err != nil {
+		return nil, err
+	}
+	return cached(m.cache, CacheNamespaceInsights, customerID, []string{cacheHash(budgetData)}, insightCacheTTL, func() ([]models.SpendingInsight, error) {
+		money := NewMoneyFormatter(dashboardData.Currency, dashboardData.Locale)
+		return ai.GenerateInsights(dashboardData.Transactions, dashboardData.SpendingData.TotalMonthlySpend, budgetData, money)
+	})
+}
+
+// PublishInsights tells subscribers that new insights were generated for a customer
+func (m *MockDataService) PublishInsights(customerID string, insights []models.SpendingInsight) models.Event {
+	titles := make([]string, 0, len(insights))
+	for _, insight := range insights {
+		titles = append(titles, insight.Title)
+	}
+	return m.events.Publish(models.Event{
+		Type:       EventInsightGenerated,
+		CustomerID: customerID,
+		Title:      "New spending insights",
+		Message:    fmt.Sprintf("%d new insights are ready.", len(insights)),
+		Data:       map[string]interface{}{"count": len(insights), "titles": titles, "insights": insights},
+	})
+}
+
+// SaveInsights keeps a customer's newly generated insights as their latest
+func (m *MockDataService) SaveInsights(customerID string, insights []models.SpendingInsight, source string) models.InsightSnapshot {
+	snapshot := models.InsightSnapshot{
+		CustomerID:  customerID,
+		Insights:    insights,
+		GeneratedAt: time.Now(),
+		Source:      source,
+	}
+	m.insights.Save(snapshot)
+	return snapshot
+}
+
+// GetLatestInsights returns the insights last generated for a customer
+func (m *MockDataService) GetLatestInsights(customerID string) (*models.InsightSnapshot, error) {
+	if _, exists := m.customers[customerID]; !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	snapshot, exists := m.insights.Latest(customerID)
+	if !exists {
+		return nil, NewNotFoundError("no insights generated yet for %s", customerID)
+	}
+	return snapshot, nil
+}
+
+// SendWeeklyDigest publishes a customer's weekly digest. It reports false if
+// this week's digest was already sent.
+func (m *MockDataService) SendWeeklyDigest(customerID string) (bool, error) {
+	data, exists := m.customers[customerID]
+	if !exists {
+		return false, NewNotFoundError("customer not found: %s", customerID)
+	}
+	now := time.Now()
+	bills, err := m.GetBillCalendar(customerID, startOfDay(now), startOfDay(now).AddDate(0, 0, 7))
+	if err != nil {
+		return false, err
+	}
+	alerts, err := m.GetAlerts(customerID, false)
+	if err != nil {
+		return false, err
+	}
+	event := WeeklyDigestEvent(customerID, m.reportingTransactions(customerID, data), bills, alerts, m.currency.Formatter(customerID), now)
+	_, published := m.events.PublishOnce(event)
+	return published, nil
+}
+
+// GetWebhooks returns a customer's webhook subscriptions
+func (m *MockDataService) GetWebhooks(customerID string) ([]models.WebhookSubscription, error) {
+	if _, exists := m.customers[customerID]; !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	return m.webhooks.Subscriptions(customerID), nil
+}
+
+// GetWebhook returns one of a customer's webhook subscriptions
+func (m *MockDataService) GetWebhook(customerID string, subscriptionID string) (*models.WebhookSubscription, error) {
+	return m.webhooks.Subscription(customerID, subscriptionID)
+}
+
+// AddWebhook subscribes a URL to a customer's events
+func (m *MockDataService) AddWebhook(customerID string, input WebhookInput) (*models.WebhookSubscription, error) {
+	if _, exists := m.customers[customerID]; !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	return m.webhooks.Subscribe(customerID, input, time.Now())
+}
+
+// UpdateWebhook changes a webhook subscription
+func (m *MockDataService) UpdateWebhook(customerID string, subscriptionID string, input WebhookInput) (*models.WebhookSubscription, error) {
+	return m.webhooks.Update(customerID, subscriptionID, input)
+}
+
+// DeleteWebhook removes a webhook subscription
+func (m *MockDataService) DeleteWebhook(customerID string, subscriptionID string) error {
+	return m.webhooks.Unsubscribe(customerID, subscriptionID)
+}
+
+// GetWebhookDeliveries returns a webhook's delivery log, newest first
+func (m *MockDataService) GetWebhookDeliveries(customerID string, subscriptionID string, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := m.webhooks.Subscription(customerID, subscriptionID); err != nil {
+		return nil, err
+	}
+	return m.webhooks.Deliveries(customerID, subscriptionID, limit), nil
+}
+
+// SendTestWebhook sends a test event to a webhook
+func (m *MockDataService) SendTestWebhook(customerID string, subscriptionID string) (*models.WebhookDelivery, error) {
+	return m.webhooks.SendTest(customerID, subscriptionID, time.Now())
+}
+
+// GetWebhookDeadLetters returns a customer's webhook deliveries that failed for good
+func (m *MockDataService) GetWebhookDeadLetters(customerID string) ([]models.WebhookDelivery, error) {
+	if _, exists := m.customers[customerID]; !exists {
+		return nil, NewNotFoundError("customer not found: %s", customerID)
+	}
+	return m.webhooks.DeadLetters(customerID), nil
+}
+
+// GetWebhookDelivery returns one of a customer's webhook deliveries
+func (m *MockDataService) GetWebhookDelivery(customerID string, deliveryID string) (*models.WebhookDelivery, error) {
+	return m.webhooks.Delivery(customerID, deliveryID)
+}
+
+// ReplayWebhookDelivery sends a dead-lettered webhook delivery again
+func (m *MockDataService) ReplayWebhookDelivery(customerID string, deliveryID string) (*models.WebhookDelivery, error) {
+	return m.webhooks.Replay(customerID, deliveryID)
 }
 
 // GetCustomerByCredentials validates username and password
@@ -508,7 +1533,24 @@ func (m *MockDataService) GetCustomerByCredentials(username, password string) (*
 			return &data.Customer, nil
 		}
 	}
-	return nil, fmt.Errorf("invalid credentials")
+	return nil, newUnauthorizedError("Username or password not found, try again")
+}
+
+// HasCustomer reports whether customerID is one of the demo customers
+func (m *MockDataService) HasCustomer(customerID string) bool {
+	_, exists := m.customers[customerID]
+	return exists
+}
+
+// InvalidateCache drops everything cached for a customer and returns how
+// many entries were removed
+func (m *MockDataService) InvalidateCache(customerID string) int {
+	return m.cache.Invalidate(customerID)
+}
+
+// GetCacheStats returns the response cache's hit and miss counts
+func (m *MockDataService) GetCacheStats() models.CacheStats {
+	return m.cache.Stats()
 }
 
 // GetAvailableCustomers returns list of available demo customers
@@ -0,0 +1,441 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Money movement kinds
+const (
+	MovementTransfer   = "transfer"
+	MovementDeposit    = "deposit"
+	MovementWithdrawal = "withdrawal"
+	MovementPurchase   = "purchase"
+)
+
+// Money movement media. Balance movements move money; rewards movements move
+// rewards points and don't show up as transactions.
+const (
+	MovementMediumBalance = "balance"
+	MovementMediumRewards = "rewards"
+)
+
+const (
+	// MaxMovementAmount caps a single transfer, deposit, withdrawal or purchase
+	MaxMovementAmount = 1000000
+	// maxMovementDescription caps descriptions at what Nessie accepts
+	maxMovementDescription = 255
+	// maxMovementFutureDays is how far ahead a movement can be dated
+	maxMovementFutureDays = 365
+)
+
+var (
+	// ErrUnknownAccount is returned when an account isn't one of the customer's
+	ErrUnknownAccount = newKindError(ErrNotFound, "unknown account")
+	// ErrUnknownMerchant is returned when a purchase names a merchant that doesn't exist
+	ErrUnknownMerchant = newKindError(ErrValidation, "unknown merchant")
+	// ErrInsufficientFunds is returned when a movement would overdraw a
+	// checking or savings account
+	ErrInsufficientFunds = newKindError(ErrValidation, "insufficient funds")
+	// ErrCurrencyMismatch is returned for transfers between accounts in
+	// different currencies, which would need a conversion we don't offer
+	ErrCurrencyMismatch = newKindError(ErrValidation, "accounts are in different currencies")
+)
+
+// MovementInput describes a transfer, deposit, withdrawal or purchase.
+// AccountID is the account money leaves, or arrives in for deposits.
+type MovementInput struct {
+	AccountID   string  `json:"-"`
+	PayeeID     string  `json:"payeeId"`
+	MerchantID  string  `json:"merchantId"`
+	Medium      string  `json:"medium"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+	Date        string  `json:"date"`
+}
+
+// ValidateMovement checks a movement of the given kind and fills in its
+// defaults: the balance medium and today's date
+func ValidateMovement(kind string, input *MovementInput) error {
+	input.AccountID = strings.TrimSpace(input.AccountID)
+	input.PayeeID = strings.TrimSpace(input.PayeeID)
+	input.MerchantID = strings.TrimSpace(input.MerchantID)
+	input.Medium = strings.ToLower(strings.TrimSpace(input.Medium))
+	input.Description = strings.TrimSpace(input.Description)
+	input.Date = strings.TrimSpace(input.Date)
+
+	switch kind {
+	case MovementTransfer:
+		if input.PayeeID == "" {
+			return NewValidationError("payeeId", "payeeId required")
+		}
+		if input.PayeeID == input.AccountID {
+			return NewValidationError("payeeId", "payeeId must be a different account")
+		}
+	case MovementPurchase:
+		if input.MerchantID == "" {
+			return NewValidationError("merchantId", "merchantId required")
+		}
+	case MovementDeposit, MovementWithdrawal:
+	default:
+		return NewValidationError("", "unsupported movement: %s", kind)
+	}
+	if input.PayeeID != "" && kind != MovementTransfer {
+		return NewValidationError("payeeId", "payeeId only applies to transfers")
+	}
+	if input.MerchantID != "" && kind != MovementPurchase {
+		return NewValidationError("merchantId", "merchantId only applies to purchases")
+	}
+	if input.AccountID == "" {
+		return NewValidationError("accountId", "accountId required")
+	}
+
+	if input.Medium == "" {
+		input.Medium = MovementMediumBalance
+	}
+	if input.Medium != MovementMediumBalance && input.Medium != MovementMediumRewards {
+		return NewValidationError("medium", "medium must be %s or %s", MovementMediumBalance, MovementMediumRewards)
+	}
+
+	if input.Amount <= 0 || math.IsInf(input.Amount, 0) || math.IsNaN(input.Amount) {
+		return NewValidationError("amount", "amount must be greater than 0")
+	}
+	if input.Amount > MaxMovementAmount {
+		return NewValidationError("amount", "amount must be at most %d", MaxMovementAmount)
+	}
+	// Rewards are whole points. Money keeps its cents on the transactions, as
+	// bill payments do; only the whole-dollar balance is rounded.
+	if input.Medium == MovementMediumRewards {
+		if input.Amount != math.Trunc(input.Amount) {
+			return NewValidationError("amount", "rewards amounts must be whole points")
+		}
+	} else if input.Amount = roundCents(input.Amount); input.Amount == 0 {
+		return NewValidationError("amount", "amount must be at least 0.01")
+	}
+
+	now := time.Now()
+	if input.Date == "" {
+		input.Date = now.Format(BillDateLayout)
+	}
+	date, err := time.Parse(BillDateLayout, input.Date)
+	if err != nil {
+		return NewValidationError("date", "date must be in YYYY-MM-DD format")
+	}
+	if date.After(now.AddDate(0, 0, maxMovementFutureDays)) {
+		return NewValidationError("date", "date can be at most %d days ahead", maxMovementFutureDays)
+	}
+
+	if len(input.Description) > maxMovementDescription {
+		return NewValidationError("description", "description must be at most %d characters", maxMovementDescription)
+	}
+	return nil
+}
+
+// BillPaymentInput describes a bill to pay from an account, either once on
+// PaymentDate or every month on RecurringDate
+type BillPaymentInput struct {
+	AccountID     string  `json:"-"`
+	Payee         string  `json:"payee"`
+	Nickname      string  `json:"nickname"`
+	Amount        float64 `json:"amount"`
+	PaymentDate   string  `json:"paymentDate"`
+	RecurringDate int     `json:"recurringDate"`
+}
+
+// ValidateBillPayment checks a bill payment and fills in today as its
+// payment date if none was given
+func ValidateBillPayment(input *BillPaymentInput) error {
+	input.AccountID = strings.TrimSpace(input.AccountID)
+	input.Payee = strings.TrimSpace(input.Payee)
+	input.Nickname = strings.TrimSpace(input.Nickname)
+	input.PaymentDate = strings.TrimSpace(input.PaymentDate)
+
+	if input.AccountID == "" {
+		return NewValidationError("accountId", "accountId required")
+	}
+	if input.Payee == "" {
+		return NewValidationError("payee", "payee required")
+	}
+	if len(input.Payee) > maxMovementDescription || len(input.Nickname) > maxMovementDescription {
+		return NewValidationError("payee", "payee and nickname must be at most %d characters", maxMovementDescription)
+	}
+	if input.Amount <= 0 || math.IsInf(input.Amount, 0) || math.IsNaN(input.Amount) {
+		return NewValidationError("amount", "amount must be greater than 0")
+	}
+	if input.Amount > MaxMovementAmount {
+		return NewValidationError("amount", "amount must be at most %d", MaxMovementAmount)
+	}
+	input.Amount = roundCents(input.Amount)
+	if input.RecurringDate < 0 || input.RecurringDate > 31 {
+		return NewValidationError("recurringDate", "recurringDate must be a day of the month from 1 to 31, or 0 for a one-time payment")
+	}
+	if input.PaymentDate == "" {
+		input.PaymentDate = time.Now().Format(BillDateLayout)
+	}
+	if _, err := time.Parse(BillDateLayout, input.PaymentDate); err != nil {
+		return NewValidationError("paymentDate", "paymentDate must be in YYYY-MM-DD format")
+	}
+	return nil
+}
+
+// billPaymentFrequency is how often a bill payment repeats
+func billPaymentFrequency(input BillPaymentInput) string {
+	if input.RecurringDate > 0 {
+		return "monthly"
+	}
+	return "once"
+}
+
+// findAccount returns the account with the given ID
+func findAccount(accounts []models.Account, accountID string) (models.Account, bool) {
+	for _, account := range accounts {
+		if account.ID == accountID {
+			return account, true
+		}
+	}
+	return models.Account{}, false
+}
+
+// checkMovementAccounts checks that a movement's accounts are the customer's.
+// Transfers only move money between the customer's own accounts.
+func checkMovementAccounts(accounts []models.Account, input MovementInput) error {
+	from, exists := findAccount(accounts, input.AccountID)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownAccount, input.AccountID)
+	}
+	if input.PayeeID != "" {
+		to, exists := findAccount(accounts, input.PayeeID)
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrUnknownAccount, input.PayeeID)
+		}
+		if accountCurrency(from) != accountCurrency(to) {
+			return fmt.Errorf("%w: %s is in %s, %s is in %s", ErrCurrencyMismatch, from.Nickname, accountCurrency(from), to.Nickname, accountCurrency(to))
+		}
+	}
+	return nil
+}
+
+// accountCurrency returns the currency an account is in
+func accountCurrency(account models.Account) string {
+	if account.Currency == "" {
+		return DefaultCurrency
+	}
+	return account.Currency
+}
+
+// movementTransactions returns the transactions a balance movement shows up
+// as: one for each account it touches. Rewards movements have none.
+func movementTransactions(movement models.MoneyMovement, accounts []models.Account, merchant *MerchantEntry) []models.Transaction {
+	if movement.Medium != MovementMediumBalance {
+		return nil
+	}
+	// Movements dated today happen now; others post at the start of their day
+	date := movement.CreatedAt
+	if movement.Date != movement.CreatedAt.Format(BillDateLayout) {
+		if parsed, err := time.ParseInLocation(BillDateLayout, movement.Date, movement.CreatedAt.Location()); err == nil {
+			date = parsed
+		}
+	}
+	transaction := models.Transaction{
+		ID:              movement.ID,
+		Type:            movement.Kind,
+		Description:     movement.Description,
+		TransactionDate: date,
+		Status:          "completed",
+		AccountID:       movement.AccountID,
+	}
+	if account, exists := findAccount(accounts, movement.AccountID); exists {
+		transaction.Currency = accountCurrency(account)
+	}
+	if date.After(movement.CreatedAt) {
+		transaction.Status = "pending"
+	}
+
+	switch movement.Kind {
+	case MovementDeposit:
+		transaction.Amount = movement.Amount
+		if transaction.Description == "" {
+			transaction.Description = "Deposit"
+		}
+		return []models.Transaction{transaction}
+	case MovementWithdrawal:
+		transaction.Amount = -movement.Amount
+		if transaction.Description == "" {
+			transaction.Description = "Withdrawal"
+		}
+		return []models.Transaction{transaction}
+	case MovementPurchase:
+		transaction.Amount = -movement.Amount
+		transaction.MerchantID = movement.MerchantID
+		if merchant != nil {
+			transaction.Merchant = models.Merchant{
+				ID:       merchant.ID,
+				Name:     merchant.Name,
+				Category: merchant.Category,
+				LogoKey:  merchant.LogoKey,
+			}
+			if transaction.Description == "" {
+				transaction.Description = merchant.Name
+			}
+		}
+		if transaction.Description == "" {
+			transaction.Description = "Purchase"
+		}
+		return []models.Transaction{transaction}
+	case MovementTransfer:
+		from, _ := findAccount(accounts, movement.AccountID)
+		to, _ := findAccount(accounts, movement.PayeeID)
+		transferType := TransferTypeInternal
+		if isCreditCardAccount(to.Type) {
+			transferType = TransferTypeCreditCardPayment
+		}
+
+		// The outflow keeps the movement's ID, as Nessie lists it on the payer
+		out := transaction
+		out.Amount = -movement.Amount
+		if out.Description == "" {
+			out.Description = "Transfer to " + to.Nickname
+		}
+		in := transaction
+		in.ID = movement.ID + "-in"
+		in.Amount = movement.Amount
+		in.AccountID = movement.PayeeID
+		if in.Description == "" {
+			in.Description = "Transfer from " + from.Nickname
+		}
+		markTransfer(&out, transferType, in.ID)
+		markTransfer(&in, transferType, out.ID)
+		return []models.Transaction{out, in}
+	}
+	return nil
+}
+
+// ledgerEntry is a recorded movement and the transactions it created
+type ledgerEntry struct {
+	movement     models.MoneyMovement
+	transactions []models.Transaction
+}
+
+// LedgerStore records the movements made on demo accounts, so their balances
+// and transactions reflect them without touching the seed data
+type LedgerStore struct {
+	mu      sync.RWMutex
+	entries map[string][]ledgerEntry
+	nextID  int
+}
+
+// defaultLedgerStore is shared so movements made through one route show up
+// in every other
+var defaultLedgerStore = NewLedgerStore()
+
+// NewLedgerStore creates an empty ledger
+func NewLedgerStore() *LedgerStore {
+	return &LedgerStore{
+		entries: make(map[string][]ledgerEntry),
+	}
+}
+
+// accountDeltas sums how much a customer's movements changed each account's
+// balance and rewards. Callers hold s.mu.
+func (s *LedgerStore) accountDeltas(customerID string) (map[string]float64, map[string]int) {
+	balances := make(map[string]float64)
+	rewards := make(map[string]int)
+	for _, entry := range s.entries[customerID] {
+		movement := entry.movement
+		if movement.Medium == MovementMediumRewards {
+			points := int(movement.Amount)
+			switch movement.Kind {
+			case MovementDeposit:
+				rewards[movement.AccountID] += points
+			case MovementTransfer:
+				rewards[movement.AccountID] -= points
+				rewards[movement.PayeeID] += points
+			default:
+				rewards[movement.AccountID] -= points
+			}
+			continue
+		}
+		for _, transaction := range entry.transactions {
+			balances[transaction.AccountID] += transaction.Amount
+		}
+	}
+	return balances, rewards
+}
+
+// applyDeltas adjusts accounts by the changes from accountDeltas. Balances
+// are whole dollars, as in Nessie, so the movements' cents are rounded off
+// their total rather than each one.
+func applyDeltas(accounts []models.Account, balances map[string]float64, rewards map[string]int) []models.Account {
+	result := make([]models.Account, len(accounts))
+	copy(result, accounts)
+	for i := range result {
+		result[i].Balance += int(math.Round(balances[result[i].ID]))
+		result[i].Rewards += rewards[result[i].ID]
+	}
+	return result
+}
+
+// Record checks that a movement doesn't overdraw a checking or savings
+// account, or spend more rewards than the account has, and records it. The
+// ID it's given is filled in on the movement and its transactions.
+func (s *LedgerStore) Record(accounts []models.Account, movement models.MoneyMovement, merchant *MerchantEntry) (models.MoneyMovement, []models.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if movement.Kind != MovementDeposit {
+		balances, rewards := s.accountDeltas(movement.CustomerID)
+		account, _ := findAccount(accounts, movement.AccountID)
+		if movement.Medium == MovementMediumRewards {
+			if available := account.Rewards + rewards[account.ID]; float64(available) < movement.Amount {
+				return movement, nil, fmt.Errorf("%w: %s has %d rewards points", ErrInsufficientFunds, account.Nickname, available)
+			}
+		} else if ClassifyAccount(account.Type) == AccountClassAsset {
+			if available := float64(account.Balance) + balances[account.ID]; available < movement.Amount {
+				return movement, nil, fmt.Errorf("%w: %s has %s available", ErrInsufficientFunds, account.Nickname, NewMoneyFormatter(accountCurrency(account), DefaultLocale).Format(available))
+			}
+		}
+	}
+
+	s.nextID++
+	movement.ID = fmt.Sprintf("mov%d", s.nextID)
+	if movement.Status == "" {
+		movement.Status = "executed"
+	}
+	transactions := movementTransactions(movement, accounts, merchant)
+	s.entries[movement.CustomerID] = append(s.entries[movement.CustomerID], ledgerEntry{movement: movement, transactions: transactions})
+	return movement, transactions, nil
+}
+
+// ApplyBalances returns a copy of a customer's accounts with the balances and
+// rewards their movements left them
+func (s *LedgerStore) ApplyBalances(customerID string, accounts []models.Account) []models.Account {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.entries[customerID]) == 0 {
+		return accounts
+	}
+	balances, rewards := s.accountDeltas(customerID)
+	return applyDeltas(accounts, balances, rewards)
+}
+
+// ApplyTransactions returns a customer's transactions followed by the ones
+// their movements created
+func (s *LedgerStore) ApplyTransactions(customerID string, transactions []models.Transaction) []models.Transaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := s.entries[customerID]
+	if len(entries) == 0 {
+		return transactions
+	}
+	result := make([]models.Transaction, len(transactions), len(transactions)+2*len(entries))
+	copy(result, transactions)
+	for _, entry := range entries {
+		result = append(result, entry.transactions...)
+	}
+	return result
+}
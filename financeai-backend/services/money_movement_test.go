@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"financeai-backend/models"
+)
+
+func TestValidateMovementAmounts(t *testing.T) {
+	tests := []struct {
+		name   string
+		medium string
+		amount float64
+		valid  bool
+	}{
+		{"whole dollars", MovementMediumBalance, 100, true},
+		{"cents", MovementMediumBalance, 100.50, true},
+		{"a cent", MovementMediumBalance, 0.01, true},
+		{"under a cent", MovementMediumBalance, 0.004, false},
+		{"whole points", MovementMediumRewards, 250, true},
+		{"fractional points", MovementMediumRewards, 2.5, false},
+		{"zero", MovementMediumBalance, 0, false},
+		{"negative", MovementMediumBalance, -5, false},
+		{"over the cap", MovementMediumBalance, MaxMovementAmount + 1, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := MovementInput{AccountID: "acc1", Medium: test.medium, Amount: test.amount}
+			err := ValidateMovement(MovementWithdrawal, &input)
+			if test.valid && err != nil {
+				t.Errorf("ValidateMovement returned %v, want nil", err)
+			}
+			if !test.valid && !errors.Is(err, ErrValidation) {
+				t.Errorf("ValidateMovement returned %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+func TestValidateMovementFillsDefaults(t *testing.T) {
+	input := MovementInput{AccountID: " acc1 ", Amount: 20}
+	if err := ValidateMovement(MovementDeposit, &input); err != nil {
+		t.Fatal(err)
+	}
+	if input.AccountID != "acc1" || input.Medium != MovementMediumBalance || input.Date != time.Now().Format(BillDateLayout) {
+		t.Errorf("validated input = %+v, want acc1 in balance today", input)
+	}
+}
+
+func TestLedgerStoreKeepsCents(t *testing.T) {
+	ledger := NewLedgerStore()
+	accounts := []models.Account{{ID: "acc1", Type: "Checking", Balance: 100}}
+	now := time.Now()
+	for _, amount := range []float64{0.40, 0.40, 0.45} {
+		movement := models.MoneyMovement{Kind: MovementDeposit, CustomerID: "c1", AccountID: "acc1", Medium: MovementMediumBalance, Amount: amount, Date: now.Format(BillDateLayout), CreatedAt: now}
+		if _, _, err := ledger.Record(accounts, movement, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	transactions := ledger.ApplyTransactions("c1", nil)
+	if len(transactions) != 3 || transactions[2].Amount != 0.45 {
+		t.Errorf("transactions = %+v, want three with their cents", transactions)
+	}
+	// $1.25 of deposits rounds to a dollar, where rounding each would add nothing
+	if balance := ledger.ApplyBalances("c1", accounts)[0].Balance; balance != 101 {
+		t.Errorf("balance = %d, want 101", balance)
+	}
+}
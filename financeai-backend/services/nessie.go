@@ -1,10 +1,19 @@
 package services
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"financeai-backend/models"
@@ -12,9 +21,40 @@ import (
 
 // NessieService handles all interactions with the Nessie API
 type NessieService struct {
-	APIKey  string
-	BaseURL string
-	Client  *http.Client
+	APIKey      string
+	BaseURL     string
+	Client      *http.Client
+	Merchants   *MerchantRegistry
+	Splits      *SplitStore
+	Annotations *AnnotationStore
+	Transfers   *TransferDetector
+	NetWorth    *NetWorthStore
+
+	// Concurrency caps how many accounts are fetched at once
+	Concurrency int
+	// CallTimeout bounds each attempt at a request to Nessie, so a hung
+	// attempt leaves time to retry. The caller's context bounds the whole
+	// call, retries included.
+	CallTimeout time.Duration
+	// Retry controls retries of rate-limited and failed requests
+	Retry RetryPolicy
+	// Cache holds responses so repeat loads don't call Nessie again. Nil
+	// disables caching.
+	Cache *ResponseCache
+	// Events is told about transactions created through the API
+	Events *EventBus
+	// Decoding collects records Nessie returned that couldn't be decoded
+	Decoding *NessieDecodeReport
+	// Currency converts amounts to each customer's reporting currency
+	Currency *CurrencyService
+}
+
+// DefaultNessieRetryPolicy retries a request twice, waiting about a quarter
+// second and then half a second
+var DefaultNessieRetryPolicy = RetryPolicy{
+	MaxAttempts:  3,
+	InitialDelay: 250 * time.Millisecond,
+	MaxDelay:     2 * time.Second,
 }
 
 // NewNessieService creates a new Nessie service instance
@@ -25,178 +65,666 @@ func NewNessieService(apiKey string) *NessieService {
 		Client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		Merchants:   defaultMerchantRegistry,
+		Splits:      defaultSplitStore,
+		Annotations: defaultAnnotationStore,
+		Transfers:   NewTransferDetector(),
+		NetWorth:    defaultNetWorthStore,
+		Concurrency: 4,
+		CallTimeout: 10 * time.Second,
+		Retry:       DefaultNessieRetryPolicy,
+		Cache:       defaultResponseCache,
+		Events:      defaultEventBus,
+		Decoding:    defaultNessieDecodeReport,
+		Currency:    defaultCurrencyService,
 	}
 }
 
-// GetCustomer fetches customer information by ID
-func (n *NessieService) GetCustomer(customerID string) (*models.Customer, error) {
-	url := fmt.Sprintf("%s/enterprise/customers/%s?key=%s", n.BaseURL, customerID, n.APIKey)
-	
-	resp, err := n.Client.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch customer: %v", err)
-	}
-	defer resp.Body.Close()
+// NessieAPIError is a Nessie response with an unexpected status code, with
+// the explanation from its body when it has one
+type NessieAPIError struct {
+	StatusCode int
+	Message    string
+	// Culprit lists the request fields Nessie rejected
+	Culprit []string
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+// Unwrap reports a 404 as ErrNotFound and any other status as ErrUpstream
+func (e *NessieAPIError) Unwrap() error {
+	if e.StatusCode == http.StatusNotFound {
+		return ErrNotFound
 	}
+	return ErrUpstream
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+func (e *NessieAPIError) Error() string {
+	message := fmt.Sprintf("API request failed with status: %d", e.StatusCode)
+	if e.Message != "" {
+		message += ": " + e.Message
+	}
+	if len(e.Culprit) > 0 {
+		message += " (" + strings.Join(e.Culprit, ", ") + ")"
 	}
+	return message
+}
 
-	var customer models.Customer
-	if err := json.Unmarshal(body, &customer); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal customer data: %v", err)
+// newNessieAPIError reads Nessie's error body, {"code", "message",
+// "culprit"}, into an error. Bodies that aren't in that shape leave just the
+// status code.
+func newNessieAPIError(statusCode int, body []byte) *NessieAPIError {
+	apiErr := &NessieAPIError{StatusCode: statusCode}
+	var errorBody struct {
+		Message string          `json:"message"`
+		Culprit json.RawMessage `json:"culprit"`
+	}
+	if err := json.Unmarshal(body, &errorBody); err == nil {
+		apiErr.Message = errorBody.Message
+		apiErr.Culprit = stringList(errorBody.Culprit)
 	}
+	return apiErr
+}
 
-	return &customer, nil
+// retryableStatus reports whether a Nessie status code is worth retrying
+func retryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
 }
 
-// GetCustomerAccounts fetches all accounts for a customer
-func (n *NessieService) GetCustomerAccounts(customerID string) ([]models.Account, error) {
-	url := fmt.Sprintf("%s/enterprise/customers/%s/accounts?key=%s", n.BaseURL, customerID, n.APIKey)
-	
-	resp, err := n.Client.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch accounts: %v", err)
+// jitter spreads a backoff delay over [delay/2, delay) so clients that failed
+// together don't all retry at the same moment
+func jitter(delay time.Duration) time.Duration {
+	if delay <= 1 {
+		return delay
 	}
-	defer resp.Body.Close()
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
-	}
+// get fetches a Nessie path and decodes the JSON response into out. Each
+// attempt gets its own deadline of CallTimeout. Network errors, timeouts,
+// 429s and 5xx responses are retried with jittered exponential backoff,
+// honoring Retry-After. It returns how many attempts were made.
+func (n *NessieService) get(ctx context.Context, path string, out interface{}) (int, error) {
+	return n.do(ctx, http.MethodGet, path, nil, out)
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+// post sends body to a Nessie path and decodes the JSON response into out.
+// Nessie may have applied a write that failed with a network error or 5xx, so
+// only 429s, which it rejects before doing anything, are retried.
+func (n *NessieService) post(ctx context.Context, path string, body interface{}, out interface{}) (int, error) {
+	return n.do(ctx, http.MethodPost, path, body, out)
+}
+
+// do makes a request to Nessie, retrying as described on get and post
+func (n *NessieService) do(ctx context.Context, method string, path string, body interface{}, out interface{}) (int, error) {
+	endpoint := fmt.Sprintf("%s%s?key=%s", n.BaseURL, path, url.QueryEscape(n.APIKey))
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return 0, fmt.Errorf("failed to encode request: %v", err)
+		}
 	}
+	write := method != http.MethodGet
 
-	var nessieResp models.NessieResponse
-	if err := json.Unmarshal(body, &nessieResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal accounts response: %v", err)
+	policy := n.Retry
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
 	}
+	var lastErr error
+	var retryAfter time.Duration
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			delay := jitter(policy.Backoff(attempt))
+			if retryAfter > delay {
+				delay = retryAfter
+			}
+			if delay > policy.MaxDelay && policy.MaxDelay > 0 {
+				delay = policy.MaxDelay
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return attempt - 1, fmt.Errorf("%w (gave up: %v)", lastErr, ctx.Err())
+			case <-timer.C:
+			}
+		}
 
-	// Convert results to Account slice
-	var accounts []models.Account
-	for _, result := range nessieResp.Results {
-		accountBytes, err := json.Marshal(result)
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if n.CallTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, n.CallTimeout)
+		}
+		var reqBody io.Reader
+		if payload != nil {
+			reqBody = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(attemptCtx, method, endpoint, reqBody)
+		if err != nil {
+			cancel()
+			return attempt, fmt.Errorf("failed to create request: %v", err)
+		}
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := n.Client.Do(req)
 		if err != nil {
+			cancel()
+			// Report the cause without the URL, which carries the API key
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			lastErr = newUpstreamError("request failed: %v", err)
+			if ctx.Err() != nil || write {
+				return attempt, lastErr
+			}
+			retryAfter = 0
 			continue
 		}
-		
-		var account models.Account
-		if err := json.Unmarshal(accountBytes, &account); err != nil {
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		cancel()
+		if resp.StatusCode != http.StatusOK && !(write && resp.StatusCode == http.StatusCreated) {
+			lastErr = newNessieAPIError(resp.StatusCode, respBody)
+			if write && resp.StatusCode != http.StatusTooManyRequests || !retryableStatus(resp.StatusCode) {
+				return attempt, lastErr
+			}
+			retryAfter = 0
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+				retryAfter = time.Duration(seconds) * time.Second
+			}
 			continue
 		}
-		
-		accounts = append(accounts, account)
+		if err != nil {
+			lastErr = newUpstreamError("failed to read response body: %v", err)
+			if write {
+				return attempt, lastErr
+			}
+			continue
+		}
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return attempt, newUpstreamError("failed to decode response: %v", err)
+		}
+		return attempt, nil
 	}
+	return policy.MaxAttempts, lastErr
+}
+
+// GetCustomer fetches customer information by ID
+func (n *NessieService) GetCustomer(ctx context.Context, customerID string) (*models.Customer, error) {
+	return cached(n.Cache, CacheNamespaceNessieCustomer, customerID, nil, nessieCustomerCacheTTL, func() (*models.Customer, error) {
+		var customer nessieCustomer
+		if _, err := n.get(ctx, fmt.Sprintf("/enterprise/customers/%s", url.PathEscape(customerID)), &customer); err != nil {
+			return nil, fmt.Errorf("failed to fetch customer: %w", err)
+		}
+		record, err := customer.record()
+		if err != nil {
+			return nil, newUpstreamError("failed to decode customer: %v", err)
+		}
+		return &record, nil
+	})
+}
 
+// GetCustomerAccounts fetches all accounts for a customer
+func (n *NessieService) GetCustomerAccounts(ctx context.Context, customerID string) ([]models.Account, error) {
+	return cached(n.Cache, CacheNamespaceNessieAccounts, customerID, nil, nessieDataCacheTTL, func() ([]models.Account, error) {
+		return n.fetchCustomerAccounts(ctx, customerID)
+	})
+}
+
+// fetchCustomerAccounts fetches a customer's accounts from Nessie
+func (n *NessieService) fetchCustomerAccounts(ctx context.Context, customerID string) ([]models.Account, error) {
+	accounts, _, err := getNessieList[nessieAccount](ctx, n, fmt.Sprintf("/enterprise/customers/%s/accounts", url.PathEscape(customerID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch accounts: %w", err)
+	}
 	return accounts, nil
 }
 
 // GetAccountTransactions fetches transactions for a specific account
-func (n *NessieService) GetAccountTransactions(accountID string) ([]models.Transaction, error) {
-	url := fmt.Sprintf("%s/enterprise/accounts/%s/transactions?key=%s", n.BaseURL, accountID, n.APIKey)
-	
-	resp, err := n.Client.Get(url)
+func (n *NessieService) GetAccountTransactions(ctx context.Context, accountID string) ([]models.Transaction, error) {
+	transactions, _, err := n.fetchAccountTransactions(ctx, accountID)
+	return transactions, err
+}
+
+// fetchAccountTransactions fetches an account's transactions and purchases
+// and reports how many attempts it took
+func (n *NessieService) fetchAccountTransactions(ctx context.Context, accountID string) ([]models.Transaction, int, error) {
+	transactions, attempts, err := getNessieList[nessieTransaction](ctx, n, fmt.Sprintf("/enterprise/accounts/%s/transactions", url.PathEscape(accountID)))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch transactions: %v", err)
+		return nil, attempts, fmt.Errorf("failed to fetch transactions: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+	// Purchases carry the merchant the transactions list leaves out
+	purchases, purchaseAttempts, err := n.fetchAccountPurchases(ctx, accountID)
+	attempts += purchaseAttempts
+	if err != nil {
+		return nil, attempts, err
 	}
+	return mergePurchases(transactions, purchases), attempts, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+// cachedAccountTransactions returns an account's transactions from the cache,
+// or fetches them, reporting how many attempts that took
+func (n *NessieService) cachedAccountTransactions(ctx context.Context, customerID string, accountID string) ([]models.Transaction, int, error) {
+	attempts := 0
+	transactions, err := cached(n.Cache, CacheNamespaceNessieTransactions, customerID, []string{accountID}, nessieDataCacheTTL, func() ([]models.Transaction, error) {
+		var transactions []models.Transaction
+		var err error
+		transactions, attempts, err = n.fetchAccountTransactions(ctx, accountID)
+		return transactions, err
+	})
+	return transactions, attempts, err
+}
+
+// fetchAllAccountTransactions fetches every account's transactions, at most
+// Concurrency at a time. Accounts that fail are reported rather than failing
+// the whole fetch, so callers can show what did load.
+func (n *NessieService) fetchAllAccountTransactions(ctx context.Context, customerID string, accounts []models.Account) ([]models.Transaction, []models.AccountFetchFailure) {
+	concurrency := n.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
 	}
 
-	var nessieResp models.NessieResponse
-	if err := json.Unmarshal(body, &nessieResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal transactions response: %v", err)
+	type accountResult struct {
+		transactions []models.Transaction
+		failure      *models.AccountFetchFailure
 	}
+	results := make([]accountResult, len(accounts))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, account := range accounts {
+		wg.Add(1)
+		go func(i int, accountID string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				results[i].failure = &models.AccountFetchFailure{AccountID: accountID, Error: ctx.Err().Error()}
+				return
+			}
+
+			transactions, attempts, err := n.cachedAccountTransactions(ctx, customerID, accountID)
+			if err != nil {
+				failure := &models.AccountFetchFailure{AccountID: accountID, Error: err.Error(), Attempts: attempts}
+				var apiErr *NessieAPIError
+				if errors.As(err, &apiErr) {
+					failure.StatusCode = apiErr.StatusCode
+				}
+				results[i].failure = failure
+				return
+			}
+			results[i].transactions = transactions
+		}(i, account.ID)
+	}
+	wg.Wait()
 
-	// Convert results to Transaction slice
+	// Keep account order so results are the same however the calls finished
 	var transactions []models.Transaction
-	for _, result := range nessieResp.Results {
-		transactionBytes, err := json.Marshal(result)
-		if err != nil {
-			continue
+	var failures []models.AccountFetchFailure
+	for _, result := range results {
+		transactions = append(transactions, result.transactions...)
+		if result.failure != nil {
+			failures = append(failures, *result.failure)
 		}
-		
-		var transaction models.Transaction
-		if err := json.Unmarshal(transactionBytes, &transaction); err != nil {
-			continue
-		}
-		
-		transactions = append(transactions, transaction)
 	}
-
-	return transactions, nil
+	return transactions, failures
 }
 
 // GetAllCustomerTransactions fetches all transactions for all customer accounts
-func (n *NessieService) GetAllCustomerTransactions(customerID string) ([]models.Transaction, error) {
+// matching filter. Accounts whose transactions couldn't be fetched are
+// returned alongside; it only fails outright if the accounts can't be listed
+// or every account failed.
+func (n *NessieService) GetAllCustomerTransactions(ctx context.Context, customerID string, filter models.TransactionFilter) ([]models.Transaction, []models.AccountFetchFailure, error) {
 	// First get all accounts
-	accounts, err := n.GetCustomerAccounts(customerID)
+	accounts, err := n.GetCustomerAccounts(ctx, customerID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get customer accounts: %v", err)
+		return nil, nil, fmt.Errorf("failed to get customer accounts: %w", err)
 	}
+	return n.customerTransactions(ctx, customerID, accounts, filter)
+}
 
-	var allTransactions []models.Transaction
+// customerTransactions fetches, enriches and filters the transactions of a
+// customer's accounts
+func (n *NessieService) customerTransactions(ctx context.Context, customerID string, accounts []models.Account, filter models.TransactionFilter) ([]models.Transaction, []models.AccountFetchFailure, error) {
+	// Skip the calls entirely for accounts the filter excludes
+	selected := accounts
+	if filter.AccountID != "" {
+		selected = nil
+		for _, account := range accounts {
+			if account.ID == filter.AccountID {
+				selected = append(selected, account)
+			}
+		}
+	}
 
-	// Fetch transactions for each account
-	for _, account := range accounts {
-		transactions, err := n.GetAccountTransactions(account.ID)
-		if err != nil {
-			// Log error but continue with other accounts
-			fmt.Printf("Warning: failed to get transactions for account %s: %v\n", account.ID, err)
-			continue
+	allTransactions, failures := n.fetchAllAccountTransactions(ctx, customerID, selected)
+	if len(selected) > 0 && len(failures) == len(selected) {
+		return nil, failures, newUpstreamError("failed to fetch transactions for all %d accounts: %s", len(failures), failures[0].Error)
+	}
+
+	// Join each purchase's merchant and use its Nessie category, then resolve
+	// merchants against the registry. Transactions still without a category
+	// fall back to keyword guessing before filtering on it.
+	n.joinMerchants(ctx, allTransactions)
+	stampCurrencies(accounts, allTransactions)
+	for i := range allTransactions {
+		n.Merchants.EnrichMerchant(&allTransactions[i])
+		if allTransactions[i].Merchant.Category == "" {
+			allTransactions[i].Merchant.Category = n.categorizeTransaction(allTransactions[i])
 		}
-		
-		allTransactions = append(allTransactions, transactions...)
 	}
 
-	return allTransactions, nil
+	// Pair transfers across the fetched accounts before narrowing to the filter
+	allTransactions = ClassifyIncome(n.Transfers.Detect(accounts, allTransactions))
+	allTransactions = n.Annotations.Apply(customerID, n.Splits.Apply(customerID, allTransactions))
+	filtered, err := FilterTransactions(allTransactions, filter)
+	return filtered, failures, err
 }
 
-// GetDashboardData aggregates all data needed for the dashboard
-func (n *NessieService) GetDashboardData(customerID string) (*models.DashboardData, error) {
-	// Fetch customer info
-	customer, err := n.GetCustomer(customerID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get customer: %v", err)
+// GetDashboardData aggregates all data needed for the dashboard. The customer
+// and their accounts are fetched at the same time.
+func (n *NessieService) GetDashboardData(ctx context.Context, customerID string) (*models.DashboardData, error) {
+	var customer *models.Customer
+	var accounts []models.Account
+	var customerErr, accountsErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		customer, customerErr = n.GetCustomer(ctx, customerID)
+	}()
+	go func() {
+		defer wg.Done()
+		accounts, accountsErr = n.GetCustomerAccounts(ctx, customerID)
+	}()
+	wg.Wait()
+	if customerErr != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", customerErr)
 	}
-
-	// Fetch accounts
-	accounts, err := n.GetCustomerAccounts(customerID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get accounts: %v", err)
+	if accountsErr != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", accountsErr)
 	}
 
 	// Fetch all transactions
-	transactions, err := n.GetAllCustomerTransactions(customerID)
+	transactions, failures, err := n.customerTransactions(ctx, customerID, accounts, models.TransactionFilter{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get transactions: %v", err)
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
 	}
 
-	// Process spending data
+	// Process spending data in the customer's reporting currency
+	accounts = n.Currency.ConvertAccounts(customerID, accounts)
+	transactions = n.Currency.ConvertTransactions(customerID, transactions)
 	spendingData := n.processSpendingData(transactions)
+	preferences := n.Currency.Preferences(customerID)
 
 	return &models.DashboardData{
-		Customer:     *customer,
-		Accounts:     accounts,
-		Transactions: transactions,
-		SpendingData: spendingData,
+		Customer:       *customer,
+		Accounts:       accounts,
+		Transactions:   transactions,
+		SpendingData:   spendingData,
+		Currency:       preferences.ReportingCurrency,
+		Locale:         preferences.Locale,
+		Partial:        len(failures) > 0,
+		FailedAccounts: failures,
 	}, nil
 }
 
+// GetNetWorth returns a customer's net worth history over the last days days,
+// recording today's balances along the way. History for accounts whose
+// transactions couldn't be fetched is flat at today's balance.
+func (n *NessieService) GetNetWorth(ctx context.Context, customerID string, days int) (*models.NetWorthSummary, error) {
+	accounts, err := n.GetCustomerAccounts(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+
+	transactions, _, err := n.customerTransactions(ctx, customerID, accounts, models.TransactionFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	now := time.Now()
+	n.NetWorth.RecordBalances(customerID, accounts, now)
+	summary := n.NetWorth.BuildNetWorth(customerID, n.Currency.ConvertAccounts(customerID, accounts), n.Currency.ConvertTransactions(customerID, transactions), days, now)
+	return &summary, nil
+}
+
+// nessieMovementPaths maps movement kinds to the account endpoints that create them
+var nessieMovementPaths = map[string]string{
+	MovementTransfer:   "transfers",
+	MovementDeposit:    "deposits",
+	MovementWithdrawal: "withdrawals",
+	MovementPurchase:   "purchases",
+}
+
+// nessieCustomer is a customer as Nessie returns it, with its date in
+// Nessie's format
+type nessieCustomer struct {
+	models.Customer
+	CreatedDate string `json:"created_date"`
+}
+
+func (c nessieCustomer) record() (models.Customer, error) {
+	customer := c.Customer
+	var err error
+	customer.CreatedDate, err = parseNessieDate("created_date", c.CreatedDate)
+	return customer, err
+}
+
+// nessieAccount is an account as Nessie returns it. Nessie only has US
+// accounts, so they don't say their currency.
+type nessieAccount struct {
+	models.Account
+}
+
+func (a nessieAccount) record() (models.Account, error) {
+	account := a.Account
+	if account.Currency == "" {
+		account.Currency = DefaultCurrency
+	}
+	return account, nil
+}
+
+// nessieTransaction is a transaction as Nessie returns it, with its date in
+// Nessie's format
+type nessieTransaction struct {
+	models.Transaction
+	TransactionDate string `json:"transaction_date"`
+}
+
+func (t nessieTransaction) record() (models.Transaction, error) {
+	transaction := t.Transaction
+	var err error
+	transaction.TransactionDate, err = parseNessieDate("transaction_date", t.TransactionDate)
+	return transaction, err
+}
+
+// nessieCreated is Nessie's response to creating an object
+type nessieCreated struct {
+	Code          int             `json:"code"`
+	Message       string          `json:"message"`
+	ObjectCreated json.RawMessage `json:"objectCreated"`
+}
+
+// nessieMovement is the part of a created transfer, deposit, withdrawal or
+// purchase that isn't already known from the request
+type nessieMovement struct {
+	ID     string `json:"_id"`
+	Status string `json:"status"`
+}
+
+// nessieBill is a bill as Nessie returns it
+type nessieBill struct {
+	ID                  string  `json:"_id"`
+	Status              string  `json:"status"`
+	Payee               string  `json:"payee"`
+	Nickname            string  `json:"nickname"`
+	PaymentDate         string  `json:"payment_date"`
+	UpcomingPaymentDate string  `json:"upcoming_payment_date"`
+	PaymentAmount       float64 `json:"payment_amount"`
+	AccountID           string  `json:"account_id"`
+}
+
+// CreateTransfer moves money or rewards from one of a customer's accounts to another
+func (n *NessieService) CreateTransfer(ctx context.Context, customerID string, input MovementInput) (*models.MoneyMovement, error) {
+	return n.CreateMovement(ctx, customerID, MovementTransfer, input)
+}
+
+// CreateDeposit adds money or rewards to one of a customer's accounts
+func (n *NessieService) CreateDeposit(ctx context.Context, customerID string, input MovementInput) (*models.MoneyMovement, error) {
+	return n.CreateMovement(ctx, customerID, MovementDeposit, input)
+}
+
+// CreateWithdrawal takes money or rewards out of one of a customer's accounts
+func (n *NessieService) CreateWithdrawal(ctx context.Context, customerID string, input MovementInput) (*models.MoneyMovement, error) {
+	return n.CreateMovement(ctx, customerID, MovementWithdrawal, input)
+}
+
+// CreatePurchase pays a Nessie merchant from one of a customer's accounts
+func (n *NessieService) CreatePurchase(ctx context.Context, customerID string, input MovementInput) (*models.MoneyMovement, error) {
+	return n.CreateMovement(ctx, customerID, MovementPurchase, input)
+}
+
+// CreateMovement validates a transfer, deposit, withdrawal or purchase,
+// checks its accounts are the customer's and creates it in Nessie. The
+// customer's cached data is dropped so the new balances and transactions
+// show up on the next read.
+func (n *NessieService) CreateMovement(ctx context.Context, customerID string, kind string, input MovementInput) (*models.MoneyMovement, error) {
+	if err := ValidateMovement(kind, &input); err != nil {
+		return nil, err
+	}
+	accounts, err := n.GetCustomerAccounts(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	if err := checkMovementAccounts(accounts, input); err != nil {
+		return nil, err
+	}
+	var merchant *MerchantEntry
+	if kind == MovementPurchase {
+		found, err := n.GetMerchant(ctx, input.MerchantID)
+		if err != nil {
+			return nil, err
+		}
+		if found == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownMerchant, input.MerchantID)
+		}
+		merchant = &MerchantEntry{ID: found.ID, Name: found.Name, Category: found.Category}
+	}
+
+	body := map[string]interface{}{
+		"medium": input.Medium,
+		"amount": input.Amount,
+	}
+	if input.Description != "" {
+		body["description"] = input.Description
+	}
+	switch kind {
+	case MovementTransfer:
+		body["payee_id"] = input.PayeeID
+		body["transaction_date"] = input.Date
+	case MovementPurchase:
+		body["merchant_id"] = input.MerchantID
+		body["purchase_date"] = input.Date
+	default:
+		body["transaction_date"] = input.Date
+	}
+	var created nessieCreated
+	path := fmt.Sprintf("/accounts/%s/%s", url.PathEscape(input.AccountID), nessieMovementPaths[kind])
+	if _, err := n.post(ctx, path, body, &created); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", kind, err)
+	}
+	var object nessieMovement
+	if err := json.Unmarshal(created.ObjectCreated, &object); err != nil || object.ID == "" {
+		return nil, newUpstreamError("failed to decode created %s", kind)
+	}
+
+	movement := models.MoneyMovement{
+		ID:          object.ID,
+		Kind:        kind,
+		CustomerID:  customerID,
+		AccountID:   input.AccountID,
+		PayeeID:     input.PayeeID,
+		MerchantID:  input.MerchantID,
+		Medium:      input.Medium,
+		Amount:      input.Amount,
+		Description: input.Description,
+		Date:        input.Date,
+		Status:      object.Status,
+		CreatedAt:   time.Now(),
+	}
+	n.Cache.Invalidate(customerID)
+	if n.Events != nil {
+		for _, transaction := range movementTransactions(movement, accounts, merchant) {
+			n.Events.PublishOnce(TransactionCreatedEvent(customerID, transaction, n.Currency.Formatter(customerID)))
+		}
+	}
+	return &movement, nil
+}
+
+// CreateBill schedules a bill to be paid from one of a customer's accounts,
+// once or every month
+func (n *NessieService) CreateBill(ctx context.Context, customerID string, input BillPaymentInput) (*models.Bill, error) {
+	if err := ValidateBillPayment(&input); err != nil {
+		return nil, err
+	}
+	accounts, err := n.GetCustomerAccounts(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	if _, exists := findAccount(accounts, input.AccountID); !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAccount, input.AccountID)
+	}
+
+	body := map[string]interface{}{
+		"status":         "pending",
+		"payee":          input.Payee,
+		"payment_date":   input.PaymentDate,
+		"payment_amount": input.Amount,
+	}
+	if input.Nickname != "" {
+		body["nickname"] = input.Nickname
+	}
+	if input.RecurringDate > 0 {
+		body["recurring_date"] = input.RecurringDate
+	}
+	var created nessieCreated
+	if _, err := n.post(ctx, fmt.Sprintf("/accounts/%s/bills", url.PathEscape(input.AccountID)), body, &created); err != nil {
+		return nil, fmt.Errorf("failed to create bill: %w", err)
+	}
+	var object nessieBill
+	if err := json.Unmarshal(created.ObjectCreated, &object); err != nil || object.ID == "" {
+		return nil, newUpstreamError("failed to decode created bill")
+	}
+
+	bill := &models.Bill{
+		ID:         object.ID,
+		CustomerID: customerID,
+		Name:       input.Payee,
+		Category:   "Other",
+		Amount:     input.Amount,
+		AccountID:  input.AccountID,
+		Frequency:  billPaymentFrequency(input),
+		AutoPay:    true,
+	}
+	if input.Nickname != "" {
+		bill.Name = input.Nickname
+	}
+	dueDate := object.UpcomingPaymentDate
+	if dueDate == "" {
+		dueDate = input.PaymentDate
+	}
+	bill.DueDate, _ = time.Parse(BillDateLayout, dueDate)
+	if entry, exists := n.Merchants.Resolve(bill.Name); exists {
+		bill.MerchantID = entry.ID
+		bill.Category = entry.Category
+	}
+	n.Cache.Invalidate(customerID)
+	return bill, nil
+}
+
 // processSpendingData processes transactions to create spending analytics
 func (n *NessieService) processSpendingData(transactions []models.Transaction) models.SpendingData {
 	// Group transactions by month
@@ -216,15 +744,19 @@ func (n *NessieService) processSpendingData(transactions []models.Transaction) m
 
 	// Process each transaction
 	for _, transaction := range transactions {
-		// Only process debit transactions (spending)
-		if transaction.Type == "deposit" || transaction.Amount < 0 {
-			// Get month from transaction date
-			month := transaction.TransactionDate.Format("Jan")
-			monthlySpending[month] += transaction.Amount
-			
-			// Categorize transaction (simplified categorization)
-			category := n.categorizeTransaction(transaction)
-			categorySpending[category] += transaction.Amount
+		// Only process spending, not income or transfers between the
+		// customer's own accounts
+		if !IsExpense(transaction) {
+			continue
+		}
+
+		// Get month from transaction date
+		month := transaction.TransactionDate.Format("Jan")
+		monthlySpending[month] += math.Abs(transaction.Amount)
+
+		// Split transactions count toward each of their categories
+		for _, allocation := range TransactionAllocations(transaction) {
+			categorySpending[allocation.Category] += math.Abs(allocation.Amount)
 		}
 	}
 
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// maxNessieDecodeErrors caps the errors kept for each endpoint
+const maxNessieDecodeErrors = 100
+
+// nessieDateLayouts are the formats Nessie dates come in. Most are plain
+// dates, but records created through other clients can carry timestamps.
+var nessieDateLayouts = []string{BillDateLayout, time.RFC3339, "2006-01-02 15:04:05"}
+
+// nessieFieldError is a field of a Nessie record with a value we can't use
+type nessieFieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *nessieFieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+// parseNessieDate parses a record's date field in any of Nessie's formats. An
+// empty date is the zero time.
+func parseNessieDate(field string, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range nessieDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, &nessieFieldError{Field: field, Err: fmt.Errorf("unrecognized date %q", value)}
+}
+
+// nessieRecord is a record as Nessie returns it, which converts to a T
+type nessieRecord[T any] interface {
+	record() (T, error)
+}
+
+// stringList decodes a field Nessie sends as either a list of strings or a
+// single string
+func stringList(data json.RawMessage) []string {
+	var values []string
+	if err := json.Unmarshal(data, &values); err == nil {
+		return values
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil && value != "" {
+		return []string{value}
+	}
+	return nil
+}
+
+// NessieDecodeReport keeps, for each Nessie endpoint, the records it last
+// returned that couldn't be decoded. Endpoints whose last response decoded
+// cleanly aren't listed.
+type NessieDecodeReport struct {
+	mu      sync.Mutex
+	results map[string]models.NessieDecodeResult
+}
+
+// defaultNessieDecodeReport is shared by every Nessie service so the report
+// covers all requests
+var defaultNessieDecodeReport = NewNessieDecodeReport()
+
+// DefaultNessieDecodeReport returns the decode report shared by the API
+func DefaultNessieDecodeReport() *NessieDecodeReport {
+	return defaultNessieDecodeReport
+}
+
+// NewNessieDecodeReport creates an empty decode report
+func NewNessieDecodeReport() *NessieDecodeReport {
+	return &NessieDecodeReport{
+		results: make(map[string]models.NessieDecodeResult),
+	}
+}
+
+// Record replaces an endpoint's entry with how its latest response decoded.
+// A nil report ignores it.
+func (r *NessieDecodeReport) Record(result models.NessieDecodeResult) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(result.Errors) == 0 {
+		delete(r.results, result.Endpoint)
+		return
+	}
+	if len(result.Errors) > maxNessieDecodeErrors {
+		result.Errors = result.Errors[:maxNessieDecodeErrors]
+	}
+	r.results[result.Endpoint] = result
+}
+
+// Results returns the endpoints with decode errors, ordered by endpoint
+func (r *NessieDecodeReport) Results() []models.NessieDecodeResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	results := make([]models.NessieDecodeResult, 0, len(r.results))
+	for _, result := range r.results {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Endpoint < results[j].Endpoint
+	})
+	return results
+}
+
+// Clear empties the report and returns how many endpoints it listed
+func (r *NessieDecodeReport) Clear() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cleared := len(r.results)
+	r.results = make(map[string]models.NessieDecodeResult)
+	return cleared
+}
+
+// nessieListRecords splits a Nessie list response into its records. The
+// enterprise endpoints wrap lists in a results envelope; the others return a
+// bare array.
+func nessieListRecords(body json.RawMessage) ([]json.RawMessage, error) {
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var records []json.RawMessage
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, err
+		}
+		return records, nil
+	}
+	var envelope models.NessieResponse[json.RawMessage]
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Results, nil
+}
+
+// decodeNessieRecords decodes each record as a W and converts it to a T.
+// Records that don't decode or convert, or have no ID, are left out and
+// described in the result.
+func decodeNessieRecords[W nessieRecord[T], T any](records []json.RawMessage) ([]T, models.NessieDecodeResult) {
+	result := models.NessieDecodeResult{Records: len(records), DecodedAt: time.Now()}
+	values := make([]T, 0, len(records))
+	for i, record := range records {
+		// Read the ID on its own so records that fail can still be identified
+		var identity struct {
+			ID string `json:"_id"`
+		}
+		json.Unmarshal(record, &identity)
+
+		var wire W
+		err := json.Unmarshal(record, &wire)
+		var value T
+		if err == nil {
+			value, err = wire.record()
+		}
+		if err != nil {
+			decodeErr := models.NessieDecodeError{Index: i, RecordID: identity.ID, Error: err.Error()}
+			var typeErr *json.UnmarshalTypeError
+			var fieldErr *nessieFieldError
+			if errors.As(err, &typeErr) {
+				decodeErr.Field = typeErr.Field
+			} else if errors.As(err, &fieldErr) {
+				decodeErr.Field = fieldErr.Field
+			}
+			result.Errors = append(result.Errors, decodeErr)
+			continue
+		}
+		if identity.ID == "" {
+			result.Errors = append(result.Errors, models.NessieDecodeError{Index: i, Field: "_id", Error: "record has no _id"})
+			continue
+		}
+		values = append(values, value)
+	}
+	result.Decoded = len(values)
+	return values, result
+}
+
+// getNessieList fetches a Nessie list endpoint and decodes its records as Ws
+// into Ts. Records that don't decode are recorded in the service's decode
+// report instead of failing the whole list. It returns how many attempts
+// were made.
+func getNessieList[W nessieRecord[T], T any](ctx context.Context, n *NessieService, path string) ([]T, int, error) {
+	var body json.RawMessage
+	attempts, err := n.get(ctx, path, &body)
+	if err != nil {
+		return nil, attempts, err
+	}
+	records, err := nessieListRecords(body)
+	if err != nil {
+		return nil, attempts, newUpstreamError("failed to decode response: %v", err)
+	}
+	values, result := decodeNessieRecords[W](records)
+	result.Endpoint = path
+	n.Decoding.Record(result)
+	return values, attempts, nil
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// nessieMerchantCacheTTL is how long merchant lookups are cached. Merchants
+// are shared by every customer and rarely change.
+const nessieMerchantCacheTTL = 24 * time.Hour
+
+// nessieCategories maps the free-form categories Nessie merchants carry to
+// the categories used for budgets and charts
+var nessieCategories = map[string]string{
+	"food":              "Food & Dining",
+	"restaurant":        "Food & Dining",
+	"restaurants":       "Food & Dining",
+	"cafe":              "Food & Dining",
+	"coffee":            "Food & Dining",
+	"bakery":            "Food & Dining",
+	"bar":               "Food & Dining",
+	"grocery":           "Food & Dining",
+	"groceries":         "Food & Dining",
+	"meal delivery":     "Food & Dining",
+	"meal takeaway":     "Food & Dining",
+	"gas":               "Transportation",
+	"gas station":       "Transportation",
+	"transportation":    "Transportation",
+	"transit":           "Transportation",
+	"taxi":              "Transportation",
+	"parking":           "Transportation",
+	"car repair":        "Transportation",
+	"auto":              "Transportation",
+	"travel":            "Transportation",
+	"airline":           "Transportation",
+	"shopping":          "Shopping",
+	"store":             "Shopping",
+	"retail":            "Shopping",
+	"clothing":          "Shopping",
+	"clothing store":    "Shopping",
+	"department store":  "Shopping",
+	"electronics":       "Shopping",
+	"electronics store": "Shopping",
+	"book store":        "Shopping",
+	"entertainment":     "Entertainment",
+	"movie theater":     "Entertainment",
+	"movies":            "Entertainment",
+	"music":             "Entertainment",
+	"night club":        "Entertainment",
+	"amusement park":    "Entertainment",
+	"health":            "Healthcare",
+	"healthcare":        "Healthcare",
+	"pharmacy":          "Healthcare",
+	"doctor":            "Healthcare",
+	"hospital":          "Healthcare",
+	"dentist":           "Healthcare",
+	"utilities":         "Utilities",
+	"utility":           "Utilities",
+	"telecom":           "Utilities",
+	"internet":          "Utilities",
+}
+
+// nessieMerchant is a merchant as Nessie returns it
+type nessieMerchant struct {
+	ID       string          `json:"_id"`
+	Name     string          `json:"name"`
+	Category json.RawMessage `json:"category"`
+	Address  struct {
+		City  string `json:"city"`
+		State string `json:"state"`
+	} `json:"address"`
+	Geocode *models.Geocode `json:"geocode"`
+}
+
+// categories returns the merchant's categories. Nessie stores a list, but
+// merchants created by hand sometimes have a single string.
+func (m nessieMerchant) categories() []string {
+	return stringList(m.Category)
+}
+
+// NessieMerchant is a merchant with the details joined onto transactions
+type NessieMerchant struct {
+	ID         string          `json:"_id"`
+	Name       string          `json:"name"`
+	Categories []string        `json:"categories"`
+	Category   string          `json:"category"`
+	City       string          `json:"city,omitempty"`
+	State      string          `json:"state,omitempty"`
+	Geocode    *models.Geocode `json:"geocode,omitempty"`
+}
+
+// mapNessieCategory returns the first of a merchant's Nessie categories that
+// maps to one of ours, or "" if none do
+func mapNessieCategory(categories []string) string {
+	for _, category := range categories {
+		if mapped, exists := nessieCategories[strings.ToLower(strings.TrimSpace(category))]; exists {
+			return mapped
+		}
+	}
+	return ""
+}
+
+// GetMerchant fetches a merchant by ID. Lookups are cached, including
+// merchants that don't exist, which return nil without an error.
+func (n *NessieService) GetMerchant(ctx context.Context, merchantID string) (*NessieMerchant, error) {
+	// Merchants are shared, so they're cached outside any customer's data
+	return cached(n.Cache, CacheNamespaceNessieMerchant, "", []string{merchantID}, nessieMerchantCacheTTL, func() (*NessieMerchant, error) {
+		var merchant nessieMerchant
+		if _, err := n.get(ctx, fmt.Sprintf("/merchants/%s", url.PathEscape(merchantID)), &merchant); err != nil {
+			var apiErr *NessieAPIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to fetch merchant: %w", err)
+		}
+		categories := merchant.categories()
+		return &NessieMerchant{
+			ID:         merchant.ID,
+			Name:       merchant.Name,
+			Categories: categories,
+			Category:   mapNessieCategory(categories),
+			City:       merchant.Address.City,
+			State:      merchant.Address.State,
+			Geocode:    merchant.Geocode,
+		}, nil
+	})
+}
+
+// nessiePurchase is a purchase as Nessie returns it
+type nessiePurchase struct {
+	ID           string  `json:"_id"`
+	MerchantID   string  `json:"merchant_id"`
+	PayerID      string  `json:"payer_id"`
+	PurchaseDate string  `json:"purchase_date"`
+	Amount       float64 `json:"amount"`
+	Status       string  `json:"status"`
+	Description  string  `json:"description"`
+}
+
+// record converts a purchase to a transaction spending from the payer account
+func (p nessiePurchase) record() (models.Transaction, error) {
+	date, err := parseNessieDate("purchase_date", p.PurchaseDate)
+	return models.Transaction{
+		ID:              p.ID,
+		Type:            MovementPurchase,
+		Amount:          -p.Amount,
+		Description:     p.Description,
+		TransactionDate: date,
+		Status:          p.Status,
+		AccountID:       p.PayerID,
+		MerchantID:      p.MerchantID,
+	}, err
+}
+
+// GetAccountPurchases fetches the purchases made from an account
+func (n *NessieService) GetAccountPurchases(ctx context.Context, accountID string) ([]models.Transaction, error) {
+	transactions, _, err := n.fetchAccountPurchases(ctx, accountID)
+	return transactions, err
+}
+
+// fetchAccountPurchases fetches an account's purchases as transactions and
+// reports how many attempts it took
+func (n *NessieService) fetchAccountPurchases(ctx context.Context, accountID string) ([]models.Transaction, int, error) {
+	transactions, attempts, err := getNessieList[nessiePurchase](ctx, n, fmt.Sprintf("/accounts/%s/purchases", url.PathEscape(accountID)))
+	if err != nil {
+		return nil, attempts, fmt.Errorf("failed to fetch purchases: %w", err)
+	}
+	// Purchases are listed per account, so they don't all name their account
+	for i := range transactions {
+		transactions[i].AccountID = accountID
+	}
+	return transactions, attempts, nil
+}
+
+// mergePurchases adds purchases to an account's transactions. A purchase the
+// transactions already include replaces it, since it knows its merchant.
+func mergePurchases(transactions []models.Transaction, purchases []models.Transaction) []models.Transaction {
+	byID := make(map[string]int, len(transactions))
+	for i, transaction := range transactions {
+		byID[transaction.ID] = i
+	}
+	for _, purchase := range purchases {
+		if i, exists := byID[purchase.ID]; exists {
+			if transactions[i].MerchantID == "" {
+				transactions[i].MerchantID = purchase.MerchantID
+			}
+			continue
+		}
+		byID[purchase.ID] = len(transactions)
+		transactions = append(transactions, purchase)
+	}
+	return transactions
+}
+
+// joinMerchants looks up the merchant of every transaction that names one,
+// at most Concurrency at a time, and fills in its name, category and
+// location. Each merchant is looked up once however many transactions it
+// has; lookups that fail leave the transaction to the keyword categorizer.
+func (n *NessieService) joinMerchants(ctx context.Context, transactions []models.Transaction) {
+	var merchantIDs []string
+	seen := make(map[string]bool)
+	for _, transaction := range transactions {
+		if id := transaction.MerchantID; id != "" && !seen[id] {
+			seen[id] = true
+			merchantIDs = append(merchantIDs, id)
+		}
+	}
+	if len(merchantIDs) == 0 {
+		return
+	}
+
+	concurrency := n.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	merchants := make(map[string]*NessieMerchant, len(merchantIDs))
+	var mu sync.Mutex
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, merchantID := range merchantIDs {
+		wg.Add(1)
+		go func(merchantID string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+			merchant, err := n.GetMerchant(ctx, merchantID)
+			if err != nil || merchant == nil {
+				return
+			}
+			mu.Lock()
+			merchants[merchantID] = merchant
+			mu.Unlock()
+		}(merchantID)
+	}
+	wg.Wait()
+
+	for i := range transactions {
+		merchant, exists := merchants[transactions[i].MerchantID]
+		if !exists {
+			continue
+		}
+		transactions[i].Merchant = models.Merchant{
+			ID:       merchant.ID,
+			Name:     merchant.Name,
+			Category: merchant.Category,
+			Geocode:  merchant.Geocode,
+		}
+		if transactions[i].Description == "" {
+			transactions[i].Description = merchant.Name
+		}
+		if transactions[i].Location == nil && (merchant.City != "" || merchant.State != "") {
+			transactions[i].Location = &models.Location{City: merchant.City, State: merchant.State}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testNessieService returns a Nessie service pointed at server with short
+// deadlines and quick retries
+func testNessieService(server *httptest.Server) *NessieService {
+	service := NewNessieService("test-key")
+	service.BaseURL = server.URL
+	service.Cache = nil
+	service.CallTimeout = 100 * time.Millisecond
+	service.Retry = RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	return service
+}
+
+func TestNessieRetriesAHungAttempt(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			// Hang until the client gives up on this attempt
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"_id":"c1"}`))
+	}))
+	defer server.Close()
+
+	var out map[string]interface{}
+	attempts, err := testNessieService(server).get(context.Background(), "/customers/c1", &out)
+	if err != nil {
+		t.Fatalf("get returned %v", err)
+	}
+	if attempts != 2 || out["_id"] != "c1" {
+		t.Errorf("get made %d attempts and decoded %v, want 2 attempts and c1", attempts, out)
+	}
+}
+
+func TestNessieCallerContextBoundsRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	var out map[string]interface{}
+	attempts, err := testNessieService(server).get(ctx, "/customers/c1", &out)
+	if !errors.Is(err, ErrUpstream) {
+		t.Errorf("get returned %v, want an upstream error", err)
+	}
+	if attempts < 2 {
+		t.Errorf("get made %d attempts, want a retry before the caller's deadline", attempts)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("get took %v, past the caller's deadline", elapsed)
+	}
+}
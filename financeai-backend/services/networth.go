@@ -0,0 +1,503 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+const (
+	// AccountClassAsset marks accounts that add to net worth
+	AccountClassAsset = "asset"
+	// AccountClassLiability marks accounts that subtract from net worth
+	AccountClassLiability = "liability"
+
+	// SnapshotSourceRecorded marks a balance captured from the account on that day
+	SnapshotSourceRecorded = "recorded"
+	// SnapshotSourceReconstructed marks a balance worked out backward from transactions
+	SnapshotSourceReconstructed = "reconstructed"
+	// SnapshotSourceManual marks a value the customer entered by hand
+	SnapshotSourceManual = "manual"
+
+	// DefaultNetWorthDays is how much history the net worth endpoint returns by default
+	DefaultNetWorthDays = 90
+	// MaxNetWorthDays caps how much history can be requested
+	MaxNetWorthDays = 730
+)
+
+// liabilityAccountTypes lists bank account types that hold debt
+var liabilityAccountTypes = map[string]bool{
+	"Credit Card":    true,
+	"Line of Credit": true,
+	"Loan":           true,
+	"Mortgage":       true,
+}
+
+// manualAccountTypes lists the kinds of manual accounts and whether each is a liability
+var manualAccountTypes = map[string]bool{
+	"vehicle":         false,
+	"real_estate":     false,
+	"investment":      false,
+	"cash":            false,
+	"other_asset":     false,
+	"student_loan":    true,
+	"auto_loan":       true,
+	"mortgage":        true,
+	"personal_loan":   true,
+	"other_liability": true,
+}
+
+// ClassifyAccount reports whether a bank account type is an asset or a liability
+func ClassifyAccount(accountType string) string {
+	if liabilityAccountTypes[accountType] {
+		return AccountClassLiability
+	}
+	return AccountClassAsset
+}
+
+// ManualAccountInput describes a manual account to create or change. Nil
+// fields are left unchanged on update.
+type ManualAccountInput struct {
+	Name     *string  `json:"name"`
+	Type     *string  `json:"type"`
+	Balance  *float64 `json:"balance"`
+	Currency *string  `json:"currency"`
+}
+
+// NetWorthStore holds recorded balance snapshots and manual accounts, keyed
+// by customer
+type NetWorthStore struct {
+	mu        sync.RWMutex
+	snapshots map[string]map[string]map[time.Time]models.BalanceSnapshot
+	manual    map[string]map[string]*models.ManualAccount
+	nextID    int
+	// currency converts balances to each customer's reporting currency
+	currency *CurrencyService
+}
+
+// defaultNetWorthStore is shared by the data providers so manual accounts and
+// recorded balances show up everywhere
+var defaultNetWorthStore = NewNetWorthStore()
+
+// NewNetWorthStore creates an empty net worth store
+func NewNetWorthStore() *NetWorthStore {
+	return &NetWorthStore{
+		snapshots: make(map[string]map[string]map[time.Time]models.BalanceSnapshot),
+		manual:    make(map[string]map[string]*models.ManualAccount),
+		currency:  defaultCurrencyService,
+	}
+}
+
+// startOfDay truncates t to midnight UTC, the key balance snapshots are stored under
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// roundCents rounds an amount to whole cents
+func roundCents(amount float64) float64 {
+	return float64(toCents(amount)) / 100
+}
+
+// recordLocked stores a snapshot, replacing any other for the same account and
+// day. Callers must hold the write lock.
+func (s *NetWorthStore) recordLocked(customerID string, snapshot models.BalanceSnapshot) {
+	snapshot.Date = startOfDay(snapshot.Date)
+	if _, exists := s.snapshots[customerID]; !exists {
+		s.snapshots[customerID] = make(map[string]map[time.Time]models.BalanceSnapshot)
+	}
+	if _, exists := s.snapshots[customerID][snapshot.AccountID]; !exists {
+		s.snapshots[customerID][snapshot.AccountID] = make(map[time.Time]models.BalanceSnapshot)
+	}
+	s.snapshots[customerID][snapshot.AccountID][snapshot.Date] = snapshot
+}
+
+// RecordBalances captures today's balance of each account, in the account's
+// own currency
+func (s *NetWorthStore) RecordBalances(customerID string, accounts []models.Account, now time.Time) []models.BalanceSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recorded := make([]models.BalanceSnapshot, 0, len(accounts))
+	for _, account := range accounts {
+		currency := account.Currency
+		if currency == "" {
+			currency = DefaultCurrency
+		}
+		snapshot := models.BalanceSnapshot{
+			AccountID: account.ID,
+			Date:      startOfDay(now),
+			Balance:   float64(account.Balance),
+			Currency:  currency,
+			Source:    SnapshotSourceRecorded,
+		}
+		s.recordLocked(customerID, snapshot)
+		recorded = append(recorded, snapshot)
+	}
+	return recorded
+}
+
+// Snapshots returns the recorded snapshots for an account, oldest first
+func (s *NetWorthStore) Snapshots(customerID string, accountID string) []models.BalanceSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshots := make([]models.BalanceSnapshot, 0, len(s.snapshots[customerID][accountID]))
+	for _, snapshot := range s.snapshots[customerID][accountID] {
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+	return snapshots
+}
+
+// validateManualAccount checks a manual account's name, type and balance
+func (s *NetWorthStore) validateManualAccount(account *models.ManualAccount) error {
+	if account.Name == "" {
+		return NewValidationError("name", "name required")
+	}
+	if _, known := manualAccountTypes[account.Type]; !known {
+		return NewValidationError("type", "unsupported manual account type: %s", account.Type)
+	}
+	if account.Balance < 0 || math.IsNaN(account.Balance) || math.IsInf(account.Balance, 0) {
+		return NewValidationError("balance", "balance must be a non-negative amount; liabilities are entered as the amount owed")
+	}
+	currency, err := s.currency.ValidateCurrency(account.Currency)
+	if err != nil {
+		return err
+	}
+	account.Currency = currency
+	return nil
+}
+
+// ManualAccounts returns a customer's manual accounts, sorted by name
+func (s *NetWorthStore) ManualAccounts(customerID string) []models.ManualAccount {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	accounts := make([]models.ManualAccount, 0, len(s.manual[customerID]))
+	for _, account := range s.manual[customerID] {
+		accounts = append(accounts, *account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Name != accounts[j].Name {
+			return accounts[i].Name < accounts[j].Name
+		}
+		return accounts[i].ID < accounts[j].ID
+	})
+	return accounts
+}
+
+// AddManualAccount creates a manual account and records its opening value
+func (s *NetWorthStore) AddManualAccount(customerID string, input ManualAccountInput, now time.Time) (*models.ManualAccount, error) {
+	account := models.ManualAccount{CustomerID: customerID, Currency: DefaultCurrency, UpdatedAt: now}
+	if input.Name != nil {
+		account.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Type != nil {
+		account.Type = strings.ToLower(strings.TrimSpace(*input.Type))
+	}
+	if input.Balance != nil {
+		account.Balance = roundCents(*input.Balance)
+	}
+	if input.Currency != nil {
+		account.Currency = *input.Currency
+	}
+	if err := s.validateManualAccount(&account); err != nil {
+		return nil, err
+	}
+	account.IsLiability = manualAccountTypes[account.Type]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	account.ID = fmt.Sprintf("manual%d", s.nextID)
+	if _, exists := s.manual[customerID]; !exists {
+		s.manual[customerID] = make(map[string]*models.ManualAccount)
+	}
+	s.manual[customerID][account.ID] = &account
+	s.recordLocked(customerID, models.BalanceSnapshot{
+		AccountID: account.ID,
+		Date:      now,
+		Balance:   account.Balance,
+		Currency:  account.Currency,
+		Source:    SnapshotSourceManual,
+	})
+	return &account, nil
+}
+
+// UpdateManualAccount changes a manual account. A new balance is recorded as
+// the account's value from today on, keeping its earlier history.
+func (s *NetWorthStore) UpdateManualAccount(customerID string, accountID string, input ManualAccountInput, now time.Time) (*models.ManualAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.manual[customerID][accountID]
+	if !exists {
+		return nil, NewNotFoundError("manual account not found: %s", accountID)
+	}
+	account := *existing
+	if input.Name != nil {
+		account.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Type != nil {
+		account.Type = strings.ToLower(strings.TrimSpace(*input.Type))
+	}
+	if input.Balance != nil {
+		account.Balance = roundCents(*input.Balance)
+	}
+	if input.Currency != nil {
+		account.Currency = *input.Currency
+	}
+	if err := s.validateManualAccount(&account); err != nil {
+		return nil, err
+	}
+	account.IsLiability = manualAccountTypes[account.Type]
+	account.UpdatedAt = now
+
+	*existing = account
+	if input.Balance != nil || input.Currency != nil {
+		s.recordLocked(customerID, models.BalanceSnapshot{
+			AccountID: account.ID,
+			Date:      now,
+			Balance:   account.Balance,
+			Currency:  account.Currency,
+			Source:    SnapshotSourceManual,
+		})
+	}
+	return &account, nil
+}
+
+// DeleteManualAccount removes a manual account and its history
+func (s *NetWorthStore) DeleteManualAccount(customerID string, accountID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.manual[customerID][accountID]; !exists {
+		return NewNotFoundError("manual account not found: %s", accountID)
+	}
+	delete(s.manual[customerID], accountID)
+	delete(s.snapshots[customerID], accountID)
+	return nil
+}
+
+// ReconstructBalanceHistory works out an account's end-of-day balance for
+// each of the last days days by walking backward from its current balance,
+// undoing each transaction on the account
+func ReconstructBalanceHistory(account models.Account, transactions []models.Transaction, days int, now time.Time) []models.BalanceSnapshot {
+	if days < 1 {
+		days = 1
+	}
+
+	// Net movement on the account per day
+	movements := make(map[time.Time]float64)
+	today := startOfDay(now)
+	var future float64
+	for _, transaction := range transactions {
+		if transaction.AccountID != account.ID {
+			continue
+		}
+		day := startOfDay(transaction.TransactionDate)
+		if day.After(today) {
+			future += transaction.Amount
+			continue
+		}
+		movements[day] += transaction.Amount
+	}
+
+	history := make([]models.BalanceSnapshot, days)
+	balance := float64(account.Balance) - future
+	for i := days - 1; i >= 0; i-- {
+		day := today.AddDate(0, 0, i-(days-1))
+		history[i] = models.BalanceSnapshot{
+			AccountID: account.ID,
+			Date:      day,
+			Balance:   roundCents(balance),
+			Source:    SnapshotSourceReconstructed,
+		}
+		balance -= movements[day]
+	}
+	return history
+}
+
+// overlaySnapshots replaces reconstructed balances with recorded ones on the
+// days both exist
+func overlaySnapshots(history []models.BalanceSnapshot, recorded []models.BalanceSnapshot) []models.BalanceSnapshot {
+	byDay := make(map[time.Time]models.BalanceSnapshot, len(recorded))
+	for _, snapshot := range recorded {
+		byDay[snapshot.Date] = snapshot
+	}
+	for i := range history {
+		if snapshot, exists := byDay[history[i].Date]; exists {
+			history[i] = snapshot
+		}
+	}
+	return history
+}
+
+// amountOwed returns what a liability's balance says is owed. Bank accounts
+// go down as they're spent on, so they owe a negative balance; manual
+// liabilities are entered as the amount owed. A balance in the customer's
+// favour owes nothing rather than counting as more debt.
+func amountOwed(balance float64, manual bool) float64 {
+	if !manual {
+		balance = -balance
+	}
+	return math.Max(balance, 0)
+}
+
+// manualHistory carries each manual value forward until the next one. Days
+// before the account was added use its first value, so adding a car doesn't
+// read as a jump in net worth.
+func manualHistory(accountID string, recorded []models.BalanceSnapshot, days int, now time.Time) []models.BalanceSnapshot {
+	history := make([]models.BalanceSnapshot, days)
+	today := startOfDay(now)
+	next := 0
+	var current models.BalanceSnapshot
+	if len(recorded) > 0 {
+		current = recorded[0]
+	}
+	for i := range history {
+		day := today.AddDate(0, 0, i-(days-1))
+		for next < len(recorded) && !recorded[next].Date.After(day) {
+			current = recorded[next]
+			next++
+		}
+		history[i] = models.BalanceSnapshot{
+			AccountID: accountID,
+			Date:      day,
+			Balance:   current.Balance,
+			Source:    SnapshotSourceManual,
+		}
+	}
+	return history
+}
+
+// reportingSnapshots converts recorded snapshots to the customer's reporting
+// currency at the rate on the day each was recorded
+func (s *NetWorthStore) reportingSnapshots(customerID string, snapshots []models.BalanceSnapshot) []models.BalanceSnapshot {
+	reporting := s.currency.Preferences(customerID).ReportingCurrency
+	for i := range snapshots {
+		snapshots[i].Balance, _ = s.currency.Convert(customerID, snapshots[i].Balance, snapshots[i].Currency, snapshots[i].Date)
+		snapshots[i].Currency = reporting
+	}
+	return snapshots
+}
+
+// BuildNetWorth combines bank accounts, reconstructed and recorded balances,
+// and manual accounts into a net worth history over the last days days, in
+// the customer's reporting currency. Accounts and transactions should
+// already be converted to it.
+// Liabilities count as the amount owed; one in credit owes nothing.
+func (s *NetWorthStore) BuildNetWorth(customerID string, accounts []models.Account, transactions []models.Transaction, days int, now time.Time) models.NetWorthSummary {
+	if days < 1 {
+		days = 1
+	}
+
+	type trackedAccount struct {
+		balance models.AccountBalance
+		history []models.BalanceSnapshot
+	}
+	var tracked []trackedAccount
+
+	for _, account := range accounts {
+		history := ReconstructBalanceHistory(account, transactions, days, now)
+		history = overlaySnapshots(history, s.reportingSnapshots(customerID, s.Snapshots(customerID, account.ID)))
+		tracked = append(tracked, trackedAccount{
+			balance: models.AccountBalance{
+				AccountID:      account.ID,
+				Name:           account.Nickname,
+				Type:           account.Type,
+				Classification: ClassifyAccount(account.Type),
+			},
+			history: history,
+		})
+	}
+	for _, account := range s.ManualAccounts(customerID) {
+		classification := AccountClassAsset
+		if account.IsLiability {
+			classification = AccountClassLiability
+		}
+		tracked = append(tracked, trackedAccount{
+			balance: models.AccountBalance{
+				AccountID:      account.ID,
+				Name:           account.Name,
+				Type:           account.Type,
+				Classification: classification,
+				Manual:         true,
+			},
+			history: manualHistory(account.ID, s.reportingSnapshots(customerID, s.Snapshots(customerID, account.ID)), days, now),
+		})
+	}
+
+	summary := models.NetWorthSummary{
+		History:   make([]models.NetWorthPoint, days),
+		Breakdown: []models.NetWorthBreakdown{},
+		Accounts:  []models.AccountBalance{},
+		Currency:  s.currency.Preferences(customerID).ReportingCurrency,
+	}
+	for i := range summary.History {
+		summary.History[i].Date = startOfDay(now).AddDate(0, 0, i-(days-1))
+	}
+
+	breakdown := make(map[string]*models.NetWorthBreakdown)
+	var breakdownKeys []string
+	for _, account := range tracked {
+		isLiability := account.balance.Classification == AccountClassLiability
+		for i, snapshot := range account.history {
+			if isLiability {
+				summary.History[i].Liabilities += amountOwed(snapshot.Balance, account.balance.Manual)
+			} else {
+				summary.History[i].Assets += snapshot.Balance
+			}
+		}
+
+		current := account.history[len(account.history)-1].Balance
+		if isLiability {
+			current = amountOwed(current, account.balance.Manual)
+		}
+		account.balance.Balance = roundCents(current)
+		summary.Accounts = append(summary.Accounts, account.balance)
+
+		key := account.balance.Classification + "|" + account.balance.Type
+		entry, exists := breakdown[key]
+		if !exists {
+			entry = &models.NetWorthBreakdown{Type: account.balance.Type, Classification: account.balance.Classification}
+			breakdown[key] = entry
+			breakdownKeys = append(breakdownKeys, key)
+		}
+		entry.Balance = roundCents(entry.Balance + current)
+		entry.Accounts++
+	}
+
+	for i := range summary.History {
+		point := &summary.History[i]
+		point.Assets = roundCents(point.Assets)
+		point.Liabilities = roundCents(point.Liabilities)
+		point.NetWorth = roundCents(point.Assets - point.Liabilities)
+	}
+
+	// Assets first, then largest balance first
+	for _, key := range breakdownKeys {
+		summary.Breakdown = append(summary.Breakdown, *breakdown[key])
+	}
+	sort.SliceStable(summary.Breakdown, func(i, j int) bool {
+		if summary.Breakdown[i].Classification != summary.Breakdown[j].Classification {
+			return summary.Breakdown[i].Classification == AccountClassAsset
+		}
+		return summary.Breakdown[i].Balance > summary.Breakdown[j].Balance
+	})
+
+	latest := summary.History[len(summary.History)-1]
+	summary.Assets = latest.Assets
+	summary.Liabilities = latest.Liabilities
+	summary.NetWorth = latest.NetWorth
+	summary.Change = roundCents(latest.NetWorth - summary.History[0].NetWorth)
+	return summary
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"financeai-backend/models"
+)
+
+func TestBuildNetWorthLiabilities(t *testing.T) {
+	now := time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)
+	store := NewNetWorthStore()
+	name, loanType, balance := "Car Loan", "auto_loan", 8000.0
+	if _, err := store.AddManualAccount("c1", ManualAccountInput{Name: &name, Type: &loanType, Balance: &balance}, now); err != nil {
+		t.Fatal(err)
+	}
+	accounts := []models.Account{
+		{ID: "checking", Nickname: "Checking", Type: "Checking", Balance: 5000},
+		{ID: "card", Nickname: "Card", Type: "Credit Card", Balance: -1200},
+		{ID: "overpaid", Nickname: "Overpaid Card", Type: "Credit Card", Balance: 150},
+	}
+	// Paying the card off yesterday took $300 off what was owed
+	transactions := []models.Transaction{
+		{ID: "payment", AccountID: "card", Amount: 300, TransactionDate: now.AddDate(0, 0, -1)},
+	}
+
+	summary := store.BuildNetWorth("c1", accounts, transactions, 3, now)
+	if summary.Assets != 5000 || summary.Liabilities != 9200 || summary.NetWorth != -4200 {
+		t.Errorf("assets %v, liabilities %v, net worth %v; want 5000, 9200 and -4200", summary.Assets, summary.Liabilities, summary.NetWorth)
+	}
+	if first := summary.History[0]; first.Liabilities != 9500 {
+		t.Errorf("liabilities before the payment = %v, want 9500", first.Liabilities)
+	}
+
+	owed := map[string]float64{}
+	for _, account := range summary.Accounts {
+		owed[account.Name] = account.Balance
+	}
+	if owed["Card"] != 1200 || owed["Overpaid Card"] != 0 || owed["Car Loan"] != 8000 {
+		t.Errorf("account balances = %v, want the card owing 1200, the overpaid card nothing and the loan 8000", owed)
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Notification channel names
+const (
+	ChannelInApp   = "in_app"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+)
+
+// NotificationChannel delivers notifications to customers
+type NotificationChannel interface {
+	Name() string
+	Send(ctx context.Context, preferences models.NotificationPreferences, notification models.Notification) error
+}
+
+// InAppChannel keeps notifications in an inbox the app reads from
+type InAppChannel struct {
+	mu    sync.RWMutex
+	inbox map[string][]models.Notification
+}
+
+// NewInAppChannel creates an empty in-app inbox
+func NewInAppChannel() *InAppChannel {
+	return &InAppChannel{
+		inbox: make(map[string][]models.Notification),
+	}
+}
+
+// Name returns the channel name
+func (c *InAppChannel) Name() string {
+	return ChannelInApp
+}
+
+// Send adds a notification to the customer's inbox
+func (c *InAppChannel) Send(ctx context.Context, preferences models.NotificationPreferences, notification models.Notification) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	notification.Deliveries = append([]models.NotificationDelivery(nil), notification.Deliveries...)
+	c.inbox[notification.CustomerID] = append(c.inbox[notification.CustomerID], notification)
+	return nil
+}
+
+// UpdateDelivery records how a notification in the inbox did on one channel
+func (c *InAppChannel) UpdateDelivery(customerID string, notificationID string, delivery models.NotificationDelivery) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.inbox[customerID] {
+		if c.inbox[customerID][i].ID != notificationID {
+			continue
+		}
+		// Replace the slice rather than writing into it, since copies
+		// handed out by List share it
+		deliveries := make([]models.NotificationDelivery, 0, len(c.inbox[customerID][i].Deliveries))
+		for _, existing := range c.inbox[customerID][i].Deliveries {
+			if existing.Channel == delivery.Channel {
+				existing = delivery
+			}
+			deliveries = append(deliveries, existing)
+		}
+		c.inbox[customerID][i].Deliveries = deliveries
+		return
+	}
+}
+
+// List returns a customer's notifications, newest first
+func (c *InAppChannel) List(customerID string, unreadOnly bool) []models.Notification {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	notifications := []models.Notification{}
+	for _, notification := range c.inbox[customerID] {
+		if unreadOnly && notification.Read {
+			continue
+		}
+		notifications = append(notifications, notification)
+	}
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+	return notifications
+}
+
+// MarkRead marks a notification in the inbox as read
+func (c *InAppChannel) MarkRead(customerID string, notificationID string) (*models.Notification, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.inbox[customerID] {
+		if c.inbox[customerID][i].ID == notificationID {
+			c.inbox[customerID][i].Read = true
+			notification := c.inbox[customerID][i]
+			return &notification, nil
+		}
+	}
+	return nil, NewNotFoundError("notification not found: %s", notificationID)
+}
+
+// Prune removes read notifications created before cutoff and returns how many it removed
+func (c *InAppChannel) Prune(cutoff time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for customerID, notifications := range c.inbox {
+		kept := notifications[:0]
+		for _, notification := range notifications {
+			if notification.Read && notification.CreatedAt.Before(cutoff) {
+				removed++
+				continue
+			}
+			kept = append(kept, notification)
+		}
+		c.inbox[customerID] = kept
+	}
+	return removed
+}
+
+// WebhookChannel sends notifications to the customer's notification webhook
+// through the webhook service, so they're signed, retried and logged like
+// any other webhook delivery
+type WebhookChannel struct {
+	Webhooks *WebhookService
+}
+
+// NewWebhookChannel creates a webhook channel that delivers through webhooks
+func NewWebhookChannel(webhooks *WebhookService) *WebhookChannel {
+	return &WebhookChannel{Webhooks: webhooks}
+}
+
+// Name returns the channel name
+func (c *WebhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+// Send delivers the notification and reports how the first attempt went.
+// Deliveries still being retried count as failed here; the webhook's
+// delivery log has how they end.
+func (c *WebhookChannel) Send(ctx context.Context, preferences models.NotificationPreferences, notification models.Notification) error {
+	delivery, err := c.Webhooks.DeliverNotification(notification)
+	if err != nil {
+		return err
+	}
+	if delivery.Status == WebhookStatusSucceeded {
+		return nil
+	}
+
+	reason := "no attempts made"
+	if len(delivery.Attempts) > 0 {
+		reason = delivery.Attempts[len(delivery.Attempts)-1].Error
+	}
+	if delivery.Status == WebhookStatusRetrying {
+		return newUpstreamError("webhook delivery %s failed and will be retried: %s", delivery.ID, reason)
+	}
+	return newUpstreamError("webhook delivery %s failed: %s", delivery.ID, reason)
+}
+
+// SMTPConfig holds the mail server settings for email notifications
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// EmailChannel sends notifications as plain text email over SMTP
+type EmailChannel struct {
+	mu     sync.RWMutex
+	config SMTPConfig
+}
+
+// NewEmailChannel creates an email channel using config
+func NewEmailChannel(config SMTPConfig) *EmailChannel {
+	return &EmailChannel{config: config}
+}
+
+// Configure replaces the channel's mail server settings
+func (c *EmailChannel) Configure(config SMTPConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config = config
+}
+
+// Name returns the channel name
+func (c *EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+// Send emails the notification to the customer's address. Authentication is
+// only used when a username is set, so a local SMTP stand-in such as MailHog
+// works without credentials.
+func (c *EmailChannel) Send(ctx context.Context, preferences models.NotificationPreferences, notification models.Notification) error {
+	c.mu.RLock()
+	config := c.config
+	c.mu.RUnlock()
+
+	if config.Host == "" {
+		return fmt.Errorf("email is not configured")
+	}
+	if preferences.Email == "" {
+		return fmt.Errorf("no email address configured")
+	}
+	if strings.ContainsAny(preferences.Email, "\r\n") || strings.ContainsAny(config.From, "\r\n") {
+		return fmt.Errorf("invalid email address")
+	}
+
+	port := config.Port
+	if port == 0 {
+		port = 25
+	}
+	from := config.From
+	if from == "" {
+		from = "notifications@finsights.local"
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", preferences.Email)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Title))
+	fmt.Fprintf(&message, "Date: %s\r\n", notification.CreatedAt.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(notification.Message, "\n", "\r\n"))
+	message.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(port))
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, from, []string{preferences.Email}, message.Bytes())
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return newUpstreamError("failed to send email: %v", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return newUpstreamError("failed to send email: %v", ctx.Err())
+	}
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// smtpMessage is a message an smtpStub accepted
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpStub is a local SMTP stand-in that speaks just enough of the protocol
+// for net/smtp, and hands each message it accepts to messages
+type smtpStub struct {
+	listener         net.Listener
+	messages         chan smtpMessage
+	rejectRecipients bool
+}
+
+func startSMTPStub(t *testing.T) *smtpStub {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	stub := &smtpStub{listener: listener, messages: make(chan smtpMessage, 10)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go stub.serve(conn)
+		}
+	}()
+	return stub
+}
+
+// config returns SMTP settings pointing at the stub
+func (s *smtpStub) config() SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	number, _ := strconv.Atoi(port)
+	return SMTPConfig{Host: host, Port: number, From: "alerts@finsights.test"}
+}
+
+func (s *smtpStub) serve(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		fmt.Fprintf(conn, "%s\r\n", line)
+	}
+
+	reply("220 localhost ESMTP stub")
+	var message smtpMessage
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message = smtpMessage{from: strings.Trim(line[len("MAIL FROM:"):], "<> ")}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			if s.rejectRecipients {
+				reply("550 no such mailbox")
+				continue
+			}
+			message.to = append(message.to, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			message.data = data.String()
+			s.messages <- message
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func testNotification() models.Notification {
+	return models.Notification{
+		ID:         "ntf1",
+		CustomerID: "sarah",
+		EventType:  EventBudgetThreshold,
+		Title:      "Food & Dining budget 80% used",
+		Message:    "You've spent $80.00 of your $100.00 Food & Dining budget this month (80%).\nKeep an eye on it.",
+		CreatedAt:  time.Date(2026, time.March, 15, 9, 0, 0, 0, time.UTC),
+		Deliveries: []models.NotificationDelivery{},
+	}
+}
+
+func TestEmailChannelSendsThroughSMTP(t *testing.T) {
+	stub := startSMTPStub(t)
+	channel := NewEmailChannel(stub.config())
+
+	preferences := models.NotificationPreferences{Email: "sarah@example.com"}
+	if err := channel.Send(context.Background(), preferences, testNotification()); err != nil {
+		t.Fatalf("Send returned %v", err)
+	}
+
+	select {
+	case message := <-stub.messages:
+		if message.from != "alerts@finsights.test" {
+			t.Errorf("MAIL FROM = %q, want alerts@finsights.test", message.from)
+		}
+		if len(message.to) != 1 || message.to[0] != "sarah@example.com" {
+			t.Errorf("RCPT TO = %v, want [sarah@example.com]", message.to)
+		}
+		for _, want := range []string{
+			"To: sarah@example.com\r\n",
+			"Subject: Food & Dining budget 80% used\r\n",
+			"Content-Type: text/plain; charset=utf-8\r\n",
+			"this month (80%).\r\nKeep an eye on it.\r\n",
+		} {
+			if !strings.Contains(message.data, want) {
+				t.Errorf("message is missing %q:\n%s", want, message.data)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message reached the SMTP stub")
+	}
+}
+
+func TestEmailChannelReportsRejectedRecipient(t *testing.T) {
+	stub := startSMTPStub(t)
+	stub.rejectRecipients = true
+	channel := NewEmailChannel(stub.config())
+
+	err := channel.Send(context.Background(), models.NotificationPreferences{Email: "nobody@example.com"}, testNotification())
+	if !errors.Is(err, ErrUpstream) {
+		t.Fatalf("Send returned %v, want an upstream error", err)
+	}
+}
+
+func TestEmailChannelChecksSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SMTPConfig
+		email  string
+	}{
+		{"no mail server", SMTPConfig{}, "sarah@example.com"},
+		{"no address", SMTPConfig{Host: "127.0.0.1"}, ""},
+		{"header injection", SMTPConfig{Host: "127.0.0.1"}, "sarah@example.com\r\nBcc: someone@example.com"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			channel := NewEmailChannel(test.config)
+			if err := channel.Send(context.Background(), models.NotificationPreferences{Email: test.email}, testNotification()); err == nil {
+				t.Fatal("Send succeeded, want an error")
+			}
+		})
+	}
+}
+
+// testWebhookService returns a webhook service that retries quickly
+func testWebhookService() *WebhookService {
+	service := NewWebhookService()
+	service.Retry = RetryPolicy{MaxAttempts: 3, InitialDelay: 10 * time.Millisecond, MaxDelay: 20 * time.Millisecond}
+	return service
+}
+
+func TestWebhookChannelSignsNotifications(t *testing.T) {
+	type request struct {
+		header http.Header
+		body   []byte
+	}
+	received := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- request{r.Header.Clone(), body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhooks := testWebhookService()
+	subscription, err := webhooks.SetNotificationWebhook("sarah", server.URL, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewWebhookChannel(webhooks).Send(context.Background(), models.NotificationPreferences{}, testNotification()); err != nil {
+		t.Fatalf("Send returned %v", err)
+	}
+
+	sent := <-received
+	if err := VerifyWebhookSignature(subscription.Secret, sent.header.Get(WebhookSignatureHeader), sent.body, time.Now()); err != nil {
+		t.Errorf("signature doesn't verify: %v", err)
+	}
+	if eventType := sent.header.Get(WebhookEventHeader); eventType != EventBudgetThreshold {
+		t.Errorf("%s = %q, want %s", WebhookEventHeader, eventType, EventBudgetThreshold)
+	}
+	var event models.Event
+	if err := json.Unmarshal(sent.body, &event); err != nil || event.Title != testNotification().Title || event.CustomerID != "sarah" {
+		t.Errorf("endpoint received %s (%v)", sent.body, err)
+	}
+	if deliveries := webhooks.Deliveries("sarah", subscription.ID, 0); len(deliveries) != 1 || deliveries[0].Status != WebhookStatusSucceeded {
+		t.Errorf("delivery log = %+v, want one succeeded delivery", deliveries)
+	}
+}
+
+func TestWebhookChannelRetriesFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhooks := testWebhookService()
+	if _, err := webhooks.SetNotificationWebhook("sarah", server.URL, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	err := NewWebhookChannel(webhooks).Send(context.Background(), models.NotificationPreferences{}, testNotification())
+	if !errors.Is(err, ErrUpstream) || !strings.Contains(err.Error(), "will be retried") {
+		t.Fatalf("Send returned %v, want an upstream error saying it'll retry", err)
+	}
+
+	deadLetters := waitForDeadLetters(t, webhooks, "sarah", 1)
+	if attempts := len(deadLetters[0].Attempts); attempts != 3 {
+		t.Errorf("dead-lettered after %d attempts, want 3", attempts)
+	}
+}
+
+func TestWebhookChannelNeedsNotificationWebhook(t *testing.T) {
+	webhooks := testWebhookService()
+	channel := NewWebhookChannel(webhooks)
+	if err := channel.Send(context.Background(), models.NotificationPreferences{}, testNotification()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Send without a notification webhook returned %v, want not found", err)
+	}
+
+	if _, err := webhooks.SetNotificationWebhook("sarah", "https://example.com/hooks", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	webhooks.DisableNotificationWebhook("sarah")
+	if err := channel.Send(context.Background(), models.NotificationPreferences{}, testNotification()); err == nil {
+		t.Error("Send to a turned off notification webhook succeeded")
+	}
+}
+
+func TestInAppChannelInbox(t *testing.T) {
+	inbox := NewInAppChannel()
+	older := testNotification()
+	older.ID, older.CreatedAt = "ntf1", time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	newer := testNotification()
+	newer.ID, newer.CreatedAt = "ntf2", time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+	newer.Deliveries = []models.NotificationDelivery{{Channel: ChannelEmail, Status: "pending"}}
+	inbox.Send(context.Background(), models.NotificationPreferences{}, older)
+	inbox.Send(context.Background(), models.NotificationPreferences{}, newer)
+
+	listed := inbox.List("sarah", false)
+	if len(listed) != 2 || listed[0].ID != "ntf2" || listed[1].ID != "ntf1" {
+		t.Fatalf("List = %+v, want ntf2 then ntf1", listed)
+	}
+	if len(inbox.List("michael", false)) != 0 {
+		t.Error("another customer's inbox isn't empty")
+	}
+
+	inbox.UpdateDelivery("sarah", "ntf2", models.NotificationDelivery{Channel: ChannelEmail, Status: "sent"})
+	if status := inbox.List("sarah", false)[0].Deliveries[0].Status; status != "sent" {
+		t.Errorf("delivery status after update = %q, want sent", status)
+	}
+	if status := listed[0].Deliveries[0].Status; status != "pending" {
+		t.Errorf("earlier List copy changed to %q", status)
+	}
+
+	if _, err := inbox.MarkRead("sarah", "ntf1"); err != nil {
+		t.Fatalf("MarkRead returned %v", err)
+	}
+	if _, err := inbox.MarkRead("sarah", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("MarkRead of a missing notification returned %v, want not found", err)
+	}
+	if unread := inbox.List("sarah", true); len(unread) != 1 || unread[0].ID != "ntf2" {
+		t.Errorf("unread = %+v, want only ntf2", unread)
+	}
+
+	if removed := inbox.Prune(time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)); removed != 1 {
+		t.Errorf("Prune removed %d, want 1", removed)
+	}
+	if remaining := inbox.List("sarah", false); len(remaining) != 1 || remaining[0].ID != "ntf2" {
+		t.Errorf("after Prune = %+v, want only ntf2", remaining)
+	}
+}
@@ -0,0 +1,657 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// NotificationEventTypes lists the events customers can turn notifications on or off for
+var NotificationEventTypes = []string{
+	EventBudgetThreshold,
+	EventLargeTransaction,
+	EventLowBalance,
+	EventBillUpcoming,
+	EventGoalMilestone,
+	EventWeeklyDigest,
+}
+
+// goalMilestones are the progress percentages that trigger goal notifications
+var goalMilestones = []float64{25, 50, 75, 100}
+
+// DefaultNotificationPreferences returns the preferences a customer starts with:
+// every event on, delivered to the in-app inbox. They have no budgets of their
+// own; until the customer sets some, the budgets they last sent for insights
+// or a monthly report are used
+func DefaultNotificationPreferences(customerID string) models.NotificationPreferences {
+	events := make(map[string]bool, len(NotificationEventTypes))
+	for _, eventType := range NotificationEventTypes {
+		events[eventType] = true
+	}
+	return models.NotificationPreferences{
+		CustomerID:                customerID,
+		Events:                    events,
+		Channels:                  []string{ChannelInApp},
+		Budgets:                   map[string]float64{},
+		BudgetThresholds:          []int{50, 80, 100},
+		LargeTransactionThreshold: 250,
+		LowBalanceThreshold:       100,
+		BillReminderDays:          3,
+	}
+}
+
+// ValidateNotificationPreferences checks preferences and normalizes them in place
+func ValidateNotificationPreferences(preferences *models.NotificationPreferences) error {
+	for eventType := range preferences.Events {
+		known := false
+		for _, candidate := range NotificationEventTypes {
+			known = known || candidate == eventType
+		}
+		if !known {
+			return NewValidationError("events", "unknown event type: %s", eventType)
+		}
+	}
+
+	seenChannels := make(map[string]bool)
+	channels := []string{}
+	for _, channel := range preferences.Channels {
+		if channel != ChannelInApp && channel != ChannelWebhook && channel != ChannelEmail {
+			return NewValidationError("channels", "unknown channel: %s", channel)
+		}
+		if !seenChannels[channel] {
+			seenChannels[channel] = true
+			channels = append(channels, channel)
+		}
+	}
+	preferences.Channels = channels
+
+	preferences.Email = strings.TrimSpace(preferences.Email)
+	if seenChannels[ChannelEmail] && preferences.Email == "" {
+		return NewValidationError("email", "email required for the email channel")
+	}
+	if preferences.Email != "" && (!strings.Contains(preferences.Email, "@") || strings.ContainsAny(preferences.Email, " \r\n")) {
+		return NewValidationError("email", "invalid email address: %s", preferences.Email)
+	}
+
+	preferences.WebhookURL = strings.TrimSpace(preferences.WebhookURL)
+	if seenChannels[ChannelWebhook] && preferences.WebhookURL == "" {
+		return NewValidationError("webhookUrl", "webhookUrl required for the webhook channel")
+	}
+	if preferences.WebhookURL != "" {
+		parsed, err := url.Parse(preferences.WebhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return NewValidationError("webhookUrl", "webhookUrl must be an http or https URL")
+		}
+	}
+
+	for key, amount := range preferences.Budgets {
+		if amount < 0 {
+			return NewValidationError("budgets", "budget for %s must not be negative", key)
+		}
+	}
+
+	seenThresholds := make(map[int]bool)
+	thresholds := []int{}
+	for _, threshold := range preferences.BudgetThresholds {
+		if threshold < 1 || threshold > 200 {
+			return NewValidationError("budgetThresholds", "budget thresholds must be between 1 and 200 percent")
+		}
+		if !seenThresholds[threshold] {
+			seenThresholds[threshold] = true
+			thresholds = append(thresholds, threshold)
+		}
+	}
+	sort.Ints(thresholds)
+	preferences.BudgetThresholds = thresholds
+
+	if preferences.LargeTransactionThreshold < 0 {
+		return NewValidationError("largeTransactionThreshold", "large transaction threshold must not be negative")
+	}
+	if preferences.LowBalanceThreshold < 0 {
+		return NewValidationError("lowBalanceThreshold", "low balance threshold must not be negative")
+	}
+	if preferences.BillReminderDays < 0 || preferences.BillReminderDays > 60 {
+		return NewValidationError("billReminderDays", "bill reminder days must be between 0 and 60")
+	}
+	return nil
+}
+
+// NotificationService turns events into notifications and delivers them on
+// the channels each customer has chosen. An event is only delivered once per
+// key, so re-evaluating a customer doesn't repeat notifications.
+type NotificationService struct {
+	mu          sync.RWMutex
+	preferences map[string]models.NotificationPreferences
+	// budgets are the budgets customers last sent with other requests, such
+	// as for insights, used until they set budgets in their preferences
+	budgets   map[string]map[string]float64
+	delivered map[string]map[string]bool
+	channels    map[string]NotificationChannel
+	inbox       *InAppChannel
+	email       *EmailChannel
+	webhooks    *WebhookService
+	nextID      int
+
+	// Timeout bounds how long each channel may take to deliver
+	Timeout time.Duration
+}
+
+// defaultNotificationService delivers the events published on the default bus
+var defaultNotificationService = newDefaultNotificationService()
+
+// NewNotificationService creates a notification service with the in-app,
+// webhook and email channels. Webhook notifications are delivered by webhooks.
+func NewNotificationService(smtpConfig SMTPConfig, webhooks *WebhookService) *NotificationService {
+	service := &NotificationService{
+		preferences: make(map[string]models.NotificationPreferences),
+		budgets:     make(map[string]map[string]float64),
+		delivered:   make(map[string]map[string]bool),
+		channels:    make(map[string]NotificationChannel),
+		inbox:       NewInAppChannel(),
+		email:       NewEmailChannel(smtpConfig),
+		webhooks:    webhooks,
+		Timeout:     15 * time.Second,
+	}
+	service.RegisterChannel(service.inbox)
+	service.RegisterChannel(NewWebhookChannel(webhooks))
+	service.RegisterChannel(service.email)
+	return service
+}
+
+func newDefaultNotificationService() *NotificationService {
+	service := NewNotificationService(SMTPConfig{}, defaultWebhookService)
+	for _, eventType := range NotificationEventTypes {
+		defaultEventBus.Subscribe(eventType, func(event models.Event) {
+			if _, err := service.Handle(event); err != nil {
+				fmt.Printf("Notification Error: %v\n", err)
+			}
+		})
+	}
+	return service
+}
+
+// DefaultNotificationService returns the notification service subscribed to the default event bus
+func DefaultNotificationService() *NotificationService {
+	return defaultNotificationService
+}
+
+// ConfigureSMTP sets the mail server the default notification service sends email through
+func ConfigureSMTP(config SMTPConfig) {
+	defaultNotificationService.email.Configure(config)
+}
+
+// RegisterChannel adds or replaces a delivery channel
+func (s *NotificationService) RegisterChannel(channel NotificationChannel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[channel.Name()] = channel
+}
+
+// Inbox returns the in-app notification inbox
+func (s *NotificationService) Inbox() *InAppChannel {
+	return s.inbox
+}
+
+// Preferences returns a customer's notification preferences. Customers who
+// haven't set budgets in them get the budgets they last sent elsewhere; see
+// RememberBudgets.
+func (s *NotificationService) Preferences(customerID string) models.NotificationPreferences {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	preferences, exists := s.preferences[customerID]
+	if !exists {
+		preferences = DefaultNotificationPreferences(customerID)
+	}
+	budgets := preferences.Budgets
+	if len(budgets) == 0 {
+		budgets = s.budgets[customerID]
+	}
+	// Copy the maps and slices so callers can't change the stored preferences
+	copied := preferences
+	copied.Events = make(map[string]bool, len(preferences.Events))
+	for key, value := range preferences.Events {
+		copied.Events[key] = value
+	}
+	copied.Budgets = make(map[string]float64, len(budgets))
+	for key, value := range budgets {
+		copied.Budgets[key] = value
+	}
+	copied.Channels = append([]string(nil), preferences.Channels...)
+	copied.BudgetThresholds = append([]int(nil), preferences.BudgetThresholds...)
+	return copied
+}
+
+// RememberBudgets records the budgets a customer sent with another request,
+// such as for insights, so budget notifications use the same budgets until
+// the customer sets their own in their preferences. Budgets that aren't
+// positive are left out, and an empty set changes nothing.
+func (s *NotificationService) RememberBudgets(customerID string, budgets map[string]float64) {
+	remembered := make(map[string]float64, len(budgets))
+	for key, amount := range budgets {
+		if amount > 0 {
+			remembered[key] = amount
+		}
+	}
+	if len(remembered) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.budgets[customerID] = remembered
+}
+
+// SetPreferences validates and stores a customer's notification preferences.
+// Turning on the webhook channel sets up the customer's notification webhook;
+// the returned preferences carry its signing secret the first time.
+func (s *NotificationService) SetPreferences(customerID string, preferences models.NotificationPreferences) (*models.NotificationPreferences, error) {
+	preferences.CustomerID = customerID
+	preferences.WebhookSecret = ""
+	if preferences.Events == nil {
+		preferences.Events = map[string]bool{}
+	}
+	if preferences.Budgets == nil {
+		preferences.Budgets = map[string]float64{}
+	}
+	if err := ValidateNotificationPreferences(&preferences); err != nil {
+		return nil, err
+	}
+
+	secret := ""
+	webhook := false
+	for _, channel := range preferences.Channels {
+		webhook = webhook || channel == ChannelWebhook
+	}
+	if webhook {
+		subscription, err := s.webhooks.SetNotificationWebhook(customerID, preferences.WebhookURL, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		secret = subscription.Secret
+	} else {
+		s.webhooks.DisableNotificationWebhook(customerID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.preferences[customerID] = preferences
+	saved := preferences
+	saved.WebhookSecret = secret
+	return &saved, nil
+}
+
+// Handle delivers an event to the customer if they've turned that event on
+// and haven't been notified about its key yet. It returns nil when the event
+// is skipped. Test events are always delivered. The inbox gets the
+// notification right away; other channels deliver in the background, so a
+// slow mail server or endpoint doesn't hold up whoever published the event,
+// and the inbox copy is updated as each finishes.
+func (s *NotificationService) Handle(event models.Event) (*models.Notification, error) {
+	return s.handle(event, false)
+}
+
+// HandleAndWait is Handle, but waits for every channel to finish and reports
+// how each did, for callers that show the outcome such as test notifications
+func (s *NotificationService) HandleAndWait(event models.Event) (*models.Notification, error) {
+	return s.handle(event, true)
+}
+
+func (s *NotificationService) handle(event models.Event, wait bool) (*models.Notification, error) {
+	preferences := s.Preferences(event.CustomerID)
+	if event.Type != EventNotificationTest && !preferences.Events[event.Type] {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	if event.Key != "" {
+		if s.delivered[event.CustomerID][event.Key] {
+			s.mu.Unlock()
+			return nil, nil
+		}
+		if _, exists := s.delivered[event.CustomerID]; !exists {
+			s.delivered[event.CustomerID] = make(map[string]bool)
+		}
+		s.delivered[event.CustomerID][event.Key] = true
+	}
+	s.nextID++
+	notification := models.Notification{
+		ID:         fmt.Sprintf("ntf%d", s.nextID),
+		CustomerID: event.CustomerID,
+		EventType:  event.Type,
+		Title:      event.Title,
+		Message:    event.Message,
+		Data:       event.Data,
+		CreatedAt:  event.OccurredAt,
+		Deliveries: []models.NotificationDelivery{},
+	}
+	channels := make(map[string]NotificationChannel, len(s.channels))
+	for name, channel := range s.channels {
+		channels[name] = channel
+	}
+	s.mu.Unlock()
+
+	// Other channels start out pending, so the inbox copy shows they're on
+	// their way
+	var external []NotificationChannel
+	sendInApp := false
+	for _, name := range preferences.Channels {
+		if name == ChannelInApp {
+			sendInApp = true
+			continue
+		}
+		if channel, exists := channels[name]; exists {
+			external = append(external, channel)
+			notification.Deliveries = append(notification.Deliveries, models.NotificationDelivery{Channel: name, Status: "pending"})
+		}
+	}
+	if sendInApp {
+		notification.Deliveries = append(notification.Deliveries, models.NotificationDelivery{Channel: ChannelInApp, Status: "sent"})
+		s.inbox.Send(context.Background(), preferences, notification)
+	}
+
+	sent := notification
+	sent.Deliveries = append([]models.NotificationDelivery(nil), notification.Deliveries...)
+	results := make([]models.NotificationDelivery, len(external))
+	var wg sync.WaitGroup
+	for i, channel := range external {
+		wg.Add(1)
+		go func(i int, channel NotificationChannel) {
+			defer wg.Done()
+			results[i] = s.deliver(channel, preferences, sent)
+			s.inbox.UpdateDelivery(sent.CustomerID, sent.ID, results[i])
+			if !wait && results[i].Error != "" {
+				fmt.Printf("Notification Error: notification %s for %s: %s: %s\n", sent.ID, sent.CustomerID, results[i].Channel, results[i].Error)
+			}
+		}(i, channel)
+	}
+	if !wait {
+		return &notification, nil
+	}
+	wg.Wait()
+
+	var failures []string
+	for i, delivery := range results {
+		notification.Deliveries[i] = delivery
+		if delivery.Error != "" {
+			failures = append(failures, fmt.Sprintf("%s: %s", delivery.Channel, delivery.Error))
+		}
+	}
+	if len(failures) > 0 {
+		return &notification, newUpstreamError("notification %s for %s: %s", notification.ID, event.CustomerID, strings.Join(failures, "; "))
+	}
+	return &notification, nil
+}
+
+// deliver sends a notification on one channel within the service's timeout
+// and reports how it went
+func (s *NotificationService) deliver(channel NotificationChannel, preferences models.NotificationPreferences, notification models.Notification) models.NotificationDelivery {
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+
+	delivery := models.NotificationDelivery{Channel: channel.Name(), Status: "sent"}
+	if err := channel.Send(ctx, preferences, notification); err != nil {
+		delivery.Status = "failed"
+		delivery.Error = err.Error()
+	}
+	return delivery
+}
+
+// NotificationSnapshot is the state of a customer's finances that
+// notification events are worked out from
+type NotificationSnapshot struct {
+	Accounts     []models.Account
+	Transactions []models.Transaction
+	Bills        []models.UpcomingBill
+	Goals        []models.Goal
+	Preferences  models.NotificationPreferences
+	// Money formats amounts in messages, in the currency the snapshot is in
+	Money MoneyFormatter
+	Now   time.Time
+}
+
+// EvaluateNotificationEvents works out the events a customer's current
+// finances call for: budgets past a threshold or exceeded this month, large recent
+// transactions, low balances, bills due soon and goal milestones. Each event
+// has a key so the same condition only notifies once.
+func EvaluateNotificationEvents(snapshot NotificationSnapshot) []models.Event {
+	var events []models.Event
+	preferences := snapshot.Preferences
+	customerID := preferences.CustomerID
+	now := snapshot.Now
+	money := snapshot.Money
+	event := func(eventType string, key string, title string, message string, data map[string]interface{}) {
+		events = append(events, models.Event{
+			Type:       eventType,
+			CustomerID: customerID,
+			Key:        key,
+			Title:      title,
+			Message:    message,
+			Data:       data,
+			OccurredAt: now,
+		})
+	}
+
+	// Budget thresholds, for the highest threshold crossed this month
+	month := now.Format(ReportMonthLayout)
+	monthTransactions := MonthTransactions(snapshot.Transactions, now)
+	categorySpending := make(map[string]float64)
+	for category, amount := range spendingByCategory(monthTransactions) {
+		categorySpending[category] = amount
+	}
+	budgetKeys := make([]string, 0, len(preferences.Budgets))
+	for key := range preferences.Budgets {
+		budgetKeys = append(budgetKeys, key)
+	}
+	sort.Strings(budgetKeys)
+	for _, key := range budgetKeys {
+		budget := preferences.Budgets[key]
+		if budget <= 0 {
+			continue
+		}
+		label, spent := key, 0.0
+		if tag := strings.TrimPrefix(key, TagBudgetPrefix); tag != key {
+			label = "#" + tag
+			for _, transaction := range monthTransactions {
+				if IsExpense(transaction) && hasTags(transaction, []string{tag}) {
+					spent += math.Abs(transaction.Amount)
+				}
+			}
+		} else {
+			for category, categoryKey := range budgetCategoryKeys {
+				if categoryKey == key {
+					label, spent = category, categorySpending[category]
+				}
+			}
+		}
+
+		percent := spent / budget * 100
+		crossed := 0
+		for _, threshold := range preferences.BudgetThresholds {
+			if percent >= float64(threshold) {
+				crossed = threshold
+			}
+		}
+		if crossed == 0 {
+			continue
+		}
+		if percent >= 100 {
+			event(EventBudgetExceeded,
+				fmt.Sprintf("budget-exceeded:%s:%s", key, month),
+				fmt.Sprintf("%s budget exceeded", label),
+				fmt.Sprintf("You've spent %s against your %s %s budget this month.", money.Format(spent), money.Format(budget), label),
+				map[string]interface{}{"budget": key, "spent": roundCents(spent), "limit": budget, "over": roundCents(spent - budget), "month": month},
+			)
+		}
+		event(EventBudgetThreshold,
+			fmt.Sprintf("budget:%s:%s:%d", key, month, crossed),
+			fmt.Sprintf("%s budget %d%% used", label, crossed),
+			fmt.Sprintf("You've spent %s of your %s %s budget this month (%.0f%%).", money.Format(spent), money.Format(budget), label, percent),
+			map[string]interface{}{"budget": key, "spent": roundCents(spent), "limit": budget, "threshold": crossed, "month": month},
+		)
+	}
+
+	// Large transactions from the last week
+	if preferences.LargeTransactionThreshold > 0 {
+		for _, transaction := range snapshot.Transactions {
+			amount := math.Abs(transaction.Amount)
+			if !IsExpense(transaction) || amount < preferences.LargeTransactionThreshold || transaction.TransactionDate.Before(now.AddDate(0, 0, -7)) {
+				continue
+			}
+			name := transaction.Merchant.Name
+			if name == "" {
+				name = transaction.Description
+			}
+			event(EventLargeTransaction,
+				"large:"+transaction.ID,
+				fmt.Sprintf("Large purchase at %s", name),
+				fmt.Sprintf("A %s charge from %s posted on %s.", money.Format(amount), name, transaction.TransactionDate.Format("Jan 2")),
+				map[string]interface{}{"transactionId": transaction.ID, "amount": roundCents(amount), "accountId": transaction.AccountID},
+			)
+		}
+	}
+
+	// Low balances on deposit accounts, at most once a day
+	for _, account := range snapshot.Accounts {
+		balance := float64(account.Balance)
+		if ClassifyAccount(account.Type) != AccountClassAsset || balance >= preferences.LowBalanceThreshold {
+			continue
+		}
+		event(EventLowBalance,
+			fmt.Sprintf("lowbalance:%s:%s", account.ID, now.Format(BillDateLayout)),
+			fmt.Sprintf("Low balance in %s", account.Nickname),
+			fmt.Sprintf("%s is down to %s, below your %s alert.", account.Nickname, money.Format(balance), money.Format(preferences.LowBalanceThreshold)),
+			map[string]interface{}{"accountId": account.ID, "balance": balance, "threshold": preferences.LowBalanceThreshold},
+		)
+	}
+
+	// Bills due within the reminder window
+	reminderEnd := startOfDay(now).AddDate(0, 0, preferences.BillReminderDays+1)
+	for _, bill := range snapshot.Bills {
+		if bill.DueDate.Before(startOfDay(now)) || !bill.DueDate.Before(reminderEnd) {
+			continue
+		}
+		message := fmt.Sprintf("%s for %s is due %s from %s.", bill.Name, money.Format(bill.Amount), bill.DueDate.Format("Mon Jan 2"), bill.AccountName)
+		if bill.InsufficientFunds {
+			message += fmt.Sprintf(" Your balance is projected to fall %s short.", money.Format(bill.Shortfall))
+		}
+		event(EventBillUpcoming,
+			fmt.Sprintf("bill:%s:%s", bill.BillID, bill.DueDate.Format(BillDateLayout)),
+			fmt.Sprintf("%s due %s", bill.Name, bill.DueDate.Format("Jan 2")),
+			message,
+			map[string]interface{}{"billId": bill.BillID, "amount": bill.Amount, "dueDate": bill.DueDate.Format(BillDateLayout), "insufficientFunds": bill.InsufficientFunds},
+		)
+	}
+
+	// Goal milestones, for the highest milestone reached
+	for _, goal := range snapshot.Goals {
+		reached := 0.0
+		for _, milestone := range goalMilestones {
+			if goal.Progress >= milestone {
+				reached = milestone
+			}
+		}
+		if reached == 0 {
+			continue
+		}
+		title := fmt.Sprintf("%s is %.0f%% funded", goal.Name, reached)
+		if reached >= 100 {
+			title = fmt.Sprintf("You reached your %s goal!", goal.Name)
+		}
+		event(EventGoalMilestone,
+			fmt.Sprintf("goal:%s:%.0f", goal.ID, reached),
+			title,
+			fmt.Sprintf("You've saved %s of your %s %s goal.", money.Format(goal.CurrentAmount), money.Format(goal.TargetAmount), goal.Name),
+			map[string]interface{}{"goalId": goal.ID, "milestone": reached, "current": goal.CurrentAmount, "target": goal.TargetAmount},
+		)
+	}
+
+	return events
+}
+
+// WeeklyDigestEvent summarizes a customer's last seven days: spending and
+// income, the biggest categories, bills due in the coming week and alerts
+// waiting for review, writing amounts with money
+func WeeklyDigestEvent(customerID string, transactions []models.Transaction, bills []models.UpcomingBill, alerts []models.Alert, money MoneyFormatter, now time.Time) models.Event {
+	weekStart := startOfDay(now).AddDate(0, 0, -7)
+	var spent, income float64
+	var week []models.Transaction
+	for _, transaction := range transactions {
+		if transaction.TransactionDate.Before(weekStart) || transaction.TransactionDate.After(now) {
+			continue
+		}
+		week = append(week, transaction)
+		if IsExpense(transaction) {
+			spent += math.Abs(transaction.Amount)
+		} else if IsIncome(transaction) {
+			income += transaction.Amount
+		}
+	}
+
+	byCategory := spendingByCategory(week)
+	categories := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if byCategory[categories[i]] != byCategory[categories[j]] {
+			return byCategory[categories[i]] > byCategory[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+	if len(categories) > 3 {
+		categories = categories[:3]
+	}
+	top := make([]map[string]interface{}, 0, len(categories))
+	var topLines []string
+	for _, category := range categories {
+		top = append(top, map[string]interface{}{"category": category, "amount": roundCents(byCategory[category])})
+		topLines = append(topLines, fmt.Sprintf("  %s: %s", category, money.Format(byCategory[category])))
+	}
+
+	var billsDue float64
+	for _, bill := range bills {
+		billsDue += bill.Amount
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "You spent %s and received %s over the last 7 days.", money.Format(spent), money.Format(income))
+	if len(topLines) > 0 {
+		fmt.Fprintf(&message, "\nTop categories:\n%s", strings.Join(topLines, "\n"))
+	}
+	if len(bills) == 1 {
+		fmt.Fprintf(&message, "\n1 bill for %s is due this week.", money.Format(billsDue))
+	} else if len(bills) > 1 {
+		fmt.Fprintf(&message, "\n%d bills totaling %s are due this week.", len(bills), money.Format(billsDue))
+	}
+	if len(alerts) == 1 {
+		message.WriteString("\n1 unusual transaction is waiting for your review.")
+	} else if len(alerts) > 1 {
+		fmt.Fprintf(&message, "\n%d unusual transactions are waiting for your review.", len(alerts))
+	}
+
+	year, isoWeek := now.ISOWeek()
+	return models.Event{
+		Type:       EventWeeklyDigest,
+		CustomerID: customerID,
+		Key:        fmt.Sprintf("digest:%d-W%02d", year, isoWeek),
+		Title:      fmt.Sprintf("Your week in review: %s spent", money.Format(spent)),
+		Message:    message.String(),
+		Data: map[string]interface{}{
+			"spent":         roundCents(spent),
+			"income":        roundCents(income),
+			"topCategories": top,
+			"billsDue":      len(bills),
+			"billsDueTotal": roundCents(billsDue),
+			"openAlerts":    len(alerts),
+		},
+		OccurredAt: now,
+	}
+}
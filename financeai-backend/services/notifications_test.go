@@ -0,0 +1,365 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// notificationTestNow is mid-month, so this month's spending is all on the 10th
+var notificationTestNow = time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+// blockingChannel delivers once release is closed, failing with err if set
+type blockingChannel struct {
+	name    string
+	release chan struct{}
+	err     error
+}
+
+func (c *blockingChannel) Name() string {
+	return c.name
+}
+
+func (c *blockingChannel) Send(ctx context.Context, preferences models.NotificationPreferences, notification models.Notification) error {
+	select {
+	case <-c.release:
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func foodPurchase(id string, amount float64, date time.Time) models.Transaction {
+	return models.Transaction{
+		ID:              id,
+		Amount:          -amount,
+		Description:     "Groceries",
+		TransactionDate: date,
+		AccountID:       "acc1",
+		Merchant:        models.Merchant{Name: "Whole Foods", Category: "Food & Dining"},
+	}
+}
+
+// budgetEvents returns the keys of the budget events in events
+func budgetEvents(events []models.Event) []string {
+	var keys []string
+	for _, event := range events {
+		if event.Type == EventBudgetThreshold || event.Type == EventBudgetExceeded {
+			keys = append(keys, event.Key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestEvaluateNotificationEventsBudgetThresholds(t *testing.T) {
+	tests := []struct {
+		name  string
+		spent float64
+		want  []string
+	}{
+		{"under every threshold", 49.99, nil},
+		{"at 50%", 50, []string{"budget:foodDining:2026-03:50"}},
+		{"between 50% and 80%", 79.99, []string{"budget:foodDining:2026-03:50"}},
+		{"at 80%", 80, []string{"budget:foodDining:2026-03:80"}},
+		{"at 100%", 100, []string{"budget-exceeded:foodDining:2026-03", "budget:foodDining:2026-03:100"}},
+		{"over budget", 140, []string{"budget-exceeded:foodDining:2026-03", "budget:foodDining:2026-03:100"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			preferences := DefaultNotificationPreferences("sarah")
+			preferences.Budgets = map[string]float64{"foodDining": 100}
+			events := EvaluateNotificationEvents(NotificationSnapshot{
+				Transactions: []models.Transaction{
+					foodPurchase("t1", test.spent, notificationTestNow.AddDate(0, 0, -5)),
+					// Last month's spending doesn't count toward this month's budget
+					foodPurchase("t0", 500, notificationTestNow.AddDate(0, -1, 0)),
+				},
+				Preferences: preferences,
+				Money:       NewMoneyFormatter("USD", "en-US"),
+				Now:         notificationTestNow,
+			})
+
+			got := budgetEvents(events)
+			if len(got) != len(test.want) {
+				t.Fatalf("budget events = %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("budget events = %v, want %v", got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateNotificationEventsTagBudget(t *testing.T) {
+	preferences := DefaultNotificationPreferences("sarah")
+	preferences.Budgets = map[string]float64{TagBudgetPrefix + "vacation": 200}
+	tagged := foodPurchase("t1", 170, notificationTestNow.AddDate(0, 0, -2))
+	tagged.Tags = []string{"vacation"}
+	events := EvaluateNotificationEvents(NotificationSnapshot{
+		Transactions: []models.Transaction{tagged, foodPurchase("t2", 300, notificationTestNow.AddDate(0, 0, -2))},
+		Preferences:  preferences,
+		Money:        NewMoneyFormatter("USD", "en-US"),
+		Now:          notificationTestNow,
+	})
+
+	got := budgetEvents(events)
+	if len(got) != 1 || got[0] != "budget:tag:vacation:2026-03:80" {
+		t.Fatalf("budget events = %v, want only the 80%% #vacation threshold", got)
+	}
+}
+
+func TestNotificationServiceRemembersBudgets(t *testing.T) {
+	service := NewNotificationService(SMTPConfig{}, NewWebhookService())
+	thresholds := func() []string {
+		return budgetEvents(EvaluateNotificationEvents(NotificationSnapshot{
+			Transactions: []models.Transaction{foodPurchase("t1", 85, notificationTestNow.AddDate(0, 0, -5))},
+			Preferences:  service.Preferences("sarah"),
+			Money:        NewMoneyFormatter("USD", "en-US"),
+			Now:          notificationTestNow,
+		}))
+	}
+
+	// Default preferences have no budgets of their own to check
+	if got := thresholds(); len(got) != 0 {
+		t.Fatalf("budget events with no budgets = %v", got)
+	}
+
+	// The budgets sent for insights are used until the customer sets some
+	service.RememberBudgets("sarah", map[string]float64{"foodDining": 100, "shopping": 0})
+	if got := thresholds(); len(got) != 1 || got[0] != "budget:foodDining:2026-03:80" {
+		t.Errorf("budget events with remembered budgets = %v, want the 80%% threshold", got)
+	}
+	if budgets := service.Preferences("sarah").Budgets; len(budgets) != 1 {
+		t.Errorf("preferences budgets = %v, want only the positive budget", budgets)
+	}
+	service.RememberBudgets("sarah", map[string]float64{})
+	if got := thresholds(); len(got) != 1 {
+		t.Errorf("an empty set of budgets replaced the remembered ones: %v", got)
+	}
+
+	preferences := DefaultNotificationPreferences("sarah")
+	preferences.Budgets = map[string]float64{"foodDining": 170}
+	if _, err := service.SetPreferences("sarah", preferences); err != nil {
+		t.Fatal(err)
+	}
+	if got := thresholds(); len(got) != 1 || got[0] != "budget:foodDining:2026-03:50" {
+		t.Errorf("budget events with the customer's own budget = %v, want the 50%% threshold", got)
+	}
+}
+
+func TestEvaluateNotificationEventsKeys(t *testing.T) {
+	preferences := DefaultNotificationPreferences("sarah")
+	preferences.Budgets = map[string]float64{"foodDining": 100}
+	snapshot := NotificationSnapshot{
+		Accounts: []models.Account{
+			{ID: "acc1", Type: "Checking", Nickname: "Everyday Checking", Balance: 40},
+			{ID: "acc2", Type: "Savings", Nickname: "Rainy Day", Balance: 5000},
+		},
+		Transactions: []models.Transaction{
+			foodPurchase("t1", 85, notificationTestNow.AddDate(0, 0, -20)),
+			foodPurchase("t2", 300, notificationTestNow.AddDate(0, 0, -1)),
+		},
+		Bills: []models.UpcomingBill{
+			{BillID: "bill1", Name: "Rent", Amount: 1800, DueDate: time.Date(2026, time.March, 17, 0, 0, 0, 0, time.UTC)},
+			{BillID: "bill2", Name: "Gym", Amount: 40, DueDate: time.Date(2026, time.March, 30, 0, 0, 0, 0, time.UTC)},
+		},
+		Goals: []models.Goal{
+			{ID: "goal1", Name: "Vacation", Progress: 62},
+			{ID: "goal2", Name: "Laptop", Progress: 10},
+		},
+		Preferences: preferences,
+		Money:       NewMoneyFormatter("USD", "en-US"),
+		Now:         notificationTestNow,
+	}
+
+	want := map[string]string{
+		"budget-exceeded:foodDining:2026-03": EventBudgetExceeded,
+		"budget:foodDining:2026-03:100":      EventBudgetThreshold,
+		"large:t2":                           EventLargeTransaction,
+		"lowbalance:acc1:2026-03-15":         EventLowBalance,
+		"bill:bill1:2026-03-17":              EventBillUpcoming,
+		"goal:goal1:50":                      EventGoalMilestone,
+	}
+	events := EvaluateNotificationEvents(snapshot)
+	if len(events) != len(want) {
+		t.Errorf("got %d events, want %d", len(events), len(want))
+	}
+	for _, event := range events {
+		if want[event.Key] != event.Type {
+			t.Errorf("unexpected %s event with key %q", event.Type, event.Key)
+		}
+		if event.CustomerID != "sarah" || !event.OccurredAt.Equal(notificationTestNow) {
+			t.Errorf("event %q has customer %q at %v", event.Key, event.CustomerID, event.OccurredAt)
+		}
+	}
+
+	// Later the same day nothing has changed, so the keys stay the same and
+	// the bus won't publish them again
+	bus := NewEventBus()
+	for _, event := range events {
+		bus.PublishOnce(event)
+	}
+	snapshot.Now = notificationTestNow.Add(3 * time.Hour)
+	for _, event := range EvaluateNotificationEvents(snapshot) {
+		if _, published := bus.PublishOnce(event); published {
+			t.Errorf("re-evaluating published %q again", event.Key)
+		}
+	}
+
+	// Low balances remind once a day and budgets once a month
+	snapshot.Now = notificationTestNow.AddDate(0, 0, 1)
+	repeated := map[string]bool{}
+	for _, event := range EvaluateNotificationEvents(snapshot) {
+		if _, published := bus.PublishOnce(event); published {
+			repeated[event.Key] = true
+		}
+	}
+	if !repeated["lowbalance:acc1:2026-03-16"] || len(repeated) != 1 {
+		t.Errorf("the next day published %v, want only the new low balance reminder", repeated)
+	}
+}
+
+func TestNotificationServiceDedupesByKey(t *testing.T) {
+	service := NewNotificationService(SMTPConfig{}, NewWebhookService())
+	event := models.Event{Type: EventLowBalance, CustomerID: "sarah", Key: "lowbalance:acc1:2026-03-15", Title: "Low balance", OccurredAt: notificationTestNow}
+
+	first, err := service.Handle(event)
+	if err != nil || first == nil {
+		t.Fatalf("first Handle = %v, %v", first, err)
+	}
+	if second, err := service.Handle(event); second != nil || err != nil {
+		t.Errorf("second Handle = %v, %v, want it skipped", second, err)
+	}
+	if other, _ := service.Handle(models.Event{Type: EventLowBalance, CustomerID: "michael", Key: event.Key}); other == nil {
+		t.Error("the same key for another customer was skipped")
+	}
+	if inbox := service.Inbox().List("sarah", false); len(inbox) != 1 {
+		t.Errorf("inbox has %d notifications, want 1", len(inbox))
+	}
+
+	preferences := DefaultNotificationPreferences("sarah")
+	preferences.Events[EventLargeTransaction] = false
+	if _, err := service.SetPreferences("sarah", preferences); err != nil {
+		t.Fatal(err)
+	}
+	if skipped, _ := service.Handle(models.Event{Type: EventLargeTransaction, CustomerID: "sarah", Key: "large:t1"}); skipped != nil {
+		t.Error("an event the customer turned off was delivered")
+	}
+}
+
+func TestNotificationServiceWebhookPreferences(t *testing.T) {
+	webhooks := NewWebhookService()
+	service := NewNotificationService(SMTPConfig{}, webhooks)
+	preferences := DefaultNotificationPreferences("sarah")
+	preferences.Channels = []string{ChannelInApp, ChannelWebhook}
+	preferences.WebhookURL = "https://example.com/hooks"
+
+	saved, err := service.SetPreferences("sarah", preferences)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(saved.WebhookSecret, "whsec_") {
+		t.Errorf("turning the webhook channel on returned secret %q", saved.WebhookSecret)
+	}
+	if stored := service.Preferences("sarah"); stored.WebhookSecret != "" {
+		t.Error("the webhook secret was stored with the preferences")
+	}
+	subscriptions := webhooks.Subscriptions("sarah")
+	if len(subscriptions) != 1 || !subscriptions[0].Notifications || !subscriptions[0].Active || subscriptions[0].URL != preferences.WebhookURL {
+		t.Fatalf("subscriptions = %+v, want one active notification webhook", subscriptions)
+	}
+
+	preferences.WebhookURL = "https://example.com/other"
+	if saved, err := service.SetPreferences("sarah", preferences); err != nil || saved.WebhookSecret != "" {
+		t.Errorf("changing the URL returned %v, secret %q; want no secret", err, saved.WebhookSecret)
+	}
+	preferences.Channels = []string{ChannelInApp}
+	if _, err := service.SetPreferences("sarah", preferences); err != nil {
+		t.Fatal(err)
+	}
+	subscriptions = webhooks.Subscriptions("sarah")
+	if len(subscriptions) != 1 || subscriptions[0].Active || subscriptions[0].URL != "https://example.com/other" {
+		t.Errorf("after turning the channel off, subscriptions = %+v, want the webhook kept but off", subscriptions)
+	}
+}
+
+func TestNotificationServiceDeliversInBackground(t *testing.T) {
+	service := NewNotificationService(SMTPConfig{}, NewWebhookService())
+	slow := &blockingChannel{name: ChannelWebhook, release: make(chan struct{})}
+	service.RegisterChannel(slow)
+	preferences := DefaultNotificationPreferences("sarah")
+	preferences.Channels = []string{ChannelInApp, ChannelWebhook}
+	preferences.WebhookURL = "https://example.com/hooks"
+	if _, err := service.SetPreferences("sarah", preferences); err != nil {
+		t.Fatal(err)
+	}
+
+	returned := make(chan *models.Notification, 1)
+	go func() {
+		notification, _ := service.Handle(models.Event{Type: EventLowBalance, CustomerID: "sarah", Key: "lowbalance:acc1", OccurredAt: notificationTestNow})
+		returned <- notification
+	}()
+	var notification *models.Notification
+	select {
+	case notification = <-returned:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handle waited for the webhook channel")
+	}
+	if status := deliveryStatus(notification.Deliveries, ChannelWebhook); status != "pending" {
+		t.Errorf("webhook delivery = %q, want pending", status)
+	}
+	if status := deliveryStatus(service.Inbox().List("sarah", false)[0].Deliveries, ChannelInApp); status != "sent" {
+		t.Errorf("inbox delivery = %q, want sent", status)
+	}
+
+	close(slow.release)
+	deadline := time.Now().Add(2 * time.Second)
+	for deliveryStatus(service.Inbox().List("sarah", false)[0].Deliveries, ChannelWebhook) != "sent" {
+		if time.Now().After(deadline) {
+			t.Fatal("the inbox copy never recorded the webhook delivery")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNotificationServiceHandleAndWaitReportsFailures(t *testing.T) {
+	service := NewNotificationService(SMTPConfig{}, NewWebhookService())
+	release := make(chan struct{})
+	close(release)
+	service.RegisterChannel(&blockingChannel{name: ChannelWebhook, release: release, err: newUpstreamError("endpoint returned status 500")})
+	preferences := DefaultNotificationPreferences("sarah")
+	preferences.Channels = []string{ChannelInApp, ChannelWebhook}
+	preferences.WebhookURL = "https://example.com/hooks"
+	if _, err := service.SetPreferences("sarah", preferences); err != nil {
+		t.Fatal(err)
+	}
+
+	notification, err := service.HandleAndWait(models.Event{Type: EventNotificationTest, CustomerID: "sarah", OccurredAt: notificationTestNow})
+	if !errors.Is(err, ErrUpstream) {
+		t.Errorf("HandleAndWait returned %v, want an upstream error", err)
+	}
+	if notification == nil || deliveryStatus(notification.Deliveries, ChannelWebhook) != "failed" || deliveryStatus(notification.Deliveries, ChannelInApp) != "sent" {
+		t.Fatalf("notification = %+v, want the webhook failed and the inbox sent", notification)
+	}
+	if status := deliveryStatus(service.Inbox().List("sarah", false)[0].Deliveries, ChannelWebhook); status != "failed" {
+		t.Errorf("inbox copy records the webhook as %q, want failed", status)
+	}
+}
+
+// deliveryStatus returns the status of the delivery on channel
+func deliveryStatus(deliveries []models.NotificationDelivery, channel string) string {
+	for _, delivery := range deliveries {
+		if delivery.Channel == channel {
+			return delivery.Status
+		}
+	}
+	return ""
+}
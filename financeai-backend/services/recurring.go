@@ -0,0 +1,117 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"financeai-backend/models"
+)
+
+const (
+	// MinRecurringOccurrences is how many charges it takes to call a merchant recurring
+	MinRecurringOccurrences = 3
+	// RecurringAmountTolerance is how far a charge can stray from the typical
+	// amount, as a fraction, and still count toward the series
+	RecurringAmountTolerance = 0.25
+)
+
+// recurringKey groups charges from the same merchant
+func recurringKey(transaction models.Transaction) string {
+	if transaction.Merchant.ID != "" {
+		return transaction.Merchant.ID
+	}
+	if IsGenericDescription(transaction.Description) {
+		return ""
+	}
+	return "unresolved:" + strings.ReplaceAll(NormalizeMerchantString(transaction.Description), " ", "-")
+}
+
+// DetectRecurringCharges finds merchants that charge the customer on a
+// regular schedule for about the same amount, like subscriptions and
+// utilities. Series that have stopped, i.e. missed two expected charges, are
+// left out.
+func DetectRecurringCharges(transactions []models.Transaction, now time.Time) []models.RecurringCharge {
+	byMerchant := make(map[string][]models.Transaction)
+	for _, transaction := range transactions {
+		if !IsExpense(transaction) {
+			continue
+		}
+		if key := recurringKey(transaction); key != "" {
+			byMerchant[key] = append(byMerchant[key], transaction)
+		}
+	}
+
+	charges := []models.RecurringCharge{}
+	for key, series := range byMerchant {
+		if len(series) < MinRecurringOccurrences {
+			continue
+		}
+		sort.Slice(series, func(i, j int) bool {
+			return series[i].TransactionDate.Before(series[j].TransactionDate)
+		})
+
+		// Every charge has to be close to the typical amount
+		amounts := make([]float64, len(series))
+		for i, transaction := range series {
+			amounts[i] = math.Abs(transaction.Amount)
+		}
+		sorted := append([]float64(nil), amounts...)
+		sort.Float64s(sorted)
+		median := sorted[len(sorted)/2]
+		consistent := true
+		var total float64
+		for _, amount := range amounts {
+			if math.Abs(amount-median) > median*RecurringAmountTolerance {
+				consistent = false
+				break
+			}
+			total += amount
+		}
+		if !consistent {
+			continue
+		}
+
+		intervals := make([]float64, 0, len(series)-1)
+		for i := 1; i < len(series); i++ {
+			intervals = append(intervals, series[i].TransactionDate.Sub(series[i-1].TransactionDate).Hours()/24)
+		}
+		frequency := cadenceFrequency(intervals)
+		if frequency == "irregular" {
+			continue
+		}
+
+		last := series[len(series)-1]
+		next := nextOccurrence(frequency, last.TransactionDate)
+		if missed := nextOccurrence(frequency, next); missed.Before(now) {
+			continue
+		}
+
+		name := last.Merchant.Name
+		if name == "" {
+			name = titleCase(NormalizeMerchantString(last.Description))
+		}
+		charges = append(charges, models.RecurringCharge{
+			ID:            "recurring:" + key,
+			MerchantID:    key,
+			Name:          name,
+			Category:      transactionCategory(last),
+			AccountID:     last.AccountID,
+			Frequency:     frequency,
+			AverageAmount: roundCents(total / float64(len(series))),
+			LastAmount:    roundCents(math.Abs(last.Amount)),
+			LastDate:      last.TransactionDate,
+			NextDueDate:   next,
+			Occurrences:   len(series),
+		})
+	}
+
+	sort.Slice(charges, func(i, j int) bool {
+		if !charges[i].NextDueDate.Equal(charges[j].NextDueDate) {
+			return charges[i].NextDueDate.Before(charges[j].NextDueDate)
+		}
+		return charges[i].ID < charges[j].ID
+	})
+	return charges
+}
@@ -0,0 +1,490 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"financeai-backend/models"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ReportMonthLayout is the format used for report month parameters (e.g. 2026-09)
+const ReportMonthLayout = "2006-01"
+
+// TagBudgetPrefix marks budgetData keys that budget a tag rather than a
+// category, e.g. "tag:work-trip"
+const TagBudgetPrefix = "tag:"
+
+// categoryHexColors mirrors the category palette used by the dashboard charts
+var categoryHexColors = map[string]string{
+	"Food & Dining":  "#ef4444",
+	"Transportation": "#3b82f6",
+	"Shopping":       "#8b5cf6",
+	"Entertainment":  "#f59e0b",
+	"Healthcare":     "#10b981",
+	"Utilities":      "#06b6d4",
+	"Other":          "#6b7280",
+}
+
+// ReportService builds monthly statements and renders them as PDF
+type ReportService struct {
+	TopMerchantLimit int
+	ChangeLimit      int
+}
+
+// NewReportService creates a new report service instance
+func NewReportService() *ReportService {
+	return &ReportService{
+		TopMerchantLimit: 5,
+		ChangeLimit:      5,
+	}
+}
+
+// MonthTransactions returns the transactions dated within the given month
+func MonthTransactions(transactions []models.Transaction, month time.Time) []models.Transaction {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	var result []models.Transaction
+	for _, transaction := range transactions {
+		date := transaction.TransactionDate.UTC()
+		if !date.Before(start) && date.Before(end) {
+			result = append(result, transaction)
+		}
+	}
+	return result
+}
+
+// BuildMonthlyReport aggregates a customer's data for the given month
+func (r *ReportService) BuildMonthlyReport(data *models.DashboardData, month time.Time, budgetData map[string]float64, insights []models.SpendingInsight) *models.MonthlyReport {
+	current := MonthTransactions(data.Transactions, month)
+	previous := MonthTransactions(data.Transactions, month.AddDate(0, -1, 0))
+
+	report := &models.MonthlyReport{
+		CustomerID:   data.Customer.ID,
+		CustomerName: strings.TrimSpace(data.Customer.FirstName + " " + data.Customer.LastName),
+		Month:        month.Format(ReportMonthLayout),
+		Currency:     data.Currency,
+		Locale:       data.Locale,
+		Insights:     insights,
+		GeneratedAt:  time.Now(),
+	}
+
+	merchants := make(map[string]*models.MerchantSpending)
+	tags := make(map[string]*models.TagSpending)
+	for _, transaction := range current {
+		// Transfers between the customer's own accounts are neither income nor spending
+		if transaction.IsTransfer {
+			continue
+		}
+		if transaction.Amount >= 0 {
+			report.Income += transaction.Amount
+			continue
+		}
+
+		spent := math.Abs(transaction.Amount)
+		report.Expenses += spent
+
+		name := transaction.Merchant.Name
+		if name == "" {
+			name = transaction.Description
+		}
+		if _, exists := merchants[name]; !exists {
+			merchants[name] = &models.MerchantSpending{Merchant: name}
+		}
+		merchants[name].Amount += spent
+		merchants[name].Count++
+
+		for _, tag := range transaction.Tags {
+			if _, exists := tags[tag]; !exists {
+				tags[tag] = &models.TagSpending{Tag: tag}
+			}
+			tags[tag].Amount += spent
+			tags[tag].Count++
+		}
+	}
+	report.Net = report.Income - report.Expenses
+
+	currentByCategory := spendingByCategory(current)
+	previousByCategory := spendingByCategory(previous)
+
+	// Category breakdown, reusing the dashboard's colors where available
+	dashboardColors := make(map[string]string)
+	for _, category := range data.SpendingData.CategorySpending {
+		dashboardColors[category.Category] = category.Color
+	}
+	for category, amount := range currentByCategory {
+		color, exists := dashboardColors[category]
+		if !exists {
+			color = categoryColor(category)
+		}
+		report.CategorySpending = append(report.CategorySpending, models.CategorySpending{
+			Category: category,
+			Amount:   amount,
+			Color:    color,
+		})
+	}
+	sort.Slice(report.CategorySpending, func(i, j int) bool {
+		return report.CategorySpending[i].Amount > report.CategorySpending[j].Amount
+	})
+
+	// Top merchants by amount spent
+	for _, merchant := range merchants {
+		report.TopMerchants = append(report.TopMerchants, *merchant)
+	}
+	sort.Slice(report.TopMerchants, func(i, j int) bool {
+		if report.TopMerchants[i].Amount == report.TopMerchants[j].Amount {
+			return report.TopMerchants[i].Merchant < report.TopMerchants[j].Merchant
+		}
+		return report.TopMerchants[i].Amount > report.TopMerchants[j].Amount
+	})
+	if len(report.TopMerchants) > r.TopMerchantLimit {
+		report.TopMerchants = report.TopMerchants[:r.TopMerchantLimit]
+	}
+
+	// Spending by user tag
+	for _, tag := range tags {
+		report.TagSpending = append(report.TagSpending, *tag)
+	}
+	sort.Slice(report.TagSpending, func(i, j int) bool {
+		if report.TagSpending[i].Amount == report.TagSpending[j].Amount {
+			return report.TagSpending[i].Tag < report.TagSpending[j].Tag
+		}
+		return report.TagSpending[i].Amount > report.TagSpending[j].Amount
+	})
+
+	// Budget variance for every category or tag with a budget set
+	for key, budget := range budgetData {
+		tag, isTag := strings.CutPrefix(key, TagBudgetPrefix)
+		if !isTag || budget <= 0 {
+			continue
+		}
+		var spent float64
+		if tagSpending, exists := tags[normalizeTag(tag)]; exists {
+			spent = tagSpending.Amount
+		}
+		report.BudgetVariance = append(report.BudgetVariance, models.BudgetVariance{
+			Category: TagBudgetPrefix + normalizeTag(tag),
+			Budget:   budget,
+			Spent:    spent,
+			Variance: budget - spent,
+		})
+	}
+	for category := range budgetCategoryKeys {
+		budget := budgetForCategory(budgetData, category)
+		if budget <= 0 {
+			continue
+		}
+		spent := currentByCategory[category]
+		report.BudgetVariance = append(report.BudgetVariance, models.BudgetVariance{
+			Category: category,
+			Budget:   budget,
+			Spent:    spent,
+			Variance: budget - spent,
+		})
+	}
+	sort.Slice(report.BudgetVariance, func(i, j int) bool {
+		return report.BudgetVariance[i].Variance < report.BudgetVariance[j].Variance
+	})
+
+	// Biggest changes vs the prior month
+	categories := make(map[string]bool)
+	for category := range currentByCategory {
+		categories[category] = true
+	}
+	for category := range previousByCategory {
+		categories[category] = true
+	}
+	for category := range categories {
+		change := models.CategoryChange{
+			Category: category,
+			Previous: previousByCategory[category],
+			Current:  currentByCategory[category],
+		}
+		change.Change = change.Current - change.Previous
+		if change.Previous > 0 {
+			change.PercentChange = change.Change / change.Previous * 100
+		}
+		report.Changes = append(report.Changes, change)
+	}
+	sort.Slice(report.Changes, func(i, j int) bool {
+		if math.Abs(report.Changes[i].Change) == math.Abs(report.Changes[j].Change) {
+			return report.Changes[i].Category < report.Changes[j].Category
+		}
+		return math.Abs(report.Changes[i].Change) > math.Abs(report.Changes[j].Change)
+	})
+	if len(report.Changes) > r.ChangeLimit {
+		report.Changes = report.Changes[:r.ChangeLimit]
+	}
+
+	return report
+}
+
+// RenderMonthlyReportPDF renders a monthly report as a PDF document
+func (r *ReportService) RenderMonthlyReportPDF(report *models.MonthlyReport) ([]byte, error) {
+	month, err := time.Parse(ReportMonthLayout, report.Month)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report month: %v", err)
+	}
+
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	pdf.SetTitle(fmt.Sprintf("FinSights Monthly Statement - %s", month.Format("January 2006")), false)
+	pdf.SetCreator("FinSights", false)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	money := NewMoneyFormatter(report.Currency, report.Locale)
+	pdf.AddPage()
+
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	contentWidth := pageWidth - left - right
+
+	// Header
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(contentWidth, 10, "FinSights Monthly Statement", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(contentWidth, 6, tr(fmt.Sprintf("%s - %s", report.CustomerName, month.Format("January 2006"))), "", 1, "L", false, 0, "")
+	pdf.CellFormat(contentWidth, 6, fmt.Sprintf("Generated %s", report.GeneratedAt.Format("Jan 2, 2006 3:04 PM")), "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(4)
+
+	// Income vs expenses
+	sectionHeading(pdf, contentWidth, "Income vs Expenses")
+	summaryWidth := contentWidth / 3
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(summaryWidth, 6, "Income", "", 0, "L", false, 0, "")
+	pdf.CellFormat(summaryWidth, 6, "Expenses", "", 0, "L", false, 0, "")
+	pdf.CellFormat(summaryWidth, 6, "Net", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(summaryWidth, 8, tr(money.Format(report.Income)), "", 0, "L", false, 0, "")
+	pdf.CellFormat(summaryWidth, 8, tr(money.Format(report.Expenses)), "", 0, "L", false, 0, "")
+	if report.Net < 0 {
+		pdf.SetTextColor(220, 38, 38)
+	} else {
+		pdf.SetTextColor(22, 163, 74)
+	}
+	pdf.CellFormat(summaryWidth, 8, tr(formatSignedAmount(money, report.Net)), "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(4)
+
+	// Category breakdown with colored bars
+	sectionHeading(pdf, contentWidth, "Spending by Category")
+	if len(report.CategorySpending) == 0 {
+		emptyRow(pdf, contentWidth, "No spending recorded for this month.")
+	}
+	labelWidth, amountWidth := 45.0, 30.0
+	barMax := contentWidth - labelWidth - amountWidth - 4
+	for _, category := range report.CategorySpending {
+		red, green, blue := hexToRGB(category.Color, categoryColor(category.Category))
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(labelWidth, 7, tr(category.Category), "", 0, "L", false, 0, "")
+		barWidth := 0.0
+		if report.Expenses > 0 {
+			barWidth = barMax * category.Amount / report.Expenses
+		}
+		x, y := pdf.GetXY()
+		pdf.SetFillColor(red, green, blue)
+		if barWidth > 0 {
+			pdf.Rect(x, y+1.5, barWidth, 4, "F")
+		}
+		pdf.SetX(x + barMax + 4)
+		pdf.CellFormat(amountWidth, 7, tr(money.Format(category.Amount)), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	// Top merchants
+	sectionHeading(pdf, contentWidth, "Top Merchants")
+	merchantColumns := []float64{contentWidth - 60, 25, 35}
+	tableHeader(pdf, merchantColumns, []string{"Merchant", "Visits", "Spent"})
+	if len(report.TopMerchants) == 0 {
+		emptyRow(pdf, contentWidth, "No merchant activity for this month.")
+	}
+	for _, merchant := range report.TopMerchants {
+		tableRow(pdf, merchantColumns, []string{
+			tr(merchant.Merchant),
+			strconv.Itoa(merchant.Count),
+			tr(money.Format(merchant.Amount)),
+		})
+	}
+	pdf.Ln(4)
+
+	// Spending by tag, only when the customer has tagged transactions
+	if len(report.TagSpending) > 0 {
+		sectionHeading(pdf, contentWidth, "Spending by Tag")
+		tagColumns := []float64{contentWidth - 60, 25, 35}
+		tableHeader(pdf, tagColumns, []string{"Tag", "Count", "Spent"})
+		for _, tag := range report.TagSpending {
+			tableRow(pdf, tagColumns, []string{
+				tr("#" + tag.Tag),
+				strconv.Itoa(tag.Count),
+				tr(money.Format(tag.Amount)),
+			})
+		}
+		pdf.Ln(4)
+	}
+
+	// Budget variance
+	sectionHeading(pdf, contentWidth, "Budget Variance")
+	budgetColumns := []float64{contentWidth - 105, 35, 35, 35}
+	if len(report.BudgetVariance) == 0 {
+		emptyRow(pdf, contentWidth, "No budgets set for this month.")
+	} else {
+		tableHeader(pdf, budgetColumns, []string{"Category", "Budget", "Spent", "Remaining"})
+	}
+	for _, variance := range report.BudgetVariance {
+		label := variance.Category
+		if tag, isTag := strings.CutPrefix(label, TagBudgetPrefix); isTag {
+			label = "#" + tag
+		}
+		tableRow(pdf, budgetColumns, []string{
+			tr(label),
+			tr(money.Format(variance.Budget)),
+			tr(money.Format(variance.Spent)),
+			tr(formatSignedAmount(money, variance.Variance)),
+		})
+	}
+	pdf.Ln(4)
+
+	// Changes vs the prior month
+	sectionHeading(pdf, contentWidth, fmt.Sprintf("Changes vs %s", month.AddDate(0, -1, 0).Format("January")))
+	changeColumns := []float64{contentWidth - 120, 30, 30, 30, 30}
+	if len(report.Changes) == 0 {
+		emptyRow(pdf, contentWidth, "No spending in either month to compare.")
+	} else {
+		tableHeader(pdf, changeColumns, []string{"Category", "Previous", "Current", "Change", "%"})
+	}
+	for _, change := range report.Changes {
+		percent := "new"
+		if change.Previous > 0 {
+			percent = fmt.Sprintf("%+.0f%%", change.PercentChange)
+		}
+		tableRow(pdf, changeColumns, []string{
+			tr(change.Category),
+			tr(money.Format(change.Previous)),
+			tr(money.Format(change.Current)),
+			tr(formatSignedAmount(money, change.Change)),
+			percent,
+		})
+	}
+	pdf.Ln(4)
+
+	// AI insights
+	sectionHeading(pdf, contentWidth, "AI Insights")
+	if len(report.Insights) == 0 {
+		emptyRow(pdf, contentWidth, "No insights available for this month.")
+	}
+	for _, insight := range report.Insights {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.MultiCell(contentWidth, 5, tr(fmt.Sprintf("%s (%s)", insight.Title, insight.Amount)), "", "L", false)
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(contentWidth, 5, tr(insight.Description), "", "L", false)
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.MultiCell(contentWidth, 5, tr("Tip: "+insight.Tip), "", "L", false)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.Ln(2)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render report PDF: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// spendingByCategory totals expenses per category
+func spendingByCategory(transactions []models.Transaction) map[string]float64 {
+	totals := make(map[string]float64)
+	for _, transaction := range transactions {
+		if transaction.Amount < 0 && !transaction.IsTransfer {
+			for _, allocation := range TransactionAllocations(transaction) {
+				totals[allocation.Category] += math.Abs(allocation.Amount)
+			}
+		}
+	}
+	return totals
+}
+
+// transactionCategory returns the category of a transaction, defaulting to Other
+func transactionCategory(transaction models.Transaction) string {
+	if transaction.Merchant.Category == "" {
+		return "Other"
+	}
+	return transaction.Merchant.Category
+}
+
+// categoryColor returns the chart color for a category
+func categoryColor(category string) string {
+	if color, exists := categoryHexColors[category]; exists {
+		return color
+	}
+	return categoryHexColors["Other"]
+}
+
+// hexToRGB parses a #rrggbb color, using fallback when the color is not hex
+func hexToRGB(color string, fallback string) (int, int, int) {
+	value := strings.TrimPrefix(color, "#")
+	if len(value) != 6 || !strings.HasPrefix(color, "#") {
+		value = strings.TrimPrefix(fallback, "#")
+	}
+	parsed, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return 107, 114, 128
+	}
+	return int(parsed >> 16 & 0xff), int(parsed >> 8 & 0xff), int(parsed & 0xff)
+}
+
+// formatSignedAmount formats an amount with an explicit sign
+func formatSignedAmount(money MoneyFormatter, amount float64) string {
+	if amount < 0 {
+		return money.Format(amount)
+	}
+	return "+" + money.Format(amount)
+}
+
+func sectionHeading(pdf *fpdf.Fpdf, width float64, title string) {
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetDrawColor(200, 200, 200)
+	pdf.CellFormat(width, 8, title, "B", 1, "L", false, 0, "")
+	pdf.Ln(2)
+}
+
+func tableHeader(pdf *fpdf.Fpdf, widths []float64, headers []string) {
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(243, 244, 246)
+	for i, header := range headers {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 7, header, "", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+func tableRow(pdf *fpdf.Fpdf, widths []float64, values []string) {
+	pdf.SetFont("Helvetica", "", 10)
+	for i, value := range values {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 6, value, "", 0, align, false, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+func emptyRow(pdf *fpdf.Fpdf, width float64, message string) {
+	pdf.SetFont("Helvetica", "I", 10)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(width, 6, message, "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+}
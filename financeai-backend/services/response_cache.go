@@ -0,0 +1,222 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Response cache namespaces, one per kind of data cached
+const (
+	CacheNamespaceDashboard          = "dashboard"
+	CacheNamespaceInsights           = "insights"
+	CacheNamespaceNessieCustomer     = "nessie-customer"
+	CacheNamespaceNessieAccounts     = "nessie-accounts"
+	CacheNamespaceNessieTransactions = "nessie-transactions"
+	// CacheNamespaceNessieMerchant is shared by every customer
+	CacheNamespaceNessieMerchant = "nessie-merchant"
+)
+
+// How long each kind of data is cached. Changes made through the API
+// invalidate it sooner; the TTLs bound how stale changes made elsewhere,
+// such as in Nessie directly, can get.
+const (
+	dashboardCacheTTL      = 5 * time.Minute
+	insightCacheTTL        = time.Hour
+	nessieCustomerCacheTTL = 30 * time.Minute
+	nessieDataCacheTTL     = 2 * time.Minute
+)
+
+// ResponseCache caches provider responses and generated insights per
+// customer. Every customer's data has a version that's part of each key, so
+// invalidating a customer also keeps results fetched before the change, but
+// stored after it, from being served.
+type ResponseCache struct {
+	mu       sync.Mutex
+	cache    Cache
+	versions map[string]int64
+	counters map[string]*models.CacheNamespaceStats
+}
+
+// defaultResponseCache is shared so invalidating a customer from any route
+// clears what every route cached
+var defaultResponseCache = NewResponseCache(NewMemoryCache(DefaultCacheSize))
+
+// NewResponseCache creates a response cache stored in cache
+func NewResponseCache(cache Cache) *ResponseCache {
+	return &ResponseCache{
+		cache:    cache,
+		versions: make(map[string]int64),
+		counters: make(map[string]*models.CacheNamespaceStats),
+	}
+}
+
+// DefaultResponseCache returns the response cache shared by the API
+func DefaultResponseCache() *ResponseCache {
+	return defaultResponseCache
+}
+
+// ConfigureCache replaces the shared response cache's backend. backend is
+// CacheBackendMemory, holding up to size entries, or CacheBackendDisk,
+// storing entries under dir.
+func ConfigureCache(backend string, size int, dir string) error {
+	var cache Cache
+	switch backend {
+	case "", CacheBackendMemory:
+		cache = NewMemoryCache(size)
+	case CacheBackendDisk:
+		diskCache, err := NewDiskCache(dir)
+		if err != nil {
+			return err
+		}
+		cache = diskCache
+	default:
+		return fmt.Errorf("unknown cache backend %q, expected %s or %s", backend, CacheBackendMemory, CacheBackendDisk)
+	}
+
+	defaultResponseCache.mu.Lock()
+	defer defaultResponseCache.mu.Unlock()
+	defaultResponseCache.cache = cache
+	defaultResponseCache.versions = make(map[string]int64)
+	return nil
+}
+
+// customerPrefix is the start of every key holding a customer's data
+func customerPrefix(customerID string) string {
+	return "data|" + url.PathEscape(customerID) + "|"
+}
+
+// versionKey is where a customer's data version is kept, so the disk
+// backend doesn't serve entries from before a restart's invalidations
+func versionKey(customerID string) string {
+	return "version|" + url.PathEscape(customerID)
+}
+
+// version returns a customer's data version. Callers hold rc.mu.
+func (rc *ResponseCache) version(customerID string) int64 {
+	if version, exists := rc.versions[customerID]; exists {
+		return version
+	}
+	var version int64
+	if value, ok := rc.cache.Get(versionKey(customerID)); ok {
+		version, _ = strconv.ParseInt(string(value), 10, 64)
+	}
+	rc.versions[customerID] = version
+	return version
+}
+
+// key returns the key for a namespace's entry of a customer's data at its
+// current version
+func (rc *ResponseCache) key(namespace string, customerID string, parts []string) string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	key := fmt.Sprintf("%sv%d|%s", customerPrefix(customerID), rc.version(customerID), namespace)
+	for _, part := range parts {
+		key += "|" + url.PathEscape(part)
+	}
+	return key
+}
+
+// backend returns the cache entries are stored in
+func (rc *ResponseCache) backend() Cache {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.cache
+}
+
+// count records a lookup in a namespace
+func (rc *ResponseCache) count(namespace string, hit bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	counter, exists := rc.counters[namespace]
+	if !exists {
+		counter = &models.CacheNamespaceStats{}
+		rc.counters[namespace] = counter
+	}
+	if hit {
+		counter.Hits++
+	} else {
+		counter.Misses++
+	}
+}
+
+// Invalidate drops everything cached for a customer, for when their
+// transactions or accounts change. It does nothing on a nil cache.
+func (rc *ResponseCache) Invalidate(customerID string) int {
+	if rc == nil {
+		return 0
+	}
+	rc.mu.Lock()
+	version := rc.version(customerID) + 1
+	rc.versions[customerID] = version
+	cache := rc.cache
+	rc.mu.Unlock()
+
+	cache.Set(versionKey(customerID), []byte(strconv.FormatInt(version, 10)), 0)
+	return cache.DeletePrefix(customerPrefix(customerID))
+}
+
+// Prune removes expired entries
+func (rc *ResponseCache) Prune() int {
+	return rc.backend().Prune()
+}
+
+// Stats returns the backend's counters with hits and misses per namespace
+func (rc *ResponseCache) Stats() models.CacheStats {
+	stats := rc.backend().Stats()
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	stats.Namespaces = make(map[string]models.CacheNamespaceStats, len(rc.counters))
+	for namespace, counter := range rc.counters {
+		stats.Namespaces[namespace] = *counter
+	}
+	return stats
+}
+
+// cached returns the value cached for a customer under namespace and parts,
+// or calls fetch and caches what it returns for ttl. Errors aren't cached, so
+// a failed fetch is retried on the next call. A nil cache always fetches.
+func cached[T any](rc *ResponseCache, namespace string, customerID string, parts []string, ttl time.Duration, fetch func() (T, error)) (T, error) {
+	if rc == nil {
+		return fetch()
+	}
+	key := rc.key(namespace, customerID, parts)
+	cache := rc.backend()
+
+	if data, ok := cache.Get(key); ok {
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			rc.count(namespace, true)
+			return value, nil
+		}
+	}
+	rc.count(namespace, false)
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	if data, err := json.Marshal(value); err == nil {
+		cache.Set(key, data, ttl)
+	}
+	return value, nil
+}
+
+// cacheHash shortens a value such as a map of budgets into a key part. JSON
+// sorts map keys, so equal maps hash the same.
+func cacheHash(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResponseCacheCachesUntilInvalidated(t *testing.T) {
+	rc := NewResponseCache(NewMemoryCache(10))
+	fetches := 0
+	fetch := func() (int, error) {
+		fetches++
+		return fetches, nil
+	}
+	get := func(customerID string) int {
+		t.Helper()
+		value, err := cached(rc, CacheNamespaceDashboard, customerID, []string{"month"}, time.Hour, fetch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	if first, second := get("c1"), get("c1"); first != 1 || second != 1 {
+		t.Errorf("values %d and %d, want the first fetch twice", first, second)
+	}
+	if other := get("c2"); other != 2 {
+		t.Errorf("another customer got %d, want a fetch of their own", other)
+	}
+
+	if removed := rc.Invalidate("c1"); removed != 1 {
+		t.Errorf("Invalidate removed %d entries, want 1", removed)
+	}
+	if value := get("c1"); value != 3 {
+		t.Errorf("after invalidating got %d, want a new fetch", value)
+	}
+	if other := get("c2"); other != 2 {
+		t.Errorf("invalidating c1 refetched c2: %d", other)
+	}
+
+	stats := rc.Stats().Namespaces[CacheNamespaceDashboard]
+	if stats.Hits != 2 || stats.Misses != 3 {
+		t.Errorf("namespace stats = %+v, want 2 hits and 3 misses", stats)
+	}
+}
+
+func TestResponseCacheDropsStaleFetches(t *testing.T) {
+	rc := NewResponseCache(NewMemoryCache(10))
+
+	// The customer's data changes while the fetch is running, so its result
+	// mustn't be served afterwards
+	stale, _ := cached(rc, CacheNamespaceInsights, "c1", nil, time.Hour, func() (string, error) {
+		rc.Invalidate("c1")
+		return "stale", nil
+	})
+	fresh, _ := cached(rc, CacheNamespaceInsights, "c1", nil, time.Hour, func() (string, error) {
+		return "fresh", nil
+	})
+	if stale != "stale" || fresh != "fresh" {
+		t.Errorf("got %q then %q, want the result fetched after the change", stale, fresh)
+	}
+}
+
+func TestResponseCacheDoesNotCacheErrors(t *testing.T) {
+	rc := NewResponseCache(NewMemoryCache(10))
+	fetches := 0
+	fetch := func() (string, error) {
+		fetches++
+		if fetches == 1 {
+			return "", errors.New("upstream down")
+		}
+		return "ok", nil
+	}
+	if _, err := cached(rc, CacheNamespaceNessieAccounts, "c1", nil, time.Hour, fetch); err == nil {
+		t.Fatal("the first fetch's error was lost")
+	}
+	if value, err := cached(rc, CacheNamespaceNessieAccounts, "c1", nil, time.Hour, fetch); err != nil || value != "ok" {
+		t.Errorf("retry returned %q, %v; want a fresh fetch", value, err)
+	}
+}
+
+func TestResponseCacheKeepsVersionsOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := NewResponseCache(disk)
+	cached(rc, CacheNamespaceDashboard, "c1", nil, time.Hour, func() (string, error) { return "before", nil })
+	rc.Invalidate("c1")
+	cached(rc, CacheNamespaceDashboard, "c1", nil, time.Hour, func() (string, error) { return "after", nil })
+
+	// A restarted server picks up the version instead of starting over at 0
+	restarted, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, _ := cached(NewResponseCache(restarted), CacheNamespaceDashboard, "c1", nil, time.Hour, func() (string, error) { return "refetched", nil })
+	if value != "after" {
+		t.Errorf("after a restart got %q, want the entry cached since the invalidation", value)
+	}
+}
@@ -0,0 +1,491 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Job run statuses
+const (
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusSkipped   = "skipped"
+)
+
+// Job run triggers
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+	JobTriggerCatchUp  = "catch-up"
+)
+
+const (
+	// DefaultSchedulerStateFile is where schedules and run history are saved
+	// when no path is configured
+	DefaultSchedulerStateFile = "data/scheduler.json"
+
+	// maxJobHistory is how many runs are kept for each job
+	maxJobHistory = 20
+)
+
+// ErrJobRunning is returned when a job is triggered while it's already running
+var ErrJobRunning = newKindError(ErrConflict, "job is already running")
+
+// JobFunc does a job's work and returns a short summary of what it did
+type JobFunc func(ctx context.Context) (string, error)
+
+// JobDefinition describes a job to register with the scheduler
+type JobDefinition struct {
+	Name        string
+	Description string
+	// Schedule is the default schedule; a saved schedule takes its place
+	Schedule string
+	Timeout  time.Duration
+	Retry    RetryPolicy
+	Run      JobFunc
+}
+
+// DefaultJobRetryPolicy tries a failed job three times, a minute apart and then two
+var DefaultJobRetryPolicy = RetryPolicy{
+	MaxAttempts:  3,
+	InitialDelay: time.Minute,
+	MaxDelay:     5 * time.Minute,
+}
+
+// scheduledJob is a registered job and its state
+type scheduledJob struct {
+	definition JobDefinition
+	spec       string
+	schedule   Schedule
+	enabled    bool
+	running    bool
+	nextRun    time.Time
+	history    []models.JobRun
+}
+
+// jobState is what's saved for each job between restarts
+type jobState struct {
+	Schedule  string          `json:"schedule"`
+	Enabled   bool            `json:"enabled"`
+	NextRunAt time.Time       `json:"next_run_at"`
+	History   []models.JobRun `json:"history"`
+}
+
+// Scheduler runs registered jobs on cron-like schedules. Schedules, the next
+// run time and recent runs are saved to a JSON file so a restart picks up
+// where it left off, running once to catch up on anything it missed. A job
+// never runs twice at the same time, and failed runs are retried with backoff.
+type Scheduler struct {
+	mu        sync.Mutex
+	jobs      map[string]*scheduledJob
+	saved     map[string]jobState
+	statePath string
+	wake      chan struct{}
+	ctx       context.Context
+	nextRunID int
+}
+
+// defaultScheduler runs the background jobs for the API
+var defaultScheduler = NewScheduler(DefaultSchedulerStateFile)
+
+// DefaultScheduler returns the scheduler that runs the API's background jobs
+func DefaultScheduler() *Scheduler {
+	return defaultScheduler
+}
+
+// NewScheduler creates a scheduler that saves its state to statePath, or to
+// DefaultSchedulerStateFile if statePath is empty
+func NewScheduler(statePath string) *Scheduler {
+	if statePath == "" {
+		statePath = DefaultSchedulerStateFile
+	}
+	return &Scheduler{
+		jobs:      make(map[string]*scheduledJob),
+		statePath: statePath,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// SetStatePath changes where the scheduler's state is saved. It must be
+// called before jobs are registered.
+func (s *Scheduler) SetStatePath(statePath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if statePath != "" {
+		s.statePath = statePath
+		s.saved = nil
+	}
+}
+
+// loadState reads the saved state the first time it's needed. A missing
+// file just means nothing was saved yet.
+func (s *Scheduler) loadState() {
+	if s.saved != nil {
+		return
+	}
+	s.saved = make(map[string]jobState)
+	data, err := os.ReadFile(s.statePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Scheduler Error: failed to read state: %v\n", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &s.saved); err != nil {
+		fmt.Printf("Scheduler Error: failed to parse state: %v\n", err)
+		s.saved = make(map[string]jobState)
+	}
+}
+
+// saveState writes every job's state to the state file, replacing it atomically
+func (s *Scheduler) saveState() {
+	state := make(map[string]jobState, len(s.jobs))
+	for name, job := range s.jobs {
+		state[name] = jobState{Schedule: job.spec, Enabled: job.enabled, NextRunAt: job.nextRun, History: job.history}
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		fmt.Printf("Scheduler Error: failed to encode state: %v\n", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.statePath), 0o755); err != nil {
+		fmt.Printf("Scheduler Error: failed to create state directory: %v\n", err)
+		return
+	}
+	temp := s.statePath + ".tmp"
+	if err := os.WriteFile(temp, data, 0o644); err != nil {
+		fmt.Printf("Scheduler Error: failed to write state: %v\n", err)
+		return
+	}
+	if err := os.Rename(temp, s.statePath); err != nil {
+		fmt.Printf("Scheduler Error: failed to save state: %v\n", err)
+	}
+}
+
+// Register adds a job, restoring its saved schedule and history if there are any
+func (s *Scheduler) Register(definition JobDefinition) error {
+	if definition.Name == "" || definition.Run == nil {
+		return fmt.Errorf("job needs a name and a run function")
+	}
+	if definition.Retry.MaxAttempts == 0 {
+		definition.Retry = DefaultJobRetryPolicy
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[definition.Name]; exists {
+		return fmt.Errorf("job already registered: %s", definition.Name)
+	}
+
+	job := &scheduledJob{definition: definition, spec: definition.Schedule, enabled: true}
+	s.loadState()
+	if saved, exists := s.saved[definition.Name]; exists {
+		if _, err := ParseSchedule(saved.Schedule); err == nil {
+			job.spec = saved.Schedule
+		}
+		job.enabled = saved.Enabled
+		job.nextRun = saved.NextRunAt
+		job.history = saved.History
+		// A run that was in progress when the process stopped didn't finish
+		for i := range job.history {
+			if job.history[i].Status == JobStatusRunning {
+				job.history[i].Status = JobStatusFailed
+				job.history[i].Error = "interrupted by shutdown"
+			}
+		}
+	}
+
+	schedule, err := ParseSchedule(job.spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule for %s: %v", definition.Name, err)
+	}
+	job.schedule = schedule
+	if job.nextRun.IsZero() {
+		job.nextRun = schedule.Next(time.Now())
+	}
+	s.jobs[definition.Name] = job
+	return nil
+}
+
+// Start runs due jobs in the background until ctx is cancelled. Jobs whose
+// saved next run time passed while the process was down run once right away.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	if s.ctx != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.ctx = ctx
+	now := time.Now()
+	for _, job := range s.jobs {
+		if job.enabled && !job.nextRun.IsZero() && job.nextRun.Before(now) {
+			s.startRun(ctx, job, JobTriggerCatchUp, now)
+		}
+	}
+	s.saveState()
+	s.mu.Unlock()
+
+	go s.loop(ctx)
+}
+
+// loop sleeps until the next job is due, runs every due job, and repeats
+func (s *Scheduler) loop(ctx context.Context) {
+	for {
+		s.mu.Lock()
+		var next time.Time
+		for _, job := range s.jobs {
+			if job.enabled && !job.nextRun.IsZero() && (next.IsZero() || job.nextRun.Before(next)) {
+				next = job.nextRun
+			}
+		}
+		s.mu.Unlock()
+
+		wait := time.Hour
+		if !next.IsZero() {
+			wait = time.Until(next)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+			continue
+		case <-timer.C:
+		}
+
+		s.mu.Lock()
+		now := time.Now()
+		for _, job := range s.jobs {
+			if job.enabled && !job.nextRun.IsZero() && !job.nextRun.After(now) {
+				s.startRun(ctx, job, JobTriggerSchedule, now)
+			}
+		}
+		s.saveState()
+		s.mu.Unlock()
+	}
+}
+
+// startRun moves a job's next run forward and starts it in the background,
+// or records a skipped run if it's still running. Callers hold s.mu.
+func (s *Scheduler) startRun(ctx context.Context, job *scheduledJob, trigger string, now time.Time) *models.JobRun {
+	if trigger != JobTriggerManual {
+		job.nextRun = job.schedule.Next(now)
+	}
+
+	s.nextRunID++
+	run := models.JobRun{
+		ID:        fmt.Sprintf("run_%d_%d", now.Unix(), s.nextRunID),
+		Job:       job.definition.Name,
+		Trigger:   trigger,
+		Status:    JobStatusRunning,
+		StartedAt: now,
+	}
+	if job.running {
+		run.Status = JobStatusSkipped
+		run.Output = "previous run still in progress"
+		run.FinishedAt = &now
+		job.addRun(run)
+		return &run
+	}
+
+	job.running = true
+	job.addRun(run)
+	go s.execute(ctx, job, run)
+	return &run
+}
+
+// addRun adds a run to the job's history, keeping only the most recent
+func (j *scheduledJob) addRun(run models.JobRun) {
+	j.history = append(j.history, run)
+	if len(j.history) > maxJobHistory {
+		j.history = j.history[len(j.history)-maxJobHistory:]
+	}
+}
+
+// updateRun replaces a run in the job's history
+func (j *scheduledJob) updateRun(run models.JobRun) {
+	for i := range j.history {
+		if j.history[i].ID == run.ID {
+			j.history[i] = run
+			return
+		}
+	}
+	j.addRun(run)
+}
+
+// execute runs a job, retrying with backoff until it succeeds or runs out of attempts
+func (s *Scheduler) execute(ctx context.Context, job *scheduledJob, run models.JobRun) {
+	policy := job.definition.Retry
+
+	var output string
+	var err error
+attempts:
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				err = fmt.Errorf("%v (retry cancelled: %v)", err, ctx.Err())
+				break attempts
+			case <-time.After(policy.Backoff(attempt)):
+			}
+		}
+		run.Attempts = attempt
+		output, err = s.attempt(ctx, job.definition)
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+		fmt.Printf("Job Error: %s attempt %d: %v\n", job.definition.Name, attempt, err)
+	}
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Output = output
+	run.Status = JobStatusSucceeded
+	if err != nil {
+		run.Status = JobStatusFailed
+		run.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.running = false
+	job.updateRun(run)
+	s.saveState()
+}
+
+// attempt runs a job once with its timeout, turning a panic into an error
+func (s *Scheduler) attempt(ctx context.Context, definition JobDefinition) (output string, err error) {
+	if definition.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, definition.Timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return definition.Run(ctx)
+}
+
+// Trigger starts a job now, outside its schedule. It fails with
+// ErrJobRunning if the job is already running.
+func (s *Scheduler) Trigger(name string) (*models.JobRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, exists := s.jobs[name]
+	if !exists {
+		return nil, NewNotFoundError("job not found: %s", name)
+	}
+	if job.running {
+		return nil, ErrJobRunning
+	}
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	run := s.startRun(ctx, job, JobTriggerManual, time.Now())
+	s.saveState()
+	return run, nil
+}
+
+// Update changes a job's schedule or turns it on or off. Nil arguments are
+// left unchanged.
+func (s *Scheduler) Update(name string, spec *string, enabled *bool) (*models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, exists := s.jobs[name]
+	if !exists {
+		return nil, NewNotFoundError("job not found: %s", name)
+	}
+	if spec != nil {
+		schedule, err := ParseSchedule(*spec)
+		if err != nil {
+			return nil, NewValidationError("schedule", "invalid schedule: %v", err)
+		}
+		job.spec = *spec
+		job.schedule = schedule
+		job.nextRun = schedule.Next(time.Now())
+	}
+	if enabled != nil {
+		if *enabled && !job.enabled {
+			job.nextRun = job.schedule.Next(time.Now())
+		}
+		job.enabled = *enabled
+	}
+	s.saveState()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	status := job.status(true)
+	return &status, nil
+}
+
+// status describes a job, with its run history if history is set
+func (j *scheduledJob) status(history bool) models.Job {
+	status := models.Job{
+		Name:        j.definition.Name,
+		Description: j.definition.Description,
+		Schedule:    j.spec,
+		Enabled:     j.enabled,
+		Running:     j.running,
+	}
+	if j.enabled && !j.nextRun.IsZero() {
+		next := j.nextRun
+		status.NextRunAt = &next
+	}
+	if len(j.history) > 0 {
+		last := j.history[len(j.history)-1]
+		status.LastRun = &last
+	}
+	if history {
+		status.History = make([]models.JobRun, 0, len(j.history))
+		for i := len(j.history) - 1; i >= 0; i-- {
+			status.History = append(status.History, j.history[i])
+		}
+	}
+	return status
+}
+
+// Jobs returns every registered job, by name
+func (s *Scheduler) Jobs() []models.Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]models.Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job.status(false))
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Name < jobs[j].Name
+	})
+	return jobs
+}
+
+// Job returns a job with its run history, newest first
+func (s *Scheduler) Job(name string) (*models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, exists := s.jobs[name]
+	if !exists {
+		return nil, NewNotFoundError("job not found: %s", name)
+	}
+	status := job.status(true)
+	return &status, nil
+}
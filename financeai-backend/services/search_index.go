@@ -0,0 +1,398 @@
+package services
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"financeai-backend/models"
+)
+
+// searchFieldWeights boosts matches in fields that identify a transaction best
+var searchFieldWeights = map[string]float64{
+	"merchant":    2.0,
+	"tags":        1.5,
+	"description": 1.0,
+	"notes":       0.5,
+}
+
+// searchStopWords are skipped when indexing and querying
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "at": true, "for": true,
+	"in": true, "of": true, "on": true, "the": true, "to": true,
+}
+
+// Weights for the different ways a query token can match an indexed term
+const (
+	exactMatchWeight       = 1.0
+	prefixMatchWeight      = 0.8
+	fuzzyMatchWeight       = 0.6
+	fuzzyPrefixMatchWeight = 0.5
+)
+
+// searchToken is a normalized token and its byte offsets in the source text
+type searchToken struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// SearchIndex is an in-memory inverted index over one customer's transactions
+type SearchIndex struct {
+	mu          sync.RWMutex
+	docs        map[string]models.Transaction
+	docText     map[string]map[string]string
+	postings    map[string]map[string]float64 // term -> transaction ID -> weighted frequency
+	sortedTerms []string
+	dirty       bool
+}
+
+// NewSearchIndex creates an empty search index
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{
+		docs:     make(map[string]models.Transaction),
+		docText:  make(map[string]map[string]string),
+		postings: make(map[string]map[string]float64),
+	}
+}
+
+// searchableText returns the indexed fields of a transaction
+func searchableText(transaction models.Transaction) map[string]string {
+	return map[string]string{
+		"merchant":    transaction.Merchant.Name,
+		"description": transaction.Description,
+		"tags":        strings.Join(transaction.Tags, " "),
+		"notes":       transaction.Notes,
+	}
+}
+
+// tokenize splits text into lowercase terms with their offsets
+func tokenize(text string) []searchToken {
+	var tokens []searchToken
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		term := strings.ToLower(text[start:end])
+		if !searchStopWords[term] {
+			tokens = append(tokens, searchToken{Term: term, Start: start, End: end})
+		}
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+	return tokens
+}
+
+// Add indexes transactions, replacing any previously indexed version
+func (s *SearchIndex) Add(transactions ...models.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, transaction := range transactions {
+		s.add(transaction)
+	}
+}
+
+// Remove drops transactions from the index by ID
+func (s *SearchIndex) Remove(ids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.remove(id)
+	}
+}
+
+// Sync brings the index in line with the given transaction list, indexing new
+// or changed transactions and removing ones that no longer exist
+func (s *SearchIndex) Sync(transactions []models.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]bool, len(transactions))
+	for _, transaction := range transactions {
+		seen[transaction.ID] = true
+		s.add(transaction)
+	}
+	for id := range s.docs {
+		if !seen[id] {
+			s.remove(id)
+		}
+	}
+}
+
+// Len returns the number of indexed transactions
+func (s *SearchIndex) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.docs)
+}
+
+func (s *SearchIndex) add(transaction models.Transaction) {
+	text := searchableText(transaction)
+	if existing, exists := s.docText[transaction.ID]; exists && sameFields(existing, text) {
+		// Text is unchanged, just refresh the stored transaction
+		s.docs[transaction.ID] = transaction
+		return
+	}
+
+	s.remove(transaction.ID)
+	s.docs[transaction.ID] = transaction
+	s.docText[transaction.ID] = text
+	for field, value := range text {
+		for _, token := range tokenize(value) {
+			if _, exists := s.postings[token.Term]; !exists {
+				s.postings[token.Term] = make(map[string]float64)
+				s.dirty = true
+			}
+			s.postings[token.Term][transaction.ID] += searchFieldWeights[field]
+		}
+	}
+}
+
+func (s *SearchIndex) remove(id string) {
+	text, exists := s.docText[id]
+	if !exists {
+		return
+	}
+	for _, value := range text {
+		for _, token := range tokenize(value) {
+			delete(s.postings[token.Term], id)
+			if len(s.postings[token.Term]) == 0 {
+				delete(s.postings, token.Term)
+				s.dirty = true
+			}
+		}
+	}
+	delete(s.docs, id)
+	delete(s.docText, id)
+}
+
+// terms returns the indexed terms in sorted order, rebuilding if needed.
+// Callers must hold the write lock.
+func (s *SearchIndex) terms() []string {
+	if s.dirty || s.sortedTerms == nil {
+		s.sortedTerms = make([]string, 0, len(s.postings))
+		for term := range s.postings {
+			s.sortedTerms = append(s.sortedTerms, term)
+		}
+		sort.Strings(s.sortedTerms)
+		s.dirty = false
+	}
+	return s.sortedTerms
+}
+
+// expandToken finds the indexed terms a query token matches, with the weight
+// of the best way each term matched (exact, prefix or within edit distance)
+func (s *SearchIndex) expandToken(token string, terms []string) map[string]float64 {
+	matches := make(map[string]float64)
+	setMatch := func(term string, weight float64) {
+		if weight > matches[term] {
+			matches[term] = weight
+		}
+	}
+
+	if _, exists := s.postings[token]; exists {
+		setMatch(token, exactMatchWeight)
+	}
+
+	if len(token) >= 2 {
+		for i := sort.SearchStrings(terms, token); i < len(terms) && strings.HasPrefix(terms[i], token); i++ {
+			setMatch(terms[i], prefixMatchWeight)
+		}
+	}
+
+	tokenRunes := []rune(token)
+	maxDistance := fuzzyDistance(len(tokenRunes))
+	if maxDistance == 0 {
+		return matches
+	}
+	for _, term := range terms {
+		// Exact and prefix matches were already scored above
+		if strings.HasPrefix(term, token) {
+			continue
+		}
+		termRunes := []rune(term)
+		if distance := editDistance(tokenRunes, termRunes); distance <= maxDistance {
+			setMatch(term, fuzzyMatchWeight/float64(distance))
+			continue
+		}
+		// Compare against the start of longer terms so "starbux" finds "starbucks"
+		if len(termRunes) > len(tokenRunes) {
+			if distance := editDistance(tokenRunes, termRunes[:len(tokenRunes)]); distance <= maxDistance {
+				setMatch(term, fuzzyPrefixMatchWeight/float64(distance))
+			}
+		}
+	}
+	return matches
+}
+
+// fuzzyDistance returns the edit distance allowed for a token of the given length
+func fuzzyDistance(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Search returns up to limit transactions matching query, ranked by relevance,
+// and the number of transactions that matched before the limit
+func (s *SearchIndex) Search(query string, limit int) ([]models.SearchResult, int) {
+	// Write lock because the sorted term list may be rebuilt
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queryTokens := tokenize(query)
+	if len(queryTokens) == 0 || len(s.docs) == 0 {
+		return []models.SearchResult{}, 0
+	}
+	terms := s.terms()
+	totalDocs := float64(len(s.docs))
+
+	scores := make(map[string]float64)
+	matchedTerms := make(map[string]map[string]bool)
+	for _, queryToken := range queryTokens {
+		// Each query token contributes its single best matching term per transaction
+		best := make(map[string]float64)
+		for term, weight := range s.expandToken(queryToken.Term, terms) {
+			postings := s.postings[term]
+			idf := math.Log(1 + totalDocs/float64(len(postings)))
+			for id, frequency := range postings {
+				score := weight * frequency * idf
+				if score > best[id] {
+					best[id] = score
+				}
+				if matchedTerms[id] == nil {
+					matchedTerms[id] = make(map[string]bool)
+				}
+				matchedTerms[id][term] = true
+			}
+		}
+		for id, score := range best {
+			scores[id] += score
+		}
+	}
+
+	results := make([]models.SearchResult, 0, len(scores))
+	for id, score := range scores {
+		highlights := make(map[string]string)
+		for field, value := range s.docText[id] {
+			if highlighted, matched := highlight(value, matchedTerms[id]); matched {
+				highlights[field] = highlighted
+			}
+		}
+		results = append(results, models.SearchResult{
+			Transaction: s.docs[id],
+			Score:       math.Round(score*1000) / 1000,
+			Highlights:  highlights,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if !results[i].Transaction.TransactionDate.Equal(results[j].Transaction.TransactionDate) {
+			return results[i].Transaction.TransactionDate.After(results[j].Transaction.TransactionDate)
+		}
+		return results[i].Transaction.ID < results[j].Transaction.ID
+	})
+	total := len(results)
+	if limit > 0 && total > limit {
+		results = results[:limit]
+	}
+	return results, total
+}
+
+// highlight wraps the matched terms of text in <mark> tags, escaping the rest
+func highlight(text string, terms map[string]bool) (string, bool) {
+	var builder strings.Builder
+	matched := false
+	last := 0
+	for _, token := range tokenize(text) {
+		if !terms[token.Term] {
+			continue
+		}
+		matched = true
+		builder.WriteString(html.EscapeString(text[last:token.Start]))
+		builder.WriteString("<mark>")
+		builder.WriteString(html.EscapeString(text[token.Start:token.End]))
+		builder.WriteString("</mark>")
+		last = token.End
+	}
+	builder.WriteString(html.EscapeString(text[last:]))
+	return builder.String(), matched
+}
+
+// editDistance computes the Levenshtein distance between two rune slices
+func editDistance(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func sameFields(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if b[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchService keeps a search index per customer
+type SearchService struct {
+	mu      sync.Mutex
+	indexes map[string]*SearchIndex
+}
+
+// NewSearchService creates a new search service instance
+func NewSearchService() *SearchService {
+	return &SearchService{
+		indexes: make(map[string]*SearchIndex),
+	}
+}
+
+// Index returns the search index for a customer, creating it if needed
+func (s *SearchService) Index(customerID string) *SearchIndex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index, exists := s.indexes[customerID]
+	if !exists {
+		index = NewSearchIndex()
+		s.indexes[customerID] = index
+	}
+	return index
+}
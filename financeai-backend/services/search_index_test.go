@@ -0,0 +1,96 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// searchTransactions have merchants that share prefixes and near spellings
+func searchTransactions() []models.Transaction {
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 12, 0, 0, 0, time.UTC) }
+	return []models.Transaction{
+		{ID: "t1", Description: "STARBUCKS STORE 1234", Merchant: models.Merchant{Name: "Starbucks"}, TransactionDate: day(1)},
+		{ID: "t2", Description: "Starbucks reload", Merchant: models.Merchant{Name: "Starbucks"}, TransactionDate: day(4)},
+		{ID: "t3", Description: "SHELL OIL 5733", Merchant: models.Merchant{Name: "Shell"}, Tags: []string{"car"}, TransactionDate: day(2)},
+		{ID: "t4", Description: "Whole Foods Market", Merchant: models.Merchant{Name: "Whole Foods"}, Notes: "starter for the party", TransactionDate: day(3)},
+		{ID: "t5", Description: "Car wash", Merchant: models.Merchant{Name: "Clean Car Wash"}, TransactionDate: day(5)},
+	}
+}
+
+func TestSearchIndexSearch(t *testing.T) {
+	index := NewSearchIndex()
+	index.Add(searchTransactions()...)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"exact", "shell", []string{"t3"}},
+		{"case and punctuation", "SHELL!", []string{"t3"}},
+		{"prefix", "whol", []string{"t4"}},
+		{"misspelled", "starbux", []string{"t2", "t1"}},
+		{"one letter off", "shall", []string{"t3"}},
+		{"merchant ranks above notes", "star", []string{"t2", "t1", "t4"}},
+		{"several fields", "wash", []string{"t5"}},
+		{"merchant ranks above tags", "car", []string{"t5", "t3"}},
+		{"only stop words", "the and", []string{}},
+		{"nothing close", "zzzz", []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, total := index.Search(test.query, 0)
+			if got := searchResultIDs(results); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Search(%q) = %v, want %v", test.query, got, test.want)
+			}
+			if total != len(test.want) {
+				t.Errorf("total = %d, want %d", total, len(test.want))
+			}
+		})
+	}
+}
+
+func TestSearchIndexLimitKeepsTotal(t *testing.T) {
+	index := NewSearchIndex()
+	index.Add(searchTransactions()...)
+	results, total := index.Search("star", 1)
+	if len(results) != 1 || total != 3 {
+		t.Errorf("returned %d results of %d, want 1 of 3", len(results), total)
+	}
+}
+
+func TestSearchIndexHighlightsAndSync(t *testing.T) {
+	index := NewSearchIndex()
+	transactions := searchTransactions()
+	index.Sync(transactions)
+
+	results, _ := index.Search("shell", 0)
+	if len(results) != 1 || results[0].Highlights["description"] != "<mark>SHELL</mark> OIL 5733" {
+		t.Errorf("highlights = %v", results[0].Highlights)
+	}
+
+	// Dropped and edited transactions are reindexed on the next sync
+	transactions[2].Description = "Chevron 0042"
+	transactions[2].Merchant.Name = "Chevron"
+	index.Sync(transactions[1:])
+	if index.Len() != 4 {
+		t.Errorf("index holds %d transactions, want 4", index.Len())
+	}
+	if results, _ := index.Search("shell", 0); len(results) != 0 {
+		t.Errorf("an edited transaction still matches its old merchant: %v", searchResultIDs(results))
+	}
+	if results, _ := index.Search("starbucks store", 0); len(results) != 1 || results[0].Transaction.ID != "t2" {
+		t.Errorf("a removed transaction is still found: %v", searchResultIDs(results))
+	}
+}
+
+func searchResultIDs(results []models.SearchResult) []string {
+	ids := []string{}
+	for _, result := range results {
+		ids = append(ids, result.Transaction.ID)
+	}
+	return ids
+}
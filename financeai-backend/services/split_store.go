@@ -0,0 +1,151 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"sync"
+
+	"financeai-backend/models"
+)
+
+// SplitStore holds the category splits users have applied to transactions,
+// keyed by customer and transaction ID
+type SplitStore struct {
+	mu     sync.RWMutex
+	splits map[string]map[string][]models.TransactionSplit
+}
+
+// defaultSplitStore is shared by the data providers so splits made through
+// one endpoint show up in every aggregation
+var defaultSplitStore = NewSplitStore()
+
+// NewSplitStore creates an empty split store
+func NewSplitStore() *SplitStore {
+	return &SplitStore{
+		splits: make(map[string]map[string][]models.TransactionSplit),
+	}
+}
+
+// ValidateSplits checks that splits are well formed and sum to the parent amount
+func ValidateSplits(transaction models.Transaction, splits []models.TransactionSplit) error {
+	if len(splits) < 2 {
+		return NewValidationError("splits", "a split needs at least two allocations")
+	}
+
+	var totalCents int64
+	for i, split := range splits {
+		if strings.TrimSpace(split.Category) == "" {
+			return NewValidationError("splits", "split %d: category required", i+1)
+		}
+		if split.Amount == 0 {
+			return NewValidationError("splits", "split %d: amount must not be zero", i+1)
+		}
+		if (split.Amount < 0) != (transaction.Amount < 0) {
+			return NewValidationError("splits", "split %d: amount must have the same sign as the transaction", i+1)
+		}
+		totalCents += toCents(split.Amount)
+	}
+
+	if totalCents != toCents(transaction.Amount) {
+		return NewValidationError("splits", "splits add up to %.2f but the transaction amount is %.2f",
+			float64(totalCents)/100, float64(toCents(transaction.Amount))/100)
+	}
+	return nil
+}
+
+// Set validates and stores the splits for a transaction
+func (s *SplitStore) Set(customerID string, transaction models.Transaction, splits []models.TransactionSplit) error {
+	if err := ValidateSplits(transaction, splits); err != nil {
+		return err
+	}
+
+	stored := make([]models.TransactionSplit, len(splits))
+	for i, split := range splits {
+		stored[i] = models.TransactionSplit{
+			Category: strings.TrimSpace(split.Category),
+			Amount:   split.Amount,
+			Note:     strings.TrimSpace(split.Note),
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.splits[customerID]; !exists {
+		s.splits[customerID] = make(map[string][]models.TransactionSplit)
+	}
+	s.splits[customerID][transaction.ID] = stored
+	return nil
+}
+
+// Clear removes the splits for a transaction
+func (s *SplitStore) Clear(customerID string, transactionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.splits[customerID], transactionID)
+}
+
+// Apply returns a copy of transactions with any stored splits attached
+func (s *SplitStore) Apply(customerID string, transactions []models.Transaction) []models.Transaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.Transaction, len(transactions))
+	copy(result, transactions)
+	for i := range result {
+		if splits, exists := s.splits[customerID][result[i].ID]; exists {
+			result[i].Splits = append([]models.TransactionSplit(nil), splits...)
+		}
+	}
+	return result
+}
+
+// TransactionAllocations returns how a transaction's amount is divided across
+// categories: its splits if it has any, otherwise its own category
+func TransactionAllocations(transaction models.Transaction) []models.TransactionSplit {
+	if len(transaction.Splits) > 0 {
+		return transaction.Splits
+	}
+	return []models.TransactionSplit{{
+		Category: transactionCategory(transaction),
+		Amount:   transaction.Amount,
+	}}
+}
+
+// applySplitsToCategorySpending moves the amounts of split transactions from
+// the parent's category to the split categories
+func applySplitsToCategorySpending(categorySpending []models.CategorySpending, transactions []models.Transaction) []models.CategorySpending {
+	deltas := make(map[string]float64)
+	for _, transaction := range transactions {
+		if len(transaction.Splits) == 0 {
+			continue
+		}
+		deltas[transactionCategory(transaction)] -= math.Abs(transaction.Amount)
+		for _, split := range transaction.Splits {
+			deltas[split.Category] += math.Abs(split.Amount)
+		}
+	}
+	if len(deltas) == 0 {
+		return categorySpending
+	}
+
+	result := make([]models.CategorySpending, 0, len(categorySpending))
+	for _, category := range categorySpending {
+		category.Amount = math.Max(0, category.Amount+deltas[category.Category])
+		delete(deltas, category.Category)
+		result = append(result, category)
+	}
+	for category, amount := range deltas {
+		if amount > 0 {
+			result = append(result, models.CategorySpending{
+				Category: category,
+				Amount:   amount,
+				Color:    categoryColor(category),
+			})
+		}
+	}
+	return result
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
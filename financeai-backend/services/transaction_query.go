@@ -0,0 +1,274 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"financeai-backend/models"
+)
+
+const (
+	// DefaultTransactionPageLimit is the page size when a cursor is given
+	// without a limit
+	DefaultTransactionPageLimit = 50
+	// MaxTransactionPageLimit caps the page size a caller can request
+	MaxTransactionPageLimit = 200
+)
+
+// transactionSortFields lists the fields transactions can be sorted by
+var transactionSortFields = map[string]bool{
+	"date":        true,
+	"amount":      true,
+	"merchant":    true,
+	"category":    true,
+	"description": true,
+}
+
+// transactionSortKey holds the value a transaction is ordered by. Only the
+// field for the active sort is set; ID breaks ties so the order is stable.
+type transactionSortKey struct {
+	Time   time.Time `json:"t,omitempty"`
+	Number float64   `json:"n,omitempty"`
+	Text   string    `json:"x,omitempty"`
+	ID     string    `json:"i"`
+}
+
+// transactionCursor is the decoded form of an opaque page cursor
+type transactionCursor struct {
+	SortBy  string             `json:"s"`
+	SortDir string             `json:"d"`
+	After   transactionSortKey `json:"a"`
+}
+
+// NormalizeTransactionSort fills in the default sort and validates it
+func NormalizeTransactionSort(filter *models.TransactionFilter) error {
+	filter.SortBy = strings.ToLower(filter.SortBy)
+	filter.SortDir = strings.ToLower(filter.SortDir)
+	if filter.SortBy == "" {
+		filter.SortBy = "date"
+	}
+	if !transactionSortFields[filter.SortBy] {
+		return NewValidationError("sort", "invalid sort field: %s", filter.SortBy)
+	}
+	if filter.SortDir == "" {
+		filter.SortDir = "desc"
+		if filter.SortBy != "date" && filter.SortBy != "amount" {
+			filter.SortDir = "asc"
+		}
+	}
+	if filter.SortDir != "asc" && filter.SortDir != "desc" {
+		return NewValidationError("order", "invalid sort direction: %s", filter.SortDir)
+	}
+	return nil
+}
+
+// FilterTransactions returns the transactions matching filter, in the
+// filter's sort order. Cursor and Limit are ignored; see PaginateTransactions.
+func FilterTransactions(transactions []models.Transaction, filter models.TransactionFilter) ([]models.Transaction, error) {
+	if err := NormalizeTransactionSort(&filter); err != nil {
+		return nil, err
+	}
+
+	search := strings.ToLower(strings.TrimSpace(filter.Search))
+	merchant := strings.ToLower(strings.TrimSpace(filter.Merchant))
+
+	result := []models.Transaction{}
+	for _, transaction := range transactions {
+		if !filter.StartDate.IsZero() && transaction.TransactionDate.Before(filter.StartDate) {
+			continue
+		}
+		if !filter.EndDate.IsZero() && !transaction.TransactionDate.Before(filter.EndDate) {
+			continue
+		}
+		if filter.AccountID != "" && transaction.AccountID != filter.AccountID {
+			continue
+		}
+		if filter.Category != "" && !hasCategory(transaction, filter.Category) {
+			continue
+		}
+		if merchant != "" && !strings.Contains(strings.ToLower(transaction.Merchant.Name), merchant) {
+			continue
+		}
+		amount := math.Abs(transaction.Amount)
+		if filter.MinAmount != nil && amount < *filter.MinAmount {
+			continue
+		}
+		if filter.MaxAmount != nil && amount > *filter.MaxAmount {
+			continue
+		}
+		if filter.Status != "" && !strings.EqualFold(transaction.Status, filter.Status) {
+			continue
+		}
+		if !hasTags(transaction, filter.Tags) {
+			continue
+		}
+		if (filter.Transfers == "exclude" && transaction.IsTransfer) || (filter.Transfers == "only" && !transaction.IsTransfer) {
+			continue
+		}
+		if search != "" &&
+			!strings.Contains(strings.ToLower(transaction.Description), search) &&
+			!strings.Contains(strings.ToLower(transaction.Merchant.Name), search) {
+			continue
+		}
+		result = append(result, transaction)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return compareTransactionSortKeys(
+			sortKeyFor(result[i], filter.SortBy),
+			sortKeyFor(result[j], filter.SortBy),
+			filter.SortDir,
+		) < 0
+	})
+
+	return result, nil
+}
+
+// PaginateTransactions returns the page of an already filtered and sorted
+// list that follows filter.Cursor, along with the cursor for the next page.
+// Without a cursor or limit the whole list is one page, as it was before
+// lists were paginated.
+func PaginateTransactions(transactions []models.Transaction, filter models.TransactionFilter) (*models.TransactionPage, error) {
+	if err := NormalizeTransactionSort(&filter); err != nil {
+		return nil, err
+	}
+	if filter.Cursor == "" && filter.Limit <= 0 {
+		return &models.TransactionPage{
+			Transactions: transactions,
+			Total:        len(transactions),
+			Count:        len(transactions),
+		}, nil
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultTransactionPageLimit
+	}
+	if limit > MaxTransactionPageLimit {
+		limit = MaxTransactionPageLimit
+	}
+
+	start := 0
+	if filter.Cursor != "" {
+		cursor, err := decodeTransactionCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.SortBy != filter.SortBy || cursor.SortDir != filter.SortDir {
+			return nil, NewValidationError("cursor", "cursor does not match the requested sort order")
+		}
+		start = sort.Search(len(transactions), func(i int) bool {
+			return compareTransactionSortKeys(sortKeyFor(transactions[i], filter.SortBy), cursor.After, filter.SortDir) > 0
+		})
+	}
+
+	end := start + limit
+	if end > len(transactions) {
+		end = len(transactions)
+	}
+
+	page := &models.TransactionPage{
+		Transactions: transactions[start:end],
+		Total:        len(transactions),
+		Count:        end - start,
+		HasMore:      end < len(transactions),
+	}
+	if page.HasMore {
+		page.NextCursor = encodeTransactionCursor(transactionCursor{
+			SortBy:  filter.SortBy,
+			SortDir: filter.SortDir,
+			After:   sortKeyFor(transactions[end-1], filter.SortBy),
+		})
+	}
+
+	return page, nil
+}
+
+// hasCategory reports whether any part of a transaction is in category
+func hasCategory(transaction models.Transaction, category string) bool {
+	for _, allocation := range TransactionAllocations(transaction) {
+		if strings.EqualFold(allocation.Category, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTags reports whether a transaction carries every one of tags
+func hasTags(transaction models.Transaction, tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, transactionTag := range transaction.Tags {
+			if transactionTag == normalizeTag(tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sortKeyFor extracts the sort key of a transaction for the given field
+func sortKeyFor(transaction models.Transaction, sortBy string) transactionSortKey {
+	key := transactionSortKey{ID: transaction.ID}
+	switch sortBy {
+	case "date":
+		key.Time = transaction.TransactionDate.UTC()
+	case "amount":
+		key.Number = math.Abs(transaction.Amount)
+	case "merchant":
+		key.Text = strings.ToLower(transaction.Merchant.Name)
+	case "category":
+		key.Text = strings.ToLower(transactionCategory(transaction))
+	case "description":
+		key.Text = strings.ToLower(transaction.Description)
+	}
+	return key
+}
+
+// compareTransactionSortKeys orders two keys in the given direction
+func compareTransactionSortKeys(a, b transactionSortKey, direction string) int {
+	result := a.Time.Compare(b.Time)
+	if result == 0 {
+		switch {
+		case a.Number < b.Number:
+			result = -1
+		case a.Number > b.Number:
+			result = 1
+		}
+	}
+	if result == 0 {
+		result = strings.Compare(a.Text, b.Text)
+	}
+	if result == 0 {
+		result = strings.Compare(a.ID, b.ID)
+	}
+	if direction == "desc" {
+		return -result
+	}
+	return result
+}
+
+func encodeTransactionCursor(cursor transactionCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeTransactionCursor(value string) (transactionCursor, error) {
+	var cursor transactionCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, NewValidationError("cursor", "invalid cursor")
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, NewValidationError("cursor", "invalid cursor")
+	}
+	return cursor, nil
+}
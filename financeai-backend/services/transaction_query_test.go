@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// queryTransactions is a small ledger with ties on date and amount, to
+// check the ID tiebreak
+func queryTransactions() []models.Transaction {
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 12, 0, 0, 0, time.UTC) }
+	return []models.Transaction{
+		{ID: "t1", AccountID: "checking", Amount: -40, TransactionDate: day(1), Description: "Whole Foods", Merchant: models.Merchant{Name: "Whole Foods"}, Tags: []string{"groceries"}, Status: "completed"},
+		{ID: "t2", AccountID: "checking", Amount: -12.5, TransactionDate: day(3), Description: "Starbucks", Merchant: models.Merchant{Name: "Starbucks"}, Status: "completed"},
+		{ID: "t3", AccountID: "card", Amount: -40, TransactionDate: day(3), Description: "Shell", Merchant: models.Merchant{Name: "Shell"}, Tags: []string{"car", "groceries"}, Status: "pending"},
+		{ID: "t4", AccountID: "checking", Amount: 2500, TransactionDate: day(5), Description: "Payroll"},
+		{ID: "t5", AccountID: "savings", Amount: 500, TransactionDate: day(3), Description: "Transfer from Checking", IsTransfer: true},
+	}
+}
+
+func transactionIDs(transactions []models.Transaction) []string {
+	ids := []string{}
+	for _, transaction := range transactions {
+		ids = append(ids, transaction.ID)
+	}
+	return ids
+}
+
+func TestFilterTransactions(t *testing.T) {
+	minAmount := 40.0
+	tests := []struct {
+		name   string
+		filter models.TransactionFilter
+		want   []string
+	}{
+		{"newest first by default", models.TransactionFilter{}, []string{"t4", "t5", "t3", "t2", "t1"}},
+		{"date range", models.TransactionFilter{StartDate: time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC)}, []string{"t5", "t3", "t2"}},
+		{"account", models.TransactionFilter{AccountID: "checking"}, []string{"t4", "t2", "t1"}},
+		{"minimum amount", models.TransactionFilter{MinAmount: &minAmount}, []string{"t4", "t5", "t3", "t1"}},
+		{"every tag", models.TransactionFilter{Tags: []string{"Groceries", "car"}}, []string{"t3"}},
+		{"status", models.TransactionFilter{Status: "PENDING"}, []string{"t3"}},
+		{"without transfers", models.TransactionFilter{Transfers: "exclude"}, []string{"t4", "t3", "t2", "t1"}},
+		{"only transfers", models.TransactionFilter{Transfers: "only"}, []string{"t5"}},
+		{"search", models.TransactionFilter{Search: " starb "}, []string{"t2"}},
+		{"amount ties broken by ID", models.TransactionFilter{SortBy: "amount", SortDir: "asc"}, []string{"t2", "t1", "t3", "t5", "t4"}},
+		{"date ties broken by ID, descending", models.TransactionFilter{SortBy: "date", SortDir: "desc"}, []string{"t4", "t5", "t3", "t2", "t1"}},
+		{"text ascending by default", models.TransactionFilter{SortBy: "description"}, []string{"t4", "t3", "t2", "t5", "t1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transactions, err := FilterTransactions(queryTransactions(), test.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := transactionIDs(transactions); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	if _, err := FilterTransactions(queryTransactions(), models.TransactionFilter{SortBy: "balance"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("sorting by an unknown field returned %v, want a validation error", err)
+	}
+}
+
+func TestPaginateTransactionsFollowsCursors(t *testing.T) {
+	for _, sortBy := range []string{"date", "amount", "description"} {
+		t.Run(sortBy, func(t *testing.T) {
+			filter := models.TransactionFilter{SortBy: sortBy, Limit: 2}
+			sorted, err := FilterTransactions(queryTransactions(), filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var seen []string
+			for pages := 0; ; pages++ {
+				if pages > len(sorted) {
+					t.Fatal("cursors never reached the last page")
+				}
+				page, err := PaginateTransactions(sorted, filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if page.Total != len(sorted) || page.Count != len(page.Transactions) || page.Count > 2 {
+					t.Errorf("page total %d, count %d of %d transactions", page.Total, page.Count, len(page.Transactions))
+				}
+				seen = append(seen, transactionIDs(page.Transactions)...)
+				if !page.HasMore {
+					if page.NextCursor != "" {
+						t.Error("the last page has a next cursor")
+					}
+					break
+				}
+				filter.Cursor = page.NextCursor
+			}
+			if want := transactionIDs(sorted); !reflect.DeepEqual(seen, want) {
+				t.Errorf("pages returned %v, want %v", seen, want)
+			}
+		})
+	}
+}
+
+func TestPaginateTransactionsWithoutLimit(t *testing.T) {
+	transactions := make([]models.Transaction, DefaultTransactionPageLimit+10)
+	for i := range transactions {
+		transactions[i] = models.Transaction{ID: string(rune('a' + i%26)), TransactionDate: time.Now()}
+	}
+	page, err := PaginateTransactions(transactions, models.TransactionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Count != len(transactions) || page.HasMore || page.NextCursor != "" {
+		t.Errorf("returned %d of %d transactions, has more %v", page.Count, len(transactions), page.HasMore)
+	}
+}
+
+func TestPaginateTransactionsRejectsBadCursors(t *testing.T) {
+	sorted, _ := FilterTransactions(queryTransactions(), models.TransactionFilter{})
+	page, err := PaginateTransactions(sorted, models.TransactionFilter{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter models.TransactionFilter
+	}{
+		{"not base64", models.TransactionFilter{Cursor: "not a cursor!"}},
+		{"not JSON", models.TransactionFilter{Cursor: "bm90IGpzb24"}},
+		{"another sort order", models.TransactionFilter{Cursor: page.NextCursor, SortBy: "amount"}},
+		{"another direction", models.TransactionFilter{Cursor: page.NextCursor, SortDir: "asc"}},
+	}
+	for _, test := range tests {
+		if _, err := PaginateTransactions(sorted, test.filter); !errors.Is(err, ErrValidation) {
+			t.Errorf("%s: returned %v, want a validation error", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"time"
+
+	"financeai-backend/models"
+)
+
+const (
+	// TransferTypeInternal marks money moved between two of a customer's own accounts
+	TransferTypeInternal = "internal"
+	// TransferTypeCreditCardPayment marks a payment toward a credit card balance
+	TransferTypeCreditCardPayment = "credit_card_payment"
+
+	// DefaultTransferWindow is how far apart the two sides of a transfer can post
+	DefaultTransferWindow = 3 * 24 * time.Hour
+)
+
+// creditCardPaymentPattern matches descriptions of card payments whose other
+// side isn't visible, e.g. a card held at another bank. Autopay and card
+// payment only count alongside a card or issuer word, so bills paid by
+// autopay or with a debit card aren't taken for card payments.
+var creditCardPaymentPattern = regexp.MustCompile(`(?i)(credit card (payment|autopay)|payment thank you|\bepay\b|cardmember serv|\b(amex|american express|chase card|citi card|discover|capital one|barclaycard)\b.*\b(e?payment|autopay|pymt|pmt)\b)`)
+
+// transferDescriptionPattern matches descriptions that read like money moved
+// between accounts, such as "Transfer to Savings" or "Payment Thank You"
+var transferDescriptionPattern = regexp.MustCompile(`(?i)(\b(transfer|xfer|trnsfr|payment|pmt)\b|\b(to|from)\b.*\b(account|acct|checking|savings)\b)`)
+
+// TransferDetector pairs outflows and inflows between a customer's accounts
+type TransferDetector struct {
+	Window time.Duration
+}
+
+// NewTransferDetector creates a transfer detector with the default date window
+func NewTransferDetector() *TransferDetector {
+	return &TransferDetector{Window: DefaultTransferWindow}
+}
+
+// Detect marks transfers in a customer's transactions. An outflow on one
+// account is paired with an inflow of the same amount on another account of
+// the customer within the window, preferring the closest date, when both
+// descriptions read like a transfer. Charges on a credit card are spending,
+// so they're never paired. Payments into a credit card account, or
+// described as card payments, are marked as credit card payments.
+func (d *TransferDetector) Detect(accounts []models.Account, transactions []models.Transaction) []models.Transaction {
+	result := make([]models.Transaction, len(transactions))
+	copy(result, transactions)
+
+	accountTypes := make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		accountTypes[account.ID] = account.Type
+	}
+
+	var outflows, inflows []int
+	for i, transaction := range result {
+		accountType, owned := accountTypes[transaction.AccountID]
+		if !owned || transaction.IsTransfer || !transferDescriptionPattern.MatchString(transaction.Description) {
+			continue
+		}
+		if transaction.Amount < 0 && !isCreditCardAccount(accountType) {
+			outflows = append(outflows, i)
+		} else if transaction.Amount > 0 {
+			inflows = append(inflows, i)
+		}
+	}
+	sort.SliceStable(outflows, func(i, j int) bool {
+		return result[outflows[i]].TransactionDate.Before(result[outflows[j]].TransactionDate)
+	})
+
+	matched := make(map[int]bool)
+	for _, out := range outflows {
+		best := -1
+		var bestGap time.Duration
+		for _, in := range inflows {
+			if matched[in] || result[in].AccountID == result[out].AccountID {
+				continue
+			}
+			if toCents(math.Abs(result[out].Amount)) != toCents(result[in].Amount) {
+				continue
+			}
+			gap := result[in].TransactionDate.Sub(result[out].TransactionDate)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap > d.Window {
+				continue
+			}
+			if best < 0 || gap < bestGap {
+				best, bestGap = in, gap
+			}
+		}
+		if best < 0 {
+			continue
+		}
+
+		matched[best] = true
+		transferType := TransferTypeInternal
+		if isCreditCardAccount(accountTypes[result[best].AccountID]) {
+			transferType = TransferTypeCreditCardPayment
+		}
+		markTransfer(&result[out], transferType, result[best].ID)
+		markTransfer(&result[best], transferType, result[out].ID)
+	}
+
+	// Card payments whose other side we can't see
+	for i := range result {
+		if !result[i].IsTransfer && creditCardPaymentPattern.MatchString(result[i].Description) {
+			markTransfer(&result[i], TransferTypeCreditCardPayment, "")
+		}
+	}
+
+	return result
+}
+
+func markTransfer(transaction *models.Transaction, transferType string, pairID string) {
+	transaction.IsTransfer = true
+	transaction.TransferType = transferType
+	transaction.TransferPairID = pairID
+}
+
+func isCreditCardAccount(accountType string) bool {
+	return accountType == "Credit Card"
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"financeai-backend/models"
+)
+
+func TestCreditCardPaymentPattern(t *testing.T) {
+	tests := []struct {
+		description string
+		want        bool
+	}{
+		{"Credit Card Payment", true},
+		{"PAYMENT THANK YOU", true},
+		{"CHASE CREDIT CARD AUTOPAY", true},
+		{"AMEX EPAYMENT ACH PMT", true},
+		{"DISCOVER E-PAYMENT", true},
+		{"CAPITAL ONE AUTOPAY PYMT", true},
+		{"CARDMEMBER SERV WEB PYMT", true},
+		{"COMCAST AUTOPAY", false},
+		{"GEICO AUTOPAY", false},
+		{"DEBIT CARD PAYMENT - WALMART", false},
+		{"Whole Foods Market", false},
+	}
+	for _, test := range tests {
+		if got := creditCardPaymentPattern.MatchString(test.description); got != test.want {
+			t.Errorf("%q matched %v, want %v", test.description, got, test.want)
+		}
+	}
+}
+
+func TestTransferDetectorPairs(t *testing.T) {
+	accounts := []models.Account{
+		{ID: "checking", Type: "Checking"},
+		{ID: "savings", Type: "Savings"},
+		{ID: "card", Type: "Credit Card"},
+	}
+	day := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+	transaction := func(id string, accountID string, amount float64, daysLater int, description string) models.Transaction {
+		return models.Transaction{ID: id, AccountID: accountID, Amount: amount, TransactionDate: day.AddDate(0, 0, daysLater), Description: description}
+	}
+
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+		// transfers maps each transaction that should be a transfer to its
+		// pair, "" for a card payment without one
+		transfers map[string]string
+	}{
+		{
+			name: "transfer between own accounts",
+			transactions: []models.Transaction{
+				transaction("out", "checking", -500, 0, "Transfer to Emergency Fund"),
+				transaction("in", "savings", 500, 1, "Transfer from Primary Checking"),
+			},
+			transfers: map[string]string{"out": "in", "in": "out"},
+		},
+		{
+			name: "card payment",
+			transactions: []models.Transaction{
+				transaction("out", "checking", -300, 0, "Credit Card Payment"),
+				transaction("in", "card", 300, 1, "Payment Thank You"),
+			},
+			transfers: map[string]string{"out": "in", "in": "out"},
+		},
+		{
+			name: "purchase and an unrelated deposit",
+			transactions: []models.Transaction{
+				transaction("groceries", "checking", -50, 0, "Whole Foods Market"),
+				transaction("zelle", "savings", 50, 1, "Zelle Transfer From Lisa Davis"),
+			},
+		},
+		{
+			name: "card purchase and a deposit",
+			transactions: []models.Transaction{
+				transaction("charge", "card", -50, 0, "Payment to Joe's Diner"),
+				transaction("deposit", "checking", 50, 1, "Transfer from Savings"),
+			},
+		},
+		{
+			name: "too far apart",
+			transactions: []models.Transaction{
+				transaction("out", "checking", -500, 0, "Transfer to Savings"),
+				transaction("in", "savings", 500, 5, "Transfer from Checking"),
+			},
+		},
+		{
+			name: "bill paid by autopay",
+			transactions: []models.Transaction{
+				transaction("comcast", "checking", -89.99, 0, "COMCAST AUTOPAY"),
+				transaction("issuer", "checking", -120, 0, "CAPITAL ONE AUTOPAY PYMT"),
+			},
+			transfers: map[string]string{"issuer": ""},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, transaction := range NewTransferDetector().Detect(accounts, test.transactions) {
+				pair, want := test.transfers[transaction.ID]
+				if transaction.IsTransfer != want || transaction.TransferPairID != pair {
+					t.Errorf("%s: transfer %v paired with %q, want transfer %v paired with %q", transaction.ID, transaction.IsTransfer, transaction.TransferPairID, want, pair)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,732 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusRetrying  = "retrying"
+	WebhookStatusSucceeded = "succeeded"
+	WebhookStatusDead      = "dead_lettered"
+)
+
+// Webhook request headers
+const (
+	WebhookSignatureHeader = "X-FinSights-Signature"
+	WebhookEventHeader     = "X-FinSights-Event"
+	WebhookDeliveryHeader  = "X-FinSights-Delivery"
+
+	// WebhookSignatureTolerance is how old a signed timestamp can be before
+	// VerifyWebhookSignature rejects it
+	WebhookSignatureTolerance = 5 * time.Minute
+
+	// maxDeliveriesPerSubscription caps the delivery log kept for each subscription
+	maxDeliveriesPerSubscription = 200
+)
+
+// WebhookEventTypes lists the events integrators can subscribe to
+var WebhookEventTypes = []string{
+	EventTransactionCreated,
+	EventBudgetExceeded,
+	EventInsightGenerated,
+	EventBudgetThreshold,
+	EventLargeTransaction,
+	EventLowBalance,
+	EventBillUpcoming,
+	EventGoalMilestone,
+	EventWeeklyDigest,
+}
+
+// RetryPolicy controls how failed webhook deliveries are retried. Attempt n
+// waits InitialDelay * 2^(n-2), capped at MaxDelay, after the one before.
+type RetryPolicy struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+// DefaultRetryPolicy retries five times over about fifteen minutes
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  6,
+	InitialDelay: 30 * time.Second,
+	MaxDelay:     10 * time.Minute,
+}
+
+// Backoff returns how long to wait before the given attempt, counting from 1
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt <= 1 {
+		return 0
+	}
+	delay := p.InitialDelay
+	for i := 2; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// WebhookInput describes a webhook subscription to create or change. Nil
+// fields are left unchanged on update.
+type WebhookInput struct {
+	URL    *string   `json:"url"`
+	Events *[]string `json:"events"`
+	Secret *string   `json:"secret"`
+	Active *bool     `json:"active"`
+}
+
+// SignWebhookPayload returns the signature header value for a payload sent
+// at timestamp: "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<payload>">"
+func SignWebhookPayload(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + webhookHMAC(secret, t, payload)
+}
+
+// VerifyWebhookSignature checks a signature header against the payload it
+// came with, rejecting signatures older than WebhookSignatureTolerance
+func VerifyWebhookSignature(secret string, header string, payload []byte, now time.Time) error {
+	var t, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			signature = value
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || signature == "" {
+		return fmt.Errorf("malformed signature header")
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > WebhookSignatureTolerance || age < -WebhookSignatureTolerance {
+		return fmt.Errorf("signature timestamp outside tolerance")
+	}
+	if !hmac.Equal([]byte(signature), []byte(webhookHMAC(secret, t, payload))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// webhookHMAC signs "<t>.<payload>" with secret
+func webhookHMAC(secret string, t string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWebhookSecret returns a random signing secret
+func newWebhookSecret() string {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Sprintf("whsec_%d", time.Now().UnixNano())
+	}
+	return "whsec_" + hex.EncodeToString(secret)
+}
+
+// WebhookService stores webhook subscriptions and delivers events to them,
+// retrying failures with exponential backoff. Deliveries that run out of
+// attempts, or that the endpoint rejects outright, go to the dead-letter store
+// where they can be replayed.
+type WebhookService struct {
+	mu            sync.RWMutex
+	subscriptions map[string]map[string]*models.WebhookSubscription
+	deliveries    map[string]*models.WebhookDelivery
+	log           map[string][]string
+	deadLetters   map[string][]string
+	nextID        int
+	nextDelivery  int
+
+	Client *http.Client
+	Retry  RetryPolicy
+}
+
+// defaultWebhookService receives every event published on the default bus
+var defaultWebhookService = newDefaultWebhookService()
+
+// NewWebhookService creates a webhook service with no subscriptions
+func NewWebhookService() *WebhookService {
+	return &WebhookService{
+		subscriptions: make(map[string]map[string]*models.WebhookSubscription),
+		deliveries:    make(map[string]*models.WebhookDelivery),
+		log:           make(map[string][]string),
+		deadLetters:   make(map[string][]string),
+		Client:        &http.Client{Timeout: 10 * time.Second},
+		Retry:         DefaultRetryPolicy,
+	}
+}
+
+func newDefaultWebhookService() *WebhookService {
+	service := NewWebhookService()
+	defaultEventBus.Subscribe(eventSubscribeWildcard, service.Dispatch)
+	return service
+}
+
+// applyWebhookInput copies the set fields of input onto subscription and validates the result
+func applyWebhookInput(subscription *models.WebhookSubscription, input WebhookInput) error {
+	// The notification webhook follows the customer's notification preferences
+	if subscription.Notifications && input.URL != nil {
+		return NewValidationError("url", "the notification webhook's url is set in notification preferences")
+	}
+	if subscription.Notifications && input.Events != nil {
+		return NewValidationError("events", "the notification webhook gets the notifications chosen in notification preferences")
+	}
+	if input.URL != nil {
+		subscription.URL = strings.TrimSpace(*input.URL)
+	}
+	if input.Events != nil {
+		subscription.Events = []string{}
+		seen := make(map[string]bool)
+		for _, eventType := range *input.Events {
+			eventType = strings.TrimSpace(eventType)
+			if seen[eventType] {
+				continue
+			}
+			known := eventType == eventSubscribeWildcard
+			for _, candidate := range WebhookEventTypes {
+				known = known || candidate == eventType
+			}
+			if !known {
+				return NewValidationError("events", "unknown event type: %s", eventType)
+			}
+			seen[eventType] = true
+			subscription.Events = append(subscription.Events, eventType)
+		}
+	}
+	if input.Secret != nil {
+		subscription.Secret = strings.TrimSpace(*input.Secret)
+	}
+	if input.Active != nil {
+		subscription.Active = *input.Active
+	}
+
+	parsed, err := url.Parse(subscription.URL)
+	if subscription.URL == "" {
+		return NewValidationError("url", "url required")
+	}
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return NewValidationError("url", "url must be an http or https URL")
+	}
+	if len(subscription.Events) == 0 && !subscription.Notifications {
+		return NewValidationError("events", "at least one event type required")
+	}
+	if subscription.Secret != "" && len(subscription.Secret) < 16 {
+		return NewValidationError("secret", "secret must be at least 16 characters")
+	}
+	return nil
+}
+
+// Subscriptions returns a customer's webhook subscriptions without their secrets
+func (s *WebhookService) Subscriptions(customerID string) []models.WebhookSubscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscriptions := make([]models.WebhookSubscription, 0, len(s.subscriptions[customerID]))
+	for _, subscription := range s.subscriptions[customerID] {
+		subscriptions = append(subscriptions, redactSubscription(*subscription))
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+	return subscriptions
+}
+
+// Subscription returns one of a customer's webhook subscriptions without its secret
+func (s *WebhookService) Subscription(customerID string, subscriptionID string) (*models.WebhookSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscription, exists := s.subscriptions[customerID][subscriptionID]
+	if !exists {
+		return nil, NewNotFoundError("webhook not found: %s", subscriptionID)
+	}
+	redacted := redactSubscription(*subscription)
+	return &redacted, nil
+}
+
+// redactSubscription copies a subscription, leaving out its secret
+func redactSubscription(subscription models.WebhookSubscription) models.WebhookSubscription {
+	subscription.Secret = ""
+	subscription.Events = append([]string{}, subscription.Events...)
+	return subscription
+}
+
+// Subscribe creates a webhook subscription. A signing secret is generated
+// when none is given; the returned subscription is the only place it's shown.
+func (s *WebhookService) Subscribe(customerID string, input WebhookInput, now time.Time) (*models.WebhookSubscription, error) {
+	subscription := models.WebhookSubscription{CustomerID: customerID, Active: true, CreatedAt: now}
+	if err := applyWebhookInput(&subscription, input); err != nil {
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		subscription.Secret = newWebhookSecret()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	subscription.ID = fmt.Sprintf("wh%d", s.nextID)
+	if _, exists := s.subscriptions[customerID]; !exists {
+		s.subscriptions[customerID] = make(map[string]*models.WebhookSubscription)
+	}
+	s.subscriptions[customerID][subscription.ID] = &subscription
+	created := subscription
+	created.Events = append([]string{}, subscription.Events...)
+	return &created, nil
+}
+
+// Update changes a webhook subscription. Sending an empty secret rotates it to
+// a new generated one. The secret is only returned when it changed.
+func (s *WebhookService) Update(customerID string, subscriptionID string, input WebhookInput) (*models.WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.subscriptions[customerID][subscriptionID]
+	if !exists {
+		return nil, NewNotFoundError("webhook not found: %s", subscriptionID)
+	}
+	subscription := *existing
+	if err := applyWebhookInput(&subscription, input); err != nil {
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		subscription.Secret = newWebhookSecret()
+	}
+	*existing = subscription
+
+	if input.Secret != nil {
+		updated := subscription
+		updated.Events = append([]string{}, subscription.Events...)
+		return &updated, nil
+	}
+	updated := redactSubscription(subscription)
+	return &updated, nil
+}
+
+// Unsubscribe deletes a webhook subscription. Its delivery log is kept.
+func (s *WebhookService) Unsubscribe(customerID string, subscriptionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscription, exists := s.subscriptions[customerID][subscriptionID]
+	if !exists {
+		return NewNotFoundError("webhook not found: %s", subscriptionID)
+	}
+	if subscription.Notifications {
+		return newConflictError("webhook %s delivers notifications; turn off the webhook channel in notification preferences instead", subscriptionID)
+	}
+	delete(s.subscriptions[customerID], subscriptionID)
+	return nil
+}
+
+// SetNotificationWebhook points a customer's notification webhook at target
+// and turns it on, creating it the first time. It's an ordinary subscription
+// apart from what it receives, so notifications are signed, retried and
+// logged like any other delivery. The secret is only returned when the
+// subscription is created.
+func (s *WebhookService) SetNotificationWebhook(customerID string, target string, now time.Time) (*models.WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if subscription := s.notificationWebhook(customerID); subscription != nil {
+		subscription.URL = target
+		subscription.Active = true
+		updated := redactSubscription(*subscription)
+		return &updated, nil
+	}
+
+	s.nextID++
+	subscription := &models.WebhookSubscription{
+		ID:            fmt.Sprintf("wh%d", s.nextID),
+		CustomerID:    customerID,
+		URL:           target,
+		Events:        []string{},
+		Secret:        newWebhookSecret(),
+		Active:        true,
+		CreatedAt:     now,
+		Notifications: true,
+	}
+	if _, exists := s.subscriptions[customerID]; !exists {
+		s.subscriptions[customerID] = make(map[string]*models.WebhookSubscription)
+	}
+	s.subscriptions[customerID][subscription.ID] = subscription
+	created := *subscription
+	created.Events = []string{}
+	return &created, nil
+}
+
+// DisableNotificationWebhook turns off a customer's notification webhook,
+// keeping its secret and delivery log in case it's turned back on
+func (s *WebhookService) DisableNotificationWebhook(customerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if subscription := s.notificationWebhook(customerID); subscription != nil {
+		subscription.Active = false
+	}
+}
+
+// notificationWebhook returns a customer's notification webhook, or nil.
+// The caller must hold s.mu.
+func (s *WebhookService) notificationWebhook(customerID string) *models.WebhookSubscription {
+	for _, subscription := range s.subscriptions[customerID] {
+		if subscription.Notifications {
+			return subscription
+		}
+	}
+	return nil
+}
+
+// Deliveries returns a subscription's delivery log, newest first
+func (s *WebhookService) Deliveries(customerID string, subscriptionID string, limit int) []models.WebhookDelivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := []models.WebhookDelivery{}
+	ids := s.log[subscriptionID]
+	for i := len(ids) - 1; i >= 0; i-- {
+		delivery := s.deliveries[ids[i]]
+		if delivery == nil || delivery.CustomerID != customerID {
+			continue
+		}
+		deliveries = append(deliveries, copyDelivery(*delivery))
+		if limit > 0 && len(deliveries) == limit {
+			break
+		}
+	}
+	return deliveries
+}
+
+// DeadLetters returns a customer's deliveries that failed for good, newest first
+func (s *WebhookService) DeadLetters(customerID string) []models.WebhookDelivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := []models.WebhookDelivery{}
+	ids := s.deadLetters[customerID]
+	for i := len(ids) - 1; i >= 0; i-- {
+		if delivery := s.deliveries[ids[i]]; delivery != nil {
+			deliveries = append(deliveries, copyDelivery(*delivery))
+		}
+	}
+	return deliveries
+}
+
+// Prune removes successful deliveries completed before cutoff from the
+// delivery log and returns how many it removed. Failed and dead-lettered
+// deliveries are kept.
+func (s *WebhookService) Prune(cutoff time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for subscriptionID, ids := range s.log {
+		kept := ids[:0]
+		for _, id := range ids {
+			delivery := s.deliveries[id]
+			if delivery != nil && delivery.Status == WebhookStatusSucceeded && delivery.CompletedAt != nil && delivery.CompletedAt.Before(cutoff) {
+				delete(s.deliveries, id)
+				removed++
+				continue
+			}
+			kept = append(kept, id)
+		}
+		s.log[subscriptionID] = kept
+	}
+	return removed
+}
+
+// copyDelivery copies a delivery so callers don't share its attempts
+func copyDelivery(delivery models.WebhookDelivery) models.WebhookDelivery {
+	delivery.Attempts = append([]models.WebhookAttempt(nil), delivery.Attempts...)
+	return delivery
+}
+
+// Dispatch sends an event to every active subscription of its customer that
+// listens for the event's type. Deliveries run in the background.
+func (s *WebhookService) Dispatch(event models.Event) {
+	s.mu.RLock()
+	var targets []models.WebhookSubscription
+	for _, subscription := range s.subscriptions[event.CustomerID] {
+		if !subscription.Active {
+			continue
+		}
+		for _, eventType := range subscription.Events {
+			if eventType == event.Type || eventType == eventSubscribeWildcard {
+				targets = append(targets, *subscription)
+				break
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, subscription := range targets {
+		delivery, err := s.enqueue(subscription, event)
+		if err != nil {
+			fmt.Printf("Webhook Error: %v\n", err)
+			continue
+		}
+		go s.attempt(delivery.ID)
+	}
+}
+
+// SendTest sends a test event to one subscription, whatever events it listens
+// for, and returns the delivery after its first attempt
+func (s *WebhookService) SendTest(customerID string, subscriptionID string, now time.Time) (*models.WebhookDelivery, error) {
+	s.mu.RLock()
+	subscription, exists := s.subscriptions[customerID][subscriptionID]
+	var target models.WebhookSubscription
+	if exists {
+		target = *subscription
+	}
+	s.mu.RUnlock()
+	if !exists {
+		return nil, NewNotFoundError("webhook not found: %s", subscriptionID)
+	}
+
+	event := models.Event{
+		ID:         newEventID(),
+		Type:       EventWebhookTest,
+		CustomerID: customerID,
+		Title:      "FinSights test event",
+		Message:    "This is a test event sent from FinSights.",
+		Data:       map[string]interface{}{"subscriptionId": subscriptionID},
+		OccurredAt: now,
+	}
+	delivery, err := s.enqueue(target, event)
+	if err != nil {
+		return nil, err
+	}
+	s.attempt(delivery.ID)
+	return s.Delivery(customerID, delivery.ID)
+}
+
+// DeliverNotification sends a notification to the customer's notification
+// webhook and returns the delivery after its first attempt. Failed attempts
+// are retried in the background like any other delivery.
+func (s *WebhookService) DeliverNotification(notification models.Notification) (*models.WebhookDelivery, error) {
+	s.mu.RLock()
+	subscription := s.notificationWebhook(notification.CustomerID)
+	var target models.WebhookSubscription
+	if subscription != nil {
+		target = *subscription
+	}
+	s.mu.RUnlock()
+	if subscription == nil {
+		return nil, NewNotFoundError("no notification webhook for %s", notification.CustomerID)
+	}
+	if !target.Active {
+		return nil, fmt.Errorf("notification webhook %s is turned off", target.ID)
+	}
+
+	event := models.Event{
+		ID:         newEventID(),
+		Type:       notification.EventType,
+		CustomerID: notification.CustomerID,
+		Title:      notification.Title,
+		Message:    notification.Message,
+		Data:       notification.Data,
+		OccurredAt: notification.CreatedAt,
+	}
+	delivery, err := s.enqueue(target, event)
+	if err != nil {
+		return nil, err
+	}
+	s.attempt(delivery.ID)
+	return s.Delivery(notification.CustomerID, delivery.ID)
+}
+
+// Delivery returns one of a customer's webhook deliveries
+func (s *WebhookService) Delivery(customerID string, deliveryID string) (*models.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	delivery, exists := s.deliveries[deliveryID]
+	if !exists || delivery.CustomerID != customerID {
+		return nil, NewNotFoundError("delivery not found: %s", deliveryID)
+	}
+	copied := copyDelivery(*delivery)
+	return &copied, nil
+}
+
+// Replay takes a dead-lettered delivery out of the dead-letter store and
+// sends it again with a fresh set of attempts
+func (s *WebhookService) Replay(customerID string, deliveryID string) (*models.WebhookDelivery, error) {
+	s.mu.Lock()
+	delivery, exists := s.deliveries[deliveryID]
+	if !exists || delivery.CustomerID != customerID {
+		s.mu.Unlock()
+		return nil, NewNotFoundError("delivery not found: %s", deliveryID)
+	}
+	if delivery.Status != WebhookStatusDead {
+		s.mu.Unlock()
+		return nil, newConflictError("delivery %s is not dead-lettered", deliveryID)
+	}
+	remaining := []string{}
+	for _, id := range s.deadLetters[customerID] {
+		if id != deliveryID {
+			remaining = append(remaining, id)
+		}
+	}
+	s.deadLetters[customerID] = remaining
+	delivery.Status = WebhookStatusPending
+	delivery.Attempts = []models.WebhookAttempt{}
+	delivery.CompletedAt = nil
+	s.mu.Unlock()
+
+	s.attempt(deliveryID)
+	return s.Delivery(customerID, deliveryID)
+}
+
+// enqueue records a pending delivery of event to subscription
+func (s *WebhookService) enqueue(subscription models.WebhookSubscription, event models.Event) (*models.WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event %s: %v", event.ID, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextDelivery++
+	delivery := &models.WebhookDelivery{
+		ID:             fmt.Sprintf("whd%d", s.nextDelivery),
+		SubscriptionID: subscription.ID,
+		CustomerID:     subscription.CustomerID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		URL:            subscription.URL,
+		Status:         WebhookStatusPending,
+		Attempts:       []models.WebhookAttempt{},
+		Payload:        string(payload),
+		CreatedAt:      time.Now(),
+	}
+	s.deliveries[delivery.ID] = delivery
+
+	// Keep the log bounded, forgetting the oldest deliveries that aren't dead-lettered
+	log := append(s.log[subscription.ID], delivery.ID)
+	if len(log) > maxDeliveriesPerSubscription {
+		for _, id := range log[:len(log)-maxDeliveriesPerSubscription] {
+			if old := s.deliveries[id]; old != nil && old.Status != WebhookStatusDead {
+				delete(s.deliveries, id)
+			}
+		}
+		log = log[len(log)-maxDeliveriesPerSubscription:]
+	}
+	s.log[subscription.ID] = log
+	return delivery, nil
+}
+
+// attempt makes the next delivery attempt and schedules a retry if it fails.
+// The current subscription secret is used, so rotating it applies to retries.
+func (s *WebhookService) attempt(deliveryID string) {
+	s.mu.RLock()
+	delivery, exists := s.deliveries[deliveryID]
+	if !exists {
+		s.mu.RUnlock()
+		return
+	}
+	payload := []byte(delivery.Payload)
+	number := len(delivery.Attempts) + 1
+	secret := ""
+	subscription, subscribed := s.subscriptions[delivery.CustomerID][delivery.SubscriptionID]
+	if subscribed {
+		secret = subscription.Secret
+	}
+	target, eventType := delivery.URL, delivery.EventType
+	s.mu.RUnlock()
+
+	attempt := models.WebhookAttempt{Number: number, At: time.Now()}
+	retryable := true
+	if !subscribed {
+		attempt.Error = "webhook subscription deleted"
+		retryable = false
+	} else {
+		attempt.StatusCode, attempt.Error, retryable = s.post(target, secret, eventType, deliveryID, payload, attempt.At)
+	}
+	attempt.DurationMs = time.Since(attempt.At).Milliseconds()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delivery, exists = s.deliveries[deliveryID]
+	if !exists {
+		return
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.NextAttemptAt = nil
+
+	if attempt.Error == "" {
+		completed := time.Now()
+		delivery.Status = WebhookStatusSucceeded
+		delivery.CompletedAt = &completed
+		return
+	}
+	if retryable && number < s.Retry.MaxAttempts {
+		delay := s.Retry.Backoff(number + 1)
+		next := time.Now().Add(delay)
+		delivery.Status = WebhookStatusRetrying
+		delivery.NextAttemptAt = &next
+		time.AfterFunc(delay, func() { s.attempt(deliveryID) })
+		return
+	}
+
+	completed := time.Now()
+	delivery.Status = WebhookStatusDead
+	delivery.CompletedAt = &completed
+	s.deadLetters[delivery.CustomerID] = append(s.deadLetters[delivery.CustomerID], deliveryID)
+}
+
+// post sends a signed payload and reports the status code, any error, and
+// whether a failure is worth retrying. Network errors, 408, 429 and 5xx
+// responses are retried; other 4xx responses are not.
+func (s *WebhookService) post(target string, secret string, eventType string, deliveryID string, payload []byte, now time.Time) (int, string, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.Client.Timeout+time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Sprintf("failed to create request: %v", err), false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FinSights-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, eventType)
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(secret, now, payload))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Sprintf("request failed: %v", err), true
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, "", false
+	}
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return resp.StatusCode, fmt.Sprintf("endpoint returned status %d", resp.StatusCode), retryable
+}
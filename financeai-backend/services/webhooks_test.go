@@ -0,0 +1,209 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"financeai-backend/models"
+)
+
+// waitForDeadLetters waits for a customer to have count dead-lettered deliveries
+func waitForDeadLetters(t *testing.T, webhooks *WebhookService, customerID string, count int) []models.WebhookDelivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		deadLetters := webhooks.DeadLetters(customerID)
+		if len(deadLetters) >= count {
+			return deadLetters
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s has %d dead letters, want %d", customerID, len(deadLetters), count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebhookSignatureRoundTrip(t *testing.T) {
+	secret := "whsec_0123456789abcdef"
+	payload := []byte(`{"type":"budget.exceeded"}`)
+	signedAt := time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)
+	header := SignWebhookPayload(secret, signedAt, payload)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		payload []byte
+		now     time.Time
+		valid   bool
+	}{
+		{"fresh", secret, header, payload, signedAt.Add(time.Second), true},
+		{"at the tolerance", secret, header, payload, signedAt.Add(WebhookSignatureTolerance), true},
+		{"too old", secret, header, payload, signedAt.Add(WebhookSignatureTolerance + time.Second), false},
+		{"from the future", secret, header, payload, signedAt.Add(-WebhookSignatureTolerance - time.Second), false},
+		{"changed payload", secret, header, []byte(`{"type":"budget.threshold"}`), signedAt, false},
+		{"wrong secret", "whsec_fedcba9876543210", header, payload, signedAt, false},
+		{"no signature", secret, "t=1773576000", payload, signedAt, false},
+		{"no timestamp", secret, "v1=abc", payload, signedAt, false},
+		{"garbage", secret, "not a signature", payload, signedAt, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := VerifyWebhookSignature(test.secret, test.header, test.payload, test.now)
+			if test.valid && err != nil {
+				t.Errorf("VerifyWebhookSignature returned %v, want nil", err)
+			}
+			if !test.valid && err == nil {
+				t.Error("VerifyWebhookSignature accepted the signature")
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 30 * time.Second},
+		{3, time.Minute},
+		{4, 2 * time.Minute},
+		{5, 4 * time.Minute},
+		{6, 8 * time.Minute},
+		{7, 10 * time.Minute},
+		{20, 10 * time.Minute},
+	}
+	for _, test := range tests {
+		if got := DefaultRetryPolicy.Backoff(test.attempt); got != test.want {
+			t.Errorf("Backoff(%d) = %v, want %v", test.attempt, got, test.want)
+		}
+	}
+}
+
+func TestWebhookServiceRetriesToDeadLetter(t *testing.T) {
+	var hits int32
+	var failing atomic.Bool
+	failing.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	webhooks := testWebhookService()
+	url := server.URL
+	events := []string{EventBudgetExceeded}
+	subscription, err := webhooks.Subscribe("sarah", WebhookInput{URL: &url, Events: &events}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhooks.Dispatch(models.Event{ID: "evt1", Type: EventBudgetExceeded, CustomerID: "sarah"})
+
+	deadLetters := waitForDeadLetters(t, webhooks, "sarah", 1)
+	delivery := deadLetters[0]
+	if delivery.Status != WebhookStatusDead || delivery.NextAttemptAt != nil || delivery.CompletedAt == nil {
+		t.Errorf("dead letter = %+v", delivery)
+	}
+	if len(delivery.Attempts) != 3 || atomic.LoadInt32(&hits) != 3 {
+		t.Fatalf("made %d attempts and %d requests, want 3", len(delivery.Attempts), hits)
+	}
+	for _, attempt := range delivery.Attempts {
+		if attempt.StatusCode != http.StatusInternalServerError || attempt.Error == "" {
+			t.Errorf("attempt %d = %+v, want a 500 failure", attempt.Number, attempt)
+		}
+	}
+	if log := webhooks.Deliveries("sarah", subscription.ID, 0); len(log) != 1 || log[0].ID != delivery.ID {
+		t.Errorf("delivery log = %+v, want the dead-lettered delivery", log)
+	}
+
+	// Once the endpoint recovers, replaying sends it again
+	failing.Store(false)
+	replayed, err := webhooks.Replay("sarah", delivery.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Status != WebhookStatusSucceeded || len(replayed.Attempts) != 1 {
+		t.Errorf("replayed delivery = %+v, want one successful attempt", replayed)
+	}
+	if remaining := webhooks.DeadLetters("sarah"); len(remaining) != 0 {
+		t.Errorf("dead letters after replay = %+v, want none", remaining)
+	}
+	if _, err := webhooks.Replay("sarah", delivery.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("replaying a delivered webhook returned %v, want a conflict", err)
+	}
+}
+
+func TestWebhookServiceDoesNotRetryRejections(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	webhooks := testWebhookService()
+	url := server.URL
+	events := []string{eventSubscribeWildcard}
+	if _, err := webhooks.Subscribe("sarah", WebhookInput{URL: &url, Events: &events}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	webhooks.Dispatch(models.Event{ID: "evt1", Type: EventTransactionCreated, CustomerID: "sarah"})
+
+	deadLetters := waitForDeadLetters(t, webhooks, "sarah", 1)
+	if len(deadLetters[0].Attempts) != 1 {
+		t.Errorf("made %d attempts, want 1", len(deadLetters[0].Attempts))
+	}
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("endpoint got %d requests, want 1", hits)
+	}
+}
+
+func TestWebhookServiceNotificationWebhook(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	webhooks := testWebhookService()
+	subscription, err := webhooks.SetNotificationWebhook("sarah", server.URL, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Events go to subscriptions that list them, not the notification webhook
+	webhooks.Dispatch(models.Event{ID: "evt1", Type: EventBudgetExceeded, CustomerID: "sarah"})
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Error("the notification webhook received a dispatched event")
+	}
+
+	// Its URL and events follow the notification preferences
+	url := "https://example.com/elsewhere"
+	if _, err := webhooks.Update("sarah", subscription.ID, WebhookInput{URL: &url}); !errors.Is(err, ErrValidation) {
+		t.Errorf("changing the URL returned %v, want a validation error", err)
+	}
+	events := []string{EventBudgetExceeded}
+	if _, err := webhooks.Update("sarah", subscription.ID, WebhookInput{Events: &events}); !errors.Is(err, ErrValidation) {
+		t.Errorf("changing the events returned %v, want a validation error", err)
+	}
+	if err := webhooks.Unsubscribe("sarah", subscription.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("deleting it returned %v, want a conflict", err)
+	}
+
+	// Rotating its secret works like any other subscription
+	empty := ""
+	rotated, err := webhooks.Update("sarah", subscription.ID, WebhookInput{Secret: &empty})
+	if err != nil || rotated.Secret == "" || rotated.Secret == subscription.Secret {
+		t.Errorf("rotating the secret returned %+v, %v", rotated, err)
+	}
+}